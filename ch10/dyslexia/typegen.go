@@ -8,6 +8,8 @@ import (
 
 var _ = types.AddType(&types.Type{Name: "main.LesionTypes", IDName: "lesion-types", Doc: "LesionTypes is the type of lesion"})
 
-var _ = types.AddType(&types.Type{Name: "main.Config", IDName: "config", Doc: "Config has config parameters related to running the sim", Fields: []types.Field{{Name: "NRuns", Doc: "total number of runs to do when running Train"}, {Name: "NEpochs", Doc: "total number of epochs per run"}, {Name: "NZero", Doc: "stop run after this number of perfect, zero-error epochs."}, {Name: "TestInterval", Doc: "how often to run through all the test patterns, in terms of training epochs.\ncan use 0 or -1 for no testing."}}})
+var _ = types.AddType(&types.Type{Name: "main.Config", IDName: "config", Doc: "Config has config parameters related to running the sim", Fields: []types.Field{{Name: "NRuns", Doc: "total number of runs to do when running Train"}, {Name: "NEpochs", Doc: "total number of epochs per run"}, {Name: "NZero", Doc: "stop run after this number of perfect, zero-error epochs."}, {Name: "TestInterval", Doc: "how often to run through all the test patterns, in terms of training epochs.\ncan use 0 or -1 for no testing."}, {Name: "GUI", Doc: "GUI means open the GUI. Otherwise it runs automatically and quits,\nsaving log files as specified in Log."}, {Name: "Run", Doc: "starting run number, which determines the random seed.\nruns count up from there, so all runs can be done in parallel\nby launching separate jobs with each starting Run and NRuns = 1."}, {Name: "RunConfig", Doc: "RunConfig has the config parameters for running without the GUI:\nSweep, Parallel, Checkpoint, Resume, Summary, Figures and Export."}, {Name: "Log", Doc: "Log has config parameters related to logging data."}}})
 
-var _ = types.AddType(&types.Type{Name: "main.Sim", IDName: "sim", Doc: "Sim encapsulates the entire simulation model, and we define all the\nfunctionality as methods on this struct.  This structure keeps all relevant\nstate information organized and available without having to pass everything around\nas arguments to methods, and provides the core GUI interface (note the view tags\nfor the fields which provide hints to how things should be displayed).", Methods: []types.Method{{Name: "LesionNet", Doc: "LesionNet does lesion of network with given proportion of neurons damaged\n0 < prop < 1.", Directives: []types.Directive{{Tool: "types", Directive: "add"}}, Args: []string{"les", "prop"}}}, Fields: []types.Field{{Name: "Lesion", Doc: "type of lesion -- use Lesion button to lesion"}, {Name: "LesionProp", Doc: "proportion of neurons lesioned -- use Lesion button to lesion"}, {Name: "Config", Doc: "Config contains misc configuration parameters for running the sim"}, {Name: "Net", Doc: "the network -- click to view / edit parameters for layers, paths, etc"}, {Name: "Params", Doc: "network parameter management"}, {Name: "Train", Doc: "training patterns"}, {Name: "Semantics", Doc: "properties of semnatic features"}, {Name: "CloseOrthos", Doc: "close orthography outputs"}, {Name: "CloseSems", Doc: "close semantic outputs"}, {Name: "Loops", Doc: "contains looper control loops for running sim"}, {Name: "Stats", Doc: "contains computed statistic values"}, {Name: "Logs", Doc: "Contains all the logs and information about the logs.'"}, {Name: "Envs", Doc: "Environments"}, {Name: "Context", Doc: "leabra timing parameters and state"}, {Name: "ViewUpdate", Doc: "netview update parameters"}, {Name: "GUI", Doc: "manages all the gui elements"}, {Name: "RandSeeds", Doc: "a list of random seeds to use for each run"}}})
+var _ = types.AddType(&types.Type{Name: "main.LogConfig", IDName: "log-config", Doc: "LogConfig has config parameters related to logging data", Fields: []types.Field{{Name: "SaveWeights", Doc: "if true, save final weights after each run"}, {Name: "Epoch", Doc: "if true, save train epoch log to file, as .epc.tsv typically"}, {Name: "Run", Doc: "if true, save run log to file, as .run.tsv typically"}, {Name: "Trial", Doc: "if true, save train trial log to file, as .trl.tsv typically. May be large."}, {Name: "TestEpoch", Doc: "if true, save testing epoch log to file, as .tst_epc.tsv typically.  In general it is better to copy testing items over to the training epoch log and record there."}, {Name: "TestTrial", Doc: "if true, save testing trial log to file, as .tst_trl.tsv typically. May be large."}}})
+
+var _ = types.AddType(&types.Type{Name: "main.Sim", IDName: "sim", Doc: "Sim encapsulates the entire simulation model, and we define all the\nfunctionality as methods on this struct.  This structure keeps all relevant\nstate information organized and available without having to pass everything around\nas arguments to methods, and provides the core GUI interface (note the view tags\nfor the fields which provide hints to how things should be displayed).", Methods: []types.Method{{Name: "LesionNet", Doc: "LesionNet does lesion of network with given proportion of neurons damaged\n0 < prop < 1.", Directives: []types.Directive{{Tool: "types", Directive: "add"}}, Args: []string{"les", "prop"}}}, Fields: []types.Field{{Name: "Lesion", Doc: "type of lesion -- use Lesion button to lesion"}, {Name: "LesionProp", Doc: "proportion of neurons lesioned -- use Lesion button to lesion"}, {Name: "Config", Doc: "Config contains misc configuration parameters for running the sim"}, {Name: "Net", Doc: "the network -- click to view / edit parameters for layers, paths, etc"}, {Name: "Params", Doc: "network parameter management"}, {Name: "Train", Doc: "training patterns"}, {Name: "Semantics", Doc: "properties of semnatic features"}, {Name: "CloseOrthos", Doc: "close orthography outputs"}, {Name: "CloseSems", Doc: "close semantic outputs"}, {Name: "Loops", Doc: "contains looper control loops for running sim"}, {Name: "Stats", Doc: "contains computed statistic values"}, {Name: "Logs", Doc: "Contains all the logs and information about the logs.'"}, {Name: "Envs", Doc: "Environments"}, {Name: "Context", Doc: "leabra timing parameters and state"}, {Name: "ViewUpdate", Doc: "netview update parameters"}, {Name: "GUI", Doc: "manages all the gui elements"}, {Name: "Figures", Doc: "the plots, grids and network snapshots that can be saved without the GUI"}, {Name: "RandSeeds", Doc: "a list of random seeds to use for each run"}}})
@@ -25,6 +25,7 @@ import (
 	"cogentcore.org/core/tensor"
 	"cogentcore.org/core/tensor/stats/metric"
 	"cogentcore.org/core/tree"
+	"github.com/CompCogNeuro/sims/v2/simcore"
 	"github.com/emer/emergent/v2/econfig"
 	"github.com/emer/emergent/v2/egui"
 	"github.com/emer/emergent/v2/elog"
@@ -46,7 +47,11 @@ func main() {
 	sim := &Sim{}
 	sim.New()
 	sim.ConfigAll()
-	sim.RunGUI()
+	if sim.Config.GUI {
+		sim.RunGUI()
+	} else {
+		sim.RunNoGUI()
+	}
 }
 
 // ParamSets is the default set of parameters.
@@ -97,6 +102,44 @@ type Config struct {
 
 	// total number of epochs per run
 	NEpochs int `default:"50"`
+
+	// GUI means open the GUI. Otherwise it runs automatically and quits,
+	// saving log files as specified in Log.
+	GUI bool `default:"true"`
+
+	// starting run number, which determines the random seed.
+	// runs count up from there, so all runs can be done in parallel
+	// by launching separate jobs with each starting Run and NRuns = 1.
+	Run int `default:"0"`
+
+	// RunConfig has the config parameters for running without the GUI:
+	// Sweep, Parallel, Checkpoint, Resume, Summary, Figures and Export.
+	simcore.RunConfig `display:"add-fields"`
+
+	// Log has config parameters related to logging data.
+	Log LogConfig `display:"add-fields"`
+}
+
+// LogConfig has config parameters related to logging data
+type LogConfig struct {
+
+	// if true, save final weights after each run
+	SaveWeights bool
+
+	// if true, save train epoch log to file, as .epc.tsv typically
+	Epoch bool `default:"true" nest:"+"`
+
+	// if true, save run log to file, as .run.tsv typically
+	Run bool `default:"true" nest:"+"`
+
+	// if true, save train trial log to file, as .trl.tsv typically. May be large.
+	Trial bool `default:"false" nest:"+"`
+
+	// if true, save testing epoch log to file, as .tst_epc.tsv typically.  In general it is better to copy testing items over to the training epoch log and record there.
+	TestEpoch bool `default:"false" nest:"+"`
+
+	// if true, save testing trial log to file, as .tst_trl.tsv typically. May be large.
+	TestTrial bool `default:"false" nest:"+"`
 }
 
 // Sim encapsulates the entire simulation model, and we define all the
@@ -153,6 +196,9 @@ type Sim struct {
 	// manages all the gui elements
 	GUI egui.GUI `display:"-"`
 
+	// the plots, grids and network snapshots that can be saved without the GUI
+	Figures simcore.Figures `display:"-"`
+
 	// a list of random seeds to use for each run
 	RandSeeds randx.Seeds `display:"-"`
 }
@@ -280,7 +326,9 @@ func (ss *Sim) ApplyParams() {
 // Init restarts the run, and initializes everything, including network weights
 // and resets the epoch log table
 func (ss *Sim) Init() {
-	ss.Stats.SetString("RunName", ss.Params.RunName(0)) // in case user interactively changes tag
+	if ss.Config.GUI {
+		ss.Stats.SetString("RunName", ss.Params.RunName(0)) // in case user interactively changes tag
+	}
 	ss.Loops.ResetCounters()
 	ss.InitRandSeed(0)
 	ss.ConfigEnv() // re-config env just in case a different set of patterns was
@@ -293,8 +341,7 @@ func (ss *Sim) Init() {
 
 // InitRandSeed initializes the random seed based on current training run number
 func (ss *Sim) InitRandSeed(run int) {
-	ss.RandSeeds.Set(run)
-	ss.RandSeeds.Set(run, &ss.Net.Rand)
+	simcore.InitRandSeed(&ss.RandSeeds, ss.Net, run)
 }
 
 // ConfigLoops configures the control loops: Training, Testing
@@ -332,6 +379,7 @@ func (ss *Sim) ConfigLoops() {
 	}
 
 	ls.Loop(etime.Train, etime.Run).OnStart.Add("NewRun", ss.NewRun)
+	simcore.SeedEpochs(ls, &ss.RandSeeds, ss.Net)
 
 	/////////////////////////////////////////////
 	// Logging
@@ -341,13 +389,21 @@ func (ss *Sim) ConfigLoops() {
 	})
 	leabra.LooperResetLogBelow(ls, &ss.Logs)
 
+	// Save weights to file, to look at later
+	ls.Loop(etime.Train, etime.Run).OnEnd.Add("SaveWeights", func() {
+		ctrString := ss.Stats.PrintValues([]string{"Run", "Epoch"}, []string{"%03d", "%05d"}, "_")
+		leabra.SaveWeightsIfConfigSet(ss.Net, ss.Config.Log.SaveWeights, ctrString, ss.Stats.String("RunName"))
+	})
+
 	////////////////////////////////////////////
 	// GUI
 
-	leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
-	leabra.LooperUpdatePlots(ls, &ss.GUI)
-	ls.Stacks[etime.Train].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
-	ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	if ss.Config.GUI {
+		leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
+		leabra.LooperUpdatePlots(ls, &ss.GUI)
+		ls.Stacks[etime.Train].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+		ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	}
 
 	ss.Loops = ls
 }
@@ -527,15 +583,7 @@ func (ss *Sim) InitStats() {
 // StatCounters saves current counters to Stats, so they are available for logging etc
 // Also saves a string rep of them for ViewUpdate.Text
 func (ss *Sim) StatCounters() {
-	ctx := &ss.Context
-	mode := ctx.Mode
-	ss.Loops.Stacks[mode].CountersToStats(&ss.Stats)
-	// always use training epoch..
-	trnEpc := ss.Loops.Stacks[etime.Train].Loops[etime.Epoch].Counter.Cur
-	ss.Stats.SetInt("Epoch", trnEpc)
-	trl := ss.Stats.Int("Trial")
-	ss.Stats.SetInt("Trial", trl)
-	ss.Stats.SetInt("Cycle", int(ctx.Cycle))
+	simcore.StatCounters(ss.Loops, &ss.Context, &ss.Stats, true) // always use training epoch
 }
 
 func (ss *Sim) NetViewCounters(tm etime.Times) {
@@ -672,13 +720,18 @@ func (ss *Sim) ConfigGUI() {
 	nv.SceneXYZ().Camera.Pose.Pos.Set(0, 1.73, 2.3)
 	nv.SceneXYZ().Camera.LookAt(math32.Vec3(0, 0, 0), math32.Vec3(0, 1, 0))
 
-	ss.GUI.AddPlots(title, &ss.Logs)
+	ss.ConfigPlots(simcore.GUIPlots{GUI: &ss.GUI})
 
 	ss.GUI.AddTableView(&ss.Logs, etime.Validate, etime.Epoch)
 
 	ss.GUI.FinalizeGUI(false)
 }
 
+// ConfigPlots adds the log plots to the GUI or the Figures.
+func (ss *Sim) ConfigPlots(pl simcore.Plots) {
+	pl.AddLogPlots("Semantics", &ss.Logs)
+}
+
 func (ss *Sim) MakeToolbar(p *tree.Plan) {
 	ss.GUI.AddLooperCtrl(p, ss.Loops)
 
@@ -735,3 +788,16 @@ func (ss *Sim) RunGUI() {
 	ss.ConfigGUI()
 	ss.GUI.Body.RunMainWindow()
 }
+
+func (ss *Sim) RunNoGUI() {
+	ss.Runner().RunNoGUI()
+}
+
+// Runner returns the simcore.Runner that runs the sim without the GUI.
+func (ss *Sim) Runner() *simcore.Runner {
+	return &simcore.Runner{Sim: ss, NetName: ss.Net.Name, RunName: ss.Params.RunName,
+		Fields: []string{"ExcitLateralScale", "InhibLateralScale", "ExcitLateralLearn", "WtWordsThr"},
+		Config: &ss.Config.RunConfig, Run: &ss.Config.Run, NRuns: &ss.Config.NRuns, NEpochs: &ss.Config.NEpochs, Log: &ss.Config.Log,
+		Net: ss.Net, Context: &ss.Context, Loops: ss.Loops, Envs: ss.Envs, Stats: &ss.Stats, Logs: &ss.Logs, Seeds: &ss.RandSeeds,
+		Figures: &ss.Figures, Init: ss.Init, ConfigPlots: ss.ConfigPlots}
+}
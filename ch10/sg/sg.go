@@ -28,6 +28,7 @@ import (
 	"cogentcore.org/core/tensor/stats/clust"
 	"cogentcore.org/core/tensor/table"
 	"cogentcore.org/core/tree"
+	"github.com/CompCogNeuro/sims/v2/simcore"
 	"github.com/emer/emergent/v2/econfig"
 	"github.com/emer/emergent/v2/egui"
 	"github.com/emer/emergent/v2/elog"
@@ -49,7 +50,11 @@ func main() {
 	sim := &Sim{}
 	sim.New()
 	sim.ConfigAll()
-	sim.RunGUI()
+	if sim.Config.GUI {
+		sim.RunGUI()
+	} else {
+		sim.RunNoGUI()
+	}
 }
 
 // ParamSets is the default set of parameters.
@@ -167,6 +172,44 @@ type Config struct {
 	// how often to run through all the test patterns, in terms of training epochs.
 	// can use 0 or -1 for no testing.
 	TestInterval int `default:"-1"`
+
+	// GUI means open the GUI. Otherwise it runs automatically and quits,
+	// saving log files as specified in Log.
+	GUI bool `default:"true"`
+
+	// starting run number, which determines the random seed.
+	// runs count up from there, so all runs can be done in parallel
+	// by launching separate jobs with each starting Run and NRuns = 1.
+	Run int `default:"0"`
+
+	// RunConfig has the config parameters for running without the GUI:
+	// Sweep, Parallel, Checkpoint, Resume, Summary, Figures and Export.
+	simcore.RunConfig `display:"add-fields"`
+
+	// Log has config parameters related to logging data.
+	Log LogConfig `display:"add-fields"`
+}
+
+// LogConfig has config parameters related to logging data
+type LogConfig struct {
+
+	// if true, save final weights after each run
+	SaveWeights bool
+
+	// if true, save train epoch log to file, as .epc.tsv typically
+	Epoch bool `default:"true" nest:"+"`
+
+	// if true, save run log to file, as .run.tsv typically
+	Run bool `default:"true" nest:"+"`
+
+	// if true, save train trial log to file, as .trl.tsv typically. May be large.
+	Trial bool `default:"false" nest:"+"`
+
+	// if true, save testing epoch log to file, as .tst_epc.tsv typically.  In general it is better to copy testing items over to the training epoch log and record there.
+	TestEpoch bool `default:"false" nest:"+"`
+
+	// if true, save testing trial log to file, as .tst_trl.tsv typically. May be large.
+	TestTrial bool `default:"false" nest:"+"`
 }
 
 // Sim encapsulates the entire simulation model, and we define all the
@@ -206,6 +249,9 @@ type Sim struct {
 	// manages all the gui elements
 	GUI egui.GUI `display:"-"`
 
+	// the plots, grids and network snapshots that can be saved without the GUI
+	Figures simcore.Figures `display:"-"`
+
 	// a list of random seeds to use for each run
 	RandSeeds randx.Seeds `display:"-"`
 }
@@ -414,7 +460,9 @@ func (ss *Sim) ApplyParams() {
 // Init restarts the run, and initializes everything, including network weights
 // and resets the epoch log table
 func (ss *Sim) Init() {
-	ss.Stats.SetString("RunName", ss.Params.RunName(0)) // in case user interactively changes tag
+	if ss.Config.GUI {
+		ss.Stats.SetString("RunName", ss.Params.RunName(0)) // in case user interactively changes tag
+	}
 	ss.Loops.ResetCounters()
 	ss.InitRandSeed(0)
 	ss.ConfigEnv() // re-config env just in case a different set of patterns was
@@ -427,8 +475,7 @@ func (ss *Sim) Init() {
 
 // InitRandSeed initializes the random seed based on current training run number
 func (ss *Sim) InitRandSeed(run int) {
-	ss.RandSeeds.Set(run)
-	ss.RandSeeds.Set(run, &ss.Net.Rand)
+	simcore.InitRandSeed(&ss.RandSeeds, ss.Net, run)
 }
 
 func (ss *Sim) TestInit() {
@@ -477,6 +524,7 @@ func (ss *Sim) ConfigLoops() {
 	}
 
 	ls.Loop(etime.Train, etime.Run).OnStart.Add("NewRun", ss.NewRun)
+	simcore.SeedEpochs(ls, &ss.RandSeeds, ss.Net)
 
 	// Train stop early condition
 	ls.Loop(etime.Train, etime.Epoch).IsDone.AddBool("NZeroStop", func() bool {
@@ -513,13 +561,21 @@ func (ss *Sim) ConfigLoops() {
 		ss.Logs.RunStats("PctCor", "FirstZero", "LastZero")
 	})
 
+	// Save weights to file, to look at later
+	ls.Loop(etime.Train, etime.Run).OnEnd.Add("SaveWeights", func() {
+		ctrString := ss.Stats.PrintValues([]string{"Run", "Epoch"}, []string{"%03d", "%05d"}, "_")
+		leabra.SaveWeightsIfConfigSet(ss.Net, ss.Config.Log.SaveWeights, ctrString, ss.Stats.String("RunName"))
+	})
+
 	////////////////////////////////////////////
 	// GUI
 
-	leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
-	leabra.LooperUpdatePlots(ls, &ss.GUI)
-	ls.Stacks[etime.Train].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
-	ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	if ss.Config.GUI {
+		leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
+		leabra.LooperUpdatePlots(ls, &ss.GUI)
+		ls.Stacks[etime.Train].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+		ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	}
 
 	ss.Loops = ls
 }
@@ -572,17 +628,7 @@ func (ss *Sim) ApplyInputs() {
 // NewRun intializes a new run of the model, using the TrainEnv.Run counter
 // for the new run value
 func (ss *Sim) NewRun() {
-	ctx := &ss.Context
-	ss.InitRandSeed(ss.Loops.Loop(etime.Train, etime.Run).Counter.Cur)
-	ss.Envs.ByMode(etime.Train).Init(0)
-	ss.Envs.ByMode(etime.Test).Init(0)
-	ctx.Reset()
-	ctx.Mode = etime.Train
-	ss.Net.InitWeights()
-	ss.InitStats()
-	ss.StatCounters()
-	ss.Logs.ResetLog(etime.Train, etime.Epoch)
-	ss.Logs.ResetLog(etime.Test, etime.Epoch)
+	simcore.NewRun(ss.Loops, &ss.Context, ss.Net, ss.Envs, &ss.Logs, &ss.RandSeeds, simcore.Hooks{InitStats: ss.InitStats, StatCounters: ss.StatCounters})
 }
 
 // TestAll runs through the full set of testing items
@@ -608,14 +654,14 @@ func (ss *Sim) ProbeAll() {
 
 	trl := ss.Logs.Log(etime.Analyze, etime.Trial)
 	stix := table.NewIndexView(trl)
-	estats.ClusterPlot(ss.GUI.PlotByName("NounClust"), stix, "Gestalt_Act", "TrialName", clust.MaxDist)
+	estats.ClusterPlot(ss.Figures.PlotByName(&ss.GUI, "NounClust"), stix, "Gestalt_Act", "TrialName", clust.MaxDist)
 
 	trl = ss.Logs.Log(etime.Validate, etime.Trial)
 	stix = table.NewIndexView(trl)
 	stix.Filter(func(et *table.Table, row int) bool {
 		return et.Float("Tick", row) == 5 // last of each sequence
 	})
-	estats.ClusterPlot(ss.GUI.PlotByName("SentClust"), stix, "GestaltCT_Act", "SentType", clust.ContrastDist)
+	estats.ClusterPlot(ss.Figures.PlotByName(&ss.GUI, "SentClust"), stix, "GestaltCT_Act", "SentType", clust.ContrastDist)
 }
 
 ////////////////////////////////////////////////////////////////////////
@@ -769,26 +815,7 @@ func (ss *Sim) ConfigLogs() {
 
 // Log is the main logging function, handles special things for different scopes
 func (ss *Sim) Log(mode etime.Modes, time etime.Times) {
-	ctx := &ss.Context
-	if mode != etime.Analyze {
-		ctx.Mode = mode // Also set specifically in a Loop callback.
-	}
-	dt := ss.Logs.Table(mode, time)
-	if dt == nil {
-		return
-	}
-	row := dt.Rows
-
-	switch {
-	case time == etime.Cycle:
-		return
-	case time == etime.Trial:
-		ss.TrialStats()
-		ss.StatCounters()
-	}
-
-	ss.Logs.LogRow(mode, time, row) // also logs to file, etc
-
+	simcore.Log(&ss.Context, &ss.Logs, mode, time, simcore.Hooks{TrialStats: ss.TrialStats, StatCounters: ss.StatCounters})
 	if mode == etime.Test {
 		ss.GUI.UpdateTableView(etime.Test, etime.Trial)
 	}
@@ -815,16 +842,23 @@ func (ss *Sim) ConfigGUI() {
 	nv.SceneXYZ().Camera.Pose.Pos.Set(0, 1.3, 2.6) // more "head on" than default which is more "top down"
 	nv.SceneXYZ().Camera.LookAt(math32.Vec3(0, 0, 0), math32.Vec3(0, 1, 0))
 
-	ss.GUI.AddPlots(title, &ss.Logs)
+	ss.ConfigPlots(simcore.GUIPlots{GUI: &ss.GUI})
 
 	ss.GUI.AddTableView(&ss.Logs, etime.Test, etime.Trial)
 
-	ss.GUI.AddMiscPlotTab("SentClust")
-	ss.GUI.AddMiscPlotTab("NounClust")
-
 	ss.GUI.FinalizeGUI(false)
 }
 
+// ConfigPlots adds the log plots, and the cluster plots of the
+// sentence and noun representations made by ProbeAll, to the GUI
+// or the Figures.
+func (ss *Sim) ConfigPlots(pl simcore.Plots) {
+	pl.AddLogPlots("Sentence Gestalt", &ss.Logs)
+
+	pl.AddPlot("SentClust")
+	pl.AddPlot("NounClust")
+}
+
 func (ss *Sim) MakeToolbar(p *tree.Plan) {
 	ss.GUI.AddLooperCtrl(p, ss.Loops)
 
@@ -872,3 +906,15 @@ func (ss *Sim) RunGUI() {
 	ss.ConfigGUI()
 	ss.GUI.Body.RunMainWindow()
 }
+
+func (ss *Sim) RunNoGUI() {
+	ss.Runner().RunNoGUI()
+}
+
+// Runner returns the simcore.Runner that runs the sim without the GUI.
+func (ss *Sim) Runner() *simcore.Runner {
+	return &simcore.Runner{Sim: ss, NetName: ss.Net.Name, RunName: ss.Params.RunName,
+		Config: &ss.Config.RunConfig, Run: &ss.Config.Run, NRuns: &ss.Config.NRuns, NEpochs: &ss.Config.NEpochs, Log: &ss.Config.Log,
+		Net: ss.Net, Context: &ss.Context, Loops: ss.Loops, Envs: ss.Envs, Stats: &ss.Stats, Logs: &ss.Logs, Seeds: &ss.RandSeeds,
+		Figures: &ss.Figures, Init: ss.Init, ConfigPlots: ss.ConfigPlots, Analyze: ss.ProbeAll}
+}
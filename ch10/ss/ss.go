@@ -26,6 +26,7 @@ import (
 	"cogentcore.org/core/tensor/table"
 	"cogentcore.org/core/tensor/tensorcore"
 	"cogentcore.org/core/tree"
+	"github.com/CompCogNeuro/sims/v2/simcore"
 	"github.com/emer/emergent/v2/econfig"
 	"github.com/emer/emergent/v2/egui"
 	"github.com/emer/emergent/v2/elog"
@@ -41,6 +42,9 @@ import (
 	"github.com/emer/leabra/v2/leabra"
 )
 
+// train_pats.tsv is not checked in to the repository, so this sim cannot
+// be built without it, and it has no golden test (see simcore/simtest/gentests).
+//
 //go:embed train_pats.tsv probe.tsv besner.tsv glushko.tsv taraban.tsv phon_cons.tsv phon_vowel.tsv trained.wts.gz
 var content embed.FS
 
@@ -58,7 +62,11 @@ func main() {
 	sim := &Sim{}
 	sim.New()
 	sim.ConfigAll()
-	sim.RunGUI()
+	if sim.Config.GUI {
+		sim.RunGUI()
+	} else {
+		sim.RunNoGUI()
+	}
 }
 
 // ParamSets is the default set of parameters.
@@ -131,6 +139,44 @@ type Config struct {
 
 	// RTThreshold is the threshold for change in max activity level from once cycle to the next
 	RTThreshold float32 `default:"0.000001"`
+
+	// GUI means open the GUI. Otherwise it runs automatically and quits,
+	// saving log files as specified in Log.
+	GUI bool `default:"true"`
+
+	// starting run number, which determines the random seed.
+	// runs count up from there, so all runs can be done in parallel
+	// by launching separate jobs with each starting Run and NRuns = 1.
+	Run int `default:"0"`
+
+	// RunConfig has the config parameters for running without the GUI:
+	// Sweep, Parallel, Checkpoint, Resume, Summary, Figures and Export.
+	simcore.RunConfig `display:"add-fields"`
+
+	// Log has config parameters related to logging data.
+	Log LogConfig `display:"add-fields"`
+}
+
+// LogConfig has config parameters related to logging data
+type LogConfig struct {
+
+	// if true, save final weights after each run
+	SaveWeights bool
+
+	// if true, save train epoch log to file, as .epc.tsv typically
+	Epoch bool `default:"true" nest:"+"`
+
+	// if true, save run log to file, as .run.tsv typically
+	Run bool `default:"true" nest:"+"`
+
+	// if true, save train trial log to file, as .trl.tsv typically. May be large.
+	Trial bool `default:"false" nest:"+"`
+
+	// if true, save testing epoch log to file, as .tst_epc.tsv typically.  In general it is better to copy testing items over to the training epoch log and record there.
+	TestEpoch bool `default:"false" nest:"+"`
+
+	// if true, save testing trial log to file, as .tst_trl.tsv typically. May be large.
+	TestTrial bool `default:"false" nest:"+"`
 }
 
 // Sim encapsulates the entire simulation model, and we define all the
@@ -193,6 +239,9 @@ type Sim struct {
 	// manages all the gui elements
 	GUI egui.GUI `display:"-"`
 
+	// the plots, grids and network snapshots that can be saved without the GUI
+	Figures simcore.Figures `display:"-"`
+
 	// a list of random seeds to use for each run
 	RandSeeds randx.Seeds `display:"-"`
 }
@@ -229,15 +278,7 @@ func (ss *Sim) ConfigAll() {
 
 // OpenPatAsset opens pattern file from embedded assets
 func (ss *Sim) OpenPatAsset(dt *table.Table, fnm, name, desc string) error {
-	dt.SetMetaData("name", name)
-	dt.SetMetaData("desc", desc)
-	err := dt.OpenFS(content, fnm, table.Tab)
-	if errors.Log(err) == nil {
-		for i := 1; i < dt.NumColumns(); i++ {
-			dt.Columns[i].SetMetaData("grid-fill", "0.9")
-		}
-	}
-	return err
+	return simcore.OpenPatAsset(content, dt, fnm, name, desc)
 }
 
 func (ss *Sim) OpenPatterns() {
@@ -343,7 +384,9 @@ func (ss *Sim) ApplyParams() {
 // Init restarts the run, and initializes everything, including network weights
 // and resets the epoch log table
 func (ss *Sim) Init() {
-	ss.Stats.SetString("RunName", ss.Params.RunName(0)) // in case user interactively changes tag
+	if ss.Config.GUI {
+		ss.Stats.SetString("RunName", ss.Params.RunName(0)) // in case user interactively changes tag
+	}
 	ss.Loops.ResetCounters()
 	ss.InitRandSeed(0)
 	// ss.ConfigEnv() // re-config env just in case a different set of patterns was
@@ -357,8 +400,7 @@ func (ss *Sim) Init() {
 
 // InitRandSeed initializes the random seed based on current training run number
 func (ss *Sim) InitRandSeed(run int) {
-	ss.RandSeeds.Set(run)
-	ss.RandSeeds.Set(run, &ss.Net.Rand)
+	simcore.InitRandSeed(&ss.RandSeeds, ss.Net, run)
 }
 
 func (ss *Sim) TestInit() {
@@ -395,6 +437,7 @@ func (ss *Sim) ConfigLoops() {
 	}
 
 	ls.Loop(etime.Train, etime.Run).OnStart.Add("NewRun", ss.NewRun)
+	simcore.SeedEpochs(ls, &ss.RandSeeds, ss.Net)
 
 	// Add Testing
 	trainEpoch := ls.Loop(etime.Train, etime.Epoch)
@@ -417,13 +460,21 @@ func (ss *Sim) ConfigLoops() {
 		ss.Logs.RunStats("PctCor", "FirstZero", "LastZero")
 	})
 
+	// Save weights to file, to look at later
+	ls.Loop(etime.Train, etime.Run).OnEnd.Add("SaveWeights", func() {
+		ctrString := ss.Stats.PrintValues([]string{"Run", "Epoch"}, []string{"%03d", "%05d"}, "_")
+		leabra.SaveWeightsIfConfigSet(ss.Net, ss.Config.Log.SaveWeights, ctrString, ss.Stats.String("RunName"))
+	})
+
 	////////////////////////////////////////////
 	// GUI
 
-	leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
-	leabra.LooperUpdatePlots(ls, &ss.GUI)
-	ls.Stacks[etime.Train].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
-	ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	if ss.Config.GUI {
+		leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
+		leabra.LooperUpdatePlots(ls, &ss.GUI)
+		ls.Stacks[etime.Train].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+		ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	}
 
 	ss.Loops = ls
 }
@@ -460,17 +511,7 @@ func (ss *Sim) ApplyInputs() {
 // NewRun intializes a new run of the model, using the TrainEnv.Run counter
 // for the new run value
 func (ss *Sim) NewRun() {
-	ctx := &ss.Context
-	ss.InitRandSeed(ss.Loops.Loop(etime.Train, etime.Run).Counter.Cur)
-	ss.Envs.ByMode(etime.Train).Init(0)
-	ss.Envs.ByMode(etime.Test).Init(0)
-	ctx.Reset()
-	ctx.Mode = etime.Train
-	ss.Net.InitWeights()
-	ss.InitStats()
-	ss.StatCounters()
-	ss.Logs.ResetLog(etime.Train, etime.Epoch)
-	ss.Logs.ResetLog(etime.Test, etime.Epoch)
+	simcore.NewRun(ss.Loops, &ss.Context, ss.Net, ss.Envs, &ss.Logs, &ss.RandSeeds, simcore.Hooks{InitStats: ss.InitStats, StatCounters: ss.StatCounters})
 }
 
 // TestAll runs through the full set of testing items
@@ -480,7 +521,9 @@ func (ss *Sim) TestAll() {
 	ss.Loops.ResetAndRun(etime.Test)
 	ss.Loops.Mode = etime.Train // Important to reset Mode back to Train because this is called from within the Train Run.
 	ss.Stats.ActRFsAvgNorm()
-	ss.GUI.ViewActRFs(&ss.Stats.ActRFs)
+	if ss.Config.GUI {
+		ss.GUI.ViewActRFs(&ss.Stats.ActRFs)
+	}
 
 }
 
@@ -511,15 +554,7 @@ func (ss *Sim) InitStats() {
 // StatCounters saves current counters to Stats, so they are available for logging etc
 // Also saves a string rep of them for ViewUpdate.Text
 func (ss *Sim) StatCounters() {
-	ctx := &ss.Context
-	mode := ctx.Mode
-	ss.Loops.Stacks[mode].CountersToStats(&ss.Stats)
-	// always use training epoch..
-	trnEpc := ss.Loops.Stacks[etime.Train].Loops[etime.Epoch].Counter.Cur
-	ss.Stats.SetInt("Epoch", trnEpc)
-	trl := ss.Stats.Int("Trial")
-	ss.Stats.SetInt("Trial", trl)
-	ss.Stats.SetInt("Cycle", int(ctx.Cycle))
+	simcore.StatCounters(ss.Loops, &ss.Context, &ss.Stats, true) // always use training epoch
 }
 
 func (ss *Sim) NetViewCounters(tm etime.Times) {
@@ -601,9 +636,10 @@ func (ss *Sim) TestEpochStats() {
 	})
 	at := allerr.NewTable()
 	ss.Logs.MiscTables["Errors"] = at
-	tv := ss.GUI.TableViews[etime.ScopeKey("Errors")]
-	tv.SetTable(at)
-	tv.AsyncUpdateTable()
+	if tv := ss.GUI.TableViews[etime.ScopeKey("Errors")]; tv != nil {
+		tv.SetTable(at)
+		tv.AsyncUpdateTable()
+	}
 
 	rtspl := split.GroupBy(tix, "Type")
 	split.AggColumn(rtspl, "RT", stats.Mean)
@@ -611,7 +647,7 @@ func (ss *Sim) TestEpochStats() {
 	rt := rtspl.AggsToTable(table.AddAggName)
 	ss.Logs.MiscTables["RT"] = rt
 
-	plt := ss.GUI.Plots[etime.ScopeKey("RT")]
+	plt := ss.Figures.PlotByName(&ss.GUI, "RT")
 	rt.SetMetaData("XAxis", "Type")
 	rt.SetMetaData("Type", "Bar")
 	rt.SetMetaData("RT:Mean:On", "+")
@@ -619,8 +655,12 @@ func (ss *Sim) TestEpochStats() {
 	rt.SetMetaData("RT:Mean:Min", "0")
 	rt.SetMetaData("RT:Mean:ErrColumn", "RT:Sem")
 
-	plt.SetTable(rt)
-	plt.GoUpdatePlot()
+	if plt != nil {
+		plt.SetTable(rt)
+		if ss.GUI.Active {
+			plt.GoUpdatePlot()
+		}
+	}
 }
 
 //////////////////////////////////////////////////////////////////////////////
@@ -711,7 +751,7 @@ func (ss *Sim) ConfigGUI() {
 
 	ss.GUI.ViewUpdate = &ss.ViewUpdate
 
-	ss.GUI.AddPlots(title, &ss.Logs)
+	ss.ConfigPlots(simcore.GUIPlots{GUI: &ss.GUI, Tabs: map[string]string{"RT": "RT Plot"}})
 
 	gui := &ss.GUI
 	if gui.TableViews == nil {
@@ -726,14 +766,19 @@ func (ss *Sim) ConfigGUI() {
 	tv.SetReadOnly(true)
 	tv.SetTable(dt)
 
-	stnm = "RT"
-	dt = ss.Logs.MiscTable(stnm)
-	plt := ss.GUI.NewPlotTab(etime.ScopeKey(stnm), stnm+" Plot")
+	ss.GUI.FinalizeGUI(false)
+}
+
+// ConfigPlots adds the log plots, and the plot of the reaction times
+// (RT) by word type, to the GUI or the Figures.
+func (ss *Sim) ConfigPlots(pl simcore.Plots) {
+	pl.AddLogPlots("Spelling to Sound", &ss.Logs)
+
+	stnm := "RT"
+	plt := pl.AddPlot(stnm)
 	plt.Options.Title = "Reaction Time by Type"
 	plt.Options.XAxis = "Type"
-	plt.SetTable(dt)
-
-	ss.GUI.FinalizeGUI(false)
+	plt.SetTable(ss.Logs.MiscTable(stnm))
 }
 
 func (ss *Sim) MakeToolbar(p *tree.Plan) {
@@ -785,3 +830,15 @@ func (ss *Sim) RunGUI() {
 	ss.ConfigGUI()
 	ss.GUI.Body.RunMainWindow()
 }
+
+func (ss *Sim) RunNoGUI() {
+	ss.Runner().RunNoGUI()
+}
+
+// Runner returns the simcore.Runner that runs the sim without the GUI.
+func (ss *Sim) Runner() *simcore.Runner {
+	return &simcore.Runner{Sim: ss, NetName: ss.Net.Name, RunName: ss.Params.RunName,
+		Config: &ss.Config.RunConfig, Run: &ss.Config.Run, NRuns: &ss.Config.NRuns, NEpochs: &ss.Config.NEpochs, Log: &ss.Config.Log,
+		Net: ss.Net, Context: &ss.Context, Loops: ss.Loops, Envs: ss.Envs, Stats: &ss.Stats, Logs: &ss.Logs, Seeds: &ss.RandSeeds,
+		Figures: &ss.Figures, Init: ss.Init, ConfigPlots: ss.ConfigPlots}
+}
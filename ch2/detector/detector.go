@@ -12,6 +12,7 @@ package main
 
 import (
 	"embed"
+	"fmt"
 	"log"
 	"reflect"
 
@@ -20,8 +21,11 @@ import (
 	"cogentcore.org/core/enums"
 	"cogentcore.org/core/icons"
 	"cogentcore.org/core/math32/minmax"
+	"cogentcore.org/core/tensor"
 	"cogentcore.org/core/tensor/table"
 	"cogentcore.org/core/tree"
+	"github.com/CompCogNeuro/sims/v2/simcore"
+	"github.com/emer/emergent/v2/econfig"
 	"github.com/emer/emergent/v2/egui"
 	"github.com/emer/emergent/v2/elog"
 	"github.com/emer/emergent/v2/emer"
@@ -42,7 +46,11 @@ func main() {
 	sim := &Sim{}
 	sim.New()
 	sim.ConfigAll()
-	sim.RunGUI()
+	if sim.Config.GUI {
+		sim.RunGUI()
+	} else {
+		sim.RunNoGUI()
+	}
 }
 
 // ParamSets is the default set of parameters.
@@ -66,6 +74,74 @@ var ParamSets = params.Sets{
 	},
 }
 
+// DetectorParams are the parameters of the RecvNeuron detector
+// and of its inputs, which are explored in the chapter.
+type DetectorParams struct {
+
+	// the leak conductance, which pulls against the excitatory
+	// input conductance to determine how hard it is to activate the receiving unit
+	GbarL float32 `default:"2" min:"0" max:"4" step:"0.05"`
+
+	// digit (0-9) whose pattern is the template for the weights of the
+	// RecvNeuron, which determines what it detects, or -1 to use the
+	// Template as it is, e.g., after drawing your own pattern in it
+	Digit int `default:"8" min:"-1" max:"9"`
+
+	// probability of flipping each pixel of the input digits
+	// to the opposite value on each trial
+	Noise float32 `default:"0" min:"0" max:"1" step:"0.05"`
+}
+
+func (dp *DetectorParams) Defaults() {
+	dp.GbarL = 2
+	dp.Digit = 8
+	dp.Noise = 0
+}
+
+// Config has config parameters related to running the sim.
+// The Sim starts with its Detector and SDT parameters: e.g., -GbarL 1.5
+// -Noise 0.2 tests a more easily activated detector with noisy digits.
+type Config struct {
+
+	// the initial Detector parameters of the Sim
+	Detector DetectorParams `display:"add-fields"`
+
+	// a .tsv file in the same format as digits.tsv, with the pattern in the
+	// Input column of its first row used as the template instead of the Digit
+	TemplateFile string
+
+	// parameters for the TuningCurve and ROC signal detection analyses
+	SDT SDTParams `display:"add-fields" nest:"+"`
+
+	// run the TuningCurve analysis after testing, saving the results
+	// to tuning.tsv, when running without the GUI.
+	Tuning bool
+
+	// run the ROC analysis after testing, saving the results
+	// to roc.tsv, when running without the GUI.
+	ROC bool
+
+	// GUI means open the GUI. Otherwise it runs automatically and quits,
+	// saving log files as specified in Log.
+	GUI bool `default:"true"`
+
+	// plots, grids or NetView to save as images without the GUI, or "all" (see simcore.Figures)
+	Figures string
+
+	// logs to export to .npz files for Python without the GUI, or "all" (see simcore.SaveLogsNPZ)
+	Export string
+
+	// Log has config parameters related to logging data.
+	Log LogConfig `display:"add-fields"`
+}
+
+// LogConfig has config parameters related to logging data
+type LogConfig struct {
+
+	// if true, save testing trial log to file, as .tst_trl.tsv typically
+	TestTrial bool `default:"true" nest:"+"`
+}
+
 // Sim encapsulates the entire simulation model, and we define all the
 // functionality as methods on this struct.  This structure keeps all relevant
 // state information organized and available without having to pass everything around
@@ -73,9 +149,18 @@ var ParamSets = params.Sets{
 // for the fields which provide hints to how things should be displayed).
 type Sim struct {
 
-	// the leak conductance, which pulls against the excitatory
-	// input conductance to determine how hard it is to activate the receiving unit
-	GbarL float32 `default:"2" min:"0" max:"4" step:"0.05"`
+	// the parameters of the detector and its inputs
+	Detector DetectorParams `display:"add-fields"`
+
+	// the template for the weights of the RecvNeuron, which is set to
+	// the pattern of the Digit on Init, unless Digit is -1
+	Template *tensor.Float32 `display:"no-inline"`
+
+	// parameters for the TuningCurve and ROC signal detection analyses
+	SDT SDTParams `display:"add-fields"`
+
+	// Config contains misc configuration parameters for running the sim
+	Config Config `display:"-"`
 
 	// the network -- click to view / edit parameters for layers, paths, etc
 	Net *leabra.Network `new-window:"+" display:"no-inline"`
@@ -107,6 +192,9 @@ type Sim struct {
 	// manages all the gui elements
 	GUI egui.GUI `display:"-"`
 
+	// the plots, grids and network snapshots that can be saved without the GUI
+	Figures simcore.Figures `display:"-"`
+
 	// a list of random seeds to use for each run
 	RandSeeds randx.Seeds `display:"-"`
 }
@@ -114,6 +202,15 @@ type Sim struct {
 // New creates new blank elements and initializes defaults
 func (ss *Sim) New() {
 	ss.Defaults()
+	ss.Template = tensor.NewFloat32([]int{7, 5})
+	econfig.Config(&ss.Config, "config.toml")
+	ss.Detector = ss.Config.Detector
+	ss.SDT = ss.Config.SDT
+	if ss.Config.TemplateFile != "" {
+		if err := ss.OpenTemplate(core.Filename(ss.Config.TemplateFile)); err != nil {
+			log.Println(err)
+		}
+	}
 	ss.Net = leabra.NewNetwork("Detector")
 	ss.Params.Config(ParamSets, "", "", ss.Net)
 	ss.Stats.Init()
@@ -124,7 +221,8 @@ func (ss *Sim) New() {
 }
 
 func (ss *Sim) Defaults() {
-	ss.GbarL = 2
+	ss.Detector.Defaults()
+	ss.SDT.Defaults()
 }
 
 //////////////////////////////////////////////////////////////////////////////
@@ -163,7 +261,7 @@ func (ss *Sim) ConfigNet(net *leabra.Network) {
 	inp := net.AddLayer2D("Input", 7, 5, leabra.InputLayer)
 	inp.Doc = "Input represents the visual appearance of different digits."
 	recv := net.AddLayer2D("RecvNeuron", 1, 1, leabra.SuperLayer)
-	recv.Doc = "RecvNeuron represents an individual neuron with synaptic weights tuned to detect the digit 8."
+	recv.Doc = "RecvNeuron represents an individual neuron with synaptic weights tuned to detect its template pattern, the digit 8 by default."
 
 	net.ConnectLayers(inp, recv, paths.NewFull(), leabra.ForwardPath)
 
@@ -173,24 +271,26 @@ func (ss *Sim) ConfigNet(net *leabra.Network) {
 	ss.InitWeights(net)
 }
 
-// InitWeights initializes weights to digit 8
+// InitWeights initializes the weights to the Template,
+// which is first set to the pattern of the Digit, unless it is -1.
 func (ss *Sim) InitWeights(net *leabra.Network) {
 	net.InitWeights()
-	digit := 8
-	pats := ss.Patterns
-	dpat := pats.Tensor("Input", digit)
+	if ss.Detector.Digit >= 0 && ss.Detector.Digit < ss.Patterns.Rows {
+		ss.Template.CopyFrom(ss.Patterns.Tensor("Input", ss.Detector.Digit))
+	}
+	tpat := ss.Template
 	recv := net.LayerByName("RecvNeuron")
 	pthi, _ := recv.RecvPathBySendName("Input")
 	pth := pthi.(*leabra.Path)
-	for i := 0; i < dpat.Len(); i++ {
-		pth.SetSynValue("Wt", i, 0, float32(dpat.Float1D(i)))
+	for i := 0; i < tpat.Len(); i++ {
+		pth.SetSynValue("Wt", i, 0, tpat.Values[i])
 	}
 }
 
 func (ss *Sim) ApplyParams() {
 	ss.Params.SetAll()
 	recv := ss.Net.LayerByName("RecvNeuron")
-	recv.Act.Gbar.L = ss.GbarL
+	recv.Act.Gbar.L = ss.Detector.GbarL
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -210,8 +310,7 @@ func (ss *Sim) Init() {
 
 // InitRandSeed initializes the random seed based on current training run number
 func (ss *Sim) InitRandSeed(run int) {
-	ss.RandSeeds.Set(run)
-	ss.RandSeeds.Set(run, &ss.Net.Rand)
+	simcore.InitRandSeed(&ss.RandSeeds, ss.Net, run)
 }
 
 // ConfigLoops configures the control loops: Training, Testing
@@ -248,9 +347,11 @@ func (ss *Sim) ConfigLoops() {
 	////////////////////////////////////////////
 	// GUI
 
-	leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
-	leabra.LooperUpdatePlots(ls, &ss.GUI)
-	ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	if ss.Config.GUI {
+		leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
+		leabra.LooperUpdatePlots(ls, &ss.GUI)
+		ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	}
 	ss.Loops = ls
 }
 
@@ -260,19 +361,38 @@ func (ss *Sim) ConfigLoops() {
 // (training, testing, etc).
 func (ss *Sim) ApplyInputs() {
 	ctx := &ss.Context
-	net := ss.Net
 	ev := ss.Envs.ByMode(ctx.Mode).(*env.FixedTable)
 	ev.Step()
-	lays := net.LayersByType(leabra.InputLayer, leabra.TargetLayer)
-	net.InitExt()
 	ss.Stats.SetString("TrialName", ev.TrialName.Cur)
-	for _, lnm := range lays {
-		ly := ss.Net.LayerByName(lnm)
-		pats := ev.State(ly.Name)
-		if pats != nil {
-			ly.ApplyExt(pats)
-		}
+	ss.ApplyPattern(ev.State("Input"), ss.Detector.Noise)
+}
+
+// ApplyPattern applies the given pattern to the Input layer,
+// with each of its pixels flipped with the given noise probability.
+func (ss *Sim) ApplyPattern(pat tensor.Tensor, noise float32) {
+	net := ss.Net
+	net.InitExt()
+	if noise > 0 {
+		pat = pat.Clone()
+		FlipPixels(pat, noise)
 	}
+	net.LayerByName("Input").ApplyExt(pat)
+}
+
+// RunTrial runs one trial of the given pattern, with the given noise,
+// directly without the looper, as in the signal detection analyses,
+// and returns the activation of the RecvNeuron at the end of it.
+func (ss *Sim) RunTrial(pat tensor.Tensor, noise float32) float32 {
+	ctx := &ss.Context
+	net := ss.Net
+	net.AlphaCycInit(false)
+	ctx.AlphaCycStart()
+	ss.ApplyPattern(pat, noise)
+	for range 20 { // same as the Test Cycle loop
+		net.Cycle(ctx)
+		ctx.CycleInc()
+	}
+	return net.LayerByName("RecvNeuron").UnitValue("Act", []int{0, 0}, 0)
 }
 
 // NewRun intializes a new run of the model, using the TrainEnv.Run counter
@@ -301,6 +421,26 @@ func (ss *Sim) OpenPatterns() {
 	}
 }
 
+// OpenTemplate opens the Template from the Input pattern in the first row
+// of the given .tsv file, in the same format as digits.tsv, and sets the
+// Digit to -1 so that it is used for the weights.
+func (ss *Sim) OpenTemplate(fnm core.Filename) error {
+	dt := table.NewTable()
+	if err := dt.OpenCSV(fnm, table.Tab); err != nil {
+		return err
+	}
+	cl, err := dt.ColumnByName("Input")
+	if err != nil {
+		return err
+	}
+	if dt.Rows == 0 || cl.Len()/dt.Rows != ss.Template.Len() {
+		return fmt.Errorf("OpenTemplate: %q does not have a 7x5 Input pattern", fnm)
+	}
+	ss.Template.CopyFrom(dt.Tensor("Input", 0))
+	ss.Detector.Digit = -1
+	return nil
+}
+
 ////////////////////////////////////////////////////////////////////////////////////////////
 // 		Stats
 
@@ -313,12 +453,7 @@ func (ss *Sim) InitStats() {
 // StatCounters saves current counters to Stats, so they are available for logging etc
 // Also saves a string rep of them for ViewUpdate.Text
 func (ss *Sim) StatCounters() {
-	ctx := &ss.Context
-	mode := ctx.Mode
-	ss.Loops.Stacks[mode].CountersToStats(&ss.Stats)
-	trl := ss.Stats.Int("Trial")
-	ss.Stats.SetInt("Trial", trl)
-	ss.Stats.SetInt("Cycle", int(ctx.Cycle))
+	simcore.StatCounters(ss.Loops, &ss.Context, &ss.Stats, false)
 }
 
 func (ss *Sim) NetViewCounters(tm etime.Times) {
@@ -366,29 +501,160 @@ func (ss *Sim) ConfigLogs() {
 	ss.Logs.SetContext(&ss.Stats, ss.Net)
 	ss.Logs.NoPlot(etime.Test, etime.Cycle)
 	ss.Logs.PlotItems("Ge", "Act")
+
+	tu := ss.Logs.MiscTable("Tuning")
+	tu.AddFloat64Column("Noise")
+	tu.AddFloat64Column("Digit")
+	tu.AddStringColumn("Name")
+	tu.AddFloat64Column("Act")
+	tu.AddFloat64Column("PDetect")
+	tu.SetMetaData("Act:On", "+")
+	tu.SetMetaData("Points", "true")
+
+	roc := ss.Logs.MiscTable("ROC")
+	for _, cn := range []string{"GbarL", "FAs", "Hits", "DPrime"} {
+		roc.AddFloat64Column(cn)
+	}
+	roc.SetMetaData("Hits:On", "+")
+	roc.SetMetaData("Points", "true")
 }
 
 // Log is the main logging function, handles special things for different scopes
 func (ss *Sim) Log(mode etime.Modes, time etime.Times) {
-	ctx := &ss.Context
-	if mode != etime.Analyze {
-		ctx.Mode = mode // Also set specifically in a Loop callback.
+	simcore.Log(&ss.Context, &ss.Logs, mode, time, simcore.Hooks{TrialStats: ss.TrialStats, StatCounters: ss.StatCounters})
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////
+// 		Signal detection
+
+// TuningCurve computes the tuning curve of the RecvNeuron across all the
+// digits, at SDT.NNoise + 1 levels of pixel-flip noise from 0 to SDT.MaxNoise,
+// recording the mean activation over SDT.NTrials noisy trials of each digit,
+// and the proportion of them that it detects (activation above SDT.ActThr),
+// in the Tuning table.
+func (ss *Sim) TuningCurve() {
+	sp := &ss.SDT
+	pats := ss.Patterns
+	tu := ss.Logs.MiscTable("Tuning")
+	tu.SetNumRows(0)
+	tup := ss.GUI.PlotByName("Tuning")
+	ss.InitRandSeed(0)
+	ss.ApplyParams()
+	ss.InitWeights(ss.Net)
+	for ni := 0; ni <= sp.NNoise; ni++ {
+		noise := sp.MaxNoise * float32(ni) / float32(sp.NNoise)
+		for di := range pats.Rows {
+			pat := pats.Tensor("Input", di)
+			sum, ndet := 0.0, 0
+			for range sp.NTrials {
+				act := ss.RunTrial(pat, noise)
+				sum += float64(act)
+				if act > sp.ActThr {
+					ndet++
+				}
+			}
+			row := tu.Rows
+			tu.AddRows(1)
+			tu.SetFloat("Noise", row, round4(noise))
+			tu.SetFloat("Digit", row, float64(di))
+			tu.SetString("Name", row, pats.StringValue("Name", di))
+			tu.SetFloat("Act", row, sum/float64(sp.NTrials))
+			tu.SetFloat("PDetect", row, float64(ndet)/float64(sp.NTrials))
+		}
+		if ss.GUI.StopNow {
+			break
+		}
+		if tup != nil {
+			tup.GoUpdatePlot()
+		}
+		simcore.WebYield()
 	}
-	dt := ss.Logs.Table(mode, time)
-	if dt == nil {
-		return
+	ss.GUI.IsRunning = false
+	if tup != nil {
+		tup.GoUpdatePlot()
+		ss.GUI.UpdateWindow()
 	}
-	row := dt.Rows
+}
 
-	switch {
-	case time == etime.Cycle:
-		return
-	case time == etime.Trial:
-		ss.TrialStats()
-		ss.StatCounters()
+// ROC computes the receiver operating characteristic (ROC) curve of the
+// RecvNeuron as a detector of its Template, at SDT.NGbarL + 1 values of
+// GbarL from SDT.GbarLMin to SDT.GbarLMax, which sets its threshold.
+// Hits are the proportion of SDT.NTrials trials of the Template with
+// SDT.ROCNoise that it detects (activation above SDT.ActThr), and false
+// alarms (FAs) are the proportion of SDT.NTrials trials of each of the
+// other digits that it detects, and d' is the sensitivity computed from
+// them, all recorded in the ROC table, with the area under the curve
+// in the AUC stat.
+func (ss *Sim) ROC() {
+	sp := &ss.SDT
+	pats := ss.Patterns
+	roc := ss.Logs.MiscTable("ROC")
+	roc.SetNumRows(0)
+	rocp := ss.GUI.PlotByName("ROC")
+	ss.InitRandSeed(0)
+	ss.ApplyParams()
+	ss.InitWeights(ss.Net)
+	var others []tensor.Tensor
+	for di := range pats.Rows {
+		pat := pats.Tensor("Input", di)
+		if !patsEqual(pat, ss.Template) {
+			others = append(others, pat)
+		}
+	}
+	recv := ss.Net.LayerByName("RecvNeuron")
+	nfas := sp.NTrials * len(others)
+	var hitRates, faRates []float64
+	for gi := 0; gi <= sp.NGbarL; gi++ {
+		gbarL := sp.GbarLMin + (sp.GbarLMax-sp.GbarLMin)*float32(gi)/float32(sp.NGbarL)
+		recv.Act.Gbar.L = gbarL
+		hits, fas := 0, 0
+		for range sp.NTrials {
+			if ss.RunTrial(ss.Template, sp.ROCNoise) > sp.ActThr {
+				hits++
+			}
+			for _, pat := range others {
+				if ss.RunTrial(pat, sp.ROCNoise) > sp.ActThr {
+					fas++
+				}
+			}
+		}
+		hr := float64(hits) / float64(sp.NTrials)
+		fr := float64(fas) / float64(nfas)
+		hitRates = append(hitRates, hr)
+		faRates = append(faRates, fr)
+		roc.AddRows(1)
+		roc.SetFloat("GbarL", gi, round4(gbarL))
+		roc.SetFloat("FAs", gi, fr)
+		roc.SetFloat("Hits", gi, hr)
+		roc.SetFloat("DPrime", gi, DPrime(hr, fr, sp.NTrials, nfas))
+		if ss.GUI.StopNow {
+			break
+		}
+		if rocp != nil {
+			rocp.GoUpdatePlot()
+		}
+		simcore.WebYield()
 	}
+	ss.Stats.SetFloat("AUC", AUC(hitRates, faRates))
+	ss.ApplyParams()
+	ss.GUI.IsRunning = false
+	if rocp != nil {
+		rocp.GoUpdatePlot()
+		ss.GUI.UpdateWindow()
+	}
+}
 
-	ss.Logs.LogRow(mode, time, row) // also logs to file, etc
+// patsEqual returns whether the two patterns have the same values.
+func patsEqual(a, b tensor.Tensor) bool {
+	if a.Len() != b.Len() {
+		return false
+	}
+	for i := range a.Len() {
+		if a.Float1D(i) != b.Float1D(i) {
+			return false
+		}
+	}
+	return true
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////
@@ -411,11 +677,29 @@ func (ss *Sim) ConfigGUI() {
 	// nv.SceneXYZ().Camera.Pose.Pos.Set(0, 1, 2.75) // more "head on" than default which is more "top down"
 	// nv.SceneXYZ().Camera.LookAt(math32.Vec3(0, 0, 0), math32.Vec3(0, 1, 0))
 
-	ss.GUI.AddPlots(title, &ss.Logs)
+	ss.ConfigPlots(simcore.GUIPlots{GUI: &ss.GUI})
 
 	ss.GUI.FinalizeGUI(false)
 }
 
+// ConfigPlots adds the log plots, and the Tuning curve of the detector
+// activity by digit for each noise level and the ROC curve of its hits
+// against false alarms, to the GUI or the Figures.
+func (ss *Sim) ConfigPlots(pl simcore.Plots) {
+	pl.AddLogPlots("Detector", &ss.Logs)
+
+	plt := pl.AddPlot("Tuning")
+	plt.Options.Title = "Tuning Curve"
+	plt.Options.XAxis = "Digit"
+	plt.Options.Legend = "Noise"
+	plt.SetTable(ss.Logs.MiscTable("Tuning"))
+
+	plt = pl.AddPlot("ROC")
+	plt.Options.Title = "ROC: Hits vs. False Alarms"
+	plt.Options.XAxis = "FAs"
+	plt.SetTable(ss.Logs.MiscTable("ROC"))
+}
+
 func (ss *Sim) MakeToolbar(p *tree.Plan) {
 	ss.GUI.AddLooperCtrl(p, ss.Loops)
 
@@ -429,6 +713,24 @@ func (ss *Sim) MakeToolbar(p *tree.Plan) {
 			ss.GUI.UpdatePlot(etime.Test, etime.Trial)
 		},
 	})
+	ss.GUI.AddToolbarItem(p, egui.ToolbarItem{Label: "Tuning Curve", Icon: icons.PlayArrow,
+		Tooltip: "Compute the tuning curve of the RecvNeuron activation across all the digits, at different levels of pixel-flip noise.",
+		Active:  egui.ActiveStopped,
+		Func: func() {
+			ss.GUI.IsRunning = true
+			go ss.TuningCurve()
+			ss.GUI.UpdateWindow()
+		},
+	})
+	ss.GUI.AddToolbarItem(p, egui.ToolbarItem{Label: "ROC", Icon: icons.PlayArrow,
+		Tooltip: "Compute the ROC curve of hits vs. false alarms for detecting the Template in noisy inputs, across values of GbarL, with d'.",
+		Active:  egui.ActiveStopped,
+		Func: func() {
+			ss.GUI.IsRunning = true
+			go ss.ROC()
+			ss.GUI.UpdateWindow()
+		},
+	})
 	////////////////////////////////////////////////
 	ss.GUI.AddToolbarItem(p, egui.ToolbarItem{Label: "Defaults", Icon: icons.Update,
 		Tooltip: "Restore initial default parameters.",
@@ -441,6 +743,15 @@ func (ss *Sim) MakeToolbar(p *tree.Plan) {
 		},
 	})
 	tree.Add(p, func(w *core.Separator) {})
+	ss.GUI.AddToolbarItem(p, egui.ToolbarItem{Label: "Edit Patterns",
+		Icon:    icons.Edit,
+		Tooltip: "Opens an editor for the test patterns, where you can click and drag on the units to draw new patterns, add, delete and name them, and save them to a .tsv file.",
+		Active:  egui.ActiveStopped,
+		Func: func() {
+			simcore.EditEnvPatterns(ss.GUI.Body, ss.Loops, ss.Envs, etime.Test, "digits.tsv")
+		},
+	})
+	tree.Add(p, func(w *core.Separator) {})
 	ss.GUI.AddToolbarItem(p, egui.ToolbarItem{Label: "README",
 		Icon:    icons.FileMarkdown,
 		Tooltip: "Opens your browser on the README file that contains instructions for how to run this model.",
@@ -456,3 +767,40 @@ func (ss *Sim) RunGUI() {
 	ss.ConfigGUI()
 	ss.GUI.Body.RunMainWindow()
 }
+
+// RunNoGUI runs the test loop without the GUI, saving the log files
+// as specified in the Config.Log settings, and then the TuningCurve
+// and ROC analyses if set in the Config, saving their results.
+func (ss *Sim) RunNoGUI() {
+	runName := ss.Params.RunName(0)
+	ss.Stats.SetString("RunName", runName) // used for naming logs, stats, etc
+	netName := ss.Net.Name
+
+	elog.SetLogFile(&ss.Logs, ss.Config.Log.TestTrial, etime.Test, etime.Trial, "tst_trl", netName, runName)
+
+	if ss.Config.Figures != "" {
+		ss.Figures.Config(ss.Net, ss.ConfigPlots)
+	}
+	ss.Init()
+	ss.Loops.Run(etime.Test)
+
+	ss.Logs.CloseLogFiles()
+
+	if ss.Config.Tuning {
+		ss.TuningCurve()
+		simcore.SaveTable(ss.Logs.MiscTable("Tuning"), "tuning", netName, runName)
+	}
+	if ss.Config.ROC {
+		ss.ROC()
+		simcore.SaveTable(ss.Logs.MiscTable("ROC"), "roc", netName, runName)
+		fmt.Printf("ROC area under the curve: %.3f\n", ss.Stats.Float("AUC"))
+	}
+
+	if ss.Config.Figures != "" {
+		ss.Figures.Save(ss.Config.Figures, netName, runName)
+	}
+
+	if ss.Config.Export != "" {
+		simcore.SaveLogsNPZ(&ss.Logs, ss.Config.Export, netName, runName)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"sort"
+
+	"cogentcore.org/core/base/randx"
+	"cogentcore.org/core/tensor"
+)
+
+// SDTParams are the parameters for the signal detection analysis of the
+// detector: its tuning curve across the digits at different levels of
+// pixel-flip noise, and the ROC curve of hits vs. false alarms across
+// different values of GbarL, which sets its detection threshold.
+type SDTParams struct {
+
+	// highest pixel-flip noise level for the tuning curve,
+	// which goes from 0 up to this in NNoise steps
+	MaxNoise float32 `default:"0.2" min:"0" max:"1" step:"0.05"`
+
+	// number of steps of noise levels above 0 for the tuning curve
+	NNoise int `default:"4" min:"1"`
+
+	// number of noisy trials for each digit at each noise level or GbarL
+	NTrials int `default:"50" min:"1"`
+
+	// activity of the RecvNeuron above which it counts as detecting the input
+	ActThr float32 `default:"0.5" min:"0" max:"1" step:"0.05"`
+
+	// pixel-flip noise level for the ROC curve, which must be above 0
+	// for there to be any graded tradeoff between hits and false alarms
+	ROCNoise float32 `default:"0.1" min:"0" max:"1" step:"0.05"`
+
+	// lowest GbarL for the ROC curve
+	GbarLMin float32 `default:"0.5" min:"0" step:"0.1"`
+
+	// highest GbarL for the ROC curve
+	GbarLMax float32 `default:"3" min:"0" step:"0.1"`
+
+	// number of steps of GbarL from GbarLMin to GbarLMax for the ROC curve
+	NGbarL int `default:"25" min:"1"`
+}
+
+func (sp *SDTParams) Defaults() {
+	sp.MaxNoise = 0.2
+	sp.NNoise = 4
+	sp.NTrials = 50
+	sp.ActThr = 0.5
+	sp.ROCNoise = 0.1
+	sp.GbarLMin = 0.5
+	sp.GbarLMax = 3
+	sp.NGbarL = 25
+}
+
+// round4 returns the given value rounded to 4 decimal places,
+// for nice values in the tables from float32 parameters.
+func round4(v float32) float64 {
+	return math.Round(float64(v)*1e4) / 1e4
+}
+
+// FlipPixels flips each of the binary values of the given pattern
+// to the opposite value with the given probability.
+func FlipPixels(pat tensor.Tensor, prob float32) {
+	for i := range pat.Len() {
+		if randx.BoolP32(prob) {
+			pat.SetFloat1D(i, 1-pat.Float1D(i))
+		}
+	}
+}
+
+// ZScore returns the z score of the given probability, i.e., the inverse
+// of the standard normal cumulative distribution function.
+func ZScore(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
+
+// DPrime returns the d' sensitivity for the given hit and false alarm
+// rates, out of nHits and nFAs trials respectively, which is the difference
+// between their z scores. Rates of 0 and 1 are moved in by half a trial
+// so that it is finite.
+func DPrime(hits, fas float64, nHits, nFAs int) float64 {
+	clip := func(p float64, n int) float64 {
+		lo := 1 / float64(2*n)
+		return min(max(p, lo), 1-lo)
+	}
+	return ZScore(clip(hits, nHits)) - ZScore(clip(fas, nFAs))
+}
+
+// AUC returns the area under the ROC curve given by the given hit and
+// false alarm rates, in any order, with the curve extended to the (0, 0)
+// and (1, 1) corners: .5 is chance and 1 is perfect detection.
+func AUC(hits, fas []float64) float64 {
+	idx := make([]int, len(fas))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		if fas[idx[i]] == fas[idx[j]] {
+			return hits[idx[i]] < hits[idx[j]]
+		}
+		return fas[idx[i]] < fas[idx[j]]
+	})
+	auc, ph, pf := 0.0, 0.0, 0.0
+	for _, i := range idx {
+		auc += (fas[i] - pf) * (hits[i] + ph) / 2
+		ph, pf = hits[i], fas[i]
+	}
+	return auc + (1-pf)*(1+ph)/2
+}
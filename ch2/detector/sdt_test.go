@@ -0,0 +1,86 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"testing"
+
+	"cogentcore.org/core/tensor"
+)
+
+func TestZScore(t *testing.T) {
+	tests := []struct {
+		p, z float64
+	}{
+		{0.5, 0},
+		{0.975, 1.959964},
+		{0.025, -1.959964},
+		{0.8413447, 1},
+	}
+	for _, tt := range tests {
+		if z := ZScore(tt.p); math.Abs(z-tt.z) > 1.0e-5 {
+			t.Errorf("ZScore(%g) = %g != %g", tt.p, z, tt.z)
+		}
+	}
+}
+
+func TestDPrime(t *testing.T) {
+	tests := []struct {
+		name        string
+		hits, fas   float64
+		nHits, nFAs int
+		want        float64
+	}{
+		{"chance", 0.3, 0.3, 10, 10, 0},
+		{"d' 2", 0.8413447, 0.1586553, 10, 10, 2},
+		{"below chance", 0.1586553, 0.8413447, 10, 10, -2},
+		{"perfect is finite", 1, 0, 10, 10, 2 * 1.644854}, // rates of .95 and .05
+		{"clipped by trials", 1, 0, 50, 10, 2.326348 + 1.644854},
+	}
+	for _, tt := range tests {
+		if d := DPrime(tt.hits, tt.fas, tt.nHits, tt.nFAs); math.Abs(d-tt.want) > 1.0e-5 {
+			t.Errorf("%s: DPrime = %g != %g", tt.name, d, tt.want)
+		}
+	}
+}
+
+func TestAUC(t *testing.T) {
+	tests := []struct {
+		name      string
+		hits, fas []float64
+		want      float64
+	}{
+		{"no points", nil, nil, 0.5},
+		{"chance", []float64{0.2, 0.5, 0.8}, []float64{0.2, 0.5, 0.8}, 0.5},
+		{"perfect", []float64{1}, []float64{0}, 1},
+		{"one point", []float64{0.8}, []float64{0.2}, 0.8},
+		{"unordered", []float64{0.9, 0.5, 0.7}, []float64{0.4, 0, 0.1}, 0.06 + 0.24 + 0.57},
+		{"ties by hits", []float64{0.6, 0.4}, []float64{0.2, 0.2}, 0.04 + 0.64},
+	}
+	for _, tt := range tests {
+		if auc := AUC(tt.hits, tt.fas); math.Abs(auc-tt.want) > 1.0e-9 {
+			t.Errorf("%s: AUC = %g != %g", tt.name, auc, tt.want)
+		}
+	}
+}
+
+func TestFlipPixels(t *testing.T) {
+	vals := []float32{0, 1, 1, 0, 1, 0}
+	for _, prob := range []float32{0, 1} {
+		pat := tensor.NewFloat32([]int{len(vals)})
+		copy(pat.Values, vals)
+		FlipPixels(pat, prob)
+		for i, v := range vals {
+			want := v
+			if prob == 1 {
+				want = 1 - v
+			}
+			if pat.Values[i] != want {
+				t.Errorf("FlipPixels(%g): pixel %d = %g != %g", prob, i, pat.Values[i], want)
+			}
+		}
+	}
+}
@@ -6,4 +6,12 @@ import (
 	"cogentcore.org/core/types"
 )
 
-var _ = types.AddType(&types.Type{Name: "main.Sim", IDName: "sim", Doc: "Sim encapsulates the entire simulation model, and we define all the\nfunctionality as methods on this struct.  This structure keeps all relevant\nstate information organized and available without having to pass everything around\nas arguments to methods, and provides the core GUI interface (note the view tags\nfor the fields which provide hints to how things should be displayed).", Fields: []types.Field{{Name: "GbarL", Doc: "the leak conductance, which pulls against the excitatory\ninput conductance to determine how hard it is to activate the receiving unit"}, {Name: "Net", Doc: "the network -- click to view / edit parameters for layers, paths, etc"}, {Name: "Params", Doc: "network parameter management"}, {Name: "Loops", Doc: "contains looper control loops for running sim"}, {Name: "Stats", Doc: "contains computed statistic values"}, {Name: "Logs", Doc: "Contains all the logs and information about the logs.'"}, {Name: "Patterns", Doc: "the training patterns to use"}, {Name: "Envs", Doc: "Environments"}, {Name: "Context", Doc: "leabra timing parameters and state"}, {Name: "ViewUpdate", Doc: "netview update parameters"}, {Name: "GUI", Doc: "manages all the gui elements"}, {Name: "RandSeeds", Doc: "a list of random seeds to use for each run"}}})
+var _ = types.AddType(&types.Type{Name: "main.DetectorParams", IDName: "detector-params", Doc: "DetectorParams are the parameters of the RecvNeuron detector\nand of its inputs, which are explored in the chapter.", Fields: []types.Field{{Name: "GbarL", Doc: "the leak conductance, which pulls against the excitatory\ninput conductance to determine how hard it is to activate the receiving unit"}, {Name: "Digit", Doc: "digit (0-9) whose pattern is the template for the weights of the\nRecvNeuron, which determines what it detects, or -1 to use the\nTemplate as it is, e.g., after drawing your own pattern in it"}, {Name: "Noise", Doc: "probability of flipping each pixel of the input digits\nto the opposite value on each trial"}}})
+
+var _ = types.AddType(&types.Type{Name: "main.Config", IDName: "config", Doc: "Config has config parameters related to running the sim.\nThe Sim starts with its Detector and SDT parameters: e.g., -GbarL 1.5\n-Noise 0.2 tests a more easily activated detector with noisy digits.", Fields: []types.Field{{Name: "Detector", Doc: "the initial Detector parameters of the Sim"}, {Name: "TemplateFile", Doc: "a .tsv file in the same format as digits.tsv, with the pattern in the\nInput column of its first row used as the template instead of the Digit"}, {Name: "SDT", Doc: "parameters for the TuningCurve and ROC signal detection analyses"}, {Name: "Tuning", Doc: "run the TuningCurve analysis after testing, saving the results\nto tuning.tsv, when running without the GUI."}, {Name: "ROC", Doc: "run the ROC analysis after testing, saving the results\nto roc.tsv, when running without the GUI."}, {Name: "GUI", Doc: "GUI means open the GUI. Otherwise it runs automatically and quits,\nsaving log files as specified in Log."}, {Name: "Figures", Doc: "plots, grids or NetView to save as images without the GUI, or \"all\" (see simcore.Figures)"}, {Name: "Export", Doc: "logs to export to .npz files for Python without the GUI, or \"all\" (see simcore.SaveLogsNPZ)"}, {Name: "Log", Doc: "Log has config parameters related to logging data."}}})
+
+var _ = types.AddType(&types.Type{Name: "main.LogConfig", IDName: "log-config", Doc: "LogConfig has config parameters related to logging data", Fields: []types.Field{{Name: "TestTrial", Doc: "if true, save testing trial log to file, as .tst_trl.tsv typically"}}})
+
+var _ = types.AddType(&types.Type{Name: "main.Sim", IDName: "sim", Doc: "Sim encapsulates the entire simulation model, and we define all the\nfunctionality as methods on this struct.  This structure keeps all relevant\nstate information organized and available without having to pass everything around\nas arguments to methods, and provides the core GUI interface (note the view tags\nfor the fields which provide hints to how things should be displayed).", Fields: []types.Field{{Name: "Detector", Doc: "the parameters of the detector and its inputs"}, {Name: "Template", Doc: "the template for the weights of the RecvNeuron, which is set to\nthe pattern of the Digit on Init, unless Digit is -1"}, {Name: "SDT", Doc: "parameters for the TuningCurve and ROC signal detection analyses"}, {Name: "Config", Doc: "Config contains misc configuration parameters for running the sim"}, {Name: "Net", Doc: "the network -- click to view / edit parameters for layers, paths, etc"}, {Name: "Params", Doc: "network parameter management"}, {Name: "Loops", Doc: "contains looper control loops for running sim"}, {Name: "Stats", Doc: "contains computed statistic values"}, {Name: "Logs", Doc: "Contains all the logs and information about the logs.'"}, {Name: "Patterns", Doc: "the training patterns to use"}, {Name: "Envs", Doc: "Environments"}, {Name: "Context", Doc: "leabra timing parameters and state"}, {Name: "ViewUpdate", Doc: "netview update parameters"}, {Name: "GUI", Doc: "manages all the gui elements"}, {Name: "Figures", Doc: "the plots, grids and network snapshots that can be saved without the GUI"}, {Name: "RandSeeds", Doc: "a list of random seeds to use for each run"}}})
+
+var _ = types.AddType(&types.Type{Name: "main.SDTParams", IDName: "sdt-params", Doc: "SDTParams are the parameters for the signal detection analysis of the\ndetector: its tuning curve across the digits at different levels of\npixel-flip noise, and the ROC curve of hits vs. false alarms across\ndifferent values of GbarL, which sets its detection threshold.", Fields: []types.Field{{Name: "MaxNoise", Doc: "highest pixel-flip noise level for the tuning curve,\nwhich goes from 0 up to this in NNoise steps"}, {Name: "NNoise", Doc: "number of steps of noise levels above 0 for the tuning curve"}, {Name: "NTrials", Doc: "number of noisy trials for each digit at each noise level or GbarL"}, {Name: "ActThr", Doc: "activity of the RecvNeuron above which it counts as detecting the input"}, {Name: "ROCNoise", Doc: "pixel-flip noise level for the ROC curve, which must be above 0\nfor there to be any graded tradeoff between hits and false alarms"}, {Name: "GbarLMin", Doc: "lowest GbarL for the ROC curve"}, {Name: "GbarLMax", Doc: "highest GbarL for the ROC curve"}, {Name: "NGbarL", Doc: "number of steps of GbarL from GbarLMin to GbarLMax for the ROC curve"}}})
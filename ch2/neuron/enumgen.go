@@ -0,0 +1,54 @@
+// Code generated by "core generate -add-types"; DO NOT EDIT.
+
+package main
+
+import (
+	"cogentcore.org/core/enums"
+)
+
+var _StimTypesValues = []StimTypes{0, 1, 2, 3, 4, 5}
+
+// StimTypesN is the highest valid value for type StimTypes, plus one.
+//
+//gosl:start
+const StimTypesN StimTypes = 6
+
+//gosl:end
+
+var _StimTypesValueMap = map[string]StimTypes{`Pulse`: 0, `Ramp`: 1, `Steps`: 2, `Sine`: 3, `Poisson`: 4, `Waveform`: 5}
+
+var _StimTypesDescMap = map[StimTypes]string{0: `Pulse is a single square pulse of input at Amp from OnCycle to OffCycle.`, 1: `Ramp increases the input linearly from 0 at OnCycle to Amp at OffCycle.`, 2: `Steps are NSteps steps of increasing input from OnCycle to OffCycle, each of equal duration, going up by Amp / NSteps each step.`, 3: `Sine is a sinusoidal input from 0 to Amp at Freq Hz from OnCycle to OffCycle, starting at 0.`, 4: `Poisson is Poisson synaptic input from OnCycle to OffCycle, with events at Rate Hz, each adding SynGe to the input, which decays with time constant SynTau.`, 5: `Waveform is the input on each cycle from the File, starting at cycle 0 regardless of OnCycle and OffCycle, and 0 after the end of it.`}
+
+var _StimTypesMap = map[StimTypes]string{0: `Pulse`, 1: `Ramp`, 2: `Steps`, 3: `Sine`, 4: `Poisson`, 5: `Waveform`}
+
+// String returns the string representation of this StimTypes value.
+func (i StimTypes) String() string { return enums.String(i, _StimTypesMap) }
+
+// SetString sets the StimTypes value from its string representation,
+// and returns an error if the string is invalid.
+func (i *StimTypes) SetString(s string) error {
+	return enums.SetString(i, s, _StimTypesValueMap, "StimTypes")
+}
+
+// Int64 returns the StimTypes value as an int64.
+func (i StimTypes) Int64() int64 { return int64(i) }
+
+// SetInt64 sets the StimTypes value from an int64.
+func (i *StimTypes) SetInt64(in int64) { *i = StimTypes(in) }
+
+// Desc returns the description of the StimTypes value.
+func (i StimTypes) Desc() string { return enums.Desc(i, _StimTypesDescMap) }
+
+// StimTypesValues returns all possible values for the type StimTypes.
+func StimTypesValues() []StimTypes { return _StimTypesValues }
+
+// Values returns all possible values for the type StimTypes.
+func (i StimTypes) Values() []enums.Enum { return enums.Values(_StimTypesValues) }
+
+// MarshalText implements the [encoding.TextMarshaler] interface.
+func (i StimTypes) MarshalText() ([]byte, error) { return []byte(i.String()), nil }
+
+// UnmarshalText implements the [encoding.TextUnmarshaler] interface.
+func (i *StimTypes) UnmarshalText(text []byte) error {
+	return enums.UnmarshalText(i, text, "StimTypes")
+}
@@ -12,14 +12,17 @@ package main
 import (
 	"fmt"
 	"log"
+	"math"
+	"os"
 	"reflect"
-	"time"
 
+	"cogentcore.org/core/base/errors"
 	"cogentcore.org/core/core"
 	"cogentcore.org/core/icons"
 	"cogentcore.org/core/math32/minmax"
-	"cogentcore.org/core/system"
 	"cogentcore.org/core/tree"
+	"github.com/CompCogNeuro/sims/v2/simcore"
+	"github.com/emer/emergent/v2/econfig"
 	"github.com/emer/emergent/v2/egui"
 	"github.com/emer/emergent/v2/elog"
 	"github.com/emer/emergent/v2/emer"
@@ -35,7 +38,11 @@ func main() {
 	sim := &Sim{}
 	sim.New()
 	sim.ConfigAll()
-	sim.RunGUI()
+	if sim.Config.GUI {
+		sim.RunGUI()
+	} else {
+		sim.RunNoGUI()
+	}
 }
 
 // ParamSets is the default set of parameters
@@ -55,46 +62,156 @@ var ParamSets = params.Sets{
 	},
 }
 
-// Sim encapsulates the entire simulation model, and we define all the
-// functionality as methods on this struct.  This structure keeps all relevant
-// state information organized and available without having to pass everything around
-// as arguments to methods, and provides the core GUI interface (note the view tags
-// for the fields which provide hints to how things should be displayed).
-type Sim struct {
+// NeuronParams are the parameters of the Neuron: its conductances and
+// reversal potentials, and the timing of its excitatory and inhibitory inputs.
+type NeuronParams struct {
 
 	// use discrete spiking equations -- otherwise use Noisy X-over-X-plus-1 rate code activation function
-	Spike bool
+	Spike bool `default:"true"`
 
 	// excitatory conductance multiplier -- determines overall value of Ge which drives neuron to be more excited -- pushes up over threshold to fire if strong enough
-	GbarE float32 `min:"0" step:"0.01" def:"0.3"`
+	GbarE float32 `default:"0.3" min:"0" step:"0.01"`
 
 	// leak conductance -- determines overall value of Gl which drives neuron to be less excited (inhibited) -- pushes back to resting membrane potential
-	GbarL float32 `min:"0" step:"0.01" def:"0.3"`
+	GbarL float32 `default:"0.3" min:"0" step:"0.01"`
 
 	// excitatory reversal (driving) potential -- determines where excitation pushes Vm up to
-	ErevE float32 `min:"0" max:"1" step:"0.01" def:"1"`
+	ErevE float32 `default:"1" min:"0" max:"1" step:"0.01"`
 
 	// leak reversal (driving) potential -- determines where excitation pulls Vm down to
-	ErevL float32 `min:"0" max:"1" step:"0.01" def:"0.3"`
+	ErevL float32 `default:"0.3" min:"0" max:"1" step:"0.01"`
+
+	// inhibitory conductance multiplier -- determines overall value of Gi from the InhibStim input, which pulls Vm toward ErevI -- 0 for no inhibition
+	GbarI float32 `default:"0" min:"0" step:"0.01"`
+
+	// inhibitory reversal (driving) potential -- determines where inhibition pulls Vm down to -- at ErevL (.3) it is purely shunting
+	ErevI float32 `default:"0.25" min:"0" max:"1" step:"0.01"`
 
 	// the variance parameter for Gaussian noise added to unit activations on every cycle
-	Noise float32 `min:"0" step:"0.01"`
+	Noise float32 `default:"0" min:"0" step:"0.01"`
 
 	// apply sodium-gated potassium adaptation mechanisms that cause the neuron to reduce spiking over time
-	KNaAdapt bool
+	KNaAdapt bool `default:"true"`
 
 	// total number of cycles to run
-	NCycles int `min:"10" def:"200"`
+	NCycles int `default:"200" min:"10"`
 
 	// when does excitatory input into neuron come on?
-	OnCycle int `min:"0" def:"10"`
+	OnCycle int `default:"10" min:"0"`
 
 	// when does excitatory input into neuron go off?
-	OffCycle int `min:"0" def:"160"`
+	OffCycle int `default:"160" min:"0"`
+
+	// when does inhibitory input into neuron come on?
+	InhibOnCycle int `default:"10" min:"0"`
+
+	// when does inhibitory input into neuron go off?
+	InhibOffCycle int `default:"160" min:"0"`
+
+	// number of steps of input amplitude from 0 to Stim.Amp for the FICurve
+	FISteps int `default:"20" min:"1"`
+}
+
+func (np *NeuronParams) Defaults() {
+	np.Spike = true
+	np.GbarE = 0.3
+	np.GbarL = 0.3
+	np.ErevE = 1
+	np.ErevL = 0.3
+	np.GbarI = 0
+	np.ErevI = 0.25
+	np.Noise = 0
+	np.KNaAdapt = true
+	np.NCycles = 200
+	np.OnCycle = 10
+	np.OffCycle = 160
+	np.InhibOnCycle = 10
+	np.InhibOffCycle = 160
+	np.FISteps = 20
+}
+
+// Config has config parameters related to running the sim.
+// Its Neuron, Stim, InhibStim and Pop are the initial Sim parameters,
+// e.g., -GbarE 0.4 -NoKNaAdapt for a more excitable neuron without adaptation.
+type Config struct {
+
+	// the starting Neuron parameters, e.g., -GbarL 0.5
+	Neuron NeuronParams `display:"add-fields"`
+
+	// stimulus protocol that determines the excitatory input on each cycle
+	Stim StimParams `display:"add-fields" nest:"+"`
+
+	// stimulus protocol that determines the inhibitory input on each cycle,
+	// from InhibOnCycle to InhibOffCycle, scaled by GbarI
+	InhibStim StimParams `display:"add-fields" nest:"+"`
+
+	// population of neurons with heterogeneous parameters, all getting the
+	// same input as the single Neuron, e.g., -Pop.N 100
+	Pop PopParams `display:"add-fields" nest:"+"`
+
+	// run the FICurve analysis instead of a single run of NCycles,
+	// saving the results to fi_curve.tsv, when running without the GUI.
+	FICurve bool
+
+	// run the SpikeVsRate comparison instead of a single run of NCycles,
+	// saving the results to spike_vs_rate.tsv, when running without the GUI.
+	SpikeVsRate bool
+
+	// GUI means open the GUI. Otherwise it runs automatically and quits,
+	// saving log files as specified in Log.
+	GUI bool `default:"true"`
+
+	// plots, grids or NetView to save as images without the GUI, or "all" (see simcore.Figures)
+	Figures string
+
+	// logs to export to .npz files for Python without the GUI, or "all" (see simcore.SaveLogsNPZ)
+	Export string
+
+	// Log has config parameters related to logging data.
+	Log LogConfig `display:"add-fields"`
+}
+
+// LogConfig has config parameters related to logging data
+type LogConfig struct {
+
+	// if true, save cycle log to file, as .cyc.tsv typically
+	Cycle bool `default:"true" nest:"+"`
+
+	// if true, save the histogram of the inter-spike intervals of the run
+	// to file, as isi_hist.tsv
+	ISIHist bool `default:"true" nest:"+"`
+}
+
+// Sim encapsulates the entire simulation model, and we define all the
+// functionality as methods on this struct.  This structure keeps all relevant
+// state information organized and available without having to pass everything around
+// as arguments to methods, and provides the core GUI interface (note the view tags
+// for the fields which provide hints to how things should be displayed).
+type Sim struct {
+
+	// the parameters of the neuron and the timing of its inputs
+	Neuron NeuronParams `display:"add-fields"`
+
+	// stimulus protocol that determines the excitatory input on each cycle
+	Stim StimParams `display:"no-inline"`
+
+	// stimulus protocol that determines the inhibitory input on each cycle,
+	// from InhibOnCycle to InhibOffCycle, scaled by GbarI
+	InhibStim StimParams `display:"no-inline"`
+
+	// population of neurons with heterogeneous parameters, all getting the
+	// same input as the single Neuron
+	Pop PopParams `display:"no-inline"`
+
+	// the parameters of each neuron in the population, drawn at the start of each run
+	PopActs []spike.ActParams `display:"-"`
 
 	// how often to update display (in cycles)
 	UpdateInterval int `min:"1" def:"10"`
 
+	// Config contains misc configuration parameters for running the sim
+	Config Config `display:"-"`
+
 	// the network -- click to view / edit parameters for layers, paths, etc
 	Net *leabra.Network `display:"-"`
 
@@ -119,6 +236,9 @@ type Sim struct {
 	// manages all the gui elements
 	GUI egui.GUI `display:"-"`
 
+	// the plots, grids and network snapshots that can be saved without the GUI
+	Figures simcore.Figures `display:"-"`
+
 	// map of values for detailed debugging / testing
 	ValMap map[string]float32 `display:"-"`
 }
@@ -127,6 +247,11 @@ type Sim struct {
 func (ss *Sim) New() {
 	ss.Net = leabra.NewNetwork("Neuron")
 	ss.Defaults()
+	econfig.Config(&ss.Config, "config.toml")
+	ss.Neuron = ss.Config.Neuron
+	ss.Stim = ss.Config.Stim
+	ss.InhibStim = ss.Config.InhibStim
+	ss.Pop = ss.Config.Pop
 	ss.Stats.Init()
 	ss.ValMap = make(map[string]float32)
 }
@@ -135,16 +260,10 @@ func (ss *Sim) Defaults() {
 	ss.SpikeParams.Defaults()
 	ss.Params.Config(ParamSets, "", "", ss.Net)
 	ss.UpdateInterval = 10
-	ss.Spike = true
-	ss.GbarE = 0.3
-	ss.GbarL = 0.3
-	ss.ErevE = 1
-	ss.ErevL = 0.3
-	ss.Noise = 0
-	ss.KNaAdapt = true
-	ss.NCycles = 200
-	ss.OnCycle = 10
-	ss.OffCycle = 160
+	ss.Neuron.Defaults()
+	ss.Stim.Defaults()
+	ss.InhibStim.Defaults()
+	ss.Pop.Defaults()
 }
 
 /////////////////////////////////////////////////////////////////////////////
@@ -158,6 +277,9 @@ func (ss *Sim) ConfigAll() {
 
 func (ss *Sim) ConfigNet(net *leabra.Network) {
 	net.AddLayer2D("Neuron", 1, 1, leabra.SuperLayer)
+	if ss.Pop.N > 0 {
+		net.AddLayer2D("Population", 1, ss.Pop.N, leabra.SuperLayer)
+	}
 	err := net.Build()
 	if err != nil {
 		log.Println(err)
@@ -194,6 +316,12 @@ func (ss *Sim) Counters() string {
 
 func (ss *Sim) UpdateView() {
 	ss.GUI.GoUpdatePlot(etime.Test, etime.Cycle)
+	if plt := ss.GUI.PlotByName("PhasePlane"); plt != nil {
+		plt.GoUpdatePlot()
+	}
+	if ss.Pop.N > 0 {
+		ss.GUI.Grid("Raster").NeedsRender()
+	}
 	ss.GUI.ViewUpdate.Text = ss.Counters()
 	ss.GUI.ViewUpdate.UpdateCycle(int(ss.Context.Cycle))
 }
@@ -209,29 +337,37 @@ func (ss *Sim) RunCycles(updt bool) {
 	ss.Net.InitActs()
 	ctx.AlphaCycStart()
 	ss.SetParams("", false)
+	errors.Log(ss.Stim.Init())
+	errors.Log(ss.InhibStim.Init())
+	ss.Logs.MiscTable("PhasePlane").SetNumRows(0)
+	if ss.Pop.N > 0 {
+		ss.PopActs = ss.Pop.Draw(&ss.SpikeParams, ss.Neuron.Noise)
+		ss.Stats.ConfigRasters(ss.Net, ss.Neuron.NCycles, []string{"Population"})
+		ss.Stats.F32Tensor("Raster_Population").SetZeros()
+	}
 	ly := ss.Net.LayerByName("Neuron")
 	nrn := &(ly.Neurons[0])
 	inputOn := false
-	for cyc := 0; cyc < ss.NCycles; cyc++ {
+	for cyc := 0; cyc < ss.Neuron.NCycles; cyc++ {
 		switch cyc {
-		case ss.OnCycle:
+		case ss.Neuron.OnCycle:
 			inputOn = true
-		case ss.OffCycle:
+		case ss.Neuron.OffCycle:
 			inputOn = false
 		}
+		ge := ss.Stim.Ge(cyc, ss.Neuron.OnCycle, ss.Neuron.OffCycle)
+		gi := ss.InhibStim.Ge(cyc, ss.Neuron.InhibOnCycle, ss.Neuron.InhibOffCycle)
 		nrn.Noise = float32(ly.Act.Noise.Gen())
-		if inputOn {
-			nrn.Ge = 1
-		} else {
-			nrn.Ge = 0
-		}
-		nrn.Ge += nrn.Noise // GeNoise
-		nrn.Gi = 0
-		if ss.Spike {
+		nrn.Ge = ge + nrn.Noise // GeNoise
+		nrn.Gi = gi
+		if ss.Neuron.Spike {
 			ss.SpikeUpdate(ss.Net, inputOn)
 		} else {
 			ss.RateUpdate(ss.Net, inputOn)
 		}
+		if ss.Pop.N > 0 {
+			ss.PopUpdate(cyc, ge, gi)
+		}
 		ctx.Cycle = cyc
 		ss.Logs.Log(etime.Test, etime.Cycle)
 		ss.RecordValues(cyc)
@@ -255,7 +391,9 @@ func (ss *Sim) RateUpdate(nt *leabra.Network, inputOn bool) {
 	nrn := &(ly.Neurons[0])
 	ly.Act.VmFromG(nrn)
 	ly.Act.ActFromG(nrn)
+	ss.Stats.SetFloat32("VmEq", VmEq(&ly.Act, nrn, nrn.Gk*ly.Act.Gbar.K))
 	nrn.Ge = nrn.Ge * ly.Act.Gbar.E // display effective Ge
+	nrn.Gi = nrn.Gi * ly.Act.Gbar.I // display effective Gi
 }
 
 // SpikeUpdate updates the neuron in spiking mode
@@ -265,7 +403,74 @@ func (ss *Sim) SpikeUpdate(nt *leabra.Network, inputOn bool) {
 	nrn := &(ly.Neurons[0])
 	ss.SpikeParams.SpikeVmFromG(nrn)
 	ss.SpikeParams.SpikeActFromVm(nrn)
+	// note: Gk already includes Gbar.K in spiking mode: SpikeVmFromG sets it
+	// to Gbar.K * (GknaFast + GknaMed + GknaSlow), while the rate code
+	// VmFromG multiplies Gk by Gbar.K, as checked by TestVmEq.
+	ss.Stats.SetFloat32("VmEq", VmEq(&ss.SpikeParams.ActParams, nrn, nrn.Gk))
 	nrn.Ge = nrn.Ge * ly.Act.Gbar.E // display effective Ge
+	nrn.Gi = nrn.Gi * ly.Act.Gbar.I // display effective Gi
+}
+
+// PopUpdate updates the neurons in the Population layer with the given
+// inputs, each with its own PopActs parameters, in spiking or rate-code
+// mode, and records their spikes in the raster and the population stats:
+// the proportion of neurons spiking on this cycle (PopSpike), their mean
+// activation (PopAct), and the Fano factor of their spike counts over the
+// last FanoWindow cycles (PopFano).
+func (ss *Sim) PopUpdate(cyc int, ge, gi float32) {
+	ly := ss.Net.LayerByName("Population")
+	rast := ss.Stats.F32Tensor("Raster_Population")
+	nspk, act := 0.0, 0.0
+	for ni := range ly.Neurons {
+		nrn := &ly.Neurons[ni]
+		ac := &ss.PopActs[ni]
+		nrn.Noise = float32(ac.Noise.Gen())
+		nrn.Ge = ge + nrn.Noise
+		nrn.Gi = gi
+		if ss.Neuron.Spike {
+			ac.SpikeVmFromG(nrn)
+			ac.SpikeActFromVm(nrn)
+		} else {
+			ac.VmFromG(nrn)
+			ac.ActFromG(nrn)
+		}
+		nrn.Ge = nrn.Ge * ac.Gbar.E // display effective Ge
+		nrn.Gi = nrn.Gi * ac.Gbar.I
+		nspk += float64(nrn.Spike)
+		act += float64(nrn.Act)
+		rast.Set([]int{ni, cyc}, nrn.Spike)
+	}
+	n := float64(len(ly.Neurons))
+	ss.Stats.SetFloat("PopSpike", nspk/n)
+	ss.Stats.SetFloat("PopAct", act/n)
+	ss.Stats.SetFloat("PopFano", FanoFactor(SpikeCounts(rast, max(cyc+1-ss.Pop.FanoWindow, 0), cyc+1)))
+}
+
+// PopStats computes the stats of the Population over the StimWindow
+// of the last run: the mean spiking rate of the neurons (PopHz), and
+// the Fano factor of their spike counts (PopFano).
+func (ss *Sim) PopStats() {
+	start, end := ss.StimWindow()
+	counts := SpikeCounts(ss.Stats.F32Tensor("Raster_Population"), start, end)
+	sum := 0.0
+	for _, c := range counts {
+		sum += c
+	}
+	ss.Stats.SetFloat("PopHz", 1000*sum/float64(len(counts)*(end-start)))
+	ss.Stats.SetFloat("PopFano", FanoFactor(counts))
+}
+
+// VmEq returns the equilibrium membrane potential for the current
+// conductances of the neuron, at which the net current Inet is 0:
+// the average of the reversal potentials weighted by their conductances.
+// This is the value that Vm goes toward, and it reaches it if the
+// conductances stay constant long enough, and it does not spike first.
+// gk is the effective potassium conductance, including Gbar.K.
+func VmEq(ac *leabra.ActParams, nrn *leabra.Neuron, gk float32) float32 {
+	ge := nrn.Ge * ac.Gbar.E
+	gi := nrn.Gi * ac.Gbar.I
+	gl := ac.Gbar.L
+	return (ge*ac.Erev.E + gl*ac.Erev.L + gi*ac.Erev.I + gk*ac.Erev.K) / (ge + gl + gi + gk)
 }
 
 func (ss *Sim) RecordValues(cyc int) {
@@ -277,6 +482,16 @@ func (ss *Sim) RecordValues(cyc int) {
 		vkey := key + fmt.Sprintf("\t%s", vnm)
 		ss.ValMap[vkey] = vals[0]
 	}
+	vmEq := ss.Stats.Float32("VmEq")
+	ss.ValMap[key+"\tVmEq"] = vmEq
+
+	pp := ss.Logs.MiscTable("PhasePlane")
+	row := pp.Rows
+	pp.SetNumRows(row + 1)
+	pp.SetFloat("Cycle", row, float64(cyc))
+	pp.SetFloat("Vm", row, float64(ss.ValMap[key+"\tVm"]))
+	pp.SetFloat("Inet", row, float64(ss.ValMap[key+"\tInet"]))
+	pp.SetFloat("VmEq", row, float64(vmEq))
 }
 
 // Stop tells the sim to stop running
@@ -284,19 +499,22 @@ func (ss *Sim) Stop() {
 	ss.GUI.StopNow = true
 }
 
-// SpikeVsRate runs comparison between spiking vs. rate-code
+// SpikeVsRate runs comparison between spiking vs. rate-code.
+// It restores the GbarE, Noise and Spike parameters that it varies
+// at the end, leaving the others as they were set.
 func (ss *Sim) SpikeVsRate() {
+	prevGbarE, prevNoise, prevSpike := ss.Neuron.GbarE, ss.Neuron.Noise, ss.Neuron.Spike
 	row := 0
 	nsamp := 100
-	// ss.KNaAdapt = false
+	// ss.Neuron.KNaAdapt = false
 	tcl := ss.Logs.Table(etime.Test, etime.Cycle)
 	svr := ss.Logs.MiscTable("SpikeVsRate")
 	svp := ss.GUI.Plots[etime.ScopeKey("SpikeVsRate")]
 	for gbarE := 0.1; gbarE <= 0.7; gbarE += 0.025 {
-		ss.GbarE = float32(gbarE)
+		ss.Neuron.GbarE = float32(gbarE)
 		spike := float64(0)
-		ss.Noise = 0.1 // RunCycles calls SetParams to set this
-		ss.Spike = true
+		ss.Neuron.Noise = 0.1 // RunCycles calls SetParams to set this
+		ss.Neuron.Spike = true
 		for ns := 0; ns < nsamp; ns++ {
 			tcl.Rows = 0
 			ss.RunCycles(false)
@@ -305,10 +523,11 @@ func (ss *Sim) SpikeVsRate() {
 			}
 			act := tcl.Float("Act", 159)
 			spike += act
+			simcore.WebYield()
 		}
 		rate := float64(0)
-		ss.Spike = false
-		// ss.Noise = 0 // doesn't make much diff
+		ss.Neuron.Spike = false
+		// ss.Neuron.Noise = 0 // doesn't make much diff
 		for ns := 0; ns < nsamp; ns++ {
 			tcl.Rows = 0
 			ss.RunCycles(false)
@@ -317,9 +536,7 @@ func (ss *Sim) SpikeVsRate() {
 			}
 			act := tcl.Float("Act", 159)
 			rate += act
-			if core.TheApp.Platform() == system.Web {
-				time.Sleep(time.Millisecond) // critical to prevent hanging!
-			}
+			simcore.WebYield()
 		}
 		if ss.GUI.StopNow {
 			break
@@ -330,13 +547,109 @@ func (ss *Sim) SpikeVsRate() {
 		svr.SetFloat("GBarE", row, gbarE)
 		svr.SetFloat("Spike", row, spike)
 		svr.SetFloat("Rate", row, rate)
-		svp.GoUpdatePlot()
+		if svp != nil {
+			svp.GoUpdatePlot()
+		}
 		row++
 	}
-	ss.Defaults()
-	svp.GoUpdatePlot()
+	ss.Neuron.GbarE, ss.Neuron.Noise, ss.Neuron.Spike = prevGbarE, prevNoise, prevSpike
+	ss.GUI.IsRunning = false
+	if svp != nil {
+		svp.GoUpdatePlot()
+		ss.GUI.UpdateWindow()
+	}
+}
+
+// StimWindow returns the cycles from which the stimulus starts, up to
+// which it ends, for the spike stats: the whole run for the Waveform input.
+func (ss *Sim) StimWindow() (start, end int) {
+	if ss.Stim.Type == Waveform {
+		return 0, ss.Neuron.NCycles
+	}
+	return ss.Neuron.OnCycle, ss.Neuron.OffCycle
+}
+
+// SpikeStats computes the stats of the spikes in the last run over the
+// StimWindow: the number of spikes (NSpikes), their rate (SpikeHz), the first
+// and last inter-spike intervals (ISIs), and the AdaptationIndex of the ISIs,
+// and the histogram of the ISIs in the ISIHist table.
+func (ss *Sim) SpikeStats() {
+	start, end := ss.StimWindow()
+	spikes := SpikeTimes(ss.Logs.Table(etime.Test, etime.Cycle), start, end)
+	isis := ISIs(spikes)
+	ss.Stats.SetFloat("NSpikes", float64(len(spikes)))
+	ss.Stats.SetFloat("SpikeHz", 1000*float64(len(spikes))/float64(end-start))
+	ss.Stats.SetFloat("FirstISI", math.NaN())
+	ss.Stats.SetFloat("LastISI", math.NaN())
+	if len(isis) > 0 {
+		ss.Stats.SetFloat("FirstISI", float64(isis[0]))
+		ss.Stats.SetFloat("LastISI", float64(isis[len(isis)-1]))
+	}
+	ss.Stats.SetFloat("AdaptIndex", AdaptationIndex(isis))
+	ih := ss.Logs.MiscTable("ISIHist")
+	ISIHist(ih, isis)
+	for _, st := range []string{"NSpikes", "SpikeHz", "FirstISI", "LastISI", "AdaptIndex"} {
+		ih.SetMetaData(st, fmt.Sprintf("%g", ss.Stats.Float(st)))
+	}
+	if plt := ss.Figures.PlotByName(&ss.GUI, "ISIHist"); plt != nil {
+		plt.Options.Title = fmt.Sprintf("ISI Histogram: %d spikes, %.1f Hz, adaptation index %.3f",
+			len(spikes), ss.Stats.Float("SpikeHz"), ss.Stats.Float("AdaptIndex"))
+		if ss.GUI.Active {
+			plt.GoUpdatePlot()
+		}
+	}
+}
+
+// FICurve runs the f-I curve analysis, running the Pulse input at FISteps + 1
+// amplitudes from 0 to Stim.Amp, in both spiking and rate-code modes, and
+// recording the SpikeStats and the mean activations during the input, and the
+// rate-code activation in Hz for comparison with the spiking rate, in the
+// FICurve table.
+func (ss *Sim) FICurve() {
+	stim, spk := ss.Stim, ss.Neuron.Spike
+	tcl := ss.Logs.Table(etime.Test, etime.Cycle)
+	fi := ss.Logs.MiscTable("FICurve")
+	fi.SetNumRows(0)
+	fip := ss.GUI.PlotByName("FICurve")
+	ss.Stim.Type = Pulse
+	for i := 0; i <= ss.Neuron.FISteps; i++ {
+		amp := stim.Amp * float32(i) / float32(ss.Neuron.FISteps)
+		ss.Stim.Amp = amp
+		ss.Neuron.Spike = true
+		tcl.Rows = 0
+		ss.RunCycles(false)
+		if ss.GUI.StopNow {
+			break
+		}
+		ss.SpikeStats()
+		spikeAct := WindowMean(tcl, "Act", ss.Neuron.OnCycle, ss.Neuron.OffCycle)
+		ss.Neuron.Spike = false
+		tcl.Rows = 0
+		ss.RunCycles(false)
+		if ss.GUI.StopNow {
+			break
+		}
+		rateAct := WindowMean(tcl, "Act", ss.Neuron.OnCycle, ss.Neuron.OffCycle)
+		fi.AddRows(1)
+		fi.SetFloat("Amp", i, float64(amp))
+		fi.SetFloat("Ge", i, float64(amp*ss.Neuron.GbarE))
+		for _, st := range []string{"NSpikes", "SpikeHz", "FirstISI", "LastISI", "AdaptIndex"} {
+			fi.SetFloat(st, i, ss.Stats.Float(st))
+		}
+		fi.SetFloat("RateCodeHz", i, rateAct*float64(ss.SpikeParams.Spike.MaxHz))
+		fi.SetFloat("SpikeAct", i, spikeAct)
+		fi.SetFloat("RateAct", i, rateAct)
+		if fip != nil {
+			fip.GoUpdatePlot()
+		}
+		simcore.WebYield()
+	}
+	ss.Stim, ss.Neuron.Spike = stim, spk
 	ss.GUI.IsRunning = false
-	ss.GUI.UpdateWindow()
+	if fip != nil {
+		fip.GoUpdatePlot()
+		ss.GUI.UpdateWindow()
+	}
 }
 
 /////////////////////////////////////////////////////////////////////////
@@ -349,15 +662,17 @@ func (ss *Sim) SpikeVsRate() {
 func (ss *Sim) SetParams(sheet string, setMsg bool) {
 	ss.Params.SetAll()
 	ly := ss.Net.LayerByName("Neuron")
-	ly.Act.Gbar.E = float32(ss.GbarE)
-	ly.Act.Gbar.L = float32(ss.GbarL)
-	ly.Act.Erev.E = float32(ss.ErevE)
-	ly.Act.Erev.L = float32(ss.ErevL)
-	ly.Act.Noise.Var = float64(ss.Noise)
-	ly.Act.KNa.On = ss.KNaAdapt
+	ly.Act.Gbar.E = float32(ss.Neuron.GbarE)
+	ly.Act.Gbar.L = float32(ss.Neuron.GbarL)
+	ly.Act.Erev.E = float32(ss.Neuron.ErevE)
+	ly.Act.Erev.L = float32(ss.Neuron.ErevL)
+	ly.Act.Gbar.I = float32(ss.Neuron.GbarI)
+	ly.Act.Erev.I = float32(ss.Neuron.ErevI)
+	ly.Act.Noise.Var = float64(ss.Neuron.Noise)
+	ly.Act.KNa.On = ss.Neuron.KNaAdapt
 	ly.Act.Update()
 	ss.SpikeParams.ActParams = ly.Act // keep sync'd
-	ss.SpikeParams.KNa.On = ss.KNaAdapt
+	ss.SpikeParams.KNa.On = ss.Neuron.KNaAdapt
 	ly.UpdateParams()
 }
 
@@ -365,7 +680,12 @@ func (ss *Sim) ConfigLogs() {
 	ss.ConfigLogItems()
 	ss.Logs.CreateTables()
 
-	ss.Logs.PlotItems("Ge", "Inet", "Vm", "Act", "Spike", "Gk")
+	ss.Logs.PlotItems("Ge", "Gi", "Inet", "Vm", "VmEq", "Act", "Spike", "Gk")
+	if ss.Pop.N > 0 {
+		ss.Logs.PlotItems("PopAct")
+		ss.Stats.ConfigRasters(ss.Net, ss.Neuron.NCycles, []string{"Population"})
+		ss.Stats.F32Tensor("Raster_Population").SetMetaData("grid-fill", "1")
+	}
 
 	ss.Logs.SetContext(&ss.Stats, ss.Net)
 	ss.Logs.ResetLog(etime.Test, etime.Cycle)
@@ -375,6 +695,27 @@ func (ss *Sim) ConfigLogs() {
 	svr.AddFloat64Column("Spike")
 	svr.AddFloat64Column("Rate")
 	svr.SetMetaData("Rate:On", "+")
+
+	fi := ss.Logs.MiscTable("FICurve")
+	for _, cn := range []string{"Amp", "Ge", "NSpikes", "SpikeHz", "RateCodeHz", "FirstISI", "LastISI", "AdaptIndex", "SpikeAct", "RateAct"} {
+		fi.AddFloat64Column(cn)
+	}
+	fi.SetMetaData("SpikeHz:On", "+")
+	fi.SetMetaData("RateCodeHz:On", "+")
+
+	ih := ss.Logs.MiscTable("ISIHist")
+	ih.AddFloat64Column("ISI")
+	ih.AddFloat64Column("N")
+	ih.SetMetaData("Type", "Bar")
+	ih.SetMetaData("N:On", "+")
+
+	pp := ss.Logs.MiscTable("PhasePlane")
+	pp.AddFloat64Column("Cycle")
+	pp.AddFloat64Column("Vm")
+	pp.AddFloat64Column("Inet")
+	pp.AddFloat64Column("VmEq")
+	pp.SetMetaData("Inet:On", "+")
+	pp.SetMetaData("Points", "true")
 }
 
 func (ss *Sim) ConfigLogItems() {
@@ -391,7 +732,7 @@ func (ss *Sim) ConfigLogItems() {
 				ctx.SetInt(int(ss.Context.Cycle))
 			}}})
 
-	vars := []string{"Ge", "Inet", "Vm", "Act", "Spike", "Gk", "ISI", "AvgISI"}
+	vars := []string{"Ge", "Gi", "Inet", "Vm", "Act", "Spike", "Gk", "ISI", "AvgISI"}
 
 	for _, vnm := range vars {
 		lg.AddItem(&elog.Item{
@@ -406,6 +747,31 @@ func (ss *Sim) ConfigLogItems() {
 				}}})
 	}
 
+	if ss.Pop.N > 0 {
+		for _, st := range []string{"PopSpike", "PopAct", "PopFano"} {
+			lg.AddItem(&elog.Item{
+				Name:   st,
+				Type:   reflect.Float64,
+				FixMax: false,
+				Range:  minmax.F32{Max: 1},
+				Write: elog.WriteMap{
+					etime.Scope(etime.Test, etime.Cycle): func(ctx *elog.Context) {
+						ctx.SetStatFloat(st)
+					}}})
+		}
+	}
+
+	// equilibrium Vm for the current conductances, to compare with Vm
+	lg.AddItem(&elog.Item{
+		Name:   "VmEq",
+		Type:   reflect.Float64,
+		FixMax: false,
+		Range:  minmax.F32{Max: 1},
+		Write: elog.WriteMap{
+			etime.Scope(etime.Test, etime.Cycle): func(ctx *elog.Context) {
+				ctx.SetStatFloat("VmEq")
+			}}})
+
 }
 
 func (ss *Sim) ResetTestCyclePlot() {
@@ -434,16 +800,41 @@ func (ss *Sim) ConfigGUI() {
 	ss.ViewUpdate.Config(nv, etime.AlphaCycle, etime.AlphaCycle)
 	ss.GUI.ViewUpdate = &ss.ViewUpdate
 
-	ss.GUI.AddPlots(title, &ss.Logs)
+	ss.ConfigPlots(simcore.GUIPlots{GUI: &ss.GUI, Tabs: map[string]string{"SpikeVsRate": "SpikeVsRate Plot"}})
+
+	ss.GUI.FinalizeGUI(false)
+}
+
+// ConfigPlots adds the log plots, the plots of the SpikeVsRate,
+// FICurve, ISIHist and PhasePlane analyses, and the spike Raster
+// of the population, if any, to the GUI or the Figures.
+func (ss *Sim) ConfigPlots(pl simcore.Plots) {
+	pl.AddLogPlots("Neuron", &ss.Logs)
 
 	svr := "SpikeVsRate"
-	dt := ss.Logs.MiscTable(svr)
-	plt := ss.GUI.AddMiscPlotTab(svr + " Plot")
+	plt := pl.AddPlot(svr)
 	plt.Options.Title = svr
 	plt.Options.XAxis = "GBarE"
-	plt.SetTable(dt)
+	plt.SetTable(ss.Logs.MiscTable(svr))
 
-	ss.GUI.FinalizeGUI(false)
+	plt = pl.AddPlot("FICurve")
+	plt.Options.Title = "F-I Curve"
+	plt.Options.XAxis = "Amp"
+	plt.SetTable(ss.Logs.MiscTable("FICurve"))
+
+	plt = pl.AddPlot("ISIHist")
+	plt.Options.Title = "ISI Histogram"
+	plt.Options.XAxis = "ISI"
+	plt.SetTable(ss.Logs.MiscTable("ISIHist"))
+
+	plt = pl.AddPlot("PhasePlane")
+	plt.Options.Title = "Phase Plane: Inet vs. Vm"
+	plt.Options.XAxis = "Vm"
+	plt.SetTable(ss.Logs.MiscTable("PhasePlane"))
+
+	if ss.Pop.N > 0 {
+		pl.AddGrid("Raster", ss.Stats.F32Tensor("Raster_Population"))
+	}
 }
 
 func (ss *Sim) MakeToolbar(p *tree.Plan) {
@@ -471,6 +862,10 @@ func (ss *Sim) MakeToolbar(p *tree.Plan) {
 				go func() {
 					ss.GUI.IsRunning = true
 					ss.RunCycles(true)
+					ss.SpikeStats()
+					if ss.Pop.N > 0 {
+						ss.PopStats()
+					}
 					ss.GUI.IsRunning = false
 					ss.GUI.UpdateWindow()
 				}()
@@ -495,6 +890,15 @@ func (ss *Sim) MakeToolbar(p *tree.Plan) {
 			ss.GUI.UpdateWindow()
 		},
 	})
+	ss.GUI.AddToolbarItem(p, egui.ToolbarItem{Label: "F-I Curve", Icon: icons.PlayArrow,
+		Tooltip: "Generate the f-I curve of spiking rate vs. Pulse input amplitude, with the ISIs and adaptation index, and the NXX1 rate code in Hz.",
+		Active:  egui.ActiveStopped,
+		Func: func() {
+			ss.GUI.IsRunning = true
+			go ss.FICurve()
+			ss.GUI.UpdateWindow()
+		},
+	})
 
 	ss.GUI.AddToolbarItem(p, egui.ToolbarItem{Label: "Defaults", Icon: icons.Update,
 		Tooltip: "Restore initial default parameters.",
@@ -521,3 +925,66 @@ func (ss *Sim) RunGUI() {
 	ss.ConfigGUI()
 	ss.GUI.Body.RunMainWindow()
 }
+
+// RunNoGUI runs without the GUI, either running NCycles and saving
+// the cycle log and ISI histogram, or running SpikeVsRate or FICurve
+// and saving its results. Any error, including a Waveform stimulus
+// that cannot be loaded, is printed and exits the program,
+// as this is only used from the command line.
+func (ss *Sim) RunNoGUI() {
+	if err := ss.runNoGUI(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func (ss *Sim) runNoGUI() error {
+	runName := ss.Params.RunName(0)
+	netName := ss.Net.Name
+	if err := errors.Join(ss.Stim.Init(), ss.InhibStim.Init()); err != nil {
+		return err
+	}
+	if ss.Config.Figures != "" {
+		ss.Figures.Config(ss.Net, ss.ConfigPlots)
+	}
+	switch {
+	case ss.Config.SpikeVsRate:
+		ss.SpikeVsRate()
+		if err := simcore.SaveTable(ss.Logs.MiscTable("SpikeVsRate"), "spike_vs_rate", netName, runName); err != nil {
+			return err
+		}
+	case ss.Config.FICurve:
+		ss.FICurve()
+		if err := simcore.SaveTable(ss.Logs.MiscTable("FICurve"), "fi_curve", netName, runName); err != nil {
+			return err
+		}
+	default:
+		elog.SetLogFile(&ss.Logs, ss.Config.Log.Cycle, etime.Test, etime.Cycle, "cyc", netName, runName)
+		ss.RunCycles(false)
+		ss.Logs.CloseLogFiles()
+		ss.SpikeStats()
+		fmt.Printf("Spikes: %d  Rate: %.1f Hz  Adaptation index: %.3f\n", int(ss.Stats.Float("NSpikes")), ss.Stats.Float("SpikeHz"), ss.Stats.Float("AdaptIndex"))
+		if ss.Pop.N > 0 {
+			ss.PopStats()
+			fmt.Printf("Population: %d neurons  Rate: %.1f Hz  Fano factor: %.3f\n", ss.Pop.N, ss.Stats.Float("PopHz"), ss.Stats.Float("PopFano"))
+		}
+		if ss.Config.Log.ISIHist {
+			if err := simcore.SaveTable(ss.Logs.MiscTable("ISIHist"), "isi_hist", netName, runName); err != nil {
+				return err
+			}
+		}
+	}
+
+	if ss.Config.Figures != "" {
+		if err := ss.Figures.Save(ss.Config.Figures, netName, runName); err != nil {
+			return err
+		}
+	}
+
+	if ss.Config.Export != "" {
+		if err := simcore.SaveLogsNPZ(&ss.Logs, ss.Config.Export, netName, runName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"cogentcore.org/core/math32"
+	"github.com/emer/leabra/v2/leabra"
+	"github.com/emer/leabra/v2/spike"
+)
+
+// TestVmEq checks VmEq against the Vm that a neuron settles to with
+// clamped conductances, in rate-code and spiking mode, with the Gk
+// given as in RateUpdate and SpikeUpdate, and a Gbar.K other than 1,
+// so that the Vm is off if Gbar.K is left out or applied twice.
+func TestVmEq(t *testing.T) {
+	ac := &leabra.ActParams{}
+	ac.Defaults()
+	ac.Gbar.E = 0.3
+	ac.Gbar.L = 0.3
+	ac.Gbar.I = 1
+	ac.Gbar.K = 2
+	ac.Noise.Type = leabra.NoNoise
+	ac.Update()
+	sp := &spike.ActParams{}
+	sp.Defaults()
+	sp.CopyFromAct(ac)
+
+	clamp := func(nrn *leabra.Neuron) {
+		nrn.Ge = 0.3 // below threshold, so that it does not spike
+		nrn.Gi = 0.05
+		nrn.GknaFast, nrn.GknaMed, nrn.GknaSlow = 0.02, 0.03, 0.05
+		nrn.Gk = nrn.GknaFast + nrn.GknaMed + nrn.GknaSlow
+	}
+
+	var rate leabra.Neuron
+	ac.InitActs(&rate)
+	for range 1000 {
+		clamp(&rate)
+		ac.VmFromG(&rate)
+	}
+	want := VmEq(ac, &rate, rate.Gk*ac.Gbar.K)
+	if math32.Abs(rate.Vm-want) > 1.0e-4 {
+		t.Errorf("rate code: settled Vm = %g != VmEq = %g", rate.Vm, want)
+	}
+
+	var spk leabra.Neuron
+	ac.InitActs(&spk)
+	for range 1000 {
+		clamp(&spk)
+		sp.SpikeVmFromG(&spk)
+	}
+	if spk.Vm >= sp.XX1.Thr {
+		t.Fatalf("spiking: settled Vm = %g is not below the threshold %g", spk.Vm, sp.XX1.Thr)
+	}
+	if got := VmEq(&sp.ActParams, &spk, spk.Gk); math32.Abs(spk.Vm-got) > 1.0e-4 {
+		t.Errorf("spiking: settled Vm = %g != VmEq = %g", spk.Vm, got)
+	}
+	if math32.Abs(spk.Vm-rate.Vm) > 1.0e-4 {
+		t.Errorf("spiking: settled Vm = %g != rate code Vm = %g", spk.Vm, rate.Vm)
+	}
+}
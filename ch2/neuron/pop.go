@@ -0,0 +1,81 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cogentcore.org/core/base/randx"
+	"github.com/emer/leabra/v2/spike"
+)
+
+// PopParams are the parameters for a Population layer of neurons that
+// all get the same input as the single Neuron, but each with its own
+// parameters, drawn from distributions around the main parameters,
+// so that the population rate code can be compared with the
+// spiking of the individual neurons.
+type PopParams struct {
+
+	// number of neurons in the Population layer: 0 for no population.
+	// This can only be set in the Config, because it determines the network.
+	N int `default:"0" min:"0" edit:"-"`
+
+	// distribution of the parameters across the population, each of which
+	// has the main parameter value as its mean, and the variability given
+	// below (the standard deviation for Gaussian, half-range for Uniform)
+	Dist randx.RandDists `default:"Gaussian"`
+
+	// variability of the GbarE excitatory conductance of each neuron
+	GbarEVar float32 `default:"0.05" min:"0" step:"0.01"`
+
+	// variability of the GbarL leak conductance of each neuron
+	GbarLVar float32 `default:"0.02" min:"0" step:"0.01"`
+
+	// variability of the firing threshold of each neuron (.5 by default)
+	ThrVar float32 `default:"0.02" min:"0" step:"0.01"`
+
+	// mean noise of the population neurons, added to Noise, so that
+	// each neuron is a noisy spiker even when the single Neuron is not
+	Noise float32 `default:"0.1" min:"0" step:"0.01"`
+
+	// variability of the noise of each neuron
+	NoiseVar float32 `default:"0.05" min:"0" step:"0.01"`
+
+	// number of cycles over which to count the spikes of each neuron
+	// for the PopFano Fano factor of the spike counts on each cycle
+	FanoWindow int `default:"50" min:"1"`
+}
+
+func (pp *PopParams) Defaults() {
+	pp.N = 0
+	pp.Dist = randx.Gaussian
+	pp.GbarEVar = 0.05
+	pp.GbarLVar = 0.02
+	pp.ThrVar = 0.02
+	pp.Noise = 0.1
+	pp.NoiseVar = 0.05
+	pp.FanoWindow = 50
+}
+
+// Gen returns a value drawn from the Dist distribution with the given
+// mean and variability, which is at least 0.
+func (pp *PopParams) Gen(mean, vr float32) float32 {
+	rp := randx.RandParams{Dist: pp.Dist, Mean: float64(mean), Var: float64(vr), Par: 1}
+	return max(float32(rp.Gen()), 0)
+}
+
+// Draw returns the parameters for each of the N neurons, drawn around
+// the given base parameters, with the given base noise.
+func (pp *PopParams) Draw(base *spike.ActParams, noise float32) []spike.ActParams {
+	acts := make([]spike.ActParams, pp.N)
+	for i := range acts {
+		ac := &acts[i]
+		*ac = *base
+		ac.Gbar.E = pp.Gen(base.Gbar.E, pp.GbarEVar)
+		ac.Gbar.L = pp.Gen(base.Gbar.L, pp.GbarLVar)
+		ac.XX1.Thr = pp.Gen(base.XX1.Thr, pp.ThrVar)
+		ac.Noise.Var = float64(pp.Gen(noise+pp.Noise, pp.NoiseVar))
+		ac.Update()
+	}
+	return acts
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+
+	"cogentcore.org/core/tensor"
+	"cogentcore.org/core/tensor/table"
+)
+
+// SpikeTimes returns the cycles of the spikes in the given cycle log,
+// from the start cycle up to the end cycle.
+func SpikeTimes(dt *table.Table, start, end int) []int {
+	var spikes []int
+	for row := range dt.Rows {
+		cyc := int(dt.Float("Cycle", row))
+		if cyc >= start && cyc < end && dt.Float("Spike", row) > 0 {
+			spikes = append(spikes, cyc)
+		}
+	}
+	return spikes
+}
+
+// ISIs returns the inter-spike intervals, in cycles, of the given spike times.
+func ISIs(spikes []int) []int {
+	var isis []int
+	for i := 1; i < len(spikes); i++ {
+		isis = append(isis, spikes[i]-spikes[i-1])
+	}
+	return isis
+}
+
+// AdaptationIndex returns the adaptation index of the given ISIs, which is
+// the mean of (ISI[k+1] - ISI[k]) / (ISI[k+1] + ISI[k]) across consecutive
+// ISIs: it is positive when the ISIs get longer (spike rate adaptation),
+// 0 for regular spiking, and NaN if there are fewer than 2 ISIs.
+func AdaptationIndex(isis []int) float64 {
+	if len(isis) < 2 {
+		return math.NaN()
+	}
+	sum := 0.0
+	for i := 1; i < len(isis); i++ {
+		sum += float64(isis[i]-isis[i-1]) / float64(isis[i]+isis[i-1])
+	}
+	return sum / float64(len(isis)-1)
+}
+
+// WindowMean returns the mean of the given column of the given cycle log,
+// from the start cycle up to the end cycle.
+func WindowMean(dt *table.Table, column string, start, end int) float64 {
+	sum, n := 0.0, 0
+	for row := range dt.Rows {
+		cyc := int(dt.Float("Cycle", row))
+		if cyc >= start && cyc < end {
+			sum += dt.Float(column, row)
+			n++
+		}
+	}
+	if n == 0 {
+		return math.NaN()
+	}
+	return sum / float64(n)
+}
+
+// ISIHist sets the given table, with ISI and N columns, to the histogram
+// of the given ISIs, with one row for each ISI in cycles from 1
+// up to the longest one, with the number of times it occurred.
+func ISIHist(dt *table.Table, isis []int) {
+	mx := 0
+	for _, isi := range isis {
+		mx = max(mx, isi)
+	}
+	dt.SetNumRows(mx)
+	for row := range mx {
+		dt.SetFloat("ISI", row, float64(row+1))
+		dt.SetFloat("N", row, 0)
+	}
+	for _, isi := range isis {
+		dt.SetFloat("N", isi-1, dt.Float("N", isi-1)+1)
+	}
+}
+
+// FanoFactor returns the Fano factor of the given spike counts, which is
+// their variance divided by their mean: 1 for Poisson spiking, and
+// lower for more regular spiking. It is NaN if there are no spikes.
+func FanoFactor(counts []float64) float64 {
+	sum := 0.0
+	for _, c := range counts {
+		sum += c
+	}
+	if sum == 0 {
+		return math.NaN()
+	}
+	mean := sum / float64(len(counts))
+	vr := 0.0
+	for _, c := range counts {
+		vr += (c - mean) * (c - mean)
+	}
+	vr /= float64(len(counts))
+	return vr / mean
+}
+
+// SpikeCounts returns the number of spikes of each neuron in the given
+// raster, with shape [neurons, cycles], from the start cycle up to the end cycle.
+func SpikeCounts(rast *tensor.Float32, start, end int) []float64 {
+	counts := make([]float64, rast.DimSize(0))
+	for ni := range counts {
+		for cyc := start; cyc < end; cyc++ {
+			counts[ni] += float64(rast.Value([]int{ni, cyc}))
+		}
+	}
+	return counts
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"cogentcore.org/core/base/errors"
+	"cogentcore.org/core/base/randx"
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/tensor/table"
+)
+
+// StimTypes are the types of stimulus protocols for the
+// excitatory input into the neuron, as in current injection
+// experiments in an electrophysiology lab.
+type StimTypes int32 //enums:enum
+
+const (
+	// Pulse is a single square pulse of input at Amp from OnCycle to OffCycle.
+	Pulse StimTypes = iota
+
+	// Ramp increases the input linearly from 0 at OnCycle to Amp at OffCycle.
+	Ramp
+
+	// Steps are NSteps steps of increasing input from OnCycle to OffCycle,
+	// each of equal duration, going up by Amp / NSteps each step.
+	Steps
+
+	// Sine is a sinusoidal input from 0 to Amp at Freq Hz from OnCycle to OffCycle,
+	// starting at 0.
+	Sine
+
+	// Poisson is Poisson synaptic input from OnCycle to OffCycle, with events at
+	// Rate Hz, each adding SynGe to the input, which decays with time constant SynTau.
+	Poisson
+
+	// Waveform is the input on each cycle from the File, starting at cycle 0
+	// regardless of OnCycle and OffCycle, and 0 after the end of it.
+	Waveform
+)
+
+// StimParams are the parameters of the stimulus protocol, which determines
+// the excitatory input into the neuron (Ge, before GbarE) on each cycle.
+// Each cycle is 1 msec.
+type StimParams struct {
+
+	// type of stimulus protocol
+	Type StimTypes
+
+	// amplitude of the input: the value for Pulse, the maximum for the others
+	Amp float32 `default:"1" min:"0" step:"0.1"`
+
+	// number of steps for Steps
+	NSteps int `default:"5" min:"1"`
+
+	// frequency of the Sine input, in Hz
+	Freq float32 `default:"10" min:"0"`
+
+	// rate of Poisson input events, in Hz
+	Rate float32 `default:"200" min:"0"`
+
+	// input added by each Poisson input event
+	SynGe float32 `default:"0.2" min:"0" step:"0.05"`
+
+	// time constant in cycles for the decay of Poisson input
+	SynTau float32 `default:"10" min:"1"`
+
+	// file with the Waveform input, a .tsv table with a Ge column
+	// or otherwise the input values in its first numerical column
+	File core.Filename `ext:".tsv"`
+
+	// the loaded Waveform input values
+	Values []float32 `display:"-"`
+
+	// the File that Values was loaded from
+	loaded core.Filename
+
+	// current Poisson input
+	syn float32
+}
+
+func (sp *StimParams) Defaults() {
+	sp.Type = Pulse
+	sp.Amp = 1
+	sp.NSteps = 5
+	sp.Freq = 10
+	sp.Rate = 200
+	sp.SynGe = 0.2
+	sp.SynTau = 10
+}
+
+// Init initializes the stimulus at the start of a run, opening
+// the File for the Waveform input if it is not already loaded.
+// It returns an error if the Waveform File is not set or cannot
+// be opened, in which case the input is 0.
+func (sp *StimParams) Init() error {
+	sp.syn = 0
+	if sp.Type != Waveform || (sp.File != "" && sp.File == sp.loaded) {
+		return nil
+	}
+	sp.Values = nil
+	sp.loaded = ""
+	if sp.File == "" {
+		return errors.New("StimParams: the Waveform input needs a File")
+	}
+	if err := sp.OpenWaveform(sp.File); err != nil {
+		return err
+	}
+	sp.loaded = sp.File
+	return nil
+}
+
+// OpenWaveform opens the input values for the Waveform input from the
+// given .tsv file, from its Ge column, or otherwise its first numerical column.
+func (sp *StimParams) OpenWaveform(fnm core.Filename) error {
+	dt := table.NewTable()
+	if err := dt.OpenCSV(fnm, table.Tab); err != nil {
+		return err
+	}
+	cl, err := dt.ColumnByName("Ge")
+	if err != nil {
+		for _, c := range dt.Columns {
+			if !c.IsString() {
+				cl = c
+				break
+			}
+		}
+	}
+	if cl == nil {
+		return fmt.Errorf("OpenWaveform: no numerical column in %q", fnm)
+	}
+	sp.Values = make([]float32, dt.Rows)
+	for i := range sp.Values {
+		sp.Values[i] = float32(cl.Float1D(i))
+	}
+	return nil
+}
+
+// Ge returns the input on the given cycle, for input on from
+// the onCycle up to the offCycle.
+func (sp *StimParams) Ge(cyc, onCycle, offCycle int) float32 {
+	if sp.Type == Waveform {
+		if cyc < len(sp.Values) {
+			return sp.Values[cyc]
+		}
+		return 0
+	}
+	if cyc < onCycle || cyc >= offCycle {
+		sp.syn = 0
+		return 0
+	}
+	t := float32(cyc - onCycle)
+	dur := float32(offCycle - onCycle)
+	switch sp.Type {
+	case Ramp:
+		return sp.Amp * t / dur
+	case Steps:
+		step := int(t * float32(sp.NSteps) / dur)
+		return sp.Amp * float32(step+1) / float32(sp.NSteps)
+	case Sine:
+		return sp.Amp * 0.5 * (1 - float32(math.Cos(2*math.Pi*float64(sp.Freq*t)/1000)))
+	case Poisson:
+		sp.syn -= sp.syn / sp.SynTau
+		sp.syn += sp.SynGe * float32(randx.PoissonGen(float64(sp.Rate)/1000))
+		return sp.syn
+	}
+	return sp.Amp
+}
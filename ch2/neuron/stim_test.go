@@ -0,0 +1,49 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cogentcore.org/core/core"
+)
+
+// TestStimWaveform checks that the Waveform input is loaded from its File,
+// and that Init returns an error, without marking the File as loaded,
+// when the File is not set or cannot be opened.
+func TestStimWaveform(t *testing.T) {
+	sp := &StimParams{}
+	sp.Defaults()
+	sp.Type = Waveform
+	if err := sp.Init(); err == nil {
+		t.Error("no error for a Waveform without a File")
+	}
+
+	fnm := core.Filename(filepath.Join(t.TempDir(), "wave.tsv"))
+	sp.File = fnm
+	if err := sp.Init(); err == nil {
+		t.Error("no error for a missing Waveform File")
+	}
+	if sp.loaded != "" {
+		t.Errorf("loaded = %q after an error", sp.loaded)
+	}
+
+	if err := os.WriteFile(string(fnm), []byte("Cycle\tGe\n0\t0.25\n1\t0.5\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := sp.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if sp.loaded != fnm || len(sp.Values) != 2 {
+		t.Fatalf("loaded = %q, Values = %v", sp.loaded, sp.Values)
+	}
+	for cyc, ge := range []float32{0.25, 0.5, 0} {
+		if g := sp.Ge(cyc, 10, 20); g != ge {
+			t.Errorf("Ge(%d) = %g != %g", cyc, g, ge)
+		}
+	}
+}
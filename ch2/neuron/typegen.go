@@ -6,4 +6,16 @@ import (
 	"cogentcore.org/core/types"
 )
 
-var _ = types.AddType(&types.Type{Name: "main.Sim", IDName: "sim", Doc: "Sim encapsulates the entire simulation model, and we define all the\nfunctionality as methods on this struct.  This structure keeps all relevant\nstate information organized and available without having to pass everything around\nas arguments to methods, and provides the core GUI interface (note the view tags\nfor the fields which provide hints to how things should be displayed).", Fields: []types.Field{{Name: "Spike", Doc: "use discrete spiking equations -- otherwise use Noisy X-over-X-plus-1 rate code activation function"}, {Name: "GbarE", Doc: "excitatory conductance multiplier -- determines overall value of Ge which drives neuron to be more excited -- pushes up over threshold to fire if strong enough"}, {Name: "GbarL", Doc: "leak conductance -- determines overall value of Gl which drives neuron to be less excited (inhibited) -- pushes back to resting membrane potential"}, {Name: "ErevE", Doc: "excitatory reversal (driving) potential -- determines where excitation pushes Vm up to"}, {Name: "ErevL", Doc: "leak reversal (driving) potential -- determines where excitation pulls Vm down to"}, {Name: "Noise", Doc: "the variance parameter for Gaussian noise added to unit activations on every cycle"}, {Name: "KNaAdapt", Doc: "apply sodium-gated potassium adaptation mechanisms that cause the neuron to reduce spiking over time"}, {Name: "NCycles", Doc: "total number of cycles to run"}, {Name: "OnCycle", Doc: "when does excitatory input into neuron come on?"}, {Name: "OffCycle", Doc: "when does excitatory input into neuron go off?"}, {Name: "UpdateInterval", Doc: "how often to update display (in cycles)"}, {Name: "Net", Doc: "the network -- click to view / edit parameters for layers, paths, etc"}, {Name: "SpikeParams"}, {Name: "Context", Doc: "leabra timing parameters and state"}, {Name: "Stats", Doc: "contains computed statistic values"}, {Name: "Logs", Doc: "logging"}, {Name: "Params", Doc: "all parameter management"}, {Name: "ViewUpdate", Doc: "netview update parameters"}, {Name: "GUI", Doc: "manages all the gui elements"}, {Name: "ValMap", Doc: "map of values for detailed debugging / testing"}}})
+var _ = types.AddType(&types.Type{Name: "main.NeuronParams", IDName: "neuron-params", Doc: "NeuronParams are the parameters of the Neuron: its conductances and\nreversal potentials, and the timing of its excitatory and inhibitory inputs.", Fields: []types.Field{{Name: "Spike", Doc: "use discrete spiking equations -- otherwise use Noisy X-over-X-plus-1 rate code activation function"}, {Name: "GbarE", Doc: "excitatory conductance multiplier -- determines overall value of Ge which drives neuron to be more excited -- pushes up over threshold to fire if strong enough"}, {Name: "GbarL", Doc: "leak conductance -- determines overall value of Gl which drives neuron to be less excited (inhibited) -- pushes back to resting membrane potential"}, {Name: "ErevE", Doc: "excitatory reversal (driving) potential -- determines where excitation pushes Vm up to"}, {Name: "ErevL", Doc: "leak reversal (driving) potential -- determines where excitation pulls Vm down to"}, {Name: "GbarI", Doc: "inhibitory conductance multiplier -- determines overall value of Gi from the InhibStim input, which pulls Vm toward ErevI -- 0 for no inhibition"}, {Name: "ErevI", Doc: "inhibitory reversal (driving) potential -- determines where inhibition pulls Vm down to -- at ErevL (.3) it is purely shunting"}, {Name: "Noise", Doc: "the variance parameter for Gaussian noise added to unit activations on every cycle"}, {Name: "KNaAdapt", Doc: "apply sodium-gated potassium adaptation mechanisms that cause the neuron to reduce spiking over time"}, {Name: "NCycles", Doc: "total number of cycles to run"}, {Name: "OnCycle", Doc: "when does excitatory input into neuron come on?"}, {Name: "OffCycle", Doc: "when does excitatory input into neuron go off?"}, {Name: "InhibOnCycle", Doc: "when does inhibitory input into neuron come on?"}, {Name: "InhibOffCycle", Doc: "when does inhibitory input into neuron go off?"}, {Name: "FISteps", Doc: "number of steps of input amplitude from 0 to Stim.Amp for the FICurve"}}})
+
+var _ = types.AddType(&types.Type{Name: "main.Config", IDName: "config", Doc: "Config has config parameters related to running the sim.\nIts Neuron, Stim, InhibStim and Pop are the initial Sim parameters,\ne.g., -GbarE 0.4 -NoKNaAdapt for a more excitable neuron without adaptation.", Fields: []types.Field{{Name: "Neuron", Doc: "the starting Neuron parameters, e.g., -GbarL 0.5"}, {Name: "Stim", Doc: "stimulus protocol that determines the excitatory input on each cycle"}, {Name: "InhibStim", Doc: "stimulus protocol that determines the inhibitory input on each cycle,\nfrom InhibOnCycle to InhibOffCycle, scaled by GbarI"}, {Name: "Pop", Doc: "population of neurons with heterogeneous parameters, all getting the\nsame input as the single Neuron, e.g., -Pop.N 100"}, {Name: "FICurve", Doc: "run the FICurve analysis instead of a single run of NCycles,\nsaving the results to fi_curve.tsv, when running without the GUI."}, {Name: "SpikeVsRate", Doc: "run the SpikeVsRate comparison instead of a single run of NCycles,\nsaving the results to spike_vs_rate.tsv, when running without the GUI."}, {Name: "GUI", Doc: "GUI means open the GUI. Otherwise it runs automatically and quits,\nsaving log files as specified in Log."}, {Name: "Figures", Doc: "plots, grids or NetView to save as images without the GUI, or \"all\" (see simcore.Figures)"}, {Name: "Export", Doc: "logs to export to .npz files for Python without the GUI, or \"all\" (see simcore.SaveLogsNPZ)"}, {Name: "Log", Doc: "Log has config parameters related to logging data."}}})
+
+var _ = types.AddType(&types.Type{Name: "main.LogConfig", IDName: "log-config", Doc: "LogConfig has config parameters related to logging data", Fields: []types.Field{{Name: "Cycle", Doc: "if true, save cycle log to file, as .cyc.tsv typically"}, {Name: "ISIHist", Doc: "if true, save the histogram of the inter-spike intervals of the run\nto file, as isi_hist.tsv"}}})
+
+var _ = types.AddType(&types.Type{Name: "main.Sim", IDName: "sim", Doc: "Sim encapsulates the entire simulation model, and we define all the\nfunctionality as methods on this struct.  This structure keeps all relevant\nstate information organized and available without having to pass everything around\nas arguments to methods, and provides the core GUI interface (note the view tags\nfor the fields which provide hints to how things should be displayed).", Fields: []types.Field{{Name: "Neuron", Doc: "the parameters of the neuron and the timing of its inputs"}, {Name: "Stim", Doc: "stimulus protocol that determines the excitatory input on each cycle"}, {Name: "InhibStim", Doc: "stimulus protocol that determines the inhibitory input on each cycle,\nfrom InhibOnCycle to InhibOffCycle, scaled by GbarI"}, {Name: "Pop", Doc: "population of neurons with heterogeneous parameters, all getting the\nsame input as the single Neuron"}, {Name: "PopActs", Doc: "the parameters of each neuron in the population, drawn at the start of each run"}, {Name: "UpdateInterval", Doc: "how often to update display (in cycles)"}, {Name: "Config", Doc: "Config contains misc configuration parameters for running the sim"}, {Name: "Net", Doc: "the network -- click to view / edit parameters for layers, paths, etc"}, {Name: "SpikeParams"}, {Name: "Context", Doc: "leabra timing parameters and state"}, {Name: "Stats", Doc: "contains computed statistic values"}, {Name: "Logs", Doc: "logging"}, {Name: "Params", Doc: "all parameter management"}, {Name: "ViewUpdate", Doc: "netview update parameters"}, {Name: "GUI", Doc: "manages all the gui elements"}, {Name: "Figures", Doc: "the plots, grids and network snapshots that can be saved without the GUI"}, {Name: "ValMap", Doc: "map of values for detailed debugging / testing"}}})
+
+var _ = types.AddType(&types.Type{Name: "main.PopParams", IDName: "pop-params", Doc: "PopParams are the parameters for a Population layer of neurons that\nall get the same input as the single Neuron, but each with its own\nparameters, drawn from distributions around the main parameters,\nso that the population rate code can be compared with the\nspiking of the individual neurons.", Fields: []types.Field{{Name: "N", Doc: "number of neurons in the Population layer: 0 for no population.\nThis can only be set in the Config, because it determines the network."}, {Name: "Dist", Doc: "distribution of the parameters across the population, each of which\nhas the main parameter value as its mean, and the variability given\nbelow (the standard deviation for Gaussian, half-range for Uniform)"}, {Name: "GbarEVar", Doc: "variability of the GbarE excitatory conductance of each neuron"}, {Name: "GbarLVar", Doc: "variability of the GbarL leak conductance of each neuron"}, {Name: "ThrVar", Doc: "variability of the firing threshold of each neuron (.5 by default)"}, {Name: "Noise", Doc: "mean noise of the population neurons, added to Noise, so that\neach neuron is a noisy spiker even when the single Neuron is not"}, {Name: "NoiseVar", Doc: "variability of the noise of each neuron"}, {Name: "FanoWindow", Doc: "number of cycles over which to count the spikes of each neuron\nfor the PopFano Fano factor of the spike counts on each cycle"}}})
+
+var _ = types.AddType(&types.Type{Name: "main.StimTypes", IDName: "stim-types", Doc: "StimTypes are the types of stimulus protocols for the\nexcitatory input into the neuron, as in current injection\nexperiments in an electrophysiology lab."})
+
+var _ = types.AddType(&types.Type{Name: "main.StimParams", IDName: "stim-params", Doc: "StimParams are the parameters of the stimulus protocol, which determines\nthe excitatory input into the neuron (Ge, before GbarE) on each cycle.\nEach cycle is 1 msec.", Fields: []types.Field{{Name: "Type", Doc: "type of stimulus protocol"}, {Name: "Amp", Doc: "amplitude of the input: the value for Pulse, the maximum for the others"}, {Name: "NSteps", Doc: "number of steps for Steps"}, {Name: "Freq", Doc: "frequency of the Sine input, in Hz"}, {Name: "Rate", Doc: "rate of Poisson input events, in Hz"}, {Name: "SynGe", Doc: "input added by each Poisson input event"}, {Name: "SynTau", Doc: "time constant in cycles for the decay of Poisson input"}, {Name: "File", Doc: "file with the Waveform input, a .tsv table with a Ge column\nor otherwise the input values in its first numerical column"}, {Name: "Values", Doc: "the loaded Waveform input values"}, {Name: "loaded", Doc: "the File that Values was loaded from"}, {Name: "syn", Doc: "current Poisson input"}}})
@@ -12,7 +12,10 @@ package main
 //go:generate core generate -add-types
 
 import (
+	"cmp"
 	"embed"
+	"fmt"
+	"slices"
 
 	"cogentcore.org/core/base/errors"
 	"cogentcore.org/core/base/randx"
@@ -20,8 +23,11 @@ import (
 	"cogentcore.org/core/enums"
 	"cogentcore.org/core/icons"
 	"cogentcore.org/core/math32"
+	"cogentcore.org/core/plot/plotcore"
 	"cogentcore.org/core/tensor/table"
 	"cogentcore.org/core/tree"
+	"github.com/CompCogNeuro/sims/v2/simcore"
+	"github.com/emer/emergent/v2/econfig"
 	"github.com/emer/emergent/v2/egui"
 	"github.com/emer/emergent/v2/elog"
 	"github.com/emer/emergent/v2/emer"
@@ -36,14 +42,18 @@ import (
 	"github.com/emer/leabra/v2/leabra"
 )
 
-//go:embed cats_dogs_pats.tsv cats_dogs.wts
+//go:embed cats_dogs_pats.tsv cats_dogs_exemplars.tsv cats_dogs.wts
 var content embed.FS
 
 func main() {
 	sim := &Sim{}
 	sim.New()
 	sim.ConfigAll()
-	sim.RunGUI()
+	if sim.Config.GUI {
+		sim.RunGUI()
+	} else {
+		sim.RunNoGUI()
+	}
 }
 
 // ParamSets is the default set of parameters.
@@ -71,6 +81,67 @@ var ParamSets = params.Sets{
 				"Layer.Inhib.Layer.Gi": "4.0",
 			}},
 	},
+	"Train": {
+		{Sel: "Path", Desc: "pure Hebbian learning of the co-occurrence of features in the exemplars, from zero weights",
+			Params: params.Params{
+				"Path.Learn.Learn":        "true",
+				"Path.Learn.XCal.MLrn":    "0",
+				"Path.Learn.XCal.SetLLrn": "true",
+				"Path.Learn.XCal.LLrn":    "1",
+				"Path.WtInit.Mean":        "0",
+				"Path.WtInit.Var":         "0",
+				"Path.Learn.WtSig.Gain":   "1", // linear weights, which grow directly from zero
+				"Path.Learn.Norm.On":      "false",
+				"Path.Learn.Momentum.On":  "false",
+				"Path.Learn.Lrate":        "0.2",
+			}},
+	},
+}
+
+// Config has config parameters related to running the sim,
+// which can be set from the command line or config.toml.
+type Config struct {
+
+	// learn the weights from the exemplars in Train.File, instead of
+	// using the hand-set weights, before testing, when running without the GUI.
+	Learn bool
+
+	// parameters for learning the weights from the exemplars.
+	Train TrainParams `display:"add-fields" nest:"+"`
+
+	// space-separated features to clamp in a Query, e.g., "cat orange",
+	// which is run instead of the test patterns when running without the GUI,
+	// printing the ranked features and identities that it activates.
+	Query string
+
+	// number of cycles per trial
+	NCycles int `default:"100"`
+
+	// GUI means open the GUI. Otherwise it runs automatically and quits,
+	// saving log files as specified in Log.
+	GUI bool `default:"true"`
+
+	// plots, grids or NetView to save as images without the GUI, or "all" (see simcore.Figures)
+	Figures string
+
+	// logs to export to .npz files for Python without the GUI, or "all" (see simcore.SaveLogsNPZ)
+	Export string
+
+	// Log has config parameters related to logging data.
+	Log LogConfig `display:"add-fields"`
+}
+
+// LogConfig has config parameters related to logging data
+type LogConfig struct {
+
+	// if true, save training epoch log to file, as .epc.tsv typically
+	Epoch bool `default:"true" nest:"+"`
+
+	// if true, save testing trial log to file, as .tst_trl.tsv typically
+	TestTrial bool `default:"true" nest:"+"`
+
+	// if true, save testing cycle log to file, as .tst_cyc.tsv typically. May be large.
+	TestCycle bool `default:"false" nest:"+"`
 }
 
 // Sim encapsulates the entire simulation model, and we define all the
@@ -80,6 +151,9 @@ var ParamSets = params.Sets{
 // for the fields which provide hints to how things should be displayed).
 type Sim struct {
 
+	// Config contains misc configuration parameters for running the sim
+	Config Config `display:"-"`
+
 	// the network -- click to view / edit parameters for layers, paths, etc
 	Net *leabra.Network `new-window:"+" display:"no-inline"`
 
@@ -98,6 +172,12 @@ type Sim struct {
 	// the patterns to use
 	Patterns *table.Table `new-window:"+" display:"no-inline"`
 
+	// the exemplars to learn the weights from
+	Exemplars *table.Table `new-window:"+" display:"no-inline"`
+
+	// parameters for learning the weights from the exemplars
+	Train TrainParams `display:"add-fields"`
+
 	// Environments
 	Envs env.Envs `display:"-"`
 
@@ -110,6 +190,9 @@ type Sim struct {
 	// manages all the gui elements
 	GUI egui.GUI `display:"-"`
 
+	// the plots, grids and network snapshots that can be saved without the GUI
+	Figures simcore.Figures `display:"-"`
+
 	// a list of random seeds to use for each run
 	RandSeeds randx.Seeds `display:"-"`
 }
@@ -117,16 +200,20 @@ type Sim struct {
 // New creates new blank elements and initializes defaults
 func (ss *Sim) New() {
 	ss.Defaults()
+	econfig.Config(&ss.Config, "config.toml")
 	ss.Net = leabra.NewNetwork("CatsAndDogs")
 	ss.Params.Config(ParamSets, "", "", ss.Net)
 	ss.Stats.Init()
 	ss.Patterns = &table.Table{}
+	ss.Exemplars = &table.Table{}
+	ss.Train = ss.Config.Train
 	ss.RandSeeds.Init(100) // max 100 runs
 	ss.InitRandSeed(0)
 	ss.Context.Defaults()
 }
 
 func (ss *Sim) Defaults() {
+	ss.Train.Defaults()
 }
 
 //////////////////////////////////////////////////////////////////////////////
@@ -143,20 +230,26 @@ func (ss *Sim) ConfigAll() {
 
 func (ss *Sim) ConfigEnv() {
 	// Can be called multiple times -- don't re-create
-	var tst *env.FixedTable
+	var trn, tst *env.FixedTable
 	if len(ss.Envs) == 0 {
+		trn = &env.FixedTable{}
 		tst = &env.FixedTable{}
 	} else {
+		trn = ss.Envs.ByMode(etime.Train).(*env.FixedTable)
 		tst = ss.Envs.ByMode(etime.Test).(*env.FixedTable)
 	}
 
+	trn.Name = etime.Train.String()
+	trn.Config(table.NewIndexView(ss.Exemplars))
+	trn.Init(0)
+
 	tst.Name = etime.Test.String()
 	tst.Config(table.NewIndexView(ss.Patterns))
 	tst.Sequential = true
 	tst.Init(0)
 
 	// note: names must be in place when adding
-	ss.Envs.Add(tst)
+	ss.Envs.Add(trn, tst)
 }
 
 func (ss *Sim) ConfigNet(net *leabra.Network) {
@@ -210,6 +303,9 @@ func (ss *Sim) InitWeights(net *leabra.Network) {
 
 func (ss *Sim) ApplyParams() {
 	ss.Params.SetAll()
+	if ss.Loops != nil {
+		ss.Loops.Loop(etime.Train, etime.Epoch).Counter.Max = ss.Train.NEpochs
+	}
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -227,20 +323,56 @@ func (ss *Sim) Init() {
 	ss.ViewUpdate.Update()
 }
 
+// TrainInit restarts training, and initializes everything, including
+// network weights, which start from zero instead of the hand-set ones.
+func (ss *Sim) TrainInit() {
+	ss.Loops.ResetCounters()
+	ss.GUI.StopNow = false
+	ss.NewTrainRun()
+	ss.ViewUpdate.RecordSyns()
+	ss.ViewUpdate.Update()
+}
+
+// NewTrainRun initializes a new training run, with the Train params
+// for learning and zero weights, and the exemplars from Train.File,
+// and resets the training epoch log.
+func (ss *Sim) NewTrainRun() {
+	ctx := &ss.Context
+	ss.InitRandSeed(0)
+	ss.ApplyParams()
+	ss.Params.SetAllSheet("Train")
+	ss.OpenExemplars()
+	trn := ss.Envs.ByMode(etime.Train).(*env.FixedTable)
+	trn.Config(table.NewIndexView(ss.Exemplars))
+	trn.Init(0)
+	ss.Loops.Loop(etime.Train, etime.Trial).Counter.Max = ss.Exemplars.Rows
+	ctx.Reset()
+	ctx.Mode = etime.Train
+	ss.Net.InitWeights()
+	ss.InitStats()
+	ss.StatCounters()
+	ss.Logs.ResetLog(etime.Train, etime.Epoch)
+}
+
 // InitRandSeed initializes the random seed based on current training run number
 func (ss *Sim) InitRandSeed(run int) {
-	ss.RandSeeds.Set(run)
-	ss.RandSeeds.Set(run, &ss.Net.Rand)
+	simcore.InitRandSeed(&ss.RandSeeds, ss.Net, run)
 }
 
 // ConfigLoops configures the control loops: Training, Testing
 func (ss *Sim) ConfigLoops() {
 	ls := looper.NewStacks()
 
-	ev := ss.Envs.ByMode(etime.Test).(*env.FixedTable)
-	ntrls := ev.Table.Len()
+	ntrn := ss.Envs.ByMode(etime.Train).(*env.FixedTable).Table.Len()
+	ntrls := ss.Envs.ByMode(etime.Test).(*env.FixedTable).Table.Len()
+
+	cycles := ss.Config.NCycles
+	ls.AddStack(etime.Train).
+		AddTime(etime.Run, 1).
+		AddTime(etime.Epoch, ss.Train.NEpochs).
+		AddTime(etime.Trial, ntrn).
+		AddTime(etime.Cycle, cycles)
 
-	cycles := 100
 	ls.AddStack(etime.Test).
 		AddTime(etime.Epoch, 1).
 		AddTime(etime.Trial, ntrls).
@@ -248,6 +380,7 @@ func (ss *Sim) ConfigLoops() {
 
 	leabra.LooperStdPhases(ls, &ss.Context, ss.Net, cycles-50, cycles-1)
 	leabra.LooperSimCycleAndLearn(ls, ss.Net, &ss.Context, &ss.ViewUpdate) // std algo code
+	ls.Stacks[etime.Train].OnInit.Add("Init", func() { ss.TrainInit() })
 	ls.Stacks[etime.Test].OnInit.Add("Init", func() { ss.Init() })
 
 	for m, _ := range ls.Stacks {
@@ -257,6 +390,11 @@ func (ss *Sim) ConfigLoops() {
 		})
 	}
 
+	trainRun := ls.Loop(etime.Train, etime.Run)
+	trainRun.OnStart.Add("NewRun", ss.NewTrainRun)
+	// back to the Base params for testing, without learning, with the learned weights
+	trainRun.OnEnd.Add("ApplyParams", ss.ApplyParams)
+
 	/////////////////////////////////////////////
 	// Logging
 
@@ -268,9 +406,12 @@ func (ss *Sim) ConfigLoops() {
 	////////////////////////////////////////////
 	// GUI
 
-	leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
-	leabra.LooperUpdatePlots(ls, &ss.GUI)
-	ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	if ss.Config.GUI {
+		leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
+		leabra.LooperUpdatePlots(ls, &ss.GUI)
+		ls.Stacks[etime.Train].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+		ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	}
 
 	ss.Loops = ls
 }
@@ -317,6 +458,20 @@ func (ss *Sim) OpenPatterns() {
 	dt.SetMetaData("name", "CatsAndDogs")
 	dt.SetMetaData("desc", "Face testing patterns")
 	errors.Log(dt.OpenFS(content, "cats_dogs_pats.tsv", table.Tab))
+	ss.OpenExemplars()
+}
+
+// OpenExemplars opens the exemplars to learn the weights from,
+// from Train.File, or the default cats_dogs_exemplars.tsv if empty.
+func (ss *Sim) OpenExemplars() {
+	dt := ss.Exemplars
+	dt.SetMetaData("name", "Exemplars")
+	dt.SetMetaData("desc", "Exemplars to learn the weights from")
+	if ss.Train.File == "" {
+		errors.Log(dt.OpenFS(content, "cats_dogs_exemplars.tsv", table.Tab))
+	} else {
+		errors.Log(dt.OpenCSV(core.Filename(ss.Train.File), table.Tab))
+	}
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////
@@ -331,12 +486,7 @@ func (ss *Sim) InitStats() {
 // StatCounters saves current counters to Stats, so they are available for logging etc
 // Also saves a string rep of them for ViewUpdate.Text
 func (ss *Sim) StatCounters() {
-	ctx := &ss.Context
-	mode := ctx.Mode
-	ss.Loops.Stacks[mode].CountersToStats(&ss.Stats)
-	trl := ss.Stats.Int("Trial")
-	ss.Stats.SetInt("Trial", trl)
-	ss.Stats.SetInt("Cycle", int(ctx.Cycle))
+	simcore.StatCounters(ss.Loops, &ss.Context, &ss.Stats, false)
 }
 
 func (ss *Sim) NetViewCounters(tm etime.Times) {
@@ -350,39 +500,32 @@ func (ss *Sim) NetViewCounters(tm etime.Times) {
 	ss.ViewUpdate.Text = ss.Stats.Print([]string{"Trial", "TrialName", "Cycle"})
 }
 
-// Harmony computes the harmony (excitatory net input Ge * Act)
-func (ss *Sim) Harmony(nt *leabra.Network) float32 {
-	harm := float32(0)
-	nu := 0
-	for _, ly := range nt.Layers {
-		if ly.Off {
-			continue
-		}
-		for i := range ly.Neurons {
-			nrn := &(ly.Neurons[i])
-			harm += nrn.Ge * nrn.Act
-			nu++
-		}
-	}
-	if nu > 0 {
-		harm /= float32(nu)
-	}
-	return harm
-}
-
 //////////////////////////////////////////////////////////////////////////////
 // 		Logging
 
 func (ss *Sim) ConfigLogs() {
 	ss.Logs.AddCounterItems(etime.Trial, etime.Cycle)
 	ss.Logs.AddStatStringItem(etime.Test, etime.Trial, "TrialName")
-	ss.Logs.AddStatAggItem("Harmony", etime.Trial, etime.Cycle)
+	ss.Logs.AddStatIntNoAggItem(etime.Train, etime.Epoch, "Epoch")
+	hi := ss.Logs.AddStatAggItem("Harmony", etime.Trial, etime.Cycle)
+	// harmony of the clamped exemplars, which increases with learning
+	ss.Logs.AddStdAggs(hi, etime.Train, etime.Epoch, etime.Trial)
 
 	ss.Logs.AddLayerTensorItems(ss.Net, "Act", etime.Test, etime.Trial, "InputLayer", "CompareLayer")
 
 	ss.Logs.CreateTables()
 	ss.Logs.SetContext(&ss.Stats, ss.Net)
 	ss.Logs.PlotItems("Harmony")
+
+	dt := ss.Logs.MiscTable("Query")
+	dt.AddStringColumn("Layer")
+	dt.AddStringColumn("Feature")
+	dt.AddFloat64Column("Act")
+	dt.AddFloat64Column("Share")
+	dt.SetMetaData("Act:On", "+")
+	dt.SetMetaData("Act:FixMin", "+")
+	dt.SetMetaData("Act:FixMax", "+")
+	dt.SetMetaData("Act:Max", "1")
 }
 
 // Log is the main logging function, handles special things for different scopes
@@ -400,7 +543,7 @@ func (ss *Sim) Log(mode etime.Modes, time etime.Times) {
 	switch {
 	case time == etime.Cycle:
 		ss.StatCounters()
-		ss.Stats.SetFloat32("Harmony", ss.Harmony(ss.Net))
+		ss.Stats.SetFloat32("Harmony", simcore.Harmony(ss.Net))
 	case time == etime.Trial:
 		ss.StatCounters()
 		ss.Logs.Log(mode, time) // also logs to file, etc
@@ -409,6 +552,78 @@ func (ss *Sim) Log(mode etime.Modes, time etime.Times) {
 	ss.Logs.LogRow(mode, time, row) // also logs to file, etc
 }
 
+// Query clamps the given space-separated features, e.g., "cat orange",
+// each of which can be qualified by its layer, e.g., FavoriteToy:shoe
+// (see ParseCues), and settles the network for NCycles with the current
+// weights, leaving all the other units free to be activated. The Query
+// table has the units that are activated, other than the clamped ones,
+// ranked by their activation, with their Share of the total activation
+// of their layer as a measure of the confidence in them relative
+// to the other features in the same layer.
+func (ss *Sim) Query(features string) error { //types:add
+	cs, err := ParseCues(features)
+	if err != nil {
+		return errors.Log(err)
+	}
+	ctx := &ss.Context
+	net := ss.Net
+	net.InitActs()
+	net.AlphaCycInit(false)
+	ctx.AlphaCycStart()
+	net.InitExt()
+	for _, lnm := range Layers {
+		ly := net.LayerByName(lnm)
+		ext := make([]float32, len(ly.Neurons))
+		for _, c := range cs {
+			if c.Layer == lnm {
+				ext[c.Unit] = 1
+			}
+		}
+		ly.ApplyExt1D32(ext)
+	}
+	for range ss.Config.NCycles {
+		net.Cycle(ctx)
+		ctx.CycleInc()
+	}
+	ss.Stats.SetString("TrialName", features)
+	ss.Stats.SetFloat32("Harmony", simcore.Harmony(net))
+
+	type unit struct {
+		layer, feature string
+		act, share     float64
+	}
+	var units []unit
+	for _, lnm := range Layers {
+		ly := net.LayerByName(lnm)
+		sum := 0.0
+		for ni := range ly.Neurons {
+			sum += float64(ly.Neurons[ni].Act)
+		}
+		for ni := range ly.Neurons {
+			act := float64(ly.Neurons[ni].Act)
+			if IsCued(cs, lnm, ni) || act < 0.01 {
+				continue
+			}
+			units = append(units, unit{lnm, Features[lnm][ni], act, act / sum})
+		}
+	}
+	slices.SortStableFunc(units, func(a, b unit) int { return cmp.Compare(b.act, a.act) })
+	dt := ss.Logs.MiscTable("Query")
+	dt.SetNumRows(len(units))
+	for row, u := range units {
+		dt.SetString("Layer", row, u.layer)
+		dt.SetString("Feature", row, u.feature)
+		dt.SetFloat("Act", row, u.act)
+		dt.SetFloat("Share", row, u.share)
+	}
+	if ss.GUI.Active {
+		ss.GUI.PlotByName("Query").UpdatePlot()
+		ss.ViewUpdate.Text = ss.Stats.Print([]string{"TrialName", "Harmony"})
+		ss.ViewUpdate.Update()
+	}
+	return nil
+}
+
 ////////////////////////////////////////////////////////////////
 // 		GUI
 
@@ -447,14 +662,48 @@ func (ss *Sim) ConfigGUI() {
 	nv.Current()
 	ss.ConfigNetView(nv)
 
-	ss.GUI.AddPlots(title, &ss.Logs)
+	ss.ConfigPlots(simcore.GUIPlots{GUI: &ss.GUI})
 
 	ss.GUI.FinalizeGUI(false)
 }
 
+// ConfigPlots adds the log plots, and the Query bar plot of the
+// unit activations for the last query, to the GUI or the Figures.
+func (ss *Sim) ConfigPlots(pl simcore.Plots) {
+	pl.AddLogPlots("CatsAndDogs", &ss.Logs)
+
+	ss.ConfigQueryPlot(pl.AddPlot("Query"))
+}
+
+// ConfigQueryPlot configures the bar plot of the activations of the units
+// in the Query table, in ranked order.
+func (ss *Sim) ConfigQueryPlot(plt *plotcore.PlotEditor) {
+	plt.Options.Title = "Query: Ranked Activations"
+	plt.Options.Type = plotcore.Bar
+	plt.Options.XAxis = "Feature"
+	plt.SetTable(ss.Logs.MiscTable("Query"))
+}
+
 func (ss *Sim) MakeToolbar(p *tree.Plan) {
 	ss.GUI.AddLooperCtrl(p, ss.Loops)
 
+	tree.Add(p, func(w *core.Separator) {})
+	ss.GUI.AddToolbarItem(p, egui.ToolbarItem{Label: "Edit Patterns",
+		Icon:    icons.Edit,
+		Tooltip: "Opens an editor for the test patterns, where you can click and drag on the units to draw new patterns, add, delete and name them, and save them to a .tsv file.",
+		Active:  egui.ActiveStopped,
+		Func: func() {
+			simcore.EditEnvPatterns(ss.GUI.Body, ss.Loops, ss.Envs, etime.Test, "cats_dogs_pats.tsv")
+		},
+	})
+	ss.GUI.AddToolbarItem(p, egui.ToolbarItem{Label: "Query",
+		Icon:    icons.Search,
+		Tooltip: "Clamps the given space-separated features, e.g., cat orange, settles the network, and ranks the features and identities that it activates in the Query tab. A feature in more than one layer can be qualified by its layer, e.g., FavoriteToy:shoe.",
+		Active:  egui.ActiveStopped,
+		Func: func() {
+			core.CallFunc(ss.GUI.Body, ss.Query)
+		},
+	})
 	tree.Add(p, func(w *core.Separator) {})
 	ss.GUI.AddToolbarItem(p, egui.ToolbarItem{Label: "README",
 		Icon:    icons.FileMarkdown,
@@ -471,3 +720,54 @@ func (ss *Sim) RunGUI() {
 	ss.ConfigGUI()
 	ss.GUI.Body.RunMainWindow()
 }
+
+// RunNoGUI runs the test loop without the GUI, saving the log files
+// as specified in the Config.Log settings. With Config.Learn, it first
+// learns the weights from the exemplars, printing the harmony of each
+// epoch, and with Config.Query, it runs the Query instead of the test
+// patterns, printing the ranked features and saving the Query table.
+func (ss *Sim) RunNoGUI() {
+	runName := ss.Params.RunName(0)
+	ss.Stats.SetString("RunName", runName) // used for naming logs, stats, etc
+	netName := ss.Net.Name
+
+	elog.SetLogFile(&ss.Logs, ss.Config.Log.TestTrial, etime.Test, etime.Trial, "tst_trl", netName, runName)
+	elog.SetLogFile(&ss.Logs, ss.Config.Log.TestCycle, etime.Test, etime.Cycle, "tst_cyc", netName, runName)
+
+	if ss.Config.Figures != "" {
+		ss.Figures.Config(ss.Net, ss.ConfigPlots)
+	}
+	if ss.Config.Learn {
+		elog.SetLogFile(&ss.Logs, ss.Config.Log.Epoch, etime.Train, etime.Epoch, "epc", netName, runName)
+		ss.TrainInit()
+		ss.Loops.Run(etime.Train)
+		dt := ss.Logs.Table(etime.Train, etime.Epoch)
+		for row := range dt.Rows {
+			fmt.Printf("Epoch: %3d  Harmony: %.4f\n", int(dt.Float("Epoch", row)), dt.Float("Harmony", row))
+		}
+	} else {
+		ss.Init()
+	}
+	if ss.Config.Query != "" {
+		if ss.Query(ss.Config.Query) == nil {
+			dt := ss.Logs.MiscTable("Query")
+			simcore.SaveTable(dt, "query", netName, runName)
+			fmt.Printf("Query: %s  Harmony: %.4f\n", ss.Config.Query, ss.Stats.Float("Harmony"))
+			for row := range dt.Rows {
+				fmt.Printf("%2d  %-12s  %-9s  Act: %.3f  Share: %.3f\n", row+1, dt.StringValue("Layer", row), dt.StringValue("Feature", row), dt.Float("Act", row), dt.Float("Share", row))
+			}
+		}
+	} else {
+		ss.Loops.ResetAndRun(etime.Test) // keeping the learned weights
+	}
+
+	ss.Logs.CloseLogFiles()
+
+	if ss.Config.Figures != "" {
+		ss.Figures.Save(ss.Config.Figures, netName, runName)
+	}
+
+	if ss.Config.Export != "" {
+		simcore.SaveLogsNPZ(&ss.Logs, ss.Config.Export, netName, runName)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Layers are the names of the layers of the network, in the order in which
+// the feature names of a Query are looked up, so that a feature name that
+// is in more than one layer refers to the first one: e.g., Chloe is the
+// Name, and Identity:Chloe is her identity unit, and shoe is the
+// FavoriteFood, and FavoriteToy:shoe is the toy.
+var Layers = []string{"Name", "Species", "Color", "Size", "FavoriteFood", "FavoriteToy", "Identity"}
+
+// Features are the names of the units in each layer, which are used
+// to specify the cues for a Query, and to report its results.
+var Features = map[string][]string{
+	"Name":         {"Chloe", "Socks", "Sylvester", "Garfield", "Fuzzy", "Daisy", "Fido", "Spot", "Snoopy", "Penny"},
+	"Identity":     {"Chloe", "Socks", "Sylvester", "Garfield", "Fuzzy", "Daisy", "Fido", "Spot", "Snoopy", "Penny"},
+	"Species":      {"cat", "dog"},
+	"Color":        {"black", "white", "brown", "orange"},
+	"Size":         {"small", "medium", "large"},
+	"FavoriteFood": {"bugs", "grass", "scraps", "shoe"},
+	"FavoriteToy":  {"string", "feather", "bone", "shoe"},
+}
+
+// Cue is one feature unit that is clamped in a Query.
+type Cue struct {
+
+	// name of the layer
+	Layer string
+
+	// index of the unit in the layer
+	Unit int
+}
+
+// ParseCues returns the cues for the given space-separated feature names,
+// each of which can be qualified by its layer as Layer:feature,
+// and otherwise is in the first of the Layers that has it.
+// Names are not case sensitive.
+func ParseCues(cues string) ([]Cue, error) {
+	var cs []Cue
+	for _, fn := range strings.Fields(cues) {
+		lays := Layers
+		if lnm, nm, ok := strings.Cut(fn, ":"); ok {
+			li := slices.IndexFunc(Layers, func(l string) bool { return strings.EqualFold(l, lnm) })
+			if li < 0 {
+				return nil, fmt.Errorf("ParseCues: layer %q not found, must be one of: %s", lnm, strings.Join(Layers, " "))
+			}
+			lays = Layers[li : li+1]
+			fn = nm
+		}
+		found := false
+		for _, lnm := range lays {
+			ui := slices.IndexFunc(Features[lnm], func(f string) bool { return strings.EqualFold(f, fn) })
+			if ui >= 0 {
+				cs = append(cs, Cue{Layer: lnm, Unit: ui})
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("ParseCues: feature %q not found in layer(s): %s", fn, strings.Join(lays, " "))
+		}
+	}
+	if len(cs) == 0 {
+		return nil, fmt.Errorf("ParseCues: no features given to clamp")
+	}
+	return cs, nil
+}
+
+// IsCued returns whether the given unit in the given layer is one of the cues.
+func IsCued(cs []Cue, layer string, unit int) bool {
+	return slices.Contains(cs, Cue{Layer: layer, Unit: unit})
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+// TrainParams are the parameters for learning the weights of the network
+// from a table of exemplars, instead of using the hand-set weights,
+// with Hebbian learning while all of the layers are clamped to the
+// features of each exemplar in turn.
+type TrainParams struct {
+
+	// number of epochs of training, each with all of the exemplars.
+	NEpochs int `default:"40" min:"1"`
+
+	// file name of the exemplars to learn from: a .tsv file with one row
+	// per exemplar and the same columns as cats_dogs_pats.tsv, with the
+	// features of each exemplar and its unit in the Name and Identity
+	// layers. If empty, it uses cats_dogs_exemplars.tsv, with the
+	// cats and dogs of the README table.
+	File string
+}
+
+func (tp *TrainParams) Defaults() {
+	tp.NEpochs = 40
+}
@@ -6,4 +6,12 @@ import (
 	"cogentcore.org/core/types"
 )
 
-var _ = types.AddType(&types.Type{Name: "main.Sim", IDName: "sim", Doc: "Sim encapsulates the entire simulation model, and we define all the\nfunctionality as methods on this struct.  This structure keeps all relevant\nstate information organized and available without having to pass everything around\nas arguments to methods, and provides the core GUI interface (note the view tags\nfor the fields which provide hints to how things should be displayed).", Fields: []types.Field{{Name: "Net", Doc: "the network -- click to view / edit parameters for layers, paths, etc"}, {Name: "Params", Doc: "network parameter management"}, {Name: "Loops", Doc: "contains looper control loops for running sim"}, {Name: "Stats", Doc: "contains computed statistic values"}, {Name: "Logs", Doc: "Contains all the logs and information about the logs.'"}, {Name: "Patterns", Doc: "the patterns to use"}, {Name: "Envs", Doc: "Environments"}, {Name: "Context", Doc: "leabra timing parameters and state"}, {Name: "ViewUpdate", Doc: "netview update parameters"}, {Name: "GUI", Doc: "manages all the gui elements"}, {Name: "RandSeeds", Doc: "a list of random seeds to use for each run"}}})
+var _ = types.AddType(&types.Type{Name: "main.Config", IDName: "config", Doc: "Config has config parameters related to running the sim,\nwhich can be set from the command line or config.toml.", Fields: []types.Field{{Name: "Learn", Doc: "learn the weights from the exemplars in Train.File, instead of\nusing the hand-set weights, before testing, when running without the GUI."}, {Name: "Train", Doc: "parameters for learning the weights from the exemplars."}, {Name: "Query", Doc: "space-separated features to clamp in a Query, e.g., \"cat orange\",\nwhich is run instead of the test patterns when running without the GUI,\nprinting the ranked features and identities that it activates."}, {Name: "NCycles", Doc: "number of cycles per trial"}, {Name: "GUI", Doc: "GUI means open the GUI. Otherwise it runs automatically and quits,\nsaving log files as specified in Log."}, {Name: "Figures", Doc: "plots, grids or NetView to save as images without the GUI, or \"all\" (see simcore.Figures)"}, {Name: "Export", Doc: "logs to export to .npz files for Python without the GUI, or \"all\" (see simcore.SaveLogsNPZ)"}, {Name: "Log", Doc: "Log has config parameters related to logging data."}}})
+
+var _ = types.AddType(&types.Type{Name: "main.LogConfig", IDName: "log-config", Doc: "LogConfig has config parameters related to logging data", Fields: []types.Field{{Name: "Epoch", Doc: "if true, save training epoch log to file, as .epc.tsv typically"}, {Name: "TestTrial", Doc: "if true, save testing trial log to file, as .tst_trl.tsv typically"}, {Name: "TestCycle", Doc: "if true, save testing cycle log to file, as .tst_cyc.tsv typically. May be large."}}})
+
+var _ = types.AddType(&types.Type{Name: "main.Sim", IDName: "sim", Doc: "Sim encapsulates the entire simulation model, and we define all the\nfunctionality as methods on this struct.  This structure keeps all relevant\nstate information organized and available without having to pass everything around\nas arguments to methods, and provides the core GUI interface (note the view tags\nfor the fields which provide hints to how things should be displayed).", Methods: []types.Method{{Name: "Query", Doc: "Query clamps the given space-separated features, e.g., \"cat orange\",\neach of which can be qualified by its layer, e.g., FavoriteToy:shoe\n(see ParseCues), and settles the network for NCycles with the current\nweights, leaving all the other units free to be activated. The Query\ntable has the units that are activated, other than the clamped ones,\nranked by their activation, with their Share of the total activation\nof their layer as a measure of the confidence in them relative\nto the other features in the same layer.", Directives: []types.Directive{{Tool: "types", Directive: "add"}}, Args: []string{"features"}, Returns: []string{"error"}}}, Fields: []types.Field{{Name: "Config", Doc: "Config contains misc configuration parameters for running the sim"}, {Name: "Net", Doc: "the network -- click to view / edit parameters for layers, paths, etc"}, {Name: "Params", Doc: "network parameter management"}, {Name: "Loops", Doc: "contains looper control loops for running sim"}, {Name: "Stats", Doc: "contains computed statistic values"}, {Name: "Logs", Doc: "Contains all the logs and information about the logs.'"}, {Name: "Patterns", Doc: "the patterns to use"}, {Name: "Exemplars", Doc: "the exemplars to learn the weights from"}, {Name: "Train", Doc: "parameters for learning the weights from the exemplars"}, {Name: "Envs", Doc: "Environments"}, {Name: "Context", Doc: "leabra timing parameters and state"}, {Name: "ViewUpdate", Doc: "netview update parameters"}, {Name: "GUI", Doc: "manages all the gui elements"}, {Name: "Figures", Doc: "the plots, grids and network snapshots that can be saved without the GUI"}, {Name: "RandSeeds", Doc: "a list of random seeds to use for each run"}}})
+
+var _ = types.AddType(&types.Type{Name: "main.Cue", IDName: "cue", Doc: "Cue is one feature unit that is clamped in a Query.", Fields: []types.Field{{Name: "Layer", Doc: "name of the layer"}, {Name: "Unit", Doc: "index of the unit in the layer"}}})
+
+var _ = types.AddType(&types.Type{Name: "main.TrainParams", IDName: "train-params", Doc: "TrainParams are the parameters for learning the weights of the network\nfrom a table of exemplars, instead of using the hand-set weights,\nwith Hebbian learning while all of the layers are clamped to the\nfeatures of each exemplar in turn.", Fields: []types.Field{{Name: "NEpochs", Doc: "number of epochs of training, each with all of the exemplars."}, {Name: "File", Doc: "file name of the exemplars to learn from: a .tsv file with one row\nper exemplar and the same columns as cats_dogs_pats.tsv, with the\nfeatures of each exemplar and its unit in the Name and Identity\nlayers. If empty, it uses cats_dogs_exemplars.tsv, with the\ncats and dogs of the README table."}}})
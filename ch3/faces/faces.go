@@ -17,6 +17,8 @@ package main
 
 import (
 	"embed"
+	"fmt"
+	"math"
 
 	"cogentcore.org/core/base/errors"
 	"cogentcore.org/core/base/randx"
@@ -30,6 +32,8 @@ import (
 	"cogentcore.org/core/tensor/stats/metric"
 	"cogentcore.org/core/tensor/table"
 	"cogentcore.org/core/tree"
+	"github.com/CompCogNeuro/sims/v2/simcore"
+	"github.com/emer/emergent/v2/econfig"
 	"github.com/emer/emergent/v2/egui"
 	"github.com/emer/emergent/v2/elog"
 	"github.com/emer/emergent/v2/emer"
@@ -52,7 +56,11 @@ func main() {
 	sim := &Sim{}
 	sim.New()
 	sim.ConfigAll()
-	sim.RunGUI()
+	if sim.Config.GUI {
+		sim.RunGUI()
+	} else {
+		sim.RunNoGUI()
+	}
 }
 
 // ParamSets is the default set of parameters.
@@ -89,6 +97,68 @@ var ParamSets = params.Sets{
 				"Layer.Inhib.Layer.Gi": "1.3",
 			}},
 	},
+	"Train": {
+		{Sel: "Path", Desc: "learning when training from random weights, fast for the few faces",
+			Params: params.Params{
+				"Path.Learn.Learn": "true",
+				"Path.Learn.Lrate": "0.1",
+			}},
+		{Sel: "#Identity", Desc: "less inhibition, so the weak initial input can activate it",
+			Params: params.Params{
+				"Layer.Inhib.Layer.Gi": "1.5",
+			}},
+	},
+}
+
+// Config has config parameters related to running the sim,
+// which can be set from the command line or config.toml.
+type Config struct {
+
+	// present inputs top-down to the Emotion, Gender and Identity layers,
+	// instead of bottom-up to the Input layer
+	TopDown bool
+
+	// present the partial faces patterns instead of the full faces
+	Partial bool
+
+	// train the network from random weights, instead of using the pretrained
+	// weights, and then test it, when running without the GUI.
+	Learn bool
+
+	// parameters for training the network from random weights.
+	Train TrainParams `display:"add-fields" nest:"+"`
+
+	// number of cycles per trial
+	NCycles int `default:"20"`
+
+	// GUI means open the GUI. Otherwise it runs automatically and quits,
+	// saving log files as specified in Log.
+	GUI bool `default:"true"`
+
+	// plots, grids or NetView to save as images without the GUI, or "all" (see simcore.Figures)
+	Figures string
+
+	// logs to export to .npz files for Python without the GUI, or "all" (see simcore.SaveLogsNPZ)
+	Export string
+
+	// Log has config parameters related to logging data.
+	Log LogConfig `display:"add-fields"`
+}
+
+// LogConfig has config parameters related to logging data
+type LogConfig struct {
+
+	// if true, save testing trial log to file, as .tst_trl.tsv typically
+	TestTrial bool `default:"true" nest:"+"`
+
+	// if true, save train epoch log to file, as .epc.tsv typically,
+	// when training with Learn.
+	Epoch bool `default:"true" nest:"+"`
+
+	// if true, save the log of the tests of all the faces during training,
+	// with the generalization to the held-out faces, to file,
+	// as .val_epc.tsv typically, when training with Learn.
+	ValidateEpoch bool `default:"true" nest:"+"`
 }
 
 // Sim encapsulates the entire simulation model, and we define all the
@@ -98,6 +168,12 @@ var ParamSets = params.Sets{
 // for the fields which provide hints to how things should be displayed).
 type Sim struct {
 
+	// parameters for training the network from random weights, with the Train controls.
+	Train TrainParams `display:"add-fields"`
+
+	// Config contains misc configuration parameters for running the sim
+	Config Config `display:"-"`
+
 	// the network -- click to view / edit parameters for layers, paths, etc
 	Net *leabra.Network `new-window:"+" display:"no-inline"`
 
@@ -131,6 +207,9 @@ type Sim struct {
 	// manages all the gui elements
 	GUI egui.GUI `display:"-"`
 
+	// the plots, grids and network snapshots that can be saved without the GUI
+	Figures simcore.Figures `display:"-"`
+
 	// a list of random seeds to use for each run
 	RandSeeds randx.Seeds `display:"-"`
 }
@@ -138,6 +217,8 @@ type Sim struct {
 // New creates new blank elements and initializes defaults
 func (ss *Sim) New() {
 	ss.Defaults()
+	econfig.Config(&ss.Config, "config.toml")
+	ss.Train = ss.Config.Train
 	ss.Net = leabra.NewNetwork("Faces")
 	ss.Params.Config(ParamSets, "", "", ss.Net)
 	ss.Stats.Init()
@@ -149,6 +230,7 @@ func (ss *Sim) New() {
 }
 
 func (ss *Sim) Defaults() {
+	ss.Train.Defaults()
 }
 
 //////////////////////////////////////////////////////////////////////////////
@@ -159,26 +241,57 @@ func (ss *Sim) ConfigAll() {
 	ss.OpenPatterns()
 	ss.ConfigEnv()
 	ss.ConfigNet(ss.Net)
+	ss.SetInput(ss.Config.TopDown)
+	ss.SetPatterns(ss.Config.Partial)
 	ss.ConfigLogs()
 	ss.ConfigLoops()
 }
 
 func (ss *Sim) ConfigEnv() {
 	// Can be called multiple times -- don't re-create
-	var tst *env.FixedTable
+	var trn, tst, val *env.FixedTable
 	if len(ss.Envs) == 0 {
+		trn = &env.FixedTable{}
 		tst = &env.FixedTable{}
+		val = &env.FixedTable{}
 	} else {
+		trn = ss.Envs.ByMode(etime.Train).(*env.FixedTable)
 		tst = ss.Envs.ByMode(etime.Test).(*env.FixedTable)
+		val = ss.Envs.ByMode(etime.Validate).(*env.FixedTable)
 	}
 
+	trn.Name = etime.Train.String()
+	trn.Config(table.NewIndexView(ss.Patterns))
+
 	tst.Name = etime.Test.String()
 	tst.Config(table.NewIndexView(ss.Patterns))
 	tst.Sequential = true
 	tst.Init(0)
 
+	// validation tests all the faces during training, including the held-out ones
+	val.Name = etime.Validate.String()
+	val.Config(table.NewIndexView(ss.Patterns))
+	val.Sequential = true
+
 	// note: names must be in place when adding
-	ss.Envs.Add(tst)
+	ss.Envs.Add(trn, tst, val)
+	ss.SetTrainFaces()
+}
+
+// SetTrainFaces sets the faces that the network is trained on, which are
+// all of the faces except the held-out ones in Train.HoldOut, and
+// the faces for testing generalization during training, which are all of them.
+func (ss *Sim) SetTrainFaces() {
+	trn := ss.Envs.ByMode(etime.Train).(*env.FixedTable)
+	trn.Table = table.NewIndexView(ss.Patterns)
+	trn.Table.Filter(func(dt *table.Table, row int) bool {
+		return !ss.Train.IsHeldOut(dt.StringValue("Name", row))
+	})
+	trn.Init(0)
+	val := ss.Envs.ByMode(etime.Validate).(*env.FixedTable)
+	val.Table = table.NewIndexView(ss.Patterns)
+	val.Init(0)
+	simcore.SetEnvTrials(ss.Loops, ss.Envs)
 }
 
 func (ss *Sim) ConfigNet(net *leabra.Network) {
@@ -219,21 +332,39 @@ func (ss *Sim) InitWeights(net *leabra.Network) {
 
 func (ss *Sim) ApplyParams() {
 	ss.Params.SetAll()
+	if ss.Loops != nil {
+		ss.Loops.Loop(etime.Train, etime.Epoch).Counter.Max = ss.Train.NEpochs
+	}
 }
 
 // SetInput sets whether the input to the network comes in bottom-up
 // (Input layer) or top-down (Higher-level category layers)
 func (ss *Sim) SetInput(topDown bool) { //types:add
+	ss.Config.TopDown = topDown
+	ss.SetLayerTypes(topDown, false)
+}
+
+// SetLayerTypes sets the layer types for the input coming in bottom-up
+// or top-down, or for training, where the input comes in bottom-up and
+// the category layers are Target layers, which are clamped to the
+// correct categories in the plus phase.
+func (ss *Sim) SetLayerTypes(topDown, train bool) {
 	inp := ss.Net.LayerByName("Input")
 	emo := ss.Net.LayerByName("Emotion")
 	gend := ss.Net.LayerByName("Gender")
 	iden := ss.Net.LayerByName("Identity")
-	if topDown {
+	switch {
+	case topDown:
 		inp.Type = leabra.CompareLayer
 		emo.Type = leabra.InputLayer
 		gend.Type = leabra.InputLayer
 		iden.Type = leabra.InputLayer
-	} else {
+	case train:
+		inp.Type = leabra.InputLayer
+		emo.Type = leabra.TargetLayer
+		gend.Type = leabra.TargetLayer
+		iden.Type = leabra.TargetLayer
+	default:
 		inp.Type = leabra.InputLayer
 		emo.Type = leabra.CompareLayer
 		gend.Type = leabra.CompareLayer
@@ -251,6 +382,7 @@ func (ss *Sim) SetPatterns(partial bool) { //types:add
 		ev.Table = table.NewIndexView(ss.Patterns)
 		ev.Init(0)
 	}
+	simcore.SetEnvTrials(ss.Loops, ss.Envs) // edited patterns can have a different number
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -268,26 +400,68 @@ func (ss *Sim) Init() {
 	ss.ViewUpdate.Update()
 }
 
+// TrainInit restarts training, and initializes everything, including
+// network weights, which are random instead of the pretrained ones.
+func (ss *Sim) TrainInit() {
+	ss.Loops.ResetCounters()
+	ss.GUI.StopNow = false
+	ss.NewTrainRun()
+	ss.ViewUpdate.RecordSyns()
+	ss.ViewUpdate.Update()
+}
+
+// NewTrainRun initializes a new training run, with random weights,
+// the Train params for learning, and the current Train.HoldOut faces,
+// and resets the training and validation epoch logs.
+func (ss *Sim) NewTrainRun() {
+	ctx := &ss.Context
+	ss.InitRandSeed(0)
+	ss.ApplyParams()
+	ss.Params.SetAllSheet("Train")
+	ss.SetTrainFaces()
+	ctx.Reset()
+	ctx.Mode = etime.Train
+	ss.Net.InitWeights()
+	ss.InitStats()
+	ss.StatCounters()
+	ss.Logs.ResetLog(etime.Train, etime.Epoch)
+	ss.Logs.ResetLog(etime.Validate, etime.Epoch)
+}
+
 // InitRandSeed initializes the random seed based on current training run number
 func (ss *Sim) InitRandSeed(run int) {
-	ss.RandSeeds.Set(run)
-	ss.RandSeeds.Set(run, &ss.Net.Rand)
+	simcore.InitRandSeed(&ss.RandSeeds, ss.Net, run)
 }
 
 // ConfigLoops configures the control loops: Training, Testing
 func (ss *Sim) ConfigLoops() {
 	ls := looper.NewStacks()
 
-	ev := ss.Envs.ByMode(etime.Test).(*env.FixedTable)
-	ntrls := ev.Table.Len()
+	ntrn := ss.Envs.ByMode(etime.Train).(*env.FixedTable).Table.Len()
+	ntst := ss.Envs.ByMode(etime.Test).(*env.FixedTable).Table.Len()
+	nval := ss.Envs.ByMode(etime.Validate).(*env.FixedTable).Table.Len()
 
+	ls.AddStack(etime.Train).
+		AddTime(etime.Run, 1).
+		AddTime(etime.Epoch, ss.Train.NEpochs).
+		AddTime(etime.Trial, ntrn).
+		AddTime(etime.Cycle, 100)
+
+	cycles := ss.Config.NCycles
 	ls.AddStack(etime.Test).
 		AddTime(etime.Epoch, 1).
-		AddTime(etime.Trial, ntrls).
-		AddTime(etime.Cycle, 20)
+		AddTime(etime.Trial, ntst).
+		AddTime(etime.Cycle, cycles)
 
-	leabra.LooperStdPhases(ls, &ss.Context, ss.Net, 15, 19)
+	ls.AddStack(etime.Validate).
+		AddTime(etime.Epoch, 1).
+		AddTime(etime.Trial, nval).
+		AddTime(etime.Cycle, cycles)
+
+	// testing just settles for NCycles, so only training has the plus phase
+	leabra.LooperStdPhases(ls, &ss.Context, ss.Net, 75, 99)
 	leabra.LooperSimCycleAndLearn(ls, ss.Net, &ss.Context, &ss.ViewUpdate) // std algo code
+	ls.Stacks[etime.Train].OnInit.Add("Init", func() { ss.TrainInit() })
 	ls.Stacks[etime.Test].OnInit.Add("Init", func() { ss.Init() })
 
 	for m, _ := range ls.Stacks {
@@ -297,6 +471,15 @@ func (ss *Sim) ConfigLoops() {
 		})
 	}
 
+	ls.Loop(etime.Train, etime.Run).OnStart.Add("NewRun", ss.NewTrainRun)
+
+	trainEpoch := ls.Loop(etime.Train, etime.Epoch)
+	trainEpoch.OnEnd.Add("ValidateAtInterval", func() {
+		if (trainEpoch.Counter.Cur+1)%ss.Train.TestInterval == 0 {
+			ss.Validate()
+		}
+	})
+
 	/////////////////////////////////////////////
 	// Logging
 
@@ -308,9 +491,12 @@ func (ss *Sim) ConfigLoops() {
 	////////////////////////////////////////////
 	// GUI
 
-	leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
-	leabra.LooperUpdatePlots(ls, &ss.GUI)
-	ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	if ss.Config.GUI {
+		leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
+		leabra.LooperUpdatePlots(ls, &ss.GUI)
+		ls.Stacks[etime.Train].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+		ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	}
 
 	ss.Loops = ls
 }
@@ -324,7 +510,8 @@ func (ss *Sim) ApplyInputs() {
 	net := ss.Net
 	ev := ss.Envs.ByMode(ctx.Mode).(*env.FixedTable)
 	ev.Step()
-	lays := net.LayersByType(leabra.InputLayer, leabra.CompareLayer)
+	ss.SetLayerTypes(ctx.Mode == etime.Test && ss.Config.TopDown, ctx.Mode == etime.Train)
+	lays := net.LayersByType(leabra.InputLayer, leabra.CompareLayer, leabra.TargetLayer)
 	net.InitExt()
 	ss.Stats.SetString("TrialName", ev.TrialName.Cur)
 	for _, lnm := range lays {
@@ -349,6 +536,15 @@ func (ss *Sim) NewRun() {
 	ss.Logs.ResetLog(etime.Test, etime.Epoch)
 }
 
+// Validate tests all of the faces during training, including the held-out
+// ones, to measure generalization, and updates the projection plots.
+func (ss *Sim) Validate() {
+	ss.Envs.ByMode(etime.Validate).Init(0)
+	ss.Loops.ResetAndRun(etime.Validate)
+	ss.Loops.Mode = etime.Train // called from within the Train Run
+	ss.ProjectionPlot(etime.Validate)
+}
+
 /////////////////////////////////////////////////////////////////////////
 //   Patterns
 
@@ -370,17 +566,17 @@ func (ss *Sim) OpenPatterns() {
 // called at start of new run
 func (ss *Sim) InitStats() {
 	ss.Stats.SetString("TrialName", "")
+	ss.Stats.SetFloat("HeldOut", 0)
+	for _, cat := range Categories {
+		ss.Stats.SetFloat(cat+"Err", 0)
+		ss.Stats.SetFloat("Gen"+cat+"Err", math.NaN()) // until tested
+	}
 }
 
 // StatCounters saves current counters to Stats, so they are available for logging etc
 // Also saves a string rep of them for ViewUpdate.Text
 func (ss *Sim) StatCounters() {
-	ctx := &ss.Context
-	mode := ctx.Mode
-	ss.Loops.Stacks[mode].CountersToStats(&ss.Stats)
-	trl := ss.Stats.Int("Trial")
-	ss.Stats.SetInt("Trial", trl)
-	ss.Stats.SetInt("Cycle", int(ctx.Cycle))
+	simcore.StatCounters(ss.Loops, &ss.Context, &ss.Stats, true) // always use training epoch
 }
 
 func (ss *Sim) NetViewCounters(tm etime.Times) {
@@ -391,84 +587,136 @@ func (ss *Sim) NetViewCounters(tm etime.Times) {
 		ss.TrialStats() // get trial stats for current di
 	}
 	ss.StatCounters()
-	ss.ViewUpdate.Text = ss.Stats.Print([]string{"Trial", "TrialName", "Cycle"})
+	ss.ViewUpdate.Text = ss.Stats.Print([]string{"Epoch", "Trial", "TrialName", "Cycle", "EmotionErr", "GenderErr", "IdentityErr"})
 }
 
-// TrialStats computes the trial-level statistics.
+// TrialStats computes the trial-level statistics: whether the most active
+// unit in each category layer is the correct one, using the minus phase
+// activity when training, and whether the face is held out from training.
 // Aggregation is done directly from log data.
 func (ss *Sim) TrialStats() {
+	ctx := &ss.Context
+	ev := ss.Envs.ByMode(ctx.Mode).(*env.FixedTable)
+	vnm := "Act"
+	if ctx.Mode == etime.Train {
+		vnm = "ActM"
+	}
+	var acts []float32
+	for _, cat := range Categories {
+		ss.Net.LayerByName(cat).UnitValues(&acts, vnm, 0)
+		ss.Stats.SetFloat(cat+"Err", CatErr(acts, ev.State(cat)))
+	}
+	if ss.Train.IsHeldOut(ev.TrialName.Cur) {
+		ss.Stats.SetFloat("HeldOut", 1)
+	} else {
+		ss.Stats.SetFloat("HeldOut", 0)
+	}
+}
+
+// GenStats computes the generalization statistics at the end of testing
+// all of the faces during training: the proportion of errors in each
+// category for the held-out faces, which are NaN if there are none.
+func (ss *Sim) GenStats() {
+	dt := ss.Logs.Table(etime.Validate, etime.Trial)
+	for _, cat := range Categories {
+		sum, n := 0.0, 0
+		for row := range dt.Rows {
+			if dt.Float("HeldOut", row) == 1 {
+				sum += dt.Float(cat+"Err", row)
+				n++
+			}
+		}
+		if n == 0 {
+			ss.Stats.SetFloat("Gen"+cat+"Err", math.NaN())
+		} else {
+			ss.Stats.SetFloat("Gen"+cat+"Err", sum/float64(n))
+		}
+	}
+}
+
+// CycleStats computes the cycle-level statistics.
+func (ss *Sim) CycleStats() {
+	ss.Stats.SetFloat32("Harmony", simcore.Harmony(ss.Net))
 }
 
 //////////////////////////////////////////////////////////////////////////////
 // 		Logging
 
 func (ss *Sim) ConfigLogs() {
-	ss.Logs.AddCounterItems(etime.Trial, etime.Cycle)
-	ss.Logs.AddStatStringItem(etime.Test, etime.Trial, "TrialName")
+	ss.Logs.AddCounterItems(etime.Epoch, etime.Trial, etime.Cycle)
+	ss.Logs.AddStatStringItem(etime.AllModes, etime.Trial, "TrialName")
+	ss.Logs.AddStatAggItem("Harmony", etime.Trial, etime.Cycle)
+
+	var gens []string
+	for _, cat := range Categories {
+		ss.Logs.AddStatAggItem(cat+"Err", etime.Epoch, etime.Trial)
+		gens = append(gens, "Gen"+cat+"Err")
+	}
+	ss.Logs.AddStatFloatNoAggItem(etime.Validate, etime.Trial, "HeldOut")
+	ss.Logs.AddStatFloatNoAggItem(etime.Validate, etime.Epoch, gens...)
+	ss.Logs.AddStatFloatNoAggItem(etime.Train, etime.Epoch, gens...)
 
 	ss.Logs.AddLayerTensorItems(ss.Net, "Act", etime.Test, etime.Trial, "InputLayer", "CompareLayer")
+	ss.Logs.AddLayerTensorItems(ss.Net, "Act", etime.Validate, etime.Trial, "InputLayer", "CompareLayer")
 
 	ss.Logs.CreateTables()
 	ss.Logs.SetContext(&ss.Stats, ss.Net)
-	ss.Logs.NoPlot(etime.Test, etime.Cycle)
-	ss.Logs.PlotItems("Emotion_Act", "Gender_Act", "Identity_Act")
+	ss.Logs.PlotItems("Emotion_Act", "Gender_Act", "Identity_Act", "Harmony", "EmotionErr", "GenderErr", "IdentityErr")
+	ss.Logs.PlotItems(gens...)
+	ss.Logs.NoPlot(etime.Train, etime.Cycle)
+	ss.Logs.NoPlot(etime.Validate, etime.Cycle)
+
+	ss.ConfigProjectionTable(ss.Logs.MiscTable("ProjectionTable"))
 }
 
 // Log is the main logging function, handles special things for different scopes
 func (ss *Sim) Log(mode etime.Modes, time etime.Times) {
-	ctx := &ss.Context
-	if mode != etime.Analyze {
-		ctx.Mode = mode // Also set specifically in a Loop callback.
-	}
-	dt := ss.Logs.Table(mode, time)
-	if dt == nil {
-		return
-	}
-	row := dt.Rows
-
-	switch {
-	case time == etime.Cycle:
-		return
-	case time == etime.Trial:
-		ss.TrialStats()
-		ss.StatCounters()
+	if mode == etime.Validate && time == etime.Epoch {
+		ss.GenStats()
 	}
-
-	ss.Logs.LogRow(mode, time, row) // also logs to file, etc
+	simcore.Log(&ss.Context, &ss.Logs, mode, time, simcore.Hooks{TrialStats: ss.TrialStats, StatCounters: ss.StatCounters, CycleStats: ss.CycleStats})
 }
 
 // ClusterPlots computes all the cluster plots from the faces input data.
 func (ss *Sim) ClusterPlots() {
 	ptix := table.NewIndexView(ss.Patterns)
-	estats.ClusterPlot(ss.GUI.PlotByName("ClustFaces"), ptix, "Input", "Name", clust.MinDist)
-	estats.ClusterPlot(ss.GUI.PlotByName("ClustEmote"), ptix, "Emotion", "Name", clust.MinDist)
-	estats.ClusterPlot(ss.GUI.PlotByName("ClustGend"), ptix, "Gender", "Name", clust.MinDist)
-	estats.ClusterPlot(ss.GUI.PlotByName("ClustIdent"), ptix, "Identity", "Name", clust.MinDist)
-	ss.ProjectionPlot()
+	estats.ClusterPlot(ss.Figures.PlotByName(&ss.GUI, "ClustFaces"), ptix, "Input", "Name", clust.MinDist)
+	estats.ClusterPlot(ss.Figures.PlotByName(&ss.GUI, "ClustEmote"), ptix, "Emotion", "Name", clust.MinDist)
+	estats.ClusterPlot(ss.Figures.PlotByName(&ss.GUI, "ClustGend"), ptix, "Gender", "Name", clust.MinDist)
+	estats.ClusterPlot(ss.Figures.PlotByName(&ss.GUI, "ClustIdent"), ptix, "Identity", "Name", clust.MinDist)
+	ss.ProjectionPlot(etime.Test)
 }
 
-func (ss *Sim) ProjectionPlot() {
+// ProjectionPlot computes the projections of the faces onto the emotion and
+// gender dimensions, from the activity of the Emotion and Gender layers, and
+// onto two random dimensions of the Input layer, from the trial log of the
+// given mode: Test for the current patterns, or Validate for all the faces
+// when testing during training, so that the categories can be seen
+// emerging over training. The random dimensions and the jitter are the same
+// each time, so that changes in the plots come from the network.
+func (ss *Sim) ProjectionPlot(mode etime.Modes) {
+	rnd := rand.New(rand.NewSource(1))
 	rvec0 := ss.Stats.F32Tensor("rvec0")
 	rvec1 := ss.Stats.F32Tensor("rvec1")
 	rvec0.SetShape([]int{256})
 	rvec1.SetShape([]int{256})
 	for i := range rvec1.Values {
-		rvec0.Values[i] = .15 * (2*rand.Float32() - 1)
-		rvec1.Values[i] = .15 * (2*rand.Float32() - 1)
+		rvec0.Values[i] = .15 * (2*rnd.Float32() - 1)
+		rvec1.Values[i] = .15 * (2*rnd.Float32() - 1)
 	}
 
-	tst := ss.Logs.Table(etime.Test, etime.Trial)
+	tst := ss.Logs.Table(mode, etime.Trial)
 	nr := tst.Rows
 	dt := ss.Logs.MiscTable("ProjectionTable")
-	ss.ConfigProjectionTable(dt)
+	dt.SetNumRows(nr)
 
 	for r := 0; r < nr; r++ {
 		// single emotion dimension from sad to happy
 		emote := 0.5*tst.TensorFloat1D("Emotion_Act", r, 0) + -0.5*tst.TensorFloat1D("Emotion_Act", r, 1)
-		emote += .1 * (2*rand.Float64() - 1) // some jitter so labels are readable
+		emote += .1 * (2*rnd.Float64() - 1) // some jitter so labels are readable
 		// single geneder dimension from male to femail
 		gend := 0.5*tst.TensorFloat1D("Gender_Act", r, 0) + -0.5*tst.TensorFloat1D("Gender_Act", r, 1)
-		gend += .1 * (2*rand.Float64() - 1) // some jitter so labels are readable
+		gend += .1 * (2*rnd.Float64() - 1) // some jitter so labels are readable
 		input := tst.Tensor("Input_Act", r).(*tensor.Float32)
 		rprjn0 := metric.InnerProduct32(rvec0.Values, input.Values)
 		rprjn1 := metric.InnerProduct32(rvec1.Values, input.Values)
@@ -480,7 +728,25 @@ func (ss *Sim) ProjectionPlot() {
 		dt.SetFloat("RndPrjn1", r, float64(rprjn1))
 	}
 
-	plt := ss.GUI.PlotByName("ProjectionRandom")
+	for _, pnm := range []string{"ProjectionRandom", "ProjectionEmoteGend"} {
+		plt := ss.GUI.PlotByName(pnm)
+		switch {
+		case plt == nil:
+		case mode == etime.Validate: // during training, in its own goroutine
+			plt.GoUpdatePlot()
+		default:
+			plt.UpdatePlot()
+		}
+	}
+}
+
+// ConfigProjectionPlots configures the plots of the projections
+// of the faces onto the emotion and gender dimensions (eg),
+// and onto random dimensions (rnd).
+func (ss *Sim) ConfigProjectionPlots(rnd, eg *plotcore.PlotEditor) {
+	dt := ss.Logs.MiscTable("ProjectionTable")
+
+	plt := rnd
 	plt.Options.Title = "Face Random Projection Plot"
 	plt.Options.XAxis = "RndPrjn0"
 	plt.SetTable(dt)
@@ -492,7 +758,7 @@ func (ss *Sim) ProjectionPlot() {
 	plt.SetColumnOptions("RndPrjn0", plotcore.Off, plotcore.FloatMin, -1, plotcore.FloatMax, 1)
 	plt.SetColumnOptions("RndPrjn1", plotcore.On, plotcore.FloatMin, -1, plotcore.FloatMax, 1)
 
-	plt = ss.GUI.PlotByName("ProjectionEmoteGend")
+	plt = eg
 	plt.Options.Title = "Face Emotion / Gender Projection Plot"
 	plt.Options.XAxis = "GendPrjn"
 	plt.SetTable(dt)
@@ -517,9 +783,6 @@ func (ss *Sim) ConfigProjectionTable(dt *table.Table) {
 		dt.AddFloat64Column("RndPrjn0")
 		dt.AddFloat64Column("RndPrjn1")
 	}
-	ev := ss.Envs.ByMode(etime.Test).(*env.FixedTable)
-	nt := ev.Table.Len() // number in indexview
-	dt.SetNumRows(nt)
 }
 
 ////////////////////////////////////////////////////////////////
@@ -569,18 +832,25 @@ func (ss *Sim) ConfigGUI() {
 	nv.Current()
 	ss.ConfigNetView(nv)
 
-	ss.GUI.AddPlots(title, &ss.Logs)
-
-	ss.GUI.AddMiscPlotTab("ClustFaces")
-	ss.GUI.AddMiscPlotTab("ClustEmote")
-	ss.GUI.AddMiscPlotTab("ClustGend")
-	ss.GUI.AddMiscPlotTab("ClustIdent")
-	ss.GUI.AddMiscPlotTab("ProjectionRandom")
-	ss.GUI.AddMiscPlotTab("ProjectionEmoteGend")
+	ss.ConfigPlots(simcore.GUIPlots{GUI: &ss.GUI})
 
 	ss.GUI.FinalizeGUI(false)
 }
 
+// ConfigPlots adds the log plots, the cluster plots of the face
+// representations by all the faces and by emotion, gender and identity,
+// and the plots of their projections onto the emotion and gender
+// dimensions and onto random ones, to the GUI or the Figures.
+func (ss *Sim) ConfigPlots(pl simcore.Plots) {
+	pl.AddLogPlots("Faces", &ss.Logs)
+
+	pl.AddPlot("ClustFaces")
+	pl.AddPlot("ClustEmote")
+	pl.AddPlot("ClustGend")
+	pl.AddPlot("ClustIdent")
+	ss.ConfigProjectionPlots(pl.AddPlot("ProjectionRandom"), pl.AddPlot("ProjectionEmoteGend"))
+}
+
 func (ss *Sim) MakeToolbar(p *tree.Plan) {
 	ss.GUI.AddLooperCtrl(p, ss.Loops)
 
@@ -611,6 +881,19 @@ func (ss *Sim) MakeToolbar(p *tree.Plan) {
 		},
 	})
 	tree.Add(p, func(w *core.Separator) {})
+	ss.GUI.AddToolbarItem(p, egui.ToolbarItem{Label: "Edit Patterns",
+		Icon:    icons.Edit,
+		Tooltip: "Opens an editor for the current full or partial face patterns, where you can click and drag on the units to draw new patterns, add, delete and name them, and save them to a .tsv file.",
+		Active:  egui.ActiveStopped,
+		Func: func() {
+			fnm := "faces.tsv"
+			if ss.Envs.ByMode(etime.Test).(*env.FixedTable).Table.Table == ss.PartialPatterns {
+				fnm = "partial_faces.tsv"
+			}
+			simcore.EditEnvPatterns(ss.GUI.Body, ss.Loops, ss.Envs, etime.Test, fnm)
+		},
+	})
+	tree.Add(p, func(w *core.Separator) {})
 	ss.GUI.AddToolbarItem(p, egui.ToolbarItem{Label: "README",
 		Icon:    icons.FileMarkdown,
 		Tooltip: "Opens your browser on the README file that contains instructions for how to run this model.",
@@ -626,3 +909,49 @@ func (ss *Sim) RunGUI() {
 	ss.ConfigGUI()
 	ss.GUI.Body.RunMainWindow()
 }
+
+// RunNoGUI runs the test loop without the GUI, saving the log files
+// as specified in the Config.Log settings. With Config.Learn, it first
+// trains the network from random weights, printing the errors for all
+// the faces and for the held-out ones each time they are tested,
+// and then tests the trained network.
+func (ss *Sim) RunNoGUI() {
+	runName := ss.Params.RunName(0)
+	ss.Stats.SetString("RunName", runName) // used for naming logs, stats, etc
+	netName := ss.Net.Name
+
+	elog.SetLogFile(&ss.Logs, ss.Config.Log.TestTrial, etime.Test, etime.Trial, "tst_trl", netName, runName)
+
+	if ss.Config.Figures != "" {
+		ss.Figures.Config(ss.Net, ss.ConfigPlots)
+	}
+	if ss.Config.Learn {
+		elog.SetLogFile(&ss.Logs, ss.Config.Log.Epoch, etime.Train, etime.Epoch, "epc", netName, runName)
+		elog.SetLogFile(&ss.Logs, ss.Config.Log.ValidateEpoch, etime.Validate, etime.Epoch, "val_epc", netName, runName)
+		ss.TrainInit()
+		ss.Loops.Run(etime.Train)
+		dt := ss.Logs.Table(etime.Validate, etime.Epoch)
+		for row := range dt.Rows {
+			fmt.Printf("Epoch: %3d", int(dt.Float("Epoch", row))+1)
+			for _, cat := range Categories {
+				fmt.Printf("  %s Err: %4.2f Gen: %4.2f", cat, dt.Float(cat+"Err", row), dt.Float("Gen"+cat+"Err", row))
+			}
+			fmt.Println()
+		}
+		ss.Loops.ResetAndRun(etime.Test) // keeping the trained weights
+	} else {
+		ss.Init()
+		ss.Loops.Run(etime.Test)
+	}
+
+	ss.Logs.CloseLogFiles()
+
+	if ss.Config.Figures != "" {
+		ss.ClusterPlots()
+		ss.Figures.Save(ss.Config.Figures, netName, runName)
+	}
+
+	if ss.Config.Export != "" {
+		simcore.SaveLogsNPZ(&ss.Logs, ss.Config.Export, netName, runName)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"cogentcore.org/core/tensor"
+)
+
+// Categories are the names of the category layers, which are trained
+// from the face images, and for which the errors are computed.
+var Categories = []string{"Emotion", "Gender", "Identity"}
+
+// TrainParams are the parameters for training the network from random
+// weights, with error-driven learning of the Emotion, Gender and Identity
+// categories of the faces, holding out some of the faces from training,
+// to test how well the categories generalize to them.
+type TrainParams struct {
+
+	// number of epochs of training, each with all of the faces that are not held out.
+	NEpochs int `default:"40" min:"1"`
+
+	// how often to test all of the faces, including the held-out ones,
+	// in terms of training epochs, which also updates the projection plots.
+	TestInterval int `default:"5" min:"1"`
+
+	// space-separated names of the faces to hold out from training,
+	// to test generalization: the defaults are one male and one female face,
+	// with different emotions, so that their identity can only be learned
+	// from their other emotion.
+	HoldOut string `default:"Alberto_sad Lisa_happy"`
+}
+
+func (tp *TrainParams) Defaults() {
+	tp.NEpochs = 40
+	tp.TestInterval = 5
+	tp.HoldOut = "Alberto_sad Lisa_happy"
+}
+
+// IsHeldOut returns whether the face with the given name is held out from training.
+func (tp *TrainParams) IsHeldOut(name string) bool {
+	return slices.Contains(strings.Fields(tp.HoldOut), name)
+}
+
+// CatErr returns 1 if the most active unit in the given activities of a
+// category layer is not the one that is active in its pattern, and 0 otherwise.
+func CatErr(acts []float32, pat tensor.Tensor) float64 {
+	ai, pi := 0, 0
+	for i, a := range acts {
+		if a > acts[ai] {
+			ai = i
+		}
+		if pat.Float1D(i) > pat.Float1D(pi) {
+			pi = i
+		}
+	}
+	if ai == pi {
+		return 0
+	}
+	return 1
+}
@@ -6,4 +6,10 @@ import (
 	"cogentcore.org/core/types"
 )
 
-var _ = types.AddType(&types.Type{Name: "main.Sim", IDName: "sim", Doc: "Sim encapsulates the entire simulation model, and we define all the\nfunctionality as methods on this struct.  This structure keeps all relevant\nstate information organized and available without having to pass everything around\nas arguments to methods, and provides the core GUI interface (note the view tags\nfor the fields which provide hints to how things should be displayed).", Methods: []types.Method{{Name: "SetInput", Doc: "SetInput sets whether the input to the network comes in bottom-up\n(Input layer) or top-down (Higher-level category layers)", Directives: []types.Directive{{Tool: "types", Directive: "add"}}, Args: []string{"topDown"}}, {Name: "SetPatterns", Doc: "SetPatterns selects which patterns to present: full or partial faces", Directives: []types.Directive{{Tool: "types", Directive: "add"}}, Args: []string{"partial"}}}, Fields: []types.Field{{Name: "Net", Doc: "the network -- click to view / edit parameters for layers, paths, etc"}, {Name: "Params", Doc: "network parameter management"}, {Name: "Loops", Doc: "contains looper control loops for running sim"}, {Name: "Stats", Doc: "contains computed statistic values"}, {Name: "Logs", Doc: "Contains all the logs and information about the logs.'"}, {Name: "Patterns", Doc: "the patterns to use"}, {Name: "PartialPatterns", Doc: "the partial patterns to use"}, {Name: "Envs", Doc: "Environments"}, {Name: "Context", Doc: "leabra timing parameters and state"}, {Name: "ViewUpdate", Doc: "netview update parameters"}, {Name: "GUI", Doc: "manages all the gui elements"}, {Name: "RandSeeds", Doc: "a list of random seeds to use for each run"}}})
+var _ = types.AddType(&types.Type{Name: "main.Config", IDName: "config", Doc: "Config has config parameters related to running the sim,\nwhich can be set from the command line or config.toml.", Fields: []types.Field{{Name: "TopDown", Doc: "present inputs top-down to the Emotion, Gender and Identity layers,\ninstead of bottom-up to the Input layer"}, {Name: "Partial", Doc: "present the partial faces patterns instead of the full faces"}, {Name: "Learn", Doc: "train the network from random weights, instead of using the pretrained\nweights, and then test it, when running without the GUI."}, {Name: "Train", Doc: "parameters for training the network from random weights."}, {Name: "NCycles", Doc: "number of cycles per trial"}, {Name: "GUI", Doc: "GUI means open the GUI. Otherwise it runs automatically and quits,\nsaving log files as specified in Log."}, {Name: "Figures", Doc: "plots, grids or NetView to save as images without the GUI, or \"all\" (see simcore.Figures)"}, {Name: "Export", Doc: "logs to export to .npz files for Python without the GUI, or \"all\" (see simcore.SaveLogsNPZ)"}, {Name: "Log", Doc: "Log has config parameters related to logging data."}}})
+
+var _ = types.AddType(&types.Type{Name: "main.LogConfig", IDName: "log-config", Doc: "LogConfig has config parameters related to logging data", Fields: []types.Field{{Name: "TestTrial", Doc: "if true, save testing trial log to file, as .tst_trl.tsv typically"}, {Name: "Epoch", Doc: "if true, save train epoch log to file, as .epc.tsv typically,\nwhen training with Learn."}, {Name: "ValidateEpoch", Doc: "if true, save the log of the tests of all the faces during training,\nwith the generalization to the held-out faces, to file,\nas .val_epc.tsv typically, when training with Learn."}}})
+
+var _ = types.AddType(&types.Type{Name: "main.Sim", IDName: "sim", Doc: "Sim encapsulates the entire simulation model, and we define all the\nfunctionality as methods on this struct.  This structure keeps all relevant\nstate information organized and available without having to pass everything around\nas arguments to methods, and provides the core GUI interface (note the view tags\nfor the fields which provide hints to how things should be displayed).", Methods: []types.Method{{Name: "SetInput", Doc: "SetInput sets whether the input to the network comes in bottom-up\n(Input layer) or top-down (Higher-level category layers)", Directives: []types.Directive{{Tool: "types", Directive: "add"}}, Args: []string{"topDown"}}, {Name: "SetPatterns", Doc: "SetPatterns selects which patterns to present: full or partial faces", Directives: []types.Directive{{Tool: "types", Directive: "add"}}, Args: []string{"partial"}}}, Fields: []types.Field{{Name: "Train", Doc: "parameters for training the network from random weights, with the Train controls."}, {Name: "Config", Doc: "Config contains misc configuration parameters for running the sim"}, {Name: "Net", Doc: "the network -- click to view / edit parameters for layers, paths, etc"}, {Name: "Params", Doc: "network parameter management"}, {Name: "Loops", Doc: "contains looper control loops for running sim"}, {Name: "Stats", Doc: "contains computed statistic values"}, {Name: "Logs", Doc: "Contains all the logs and information about the logs.'"}, {Name: "Patterns", Doc: "the patterns to use"}, {Name: "PartialPatterns", Doc: "the partial patterns to use"}, {Name: "Envs", Doc: "Environments"}, {Name: "Context", Doc: "leabra timing parameters and state"}, {Name: "ViewUpdate", Doc: "netview update parameters"}, {Name: "GUI", Doc: "manages all the gui elements"}, {Name: "Figures", Doc: "the plots, grids and network snapshots that can be saved without the GUI"}, {Name: "RandSeeds", Doc: "a list of random seeds to use for each run"}}})
+
+var _ = types.AddType(&types.Type{Name: "main.TrainParams", IDName: "train-params", Doc: "TrainParams are the parameters for training the network from random\nweights, with error-driven learning of the Emotion, Gender and Identity\ncategories of the faces, holding out some of the faces from training,\nto test how well the categories generalize to them.", Fields: []types.Field{{Name: "NEpochs", Doc: "number of epochs of training, each with all of the faces that are not held out."}, {Name: "TestInterval", Doc: "how often to test all of the faces, including the held-out ones,\nin terms of training epochs, which also updates the projection plots."}, {Name: "HoldOut", Doc: "space-separated names of the faces to hold out from training,\nto test generalization: the defaults are one male and one female face,\nwith different emotions, so that their identity can only be learned\nfrom their other emotion."}}})
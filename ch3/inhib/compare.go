@@ -0,0 +1,79 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+)
+
+// CompareParams are the parameters for the CompareInhib experiment, which
+// compares FFFB inhibition with the unit-level inhibition from the Inhib
+// neurons, in the feedforward and bidirectional networks, across different
+// levels of InputPct, with a new random input pattern on each trial.
+type CompareParams struct {
+
+	// lowest InputPct.
+	PctMin float32 `default:"10" min:"1" max:"50" step:"1"`
+
+	// highest InputPct.
+	PctMax float32 `default:"30" min:"1" max:"50" step:"1"`
+
+	// number of steps of InputPct from PctMin to PctMax.
+	NPct int `default:"4" min:"0"`
+
+	// number of trials for each condition, each with a new random input pattern.
+	NTrials int `default:"10" min:"1"`
+
+	// number of cycles per trial.
+	Cycles int `default:"200" min:"10"`
+
+	// number of cycles at the end of each trial over which the steady-state
+	// Hidden activity is averaged.
+	SteadyCycles int `default:"50" min:"1"`
+
+	// tolerance for settling: the settling time is the number of cycles
+	// until the Hidden activity stays within this distance of its
+	// steady-state value for the rest of the trial.
+	SettleTol float32 `default:"0.01" min:"0" step:"0.005"`
+
+	// time constant (tau) for updating G conductances into the Hidden and
+	// Inhib neurons with FFFB inhibition, which is the standard default
+	// (see the README): HiddenGTau and InhibGTau are used for the
+	// unit-level inhibition.
+	FFFBGTau float32 `default:"1.4" min:"1" step:"0.1"`
+}
+
+func (cp *CompareParams) Defaults() {
+	cp.PctMin = 10
+	cp.PctMax = 30
+	cp.NPct = 4
+	cp.NTrials = 10
+	cp.Cycles = 200
+	cp.SteadyCycles = 50
+	cp.SettleTol = 0.01
+	cp.FFFBGTau = 1.4
+}
+
+// Pct returns the InputPct for the given step from PctMin to PctMax,
+// rounded to the nearest whole number of active input units.
+func (cp *CompareParams) Pct(step int) float32 {
+	if cp.NPct == 0 {
+		return cp.PctMin
+	}
+	return float32(math.Round(float64(cp.PctMin + (cp.PctMax-cp.PctMin)*float32(step)/float32(cp.NPct))))
+}
+
+// SteadySettle returns the steady-state value of the given activities,
+// averaged over the last steady ones, and the settling time, which is the
+// number of cycles until they stay within tol of the steady-state value.
+func SteadySettle(acts []float64, steady int, tol float64) (act float64, settle int) {
+	act, _ = MeanSD(acts[max(len(acts)-steady, 0):])
+	for cyc := len(acts) - 1; cyc >= 0; cyc-- {
+		if math.Abs(acts[cyc]-act) > tol {
+			return act, cyc + 1
+		}
+	}
+	return act, 0
+}
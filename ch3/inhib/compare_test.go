@@ -0,0 +1,53 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComparePct(t *testing.T) {
+	cp := &CompareParams{}
+	cp.Defaults()
+	for step, want := range []float32{10, 15, 20, 25, 30} {
+		if pct := cp.Pct(step); pct != want {
+			t.Errorf("Pct(%d) = %g != %g", step, pct, want)
+		}
+	}
+	cp.PctMax = 20
+	cp.NPct = 3
+	for step, want := range []float32{10, 13, 17, 20} { // rounded
+		if pct := cp.Pct(step); pct != want {
+			t.Errorf("Pct(%d) of 10-20 in 3 steps = %g != %g", step, pct, want)
+		}
+	}
+	cp.NPct = 0
+	if pct := cp.Pct(0); pct != cp.PctMin {
+		t.Errorf("Pct(0) with NPct = 0: %g != PctMin %g", pct, cp.PctMin)
+	}
+}
+
+func TestSteadySettle(t *testing.T) {
+	tests := []struct {
+		name   string
+		acts   []float64
+		steady int
+		act    float64
+		settle int
+	}{
+		{"step", []float64{0, 0.2, 0.4, 0.5, 0.5, 0.5, 0.5}, 3, 0.5, 3},
+		{"within tol", []float64{0, 0.495, 0.5, 0.505, 0.5}, 2, 0.5025, 1},
+		{"overshoot", []float64{0, 0.8, 0.4, 0.52, 0.5, 0.5}, 2, 0.5, 4},
+		{"constant", []float64{0.3, 0.3, 0.3}, 2, 0.3, 0},
+		{"steady longer than acts", []float64{0.2, 0.4}, 5, 0.3, 2},
+	}
+	for _, tt := range tests {
+		act, settle := SteadySettle(tt.acts, tt.steady, 0.01)
+		if math.Abs(act-tt.act) > 1.0e-9 || settle != tt.settle {
+			t.Errorf("%s: SteadySettle = %g, %d != %g, %d", tt.name, act, settle, tt.act, tt.settle)
+		}
+	}
+}
@@ -10,14 +10,20 @@ package main
 //go:generate core generate -add-types
 
 import (
+	"fmt"
+	"math"
+
 	"cogentcore.org/core/base/errors"
 	"cogentcore.org/core/base/randx"
 	"cogentcore.org/core/core"
 	"cogentcore.org/core/enums"
 	"cogentcore.org/core/icons"
+	"cogentcore.org/core/plot/plotcore"
 	"cogentcore.org/core/tensor"
 	"cogentcore.org/core/tensor/table"
 	"cogentcore.org/core/tree"
+	"github.com/CompCogNeuro/sims/v2/simcore"
+	"github.com/emer/emergent/v2/econfig"
 	"github.com/emer/emergent/v2/egui"
 	"github.com/emer/emergent/v2/elog"
 	"github.com/emer/emergent/v2/emer"
@@ -36,7 +42,11 @@ func main() {
 	sim := &Sim{}
 	sim.New()
 	sim.ConfigAll()
-	sim.RunGUI()
+	if sim.Config.GUI {
+		sim.RunGUI()
+	} else {
+		sim.RunNoGUI()
+	}
 }
 
 // ParamSets is the default set of parameters.
@@ -90,16 +100,21 @@ var ParamSets = params.Sets{
 	},
 }
 
-// Sim encapsulates the entire simulation model, and we define all the
-// functionality as methods on this struct.  This structure keeps all relevant
-// state information organized and available without having to pass everything around
-// as arguments to methods, and provides the core GUI interface (note the view tags
-// for the fields which provide hints to how things should be displayed).
-type Sim struct {
+// InhibParams are the parameters of the inhibition in the networks:
+// which network is used, its input, and the strengths and time constants
+// of the connections to and from the Inhib neurons, or the FFFB
+// computed inhibition that replaces them.
+type InhibParams struct {
+
 	// if true, use the bidirectionally connected network,
 	// otherwise use the simpler feedforward network.
 	BidirNet bool
 
+	// if true, use the topographic network, where the Hidden and Inhib
+	// neurons are arranged on a 2D sheet with local Gaussian connectivity,
+	// instead of the feedforward or bidirectional network.
+	TopoNet bool
+
 	// simulate trained weights by having higher variance and Gaussian
 	// distributed weight values -- otherwise lower variance, uniform.
 	TrainedWts bool
@@ -137,6 +152,94 @@ type Sim struct {
 	// hidden and inhib layers.  This must be set to 0 to turn off the
 	// connection-based inhibition when using the FFFBInhib computed inbhition.
 	FmInhibWtScaleAbs float32 `default:"1"`
+}
+
+// Defaults sets the default parameters, keeping the choice of network.
+func (ip *InhibParams) Defaults() {
+	ip.TrainedWts = false
+	ip.InputPct = 20
+	ip.FFFBInhib = false
+	ip.HiddenGbarI = 0.4
+	ip.InhibGbarI = 0.75
+	ip.FFinhibWtScale = 1
+	ip.FBinhibWtScale = 1
+	ip.HiddenGTau = 40
+	ip.InhibGTau = 20
+	ip.FmInhibWtScaleAbs = 1
+}
+
+// Config has config parameters related to running the sim.
+// -FFFBInhib -FmInhibWtScaleAbs 0, for example, runs the FFFB computed
+// inhibition alone, by setting the Inhib parameters that the Sim starts with,
+// along with its Osc and Comp.
+type Config struct {
+
+	// the inhibition parameters, e.g., -InputPct 30 for more active inputs
+	Inhib InhibParams `display:"add-fields"`
+
+	// parameters for the topographic network.
+	Topo TopoParams `display:"add-fields" nest:"+"`
+
+	// parameters for the Oscillation analysis of the power spectrum of
+	// the Hidden layer activity as a function of the G taus.
+	Osc OscParams `display:"add-fields" nest:"+"`
+
+	// run the Oscillation analysis instead of the test trials, saving the
+	// results to oscillation.tsv and spectrum.tsv, when running without the GUI.
+	Oscillation bool
+
+	// parameters for the CompareInhib experiment comparing FFFB with
+	// the unit-level inhibition across levels of InputPct.
+	Comp CompareParams `display:"add-fields" nest:"+"`
+
+	// run the CompareInhib experiment instead of the test trials, saving
+	// the results to compare.tsv, when running without the GUI.
+	CompareInhib bool
+
+	// GUI means open the GUI. Otherwise it runs automatically and quits,
+	// saving log files as specified in Log.
+	GUI bool `default:"true"`
+
+	// plots, grids or NetView to save as images without the GUI, or "all" (see simcore.Figures)
+	Figures string
+
+	// logs to export to .npz files for Python without the GUI, or "all" (see simcore.SaveLogsNPZ)
+	Export string
+
+	// Log has config parameters related to logging data.
+	Log LogConfig `display:"add-fields"`
+}
+
+// LogConfig has config parameters related to logging data
+type LogConfig struct {
+
+	// if true, save testing trial log to file, as .tst_trl.tsv typically
+	TestTrial bool `default:"true" nest:"+"`
+
+	// if true, save testing cycle log to file, as .tst_cyc.tsv typically. May be large.
+	TestCycle bool `default:"false" nest:"+"`
+}
+
+// Sim encapsulates the entire simulation model, and we define all the
+// functionality as methods on this struct.  This structure keeps all relevant
+// state information organized and available without having to pass everything around
+// as arguments to methods, and provides the core GUI interface (note the view tags
+// for the fields which provide hints to how things should be displayed).
+type Sim struct {
+
+	// the parameters of the inhibition in the networks
+	Inhib InhibParams `display:"add-fields"`
+
+	// parameters for the Oscillation analysis of the power spectrum of
+	// the Hidden layer activity as a function of the G taus.
+	Osc OscParams `display:"add-fields"`
+
+	// parameters for the CompareInhib experiment comparing FFFB with
+	// the unit-level inhibition across levels of InputPct.
+	Comp CompareParams `display:"add-fields"`
+
+	// Config contains misc configuration parameters for running the sim
+	Config Config `display:"-"`
 
 	// the feedforward network -- click to view / edit parameters for layers, paths, etc
 	NetFF *leabra.Network `new-window:"+" display:"no-inline"`
@@ -144,12 +247,16 @@ type Sim struct {
 	// the bidirectional network -- click to view / edit parameters for layers, paths, etc
 	NetBidir *leabra.Network `new-window:"+" display:"no-inline"`
 
+	// the topographic network -- click to view / edit parameters for layers, paths, etc
+	NetTopo *leabra.Network `new-window:"+" display:"no-inline"`
+
 	// network parameter management
 	Params emer.NetParams `display:"add-fields"`
 
 	// contains looper control loops for running sim
 	LoopsFF    *looper.Stacks `display:"-"`
 	LoopsBidir *looper.Stacks `display:"-"`
+	LoopsTopo  *looper.Stacks `display:"-"`
 
 	// contains computed statistic values
 	Stats estats.Stats `display:"-"`
@@ -172,8 +279,12 @@ type Sim struct {
 	// manages all the gui elements
 	GUI egui.GUI `display:"-"`
 
+	// the plots, grids and network snapshots that can be saved without the GUI
+	Figures simcore.Figures `display:"-"`
+
 	NetviewFF    *netview.NetView `display:"-"`
 	NetviewBidir *netview.NetView `display:"-"`
+	NetviewTopo  *netview.NetView `display:"-"`
 
 	// a list of random seeds to use for each run
 	RandSeeds randx.Seeds `display:"-"`
@@ -182,8 +293,13 @@ type Sim struct {
 // New creates new blank elements and initializes defaults
 func (ss *Sim) New() {
 	ss.Defaults()
+	econfig.Config(&ss.Config, "config.toml")
+	ss.Inhib = ss.Config.Inhib
+	ss.Osc = ss.Config.Osc
+	ss.Comp = ss.Config.Comp
 	ss.NetFF = leabra.NewNetwork("InhibFF")
 	ss.NetBidir = leabra.NewNetwork("InhibBidir")
+	ss.NetTopo = leabra.NewNetwork("InhibTopo")
 	ss.Params.Config(ParamSets, "", "", ss.Net())
 	ss.Stats.Init()
 	ss.Patterns = &table.Table{}
@@ -193,16 +309,9 @@ func (ss *Sim) New() {
 }
 
 func (ss *Sim) Defaults() {
-	ss.TrainedWts = false
-	ss.InputPct = 20
-	ss.FFFBInhib = false
-	ss.HiddenGbarI = 0.4
-	ss.InhibGbarI = 0.75
-	ss.FFinhibWtScale = 1
-	ss.FBinhibWtScale = 1
-	ss.HiddenGTau = 40
-	ss.InhibGTau = 20
-	ss.FmInhibWtScaleAbs = 1
+	ss.Inhib.Defaults()
+	ss.Osc.Defaults()
+	ss.Comp.Defaults()
 }
 
 //////////////////////////////////////////////////////////////////////////////
@@ -210,7 +319,10 @@ func (ss *Sim) Defaults() {
 
 // Net returns the current active network
 func (ss *Sim) Net() *leabra.Network {
-	if ss.BidirNet {
+	if ss.Inhib.TopoNet {
+		return ss.NetTopo
+	}
+	if ss.Inhib.BidirNet {
 		return ss.NetBidir
 	} else {
 		return ss.NetFF
@@ -219,7 +331,10 @@ func (ss *Sim) Net() *leabra.Network {
 
 // Loops returns the current active looper
 func (ss *Sim) Loops() *looper.Stacks {
-	if ss.BidirNet {
+	if ss.Inhib.TopoNet {
+		return ss.LoopsTopo
+	}
+	if ss.Inhib.BidirNet {
 		return ss.LoopsBidir
 	} else {
 		return ss.LoopsFF
@@ -232,9 +347,11 @@ func (ss *Sim) ConfigAll() {
 	ss.ConfigEnv()
 	ss.ConfigNetFF(ss.NetFF)
 	ss.ConfigNetBidir(ss.NetBidir)
+	ss.ConfigNetTopo(ss.NetTopo)
 	ss.ConfigLogs()
 	ss.LoopsFF = ss.ConfigLoops(ss.NetFF)
 	ss.LoopsBidir = ss.ConfigLoops(ss.NetBidir)
+	ss.LoopsTopo = ss.ConfigLoops(ss.NetTopo)
 }
 
 func (ss *Sim) ConfigNetFF(net *leabra.Network) {
@@ -296,60 +413,90 @@ func (ss *Sim) ConfigNetBidir(net *leabra.Network) {
 	ss.InitWeights(net)
 }
 
-// InitWeights initializes weights to digit 8
+// ConfigNetTopo configures the topographic network, where the Hidden and
+// Inhib neurons are arranged on a common 2D sheet, with the Inhib neurons
+// spread evenly among the Hidden ones, and all the connections are local,
+// with Gaussian weights as a function of distance on the sheet,
+// wrapping around at the edges.
+func (ss *Sim) ConfigNetTopo(net *leabra.Network) {
+	net.SetRandSeed(ss.RandSeeds[0]) // init new separate random seed, using run = 0
+	tp := &ss.Config.Topo
+
+	inp := net.AddLayer2D("Input", 10, 10, leabra.InputLayer)
+	hid := net.AddLayer2D("Hidden", 10, 10, leabra.SuperLayer)
+	inh := net.AddLayer2D("Inhib", tp.InhibSize, tp.InhibSize, leabra.SuperLayer)
+	inh.AddClass("InhibLay")
+
+	net.ConnectLayers(inp, hid, tp.Circle(tp.ExciteRadius, 10), leabra.ForwardPath).AddClass("Excite")
+	net.ConnectLayers(hid, inh, tp.Circle(tp.ExciteRadius, 10), leabra.BackPath)
+	net.ConnectLayers(inp, inh, tp.Circle(tp.ExciteRadius, 10), leabra.ForwardPath)
+	net.ConnectLayers(inh, hid, tp.Circle(tp.InhibRadius, tp.InhibSize), leabra.InhibPath)
+	net.ConnectLayers(inh, inh, tp.Circle(tp.InhibRadius, tp.InhibSize), leabra.InhibPath)
+
+	inh.PlaceRightOf(hid, 2)
+
+	net.Build()
+	net.Defaults()
+	ss.ApplyParams(net)
+	ss.InitWeights(net)
+}
+
+// InitWeights initializes the weights, with the Gaussian topographic
+// scaling of the weights in the topographic network.
 func (ss *Sim) InitWeights(net *leabra.Network) {
+	net.InitTopoScales() // scales must be set before the weights
 	net.InitWeights()
 }
 
 func (ss *Sim) ApplyParams(net *leabra.Network) {
 	ss.Params.Network = net
 	ss.Params.SetAll()
-	if ss.TrainedWts {
+	if ss.Inhib.TrainedWts {
 		ss.Params.SetAllSheet("Trained")
 	} else {
 		ss.Params.SetAllSheet("Untrained")
 	}
-	ffinhsc := ss.FFinhibWtScale
+	ffinhsc := ss.Inhib.FFinhibWtScale
 	if net == ss.NetBidir {
 		ffinhsc *= 0.5 // 2 inhib prjns so .5 ea
 	}
 	hid := net.LayerByName("Hidden")
-	hid.Act.Gbar.I = ss.HiddenGbarI
-	hid.Act.Dt.GTau = ss.HiddenGTau
+	hid.Act.Gbar.I = ss.Inhib.HiddenGbarI
+	hid.Act.Dt.GTau = ss.Inhib.HiddenGTau
 	hid.Act.Update()
 	inh := net.LayerByName("Inhib")
-	inh.Act.Gbar.I = ss.InhibGbarI
-	inh.Act.Dt.GTau = ss.InhibGTau
+	inh.Act.Gbar.I = ss.Inhib.InhibGbarI
+	inh.Act.Dt.GTau = ss.Inhib.InhibGTau
 	inh.Act.Update()
 	ff := errors.Log1(inh.RecvPathBySendName("Input")).(*leabra.Path)
 	ff.WtScale.Rel = ffinhsc
 	fb := errors.Log1(inh.RecvPathBySendName("Hidden")).(*leabra.Path)
-	fb.WtScale.Rel = ss.FBinhibWtScale
-	hid.Inhib.Layer.On = ss.FFFBInhib
-	inh.Inhib.Layer.On = ss.FFFBInhib
+	fb.WtScale.Rel = ss.Inhib.FBinhibWtScale
+	hid.Inhib.Layer.On = ss.Inhib.FFFBInhib
+	inh.Inhib.Layer.On = ss.Inhib.FFFBInhib
 	fi := errors.Log1(hid.RecvPathBySendName("Inhib")).(*leabra.Path)
-	fi.WtScale.Abs = ss.FmInhibWtScaleAbs
+	fi.WtScale.Abs = ss.Inhib.FmInhibWtScaleAbs
 	fi = errors.Log1(inh.RecvPathBySendName("Inhib")).(*leabra.Path)
-	fi.WtScale.Abs = ss.FmInhibWtScaleAbs
+	fi.WtScale.Abs = ss.Inhib.FmInhibWtScaleAbs
 	if net == ss.NetBidir {
 		hid = net.LayerByName("Hidden2")
-		hid.Act.Gbar.I = ss.HiddenGbarI
-		hid.Act.Dt.GTau = ss.HiddenGTau
+		hid.Act.Gbar.I = ss.Inhib.HiddenGbarI
+		hid.Act.Dt.GTau = ss.Inhib.HiddenGTau
 		hid.Act.Update()
 		inh = net.LayerByName("Inhib2")
-		inh.Act.Gbar.I = ss.InhibGbarI
-		inh.Act.Dt.GTau = ss.InhibGTau
+		inh.Act.Gbar.I = ss.Inhib.InhibGbarI
+		inh.Act.Dt.GTau = ss.Inhib.InhibGTau
 		inh.Act.Update()
-		hid.Inhib.Layer.On = ss.FFFBInhib
-		inh.Inhib.Layer.On = ss.FFFBInhib
+		hid.Inhib.Layer.On = ss.Inhib.FFFBInhib
+		inh.Inhib.Layer.On = ss.Inhib.FFFBInhib
 		fi = errors.Log1(hid.RecvPathBySendName("Inhib2")).(*leabra.Path)
-		fi.WtScale.Abs = ss.FmInhibWtScaleAbs
+		fi.WtScale.Abs = ss.Inhib.FmInhibWtScaleAbs
 		fi = errors.Log1(inh.RecvPathBySendName("Inhib2")).(*leabra.Path)
-		fi.WtScale.Abs = ss.FmInhibWtScaleAbs
+		fi.WtScale.Abs = ss.Inhib.FmInhibWtScaleAbs
 		ff = errors.Log1(inh.RecvPathBySendName("Hidden")).(*leabra.Path)
 		ff.WtScale.Rel = ffinhsc
 		fb = errors.Log1(inh.RecvPathBySendName("Hidden2")).(*leabra.Path)
-		fb.WtScale.Rel = ss.FBinhibWtScale
+		fb.WtScale.Rel = ss.Inhib.FBinhibWtScale
 		inh = net.LayerByName("Inhib")
 		ff = errors.Log1(inh.RecvPathBySendName("Hidden2")).(*leabra.Path)
 		ff.WtScale.Rel = ffinhsc
@@ -364,7 +511,7 @@ func (ss *Sim) ConfigPatterns() {
 	dt.AddFloat32TensorColumn("Input", []int{10, 10}, "Y", "X")
 	dt.SetNumRows(1)
 
-	patgen.PermutedBinaryRows(dt.Columns[1].(*tensor.Float32), int(ss.InputPct), 1, 0)
+	patgen.PermutedBinaryRows(dt.Columns[1].(*tensor.Float32), int(ss.Inhib.InputPct), 1, 0)
 }
 
 func (ss *Sim) ConfigEnv() {
@@ -391,13 +538,17 @@ func (ss *Sim) ConfigEnv() {
 // Init restarts the run, and initializes everything, including network weights
 // and resets the epoch log table
 func (ss *Sim) Init() {
-	if ss.BidirNet {
+	switch {
+	case ss.Inhib.TopoNet:
+		ss.ViewUpdate.View = ss.NetviewTopo
+	case ss.Inhib.BidirNet:
 		ss.ViewUpdate.View = ss.NetviewBidir
-	} else {
+	default:
 		ss.ViewUpdate.View = ss.NetviewFF
 	}
 	ss.LoopsFF.ResetCounters()
 	ss.LoopsBidir.ResetCounters()
+	ss.LoopsTopo.ResetCounters()
 	// ss.InitRandSeed(0)
 	ss.GUI.StopNow = false
 	ss.ApplyParams(ss.Net())
@@ -445,9 +596,11 @@ func (ss *Sim) ConfigLoops(net *leabra.Network) *looper.Stacks {
 	////////////////////////////////////////////
 	// GUI
 
-	leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, net, ss.NetViewCounters)
-	leabra.LooperUpdatePlots(ls, &ss.GUI)
-	ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	if ss.Config.GUI {
+		leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, net, ss.NetViewCounters)
+		leabra.LooperUpdatePlots(ls, &ss.GUI)
+		ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	}
 	return ls
 }
 
@@ -517,26 +670,6 @@ func (ss *Sim) NetViewCounters(tm etime.Times) {
 	ss.ViewUpdate.Text = ss.Stats.Print([]string{"Trial", "TrialName", "Cycle"})
 }
 
-// Harmony computes the harmony (excitatory net input Ge * Act)
-func (ss *Sim) Harmony(nt *leabra.Network) float32 {
-	harm := float32(0)
-	nu := 0
-	for _, ly := range nt.Layers {
-		if ly.Off {
-			continue
-		}
-		for i := range ly.Neurons {
-			nrn := &(ly.Neurons[i])
-			harm += nrn.Ge * nrn.Act
-			nu++
-		}
-	}
-	if nu > 0 {
-		harm /= float32(nu)
-	}
-	return harm
-}
-
 //////////////////////////////////////////////////////////////////////////////
 // 		Logging
 
@@ -551,6 +684,36 @@ func (ss *Sim) ConfigLogs() {
 	ss.Logs.CreateTables()
 	ss.Logs.SetContext(&ss.Stats, ss.Net())
 	ss.Logs.PlotItems("HiddenActAvg", "InhibActAvg")
+
+	dt := ss.Logs.MiscTable("Oscillation")
+	dt.AddStringColumn("Inhib")
+	for _, cn := range []string{"HiddenGTau", "InhibGTau", "MeanAct", "SDAct", "PeakHz", "PeakPower", "PeakFrac"} {
+		dt.AddFloat64Column(cn)
+	}
+	dt.SetMetaData("SDAct:On", "+")
+	dt.SetMetaData("Points", "true")
+
+	dt = ss.Logs.MiscTable("Spectrum")
+	dt.AddStringColumn("Condition")
+	dt.AddFloat64Column("Hz")
+	dt.AddFloat64Column("Power")
+	dt.SetMetaData("Power:On", "+")
+
+	dt = ss.Logs.MiscTable("OscTrace")
+	dt.AddStringColumn("Condition")
+	dt.AddFloat64Column("Cycle")
+	dt.AddFloat64Column("HiddenAct")
+	dt.SetMetaData("HiddenAct:On", "+")
+
+	dt = ss.Logs.MiscTable("Compare")
+	dt.AddStringColumn("Net")
+	dt.AddStringColumn("Inhib")
+	dt.AddStringColumn("Condition")
+	for _, cn := range []string{"InputPct", "ActPct", "ActPctSD", "SettleCycles", "SettleSD"} {
+		dt.AddFloat64Column(cn)
+	}
+	dt.SetMetaData("ActPct:On", "+")
+	dt.SetMetaData("Points", "true")
 }
 
 func (ss *Sim) CycleStats() {
@@ -561,6 +724,200 @@ func (ss *Sim) CycleStats() {
 	}
 }
 
+// SettleTrial runs one trial of the given number of cycles on the current
+// network, with the given input pattern, starting from initialized
+// activations, and returns the average activity of the Hidden layer
+// on each cycle.
+func (ss *Sim) SettleTrial(pat tensor.Tensor, cycles int) []float64 {
+	ctx := &ss.Context
+	net := ss.Net()
+	ss.ApplyParams(net)
+	net.InitActs()
+	net.AlphaCycInit(false)
+	ctx.AlphaCycStart()
+	net.InitExt()
+	net.LayerByName("Input").ApplyExt(pat)
+	hid := net.LayerByName("Hidden")
+	acts := make([]float64, cycles)
+	for cyc := range cycles {
+		net.Cycle(ctx)
+		ctx.CycleInc()
+		acts[cyc] = float64(hid.Pools[0].Inhib.Act.Avg)
+	}
+	return acts
+}
+
+// Oscillation runs the analysis of the oscillations in the activity of the
+// Hidden layer of the current network: for Osc.NGTau + 1 values of HiddenGTau
+// from Osc.GTauMin to Osc.GTauMax, with InhibGTau half of it, it runs a long
+// trial with the unit-level inhibition, and with FFFB inhibition if Osc.FFFB,
+// and computes the power spectrum of the Hidden activity after the initial
+// settling. The Oscillation table has the mean and SD of the activity and the
+// frequency, power and fraction of the total power of the peak of the
+// spectrum for each condition, the Spectrum table has the spectra, and
+// the OscTrace table has the activity on each cycle.
+func (ss *Sim) Oscillation() {
+	op := &ss.Osc
+	fffb, hgbi, igbi, fmabs := ss.Inhib.FFFBInhib, ss.Inhib.HiddenGbarI, ss.Inhib.InhibGbarI, ss.Inhib.FmInhibWtScaleAbs
+	hgtau, igtau := ss.Inhib.HiddenGTau, ss.Inhib.InhibGTau
+	osc := ss.Logs.MiscTable("Oscillation")
+	osc.SetNumRows(0)
+	spec := ss.Logs.MiscTable("Spectrum")
+	spec.SetNumRows(0)
+	trc := ss.Logs.MiscTable("OscTrace")
+	trc.SetNumRows(0)
+	plts := []*plotcore.PlotEditor{ss.GUI.PlotByName("Oscillation"), ss.GUI.PlotByName("Spectrum"), ss.GUI.PlotByName("OscTrace")}
+	inhibs := []string{"Unit"}
+	if op.FFFB {
+		inhibs = append(inhibs, "FFFB")
+	}
+	ss.InitRandSeed(0)
+	for _, inhib := range inhibs {
+		for step := 0; step <= op.NGTau; step++ {
+			ss.Inhib.FFFBInhib = inhib == "FFFB"
+			if ss.Inhib.FFFBInhib {
+				ss.Inhib.HiddenGbarI, ss.Inhib.InhibGbarI, ss.Inhib.FmInhibWtScaleAbs = 1, 1, 0
+			} else {
+				ss.Inhib.HiddenGbarI, ss.Inhib.InhibGbarI, ss.Inhib.FmInhibWtScaleAbs = hgbi, igbi, fmabs
+			}
+			ss.Inhib.HiddenGTau = op.GTau(step)
+			ss.Inhib.InhibGTau = 0.5 * ss.Inhib.HiddenGTau
+			acts := ss.SettleTrial(ss.Patterns.Tensor("Input", 0), op.Cycles)[op.Skip:]
+			n := len(acts)
+			mean, sd := MeanSD(acts)
+			pow := PowerSpectrum(acts)
+			hz, peak, frac := math.NaN(), 0.0, 0.0
+			if sd >= float64(op.MinSD) {
+				hz, peak, frac = SpectrumPeak(pow, n, float64(op.MinHz), float64(op.MaxHz))
+			}
+			gtau := math.Round(float64(ss.Inhib.HiddenGTau)*100) / 100
+			cond := fmt.Sprintf("%s GTau %g", inhib, gtau)
+
+			row := osc.Rows
+			osc.AddRows(1)
+			osc.SetString("Inhib", row, inhib)
+			osc.SetFloat("HiddenGTau", row, gtau)
+			osc.SetFloat("InhibGTau", row, gtau/2)
+			osc.SetFloat("MeanAct", row, mean)
+			osc.SetFloat("SDAct", row, sd)
+			osc.SetFloat("PeakHz", row, hz)
+			osc.SetFloat("PeakPower", row, peak)
+			osc.SetFloat("PeakFrac", row, frac)
+			for i, p := range pow {
+				f := float64(i) * 1000 / float64(n)
+				if i == 0 || f > float64(op.MaxHz) {
+					continue
+				}
+				row = spec.Rows
+				spec.AddRows(1)
+				spec.SetString("Condition", row, cond)
+				spec.SetFloat("Hz", row, f)
+				spec.SetFloat("Power", row, p)
+			}
+			for i, a := range acts {
+				row = trc.Rows
+				trc.AddRows(1)
+				trc.SetString("Condition", row, cond)
+				trc.SetFloat("Cycle", row, float64(op.Skip+i))
+				trc.SetFloat("HiddenAct", row, a)
+			}
+			if ss.GUI.StopNow {
+				break
+			}
+			if plts[0] != nil {
+				for _, plt := range plts {
+					plt.GoUpdatePlot()
+				}
+			}
+			simcore.WebYield()
+		}
+	}
+	ss.Inhib.FFFBInhib = fffb
+	ss.Inhib.HiddenGbarI, ss.Inhib.InhibGbarI, ss.Inhib.FmInhibWtScaleAbs = hgbi, igbi, fmabs
+	ss.Inhib.HiddenGTau, ss.Inhib.InhibGTau = hgtau, igtau
+	ss.ApplyParams(ss.Net())
+	ss.GUI.IsRunning = false
+	if plts[0] != nil {
+		for _, plt := range plts {
+			plt.GoUpdatePlot()
+		}
+		ss.GUI.UpdateWindow()
+	}
+}
+
+// CompareInhib runs the experiment comparing FFFB inhibition with the
+// unit-level inhibition from the Inhib neurons, in the feedforward and
+// bidirectional networks: for Comp.NPct + 1 levels of InputPct from
+// Comp.PctMin to Comp.PctMax, it runs Comp.NTrials trials, each with a new
+// random input pattern, and records the mean and SD across trials of the
+// steady-state percent activity of the Hidden layer, and of its settling
+// time, in the Compare table. FFFB uses the settings from the README:
+// GbarI = 1, FmInhibWtScaleAbs = 0, and G taus of Comp.FFFBGTau.
+func (ss *Sim) CompareInhib() {
+	cp := &ss.Comp
+	bidir, topo, fffb := ss.Inhib.BidirNet, ss.Inhib.TopoNet, ss.Inhib.FFFBInhib
+	hgbi, igbi, fmabs := ss.Inhib.HiddenGbarI, ss.Inhib.InhibGbarI, ss.Inhib.FmInhibWtScaleAbs
+	hgtau, igtau := ss.Inhib.HiddenGTau, ss.Inhib.InhibGTau
+	dt := ss.Logs.MiscTable("Compare")
+	dt.SetNumRows(0)
+	plt := ss.GUI.PlotByName("Compare")
+	pat := tensor.NewFloat32([]int{1, 10, 10})
+	ss.InitRandSeed(0)
+	for _, net := range []string{"FF", "Bidir"} {
+		ss.Inhib.BidirNet = net == "Bidir"
+		ss.Inhib.TopoNet = false
+		for _, inhib := range []string{"Unit", "FFFB"} {
+			ss.Inhib.FFFBInhib = inhib == "FFFB"
+			if ss.Inhib.FFFBInhib {
+				ss.Inhib.HiddenGbarI, ss.Inhib.InhibGbarI, ss.Inhib.FmInhibWtScaleAbs = 1, 1, 0
+				ss.Inhib.HiddenGTau, ss.Inhib.InhibGTau = cp.FFFBGTau, cp.FFFBGTau
+			} else {
+				ss.Inhib.HiddenGbarI, ss.Inhib.InhibGbarI, ss.Inhib.FmInhibWtScaleAbs = hgbi, igbi, fmabs
+				ss.Inhib.HiddenGTau, ss.Inhib.InhibGTau = hgtau, igtau
+			}
+			for step := 0; step <= cp.NPct; step++ {
+				pct := cp.Pct(step)
+				acts := make([]float64, cp.NTrials)
+				settles := make([]float64, cp.NTrials)
+				for trl := range cp.NTrials {
+					patgen.PermutedBinaryRows(pat, int(pct), 1, 0)
+					act, settle := SteadySettle(ss.SettleTrial(pat.SubSpace([]int{0}), cp.Cycles), cp.SteadyCycles, float64(cp.SettleTol))
+					acts[trl] = 100 * act
+					settles[trl] = float64(settle)
+				}
+				act, actSD := MeanSD(acts)
+				settle, settleSD := MeanSD(settles)
+				row := dt.Rows
+				dt.AddRows(1)
+				dt.SetString("Net", row, net)
+				dt.SetString("Inhib", row, inhib)
+				dt.SetString("Condition", row, net+" "+inhib)
+				dt.SetFloat("InputPct", row, float64(pct))
+				dt.SetFloat("ActPct", row, act)
+				dt.SetFloat("ActPctSD", row, actSD)
+				dt.SetFloat("SettleCycles", row, settle)
+				dt.SetFloat("SettleSD", row, settleSD)
+				if ss.GUI.StopNow {
+					break
+				}
+				if plt != nil {
+					plt.GoUpdatePlot()
+				}
+				simcore.WebYield()
+			}
+		}
+	}
+	ss.Inhib.BidirNet, ss.Inhib.TopoNet, ss.Inhib.FFFBInhib = bidir, topo, fffb
+	ss.Inhib.HiddenGbarI, ss.Inhib.InhibGbarI, ss.Inhib.FmInhibWtScaleAbs = hgbi, igbi, fmabs
+	ss.Inhib.HiddenGTau, ss.Inhib.InhibGTau = hgtau, igtau
+	ss.ApplyParams(ss.Net())
+	ss.GUI.IsRunning = false
+	if plt != nil {
+		plt.GoUpdatePlot()
+		ss.GUI.UpdateWindow()
+	}
+}
+
 // Log is the main logging function, handles special things for different scopes
 func (ss *Sim) Log(mode etime.Modes, time etime.Times) {
 	ctx := &ss.Context
@@ -612,14 +969,54 @@ func (ss *Sim) ConfigGUI() {
 	nv.Options.PathWidth = 0.005
 	nv.Current()
 
-	ss.GUI.AddPlots(title, &ss.Logs)
+	nv = ss.GUI.AddNetView("Topo Net")
+	ss.NetviewTopo = nv
+	nv.Options.MaxRecs = 300
+	nv.Options.Raster.Max = 100
+	nv.SetNet(ss.NetTopo)
+	nv.Options.PathWidth = 0.005
+	nv.Current()
+
+	ss.ConfigPlots(simcore.GUIPlots{GUI: &ss.GUI})
 
 	ss.GUI.FinalizeGUI(false)
 }
 
+// ConfigPlots adds the log plots, and the plots of the Oscillation
+// by GTau, the Spectrum and the OscTrace of the hidden activity,
+// and the Compare of FFFB vs. unit inhibition, to the GUI or the Figures.
+func (ss *Sim) ConfigPlots(pl simcore.Plots) {
+	pl.AddLogPlots("Inhib", &ss.Logs)
+
+	plt := pl.AddPlot("Oscillation")
+	plt.Options.Title = "Oscillation vs. GTau"
+	plt.Options.XAxis = "HiddenGTau"
+	plt.Options.Legend = "Inhib"
+	plt.SetTable(ss.Logs.MiscTable("Oscillation"))
+
+	plt = pl.AddPlot("Spectrum")
+	plt.Options.Title = "Hidden Activity Power Spectrum"
+	plt.Options.XAxis = "Hz"
+	plt.Options.Legend = "Condition"
+	plt.SetTable(ss.Logs.MiscTable("Spectrum"))
+
+	plt = pl.AddPlot("OscTrace")
+	plt.Options.Title = "Hidden Activity"
+	plt.Options.XAxis = "Cycle"
+	plt.Options.Legend = "Condition"
+	plt.SetTable(ss.Logs.MiscTable("OscTrace"))
+
+	plt = pl.AddPlot("Compare")
+	plt.Options.Title = "FFFB vs. Unit Inhibition"
+	plt.Options.XAxis = "InputPct"
+	plt.Options.Legend = "Condition"
+	plt.SetTable(ss.Logs.MiscTable("Compare"))
+}
+
 func (ss *Sim) MakeToolbar(p *tree.Plan) {
 	ss.GUI.AddLooperCtrl(p, ss.LoopsFF, "FF")
 	ss.GUI.AddLooperCtrl(p, ss.LoopsBidir, "Bidir")
+	ss.GUI.AddLooperCtrl(p, ss.LoopsTopo, "Topo")
 
 	////////////////////////////////////////////////
 	ss.GUI.AddToolbarItem(p, egui.ToolbarItem{Label: "Defaults", Icon: icons.Update,
@@ -632,6 +1029,24 @@ func (ss *Sim) MakeToolbar(p *tree.Plan) {
 			ss.GUI.UpdateWindow()
 		},
 	})
+	ss.GUI.AddToolbarItem(p, egui.ToolbarItem{Label: "Oscillation", Icon: icons.PlayArrow,
+		Tooltip: "Run long trials on the current network with different values of HiddenGTau and InhibGTau, with unit-level and FFFB inhibition, and compute the power spectrum of the Hidden layer activity.",
+		Active:  egui.ActiveStopped,
+		Func: func() {
+			ss.GUI.IsRunning = true
+			go ss.Oscillation()
+			ss.GUI.UpdateWindow()
+		},
+	})
+	ss.GUI.AddToolbarItem(p, egui.ToolbarItem{Label: "Compare Inhib", Icon: icons.PlayArrow,
+		Tooltip: "Compare FFFB with unit-level inhibition, in the FF and Bidir networks, across levels of InputPct, measuring the steady-state Hidden activity, its variability across input patterns, and the settling time.",
+		Active:  egui.ActiveStopped,
+		Func: func() {
+			ss.GUI.IsRunning = true
+			go ss.CompareInhib()
+			ss.GUI.UpdateWindow()
+		},
+	})
 	ss.GUI.AddToolbarItem(p, egui.ToolbarItem{Label: "ConfigPats",
 		Icon:    icons.Image,
 		Tooltip: "config patterns",
@@ -656,3 +1071,48 @@ func (ss *Sim) RunGUI() {
 	ss.ConfigGUI()
 	ss.GUI.Body.RunMainWindow()
 }
+
+// RunNoGUI runs the test loop without the GUI, saving the log files
+// as specified in the Config.Log settings, or runs the Oscillation
+// analysis or the CompareInhib experiment and saves its results.
+func (ss *Sim) RunNoGUI() {
+	runName := ss.Params.RunName(0)
+	ss.Stats.SetString("RunName", runName) // used for naming logs, stats, etc
+	netName := ss.Net().Name
+
+	elog.SetLogFile(&ss.Logs, ss.Config.Log.TestTrial, etime.Test, etime.Trial, "tst_trl", netName, runName)
+	elog.SetLogFile(&ss.Logs, ss.Config.Log.TestCycle, etime.Test, etime.Cycle, "tst_cyc", netName, runName)
+
+	if ss.Config.Figures != "" {
+		ss.Figures.Config(ss.Net(), ss.ConfigPlots)
+	}
+	ss.Init()
+	if ss.Config.Oscillation {
+		ss.Oscillation()
+		simcore.SaveTable(ss.Logs.MiscTable("Oscillation"), "oscillation", netName, runName)
+		simcore.SaveTable(ss.Logs.MiscTable("Spectrum"), "spectrum", netName, runName)
+		osc := ss.Logs.MiscTable("Oscillation")
+		for row := range osc.Rows {
+			fmt.Printf("%-4s  HiddenGTau: %5.2f  MeanAct: %.3f  SDAct: %.4f  PeakHz: %5.1f  PeakFrac: %.3f\n", osc.StringValue("Inhib", row), osc.Float("HiddenGTau", row), osc.Float("MeanAct", row), osc.Float("SDAct", row), osc.Float("PeakHz", row), osc.Float("PeakFrac", row))
+		}
+	} else if ss.Config.CompareInhib {
+		ss.CompareInhib()
+		dt := ss.Logs.MiscTable("Compare")
+		simcore.SaveTable(dt, "compare", netName, runName)
+		for row := range dt.Rows {
+			fmt.Printf("%-10s  InputPct: %2.0f  ActPct: %5.2f (SD %4.2f)  SettleCycles: %5.1f (SD %4.1f)\n", dt.StringValue("Condition", row), dt.Float("InputPct", row), dt.Float("ActPct", row), dt.Float("ActPctSD", row), dt.Float("SettleCycles", row), dt.Float("SettleSD", row))
+		}
+	} else {
+		ss.Loops().Run(etime.Test)
+	}
+
+	ss.Logs.CloseLogFiles()
+
+	if ss.Config.Figures != "" {
+		ss.Figures.Save(ss.Config.Figures, netName, runName)
+	}
+
+	if ss.Config.Export != "" {
+		simcore.SaveLogsNPZ(&ss.Logs, ss.Config.Export, netName, runName)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/emer/emergent/v2/paths"
+	"gonum.org/v1/gonum/dsp/fourier"
+)
+
+// TopoParams are the parameters for the topographic network, where the
+// Hidden and Inhib neurons are arranged on a common 2D sheet, and all the
+// connections are local, with Gaussian weights as a function of distance
+// on the sheet. These take effect when the network is built at startup.
+type TopoParams struct {
+
+	// number of Inhib neurons along each side of the sheet, spread evenly
+	// among the 10x10 Hidden neurons.
+	InhibSize int `default:"5" min:"1"`
+
+	// radius of the excitatory connections from the Input and Hidden
+	// neurons, in units of Hidden neurons on the sheet.
+	ExciteRadius int `default:"3" min:"1"`
+
+	// radius of the connections from the Inhib neurons, in units of Hidden
+	// neurons on the sheet: inhibition that reaches further than the
+	// excitation makes for center-surround interactions.
+	InhibRadius int `default:"4" min:"1"`
+
+	// Gaussian sigma (width) of the weights, as a proportion of the radius.
+	Sigma float32 `default:"1" min:"0.1" step:"0.1"`
+}
+
+func (tp *TopoParams) Defaults() {
+	tp.InhibSize = 5
+	tp.ExciteRadius = 3
+	tp.InhibRadius = 4
+	tp.Sigma = 1
+}
+
+// Circle returns the circular pattern of connectivity with Gaussian weights
+// for the given radius in Hidden units, from a sending layer of the given
+// size along each side, for which the radius is scaled to its own units,
+// wrapping around at the edges of the sheet.
+func (tp *TopoParams) Circle(radius, sendSize int) *paths.Circle {
+	circ := paths.NewCircle()
+	circ.TopoWeights = true
+	circ.AutoScale = true
+	circ.Wrap = true
+	circ.Radius = max(radius*sendSize/10, 1)
+	circ.Sigma = tp.Sigma
+	return circ
+}
+
+// OscParams are the parameters for the Oscillation analysis, which runs long
+// trials with different values of HiddenGTau, with InhibGTau half of it as in
+// the defaults, and computes the power spectrum of the average activity
+// of the Hidden layer, for the unit-level inhibition and for FFFB.
+type OscParams struct {
+
+	// number of cycles to run for each condition (1 cycle = 1 msec),
+	// including the Skip ones. The frequency resolution of the power
+	// spectrum is 1000 / (Cycles - Skip) Hz.
+	Cycles int `default:"1200" min:"100"`
+
+	// number of initial cycles to skip, while the activity is settling
+	// from its initial state, before computing the power spectrum.
+	Skip int `default:"200" min:"0"`
+
+	// lowest HiddenGTau.
+	GTauMin float32 `default:"2.5" min:"1" step:"0.5"`
+
+	// highest HiddenGTau.
+	GTauMax float32 `default:"40" min:"1" step:"5"`
+
+	// number of steps of HiddenGTau from GTauMin to GTauMax, which are
+	// spaced geometrically, e.g., 2.5, 5, 10, 20, 40 for 4 steps.
+	NGTau int `default:"4" min:"0"`
+
+	// also run each HiddenGTau with FFFB inhibition instead of the
+	// unit-level inhibition, with the settings from the README:
+	// GbarI = 1 and FmInhibWtScaleAbs = 0.
+	FFFB bool `default:"true"`
+
+	// lowest frequency for the peak of the power spectrum, in Hz,
+	// to exclude slow drifts in activity.
+	MinHz float32 `default:"5" min:"0"`
+
+	// highest frequency for the peak of the power spectrum, and to record
+	// in the Spectrum table, in Hz.
+	MaxHz float32 `default:"250" min:"10"`
+
+	// minimum standard deviation of the Hidden activity for it to count as
+	// oscillating: below this, there is no peak (PeakHz is NaN), as the
+	// spectrum of the tiny residual fluctuations is meaningless.
+	MinSD float32 `default:"0.0001" min:"0"`
+}
+
+func (op *OscParams) Defaults() {
+	op.Cycles = 1200
+	op.Skip = 200
+	op.GTauMin = 2.5
+	op.GTauMax = 40
+	op.NGTau = 4
+	op.FFFB = true
+	op.MinHz = 5
+	op.MaxHz = 250
+	op.MinSD = 0.0001
+}
+
+// GTau returns the HiddenGTau for the given step from GTauMin to GTauMax.
+func (op *OscParams) GTau(step int) float32 {
+	if op.NGTau == 0 {
+		return op.GTauMin
+	}
+	return op.GTauMin * float32(math.Pow(float64(op.GTauMax/op.GTauMin), float64(step)/float64(op.NGTau)))
+}
+
+// MeanSD returns the mean and standard deviation of the given values.
+func MeanSD(vals []float64) (mean, sd float64) {
+	n := float64(len(vals))
+	for _, v := range vals {
+		mean += v
+	}
+	mean /= n
+	for _, v := range vals {
+		sd += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sd / n)
+}
+
+// PowerSpectrum returns the power spectrum of the given activity values,
+// one per cycle, after subtracting the mean and applying a Hann window,
+// for frequencies from 0 up to the Nyquist frequency in steps of
+// 1000 / len(acts) Hz. The power is the squared amplitude divided by the
+// number of values.
+func PowerSpectrum(acts []float64) []float64 {
+	n := len(acts)
+	mean, _ := MeanSD(acts)
+	seq := make([]float64, n)
+	for i, a := range acts {
+		hann := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n-1))
+		seq[i] = (a - mean) * hann
+	}
+	coefs := fourier.NewFFT(n).Coefficients(nil, seq)
+	pow := make([]float64, len(coefs))
+	for i, c := range coefs {
+		a := cmplx.Abs(c)
+		pow[i] = a * a / float64(n)
+	}
+	return pow
+}
+
+// SpectrumPeak returns the frequency in Hz and power of the peak of the given
+// power spectrum of n values, from minHz to maxHz, and the fraction of the
+// total power (excluding the mean) in the peak and its two neighboring
+// frequencies, which is high for a regular oscillation.
+func SpectrumPeak(pow []float64, n int, minHz, maxHz float64) (hz, peak, frac float64) {
+	res := 1000 / float64(n)
+	mi := -1
+	total := 0.0
+	for i := 1; i < len(pow); i++ {
+		total += pow[i]
+		f := float64(i) * res
+		if f >= minHz && f <= maxHz && (mi < 0 || pow[i] > pow[mi]) {
+			mi = i
+		}
+	}
+	if mi < 0 || total == 0 {
+		return math.NaN(), 0, 0
+	}
+	sum := 0.0
+	for i := max(mi-1, 1); i <= min(mi+1, len(pow)-1); i++ {
+		sum += pow[i]
+	}
+	return float64(mi) * res, pow[mi], sum / total
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGTau(t *testing.T) {
+	op := &OscParams{}
+	op.Defaults()
+	for step, want := range []float32{2.5, 5, 10, 20, 40} {
+		if gt := op.GTau(step); math.Abs(float64(gt-want)) > 1.0e-5 {
+			t.Errorf("GTau(%d) = %g != %g", step, gt, want)
+		}
+	}
+	op.NGTau = 0
+	if gt := op.GTau(0); gt != op.GTauMin {
+		t.Errorf("GTau(0) with NGTau = 0: %g != GTauMin %g", gt, op.GTauMin)
+	}
+}
+
+func TestMeanSD(t *testing.T) {
+	mean, sd := MeanSD([]float64{2, 4, 4, 4, 5, 5, 7, 9})
+	if mean != 5 || sd != 2 {
+		t.Errorf("MeanSD = %g, %g != 5, 2", mean, sd)
+	}
+	if mean, sd := MeanSD([]float64{3, 3}); mean != 3 || sd != 0 {
+		t.Errorf("MeanSD of constant = %g, %g != 3, 0", mean, sd)
+	}
+}
+
+// sineActs returns n cycles of activity oscillating at the given
+// frequency in Hz around 0.5, with 1 cycle = 1 msec.
+func sineActs(n int, hz float64) []float64 {
+	acts := make([]float64, n)
+	for i := range acts {
+		acts[i] = 0.5 + 0.1*math.Sin(2*math.Pi*hz*float64(i)/1000)
+	}
+	return acts
+}
+
+// TestPowerSpectrum checks that the power spectrum of a sine wave peaks at
+// its frequency, with most of the power there, and that the mean is removed.
+func TestPowerSpectrum(t *testing.T) {
+	n := 1000
+	pow := PowerSpectrum(sineActs(n, 40))
+	if len(pow) != n/2+1 {
+		t.Fatalf("%d frequencies != %d", len(pow), n/2+1)
+	}
+	if pow[0] > 1.0e-6 {
+		t.Errorf("power at 0 Hz = %g: the mean was not removed", pow[0])
+	}
+	hz, peak, frac := SpectrumPeak(pow, n, 5, 250)
+	if hz != 40 || peak != pow[40] {
+		t.Errorf("peak at %g Hz with power %g, not at 40 Hz", hz, peak)
+	}
+	if frac < 0.9 {
+		t.Errorf("fraction of power in the peak = %g < 0.9", frac)
+	}
+
+	// a peak outside the range is not found
+	if hz, _, _ := SpectrumPeak(pow, n, 50, 250); hz == 40 {
+		t.Errorf("peak at %g Hz found outside of 50-250 Hz", hz)
+	}
+	hz, peak, frac = SpectrumPeak(PowerSpectrum(make([]float64, n)), n, 5, 250)
+	if !math.IsNaN(hz) || peak != 0 || frac != 0 {
+		t.Errorf("peak of constant activity = %g Hz, %g, %g: not NaN, 0, 0", hz, peak, frac)
+	}
+
+	// the frequency resolution is 1000 / n Hz
+	n = 500
+	if hz, _, _ := SpectrumPeak(PowerSpectrum(sineActs(n, 40)), n, 5, 250); hz != 40 {
+		t.Errorf("peak at %g Hz with %d cycles, not at 40 Hz", hz, n)
+	}
+}
+
+func TestTopoCircle(t *testing.T) {
+	tp := &TopoParams{}
+	tp.Defaults()
+	tests := []struct {
+		radius, sendSize, want int
+	}{
+		{3, 10, 3},
+		{4, 5, 2},
+		{3, 5, 1},
+		{1, 5, 1}, // at least 1
+	}
+	for _, tt := range tests {
+		circ := tp.Circle(tt.radius, tt.sendSize)
+		if circ.Radius != tt.want || !circ.TopoWeights || !circ.Wrap || circ.Sigma != tp.Sigma {
+			t.Errorf("Circle(%d, %d): Radius %d != %d, or not Gaussian wrapped weights: %+v", tt.radius, tt.sendSize, circ.Radius, tt.want, circ)
+		}
+	}
+}
@@ -6,4 +6,16 @@ import (
 	"cogentcore.org/core/types"
 )
 
-var _ = types.AddType(&types.Type{Name: "main.Sim", IDName: "sim", Doc: "Sim encapsulates the entire simulation model, and we define all the\nfunctionality as methods on this struct.  This structure keeps all relevant\nstate information organized and available without having to pass everything around\nas arguments to methods, and provides the core GUI interface (note the view tags\nfor the fields which provide hints to how things should be displayed).", Fields: []types.Field{{Name: "BidirNet", Doc: "if true, use the bidirectionally connected network,\notherwise use the simpler feedforward network."}, {Name: "TrainedWts", Doc: "simulate trained weights by having higher variance and Gaussian\ndistributed weight values -- otherwise lower variance, uniform."}, {Name: "InputPct", Doc: "percent of active units in input layer (literally number of active units,\nbecause input has 100 units total)."}, {Name: "FFFBInhib", Doc: "use feedforward, feedback (FFFB) computed inhibition instead\nof unit-level inhibition."}, {Name: "HiddenGbarI", Doc: "inhibitory conductance strength for inhibition into Hidden layer."}, {Name: "InhibGbarI", Doc: "inhibitory conductance strength for inhibition into Inhib layer\n(self-inhibition -- tricky!)."}, {Name: "FFinhibWtScale", Doc: "feedforward (FF) inhibition relative strength: for FF projections into Inhib neurons."}, {Name: "FBinhibWtScale", Doc: "feedback (FB) inhibition relative strength: for projections into Inhib neurons."}, {Name: "HiddenGTau", Doc: "time constant (tau) for updating G conductances into Hidden neurons\nMuch slower than std default of 1.4."}, {Name: "InhibGTau", Doc: "time constant (tau) for updating G conductances into Inhib neurons.\nMuch slower than std default of 1.4, but 2x faster than Hidden."}, {Name: "FmInhibWtScaleAbs", Doc: "absolute weight scaling of projections from inhibition onto\nhidden and inhib layers.  This must be set to 0 to turn off the\nconnection-based inhibition when using the FFFBInhib computed inbhition."}, {Name: "NetFF", Doc: "the feedforward network -- click to view / edit parameters for layers, paths, etc"}, {Name: "NetBidir", Doc: "the bidirectional network -- click to view / edit parameters for layers, paths, etc"}, {Name: "Params", Doc: "network parameter management"}, {Name: "LoopsFF", Doc: "contains looper control loops for running sim"}, {Name: "LoopsBidir"}, {Name: "Stats", Doc: "contains computed statistic values"}, {Name: "Logs", Doc: "Contains all the logs and information about the logs.'"}, {Name: "Patterns", Doc: "the patterns to use"}, {Name: "Envs", Doc: "Environments"}, {Name: "Context", Doc: "leabra timing parameters and state"}, {Name: "ViewUpdate", Doc: "netview update parameters"}, {Name: "GUI", Doc: "manages all the gui elements"}, {Name: "NetviewFF"}, {Name: "NetviewBidir"}, {Name: "RandSeeds", Doc: "a list of random seeds to use for each run"}}})
+var _ = types.AddType(&types.Type{Name: "main.CompareParams", IDName: "compare-params", Doc: "CompareParams are the parameters for the CompareInhib experiment, which\ncompares FFFB inhibition with the unit-level inhibition from the Inhib\nneurons, in the feedforward and bidirectional networks, across different\nlevels of InputPct, with a new random input pattern on each trial.", Fields: []types.Field{{Name: "PctMin", Doc: "lowest InputPct."}, {Name: "PctMax", Doc: "highest InputPct."}, {Name: "NPct", Doc: "number of steps of InputPct from PctMin to PctMax."}, {Name: "NTrials", Doc: "number of trials for each condition, each with a new random input pattern."}, {Name: "Cycles", Doc: "number of cycles per trial."}, {Name: "SteadyCycles", Doc: "number of cycles at the end of each trial over which the steady-state\nHidden activity is averaged."}, {Name: "SettleTol", Doc: "tolerance for settling: the settling time is the number of cycles\nuntil the Hidden activity stays within this distance of its\nsteady-state value for the rest of the trial."}, {Name: "FFFBGTau", Doc: "time constant (tau) for updating G conductances into the Hidden and\nInhib neurons with FFFB inhibition, which is the standard default\n(see the README): HiddenGTau and InhibGTau are used for the\nunit-level inhibition."}}})
+
+var _ = types.AddType(&types.Type{Name: "main.InhibParams", IDName: "inhib-params", Doc: "InhibParams are the parameters of the inhibition in the networks:\nwhich network is used, its input, and the strengths and time constants\nof the connections to and from the Inhib neurons, or the FFFB\ncomputed inhibition that replaces them.", Fields: []types.Field{{Name: "BidirNet", Doc: "if true, use the bidirectionally connected network,\notherwise use the simpler feedforward network."}, {Name: "TopoNet", Doc: "if true, use the topographic network, where the Hidden and Inhib\nneurons are arranged on a 2D sheet with local Gaussian connectivity,\ninstead of the feedforward or bidirectional network."}, {Name: "TrainedWts", Doc: "simulate trained weights by having higher variance and Gaussian\ndistributed weight values -- otherwise lower variance, uniform."}, {Name: "InputPct", Doc: "percent of active units in input layer (literally number of active units,\nbecause input has 100 units total)."}, {Name: "FFFBInhib", Doc: "use feedforward, feedback (FFFB) computed inhibition instead\nof unit-level inhibition."}, {Name: "HiddenGbarI", Doc: "inhibitory conductance strength for inhibition into Hidden layer."}, {Name: "InhibGbarI", Doc: "inhibitory conductance strength for inhibition into Inhib layer\n(self-inhibition -- tricky!)."}, {Name: "FFinhibWtScale", Doc: "feedforward (FF) inhibition relative strength: for FF projections into Inhib neurons."}, {Name: "FBinhibWtScale", Doc: "feedback (FB) inhibition relative strength: for projections into Inhib neurons."}, {Name: "HiddenGTau", Doc: "time constant (tau) for updating G conductances into Hidden neurons\nMuch slower than std default of 1.4."}, {Name: "InhibGTau", Doc: "time constant (tau) for updating G conductances into Inhib neurons.\nMuch slower than std default of 1.4, but 2x faster than Hidden."}, {Name: "FmInhibWtScaleAbs", Doc: "absolute weight scaling of projections from inhibition onto\nhidden and inhib layers.  This must be set to 0 to turn off the\nconnection-based inhibition when using the FFFBInhib computed inbhition."}}})
+
+var _ = types.AddType(&types.Type{Name: "main.Config", IDName: "config", Doc: "Config has config parameters related to running the sim.\n-FFFBInhib -FmInhibWtScaleAbs 0, for example, runs the FFFB computed\ninhibition alone, by setting the Inhib parameters that the Sim starts with,\nalong with its Osc and Comp.", Fields: []types.Field{{Name: "Inhib", Doc: "the inhibition parameters, e.g., -InputPct 30 for more active inputs"}, {Name: "Topo", Doc: "parameters for the topographic network."}, {Name: "Osc", Doc: "parameters for the Oscillation analysis of the power spectrum of\nthe Hidden layer activity as a function of the G taus."}, {Name: "Oscillation", Doc: "run the Oscillation analysis instead of the test trials, saving the\nresults to oscillation.tsv and spectrum.tsv, when running without the GUI."}, {Name: "Comp", Doc: "parameters for the CompareInhib experiment comparing FFFB with\nthe unit-level inhibition across levels of InputPct."}, {Name: "CompareInhib", Doc: "run the CompareInhib experiment instead of the test trials, saving\nthe results to compare.tsv, when running without the GUI."}, {Name: "GUI", Doc: "GUI means open the GUI. Otherwise it runs automatically and quits,\nsaving log files as specified in Log."}, {Name: "Figures", Doc: "plots, grids or NetView to save as images without the GUI, or \"all\" (see simcore.Figures)"}, {Name: "Export", Doc: "logs to export to .npz files for Python without the GUI, or \"all\" (see simcore.SaveLogsNPZ)"}, {Name: "Log", Doc: "Log has config parameters related to logging data."}}})
+
+var _ = types.AddType(&types.Type{Name: "main.LogConfig", IDName: "log-config", Doc: "LogConfig has config parameters related to logging data", Fields: []types.Field{{Name: "TestTrial", Doc: "if true, save testing trial log to file, as .tst_trl.tsv typically"}, {Name: "TestCycle", Doc: "if true, save testing cycle log to file, as .tst_cyc.tsv typically. May be large."}}})
+
+var _ = types.AddType(&types.Type{Name: "main.Sim", IDName: "sim", Doc: "Sim encapsulates the entire simulation model, and we define all the\nfunctionality as methods on this struct.  This structure keeps all relevant\nstate information organized and available without having to pass everything around\nas arguments to methods, and provides the core GUI interface (note the view tags\nfor the fields which provide hints to how things should be displayed).", Fields: []types.Field{{Name: "Inhib", Doc: "the parameters of the inhibition in the networks"}, {Name: "Osc", Doc: "parameters for the Oscillation analysis of the power spectrum of\nthe Hidden layer activity as a function of the G taus."}, {Name: "Comp", Doc: "parameters for the CompareInhib experiment comparing FFFB with\nthe unit-level inhibition across levels of InputPct."}, {Name: "Config", Doc: "Config contains misc configuration parameters for running the sim"}, {Name: "NetFF", Doc: "the feedforward network -- click to view / edit parameters for layers, paths, etc"}, {Name: "NetBidir", Doc: "the bidirectional network -- click to view / edit parameters for layers, paths, etc"}, {Name: "NetTopo", Doc: "the topographic network -- click to view / edit parameters for layers, paths, etc"}, {Name: "Params", Doc: "network parameter management"}, {Name: "LoopsFF", Doc: "contains looper control loops for running sim"}, {Name: "LoopsBidir"}, {Name: "LoopsTopo"}, {Name: "Stats", Doc: "contains computed statistic values"}, {Name: "Logs", Doc: "Contains all the logs and information about the logs.'"}, {Name: "Patterns", Doc: "the patterns to use"}, {Name: "Envs", Doc: "Environments"}, {Name: "Context", Doc: "leabra timing parameters and state"}, {Name: "ViewUpdate", Doc: "netview update parameters"}, {Name: "GUI", Doc: "manages all the gui elements"}, {Name: "Figures", Doc: "the plots, grids and network snapshots that can be saved without the GUI"}, {Name: "NetviewFF"}, {Name: "NetviewBidir"}, {Name: "NetviewTopo"}, {Name: "RandSeeds", Doc: "a list of random seeds to use for each run"}}})
+
+var _ = types.AddType(&types.Type{Name: "main.TopoParams", IDName: "topo-params", Doc: "TopoParams are the parameters for the topographic network, where the\nHidden and Inhib neurons are arranged on a common 2D sheet, and all the\nconnections are local, with Gaussian weights as a function of distance\non the sheet. These take effect when the network is built at startup.", Fields: []types.Field{{Name: "InhibSize", Doc: "number of Inhib neurons along each side of the sheet, spread evenly\namong the 10x10 Hidden neurons."}, {Name: "ExciteRadius", Doc: "radius of the excitatory connections from the Input and Hidden\nneurons, in units of Hidden neurons on the sheet."}, {Name: "InhibRadius", Doc: "radius of the connections from the Inhib neurons, in units of Hidden\nneurons on the sheet: inhibition that reaches further than the\nexcitation makes for center-surround interactions."}, {Name: "Sigma", Doc: "Gaussian sigma (width) of the weights, as a proportion of the radius."}}})
+
+var _ = types.AddType(&types.Type{Name: "main.OscParams", IDName: "osc-params", Doc: "OscParams are the parameters for the Oscillation analysis, which runs long\ntrials with different values of HiddenGTau, with InhibGTau half of it as in\nthe defaults, and computes the power spectrum of the average activity\nof the Hidden layer, for the unit-level inhibition and for FFFB.", Fields: []types.Field{{Name: "Cycles", Doc: "number of cycles to run for each condition (1 cycle = 1 msec),\nincluding the Skip ones. The frequency resolution of the power\nspectrum is 1000 / (Cycles - Skip) Hz."}, {Name: "Skip", Doc: "number of initial cycles to skip, while the activity is settling\nfrom its initial state, before computing the power spectrum."}, {Name: "GTauMin", Doc: "lowest HiddenGTau."}, {Name: "GTauMax", Doc: "highest HiddenGTau."}, {Name: "NGTau", Doc: "number of steps of HiddenGTau from GTauMin to GTauMax, which are\nspaced geometrically, e.g., 2.5, 5, 10, 20, 40 for 4 steps."}, {Name: "FFFB", Doc: "also run each HiddenGTau with FFFB inhibition instead of the\nunit-level inhibition, with the settings from the README:\nGbarI = 1 and FmInhibWtScaleAbs = 0."}, {Name: "MinHz", Doc: "lowest frequency for the peak of the power spectrum, in Hz,\nto exclude slow drifts in activity."}, {Name: "MaxHz", Doc: "highest frequency for the peak of the power spectrum, and to record\nin the Spectrum table, in Hz."}, {Name: "MinSD", Doc: "minimum standard deviation of the Hidden activity for it to count as\noscillating: below this, there is no peak (PeakHz is NaN), as the\nspectrum of the tiny residual fluctuations is meaningless."}}})
@@ -0,0 +1,162 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+)
+
+// Percept values: which of the two cube interpretations is dominant,
+// i.e., the pool of the NeckerCube layer with more activity.
+const (
+	// NoPercept is before either cube is dominant, at the start of a trial.
+	NoPercept = 0
+
+	// PerceptA is the left cube, the first pool.
+	PerceptA = 1
+
+	// PerceptB is the right cube, the second pool.
+	PerceptB = 2
+)
+
+// PerceptNames are the names of the percepts, for the Percept of each trial,
+// where Mixed means that neither cube is dominant at the end of the trial.
+var PerceptNames = []string{"Mixed", "A", "B"}
+
+// DomParams are the parameters for classifying which cube interpretation
+// is dominant, and for the Dominance analysis of the durations of each
+// percept across long runs, as a function of Noise and KNaAdapt.
+type DomParams struct {
+
+	// difference in the mean activity of the two cubes for one of them to
+	// become the dominant percept: there is a switch when the other one
+	// has this much more activity, so small fluctuations are ignored
+	Margin float32 `default:"0.2" min:"0" max:"1" step:"0.05"`
+
+	// number of trials to run for each condition in the Dominance analysis
+	NTrials int `default:"10" min:"1"`
+
+	// number of cycles per trial in the Dominance analysis, which must be
+	// long enough for many alternations with adaptation (1 cycle = 1 msec)
+	Cycles int `default:"5000" min:"100"`
+
+	// lowest Noise in the Dominance analysis
+	NoiseMin float32 `default:"0.005" min:"0" step:"0.005"`
+
+	// highest Noise in the Dominance analysis
+	NoiseMax float32 `default:"0.02" min:"0" step:"0.005"`
+
+	// number of steps of Noise from NoiseMin to NoiseMax in the Dominance analysis
+	NNoise int `default:"3" min:"0"`
+
+	// size of the bins of the histogram of dominance durations, in cycles
+	BinSize int `default:"50" min:"1"`
+}
+
+func (dp *DomParams) Defaults() {
+	dp.Margin = 0.2
+	dp.NTrials = 10
+	dp.Cycles = 5000
+	dp.NoiseMin = 0.005
+	dp.NoiseMax = 0.02
+	dp.NNoise = 3
+	dp.BinSize = 50
+}
+
+// Percepts tracks the dominant percept over the cycles of a trial,
+// and the durations of the periods of dominance between switches.
+type Percepts struct {
+
+	// the current dominant percept: NoPercept, PerceptA or PerceptB
+	Cur int
+
+	// the cycle on which the current percept became dominant
+	Start int
+
+	// the number of switches between percepts in the trial
+	NSwitches int
+
+	// the durations in cycles of the complete periods of dominance in the
+	// trial, which start and end with a switch: the first period, which
+	// starts with the initial settling, and the last one, which is cut off
+	// at the end of the trial, are not included
+	Durs []int
+}
+
+// Init initializes the tracking at the start of a trial.
+func (pc *Percepts) Init() {
+	pc.Cur = NoPercept
+	pc.Start = 0
+	pc.NSwitches = 0
+	pc.Durs = nil
+}
+
+// Update updates the dominant percept on the given cycle from the
+// mean activities of the two cubes, with the given margin.
+func (pc *Percepts) Update(cyc int, actA, actB, margin float32) {
+	next := pc.Cur
+	switch {
+	case actA-actB > margin:
+		next = PerceptA
+	case actB-actA > margin:
+		next = PerceptB
+	}
+	if next == pc.Cur {
+		return
+	}
+	if pc.Cur != NoPercept {
+		if pc.NSwitches > 0 {
+			pc.Durs = append(pc.Durs, cyc-pc.Start)
+		}
+		pc.NSwitches++
+	}
+	pc.Cur = next
+	pc.Start = cyc
+}
+
+// Final returns the percept at the end of the trial from the final mean
+// activities of the two cubes, which is NoPercept (Mixed) if neither
+// has margin more activity than the other.
+func Final(actA, actB, margin float32) int {
+	switch {
+	case actA-actB > margin:
+		return PerceptA
+	case actB-actA > margin:
+		return PerceptB
+	}
+	return NoPercept
+}
+
+// DurStats returns the mean, standard deviation and coefficient of
+// variation (SD / mean) of the given durations, which are NaN if empty.
+func DurStats(durs []int) (mean, sd, cv float64) {
+	if len(durs) == 0 {
+		return math.NaN(), math.NaN(), math.NaN()
+	}
+	for _, d := range durs {
+		mean += float64(d)
+	}
+	mean /= float64(len(durs))
+	for _, d := range durs {
+		sd += (float64(d) - mean) * (float64(d) - mean)
+	}
+	sd = math.Sqrt(sd / float64(len(durs)))
+	return mean, sd, sd / mean
+}
+
+// DurHist returns the histogram of the given durations, with the given
+// bin size, as the proportion of the durations in each bin, up to the
+// bin of the longest one.
+func DurHist(durs []int, binSize int) []float64 {
+	mx := 0
+	for _, d := range durs {
+		mx = max(mx, d)
+	}
+	hist := make([]float64, mx/binSize+1)
+	for _, d := range durs {
+		hist[d/binSize] += 1 / float64(len(durs))
+	}
+	return hist
+}
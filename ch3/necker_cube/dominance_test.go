@@ -0,0 +1,103 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// TestPercepts checks the switches and the durations of the complete
+// periods of dominance tracked over a sequence of activities, where
+// differences within the margin do not switch.
+func TestPercepts(t *testing.T) {
+	acts := []struct {
+		cyc        int
+		actA, actB float32
+	}{
+		{0, 0.1, 0.1},   // settling: no percept
+		{5, 0.5, 0.2},   // A first becomes dominant
+		{20, 0.3, 0.4},  // within the margin: still A
+		{30, 0.2, 0.5},  // switch to B: the first period is not complete
+		{70, 0.6, 0.2},  // switch to A after 40 cycles of B
+		{80, 0.6, 0.6},  // mixed: still A
+		{130, 0.1, 0.6}, // switch to B after 60 cycles of A
+		{150, 0.1, 0.8}, // B continues to the end, not counted
+	}
+	var pc Percepts
+	pc.Init()
+	curs := []int{NoPercept, PerceptA, PerceptA, PerceptB, PerceptA, PerceptA, PerceptB, PerceptB}
+	for i, a := range acts {
+		pc.Update(a.cyc, a.actA, a.actB, 0.2)
+		if pc.Cur != curs[i] {
+			t.Errorf("cycle %d: percept %s != %s", a.cyc, PerceptNames[pc.Cur], PerceptNames[curs[i]])
+		}
+	}
+	if pc.NSwitches != 3 {
+		t.Errorf("NSwitches = %d != 3", pc.NSwitches)
+	}
+	if !slices.Equal(pc.Durs, []int{40, 60}) {
+		t.Errorf("Durs = %v != [40 60]", pc.Durs)
+	}
+	if pc.Start != 130 {
+		t.Errorf("Start = %d != 130", pc.Start)
+	}
+
+	pc.Init()
+	if pc.Cur != NoPercept || pc.NSwitches != 0 || pc.Durs != nil {
+		t.Errorf("Init did not reset: %+v", pc)
+	}
+}
+
+func TestFinal(t *testing.T) {
+	tests := []struct {
+		actA, actB float32
+		want       int
+	}{
+		{0.8, 0.1, PerceptA},
+		{0.1, 0.8, PerceptB},
+		{0.5, 0.4, NoPercept},
+		{0.4, 0.5, NoPercept},
+	}
+	for _, tt := range tests {
+		if got := Final(tt.actA, tt.actB, 0.2); got != tt.want {
+			t.Errorf("Final(%g, %g) = %s != %s", tt.actA, tt.actB, PerceptNames[got], PerceptNames[tt.want])
+		}
+	}
+}
+
+func TestDurStats(t *testing.T) {
+	mean, sd, cv := DurStats(nil)
+	if !math.IsNaN(mean) || !math.IsNaN(sd) || !math.IsNaN(cv) {
+		t.Errorf("DurStats(nil) = %g, %g, %g, not NaN", mean, sd, cv)
+	}
+	mean, sd, cv = DurStats([]int{100, 200, 300, 400})
+	wsd := math.Sqrt(12500)
+	if mean != 250 || math.Abs(sd-wsd) > 1.0e-9 || math.Abs(cv-wsd/250) > 1.0e-9 {
+		t.Errorf("DurStats = %g, %g, %g != 250, %g, %g", mean, sd, cv, wsd, wsd/250)
+	}
+	mean, sd, cv = DurStats([]int{80})
+	if mean != 80 || sd != 0 || cv != 0 {
+		t.Errorf("DurStats of one duration = %g, %g, %g != 80, 0, 0", mean, sd, cv)
+	}
+}
+
+func TestDurHist(t *testing.T) {
+	hist := DurHist([]int{10, 60, 70, 149, 150}, 50)
+	want := []float64{0.2, 0.4, 0.2, 0.2}
+	if len(hist) != len(want) {
+		t.Fatalf("DurHist = %v != %v", hist, want)
+	}
+	for i := range want {
+		if math.Abs(hist[i]-want[i]) > 1.0e-9 {
+			t.Errorf("DurHist = %v != %v", hist, want)
+			break
+		}
+	}
+	if hist := DurHist(nil, 50); len(hist) != 1 || hist[0] != 0 {
+		t.Errorf("DurHist(nil) = %v != [0]", hist)
+	}
+}
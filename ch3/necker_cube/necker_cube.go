@@ -11,12 +11,16 @@ package main
 
 import (
 	"embed"
+	"fmt"
+	"math"
 
 	"cogentcore.org/core/base/randx"
 	"cogentcore.org/core/core"
 	"cogentcore.org/core/enums"
 	"cogentcore.org/core/icons"
 	"cogentcore.org/core/tree"
+	"github.com/CompCogNeuro/sims/v2/simcore"
+	"github.com/emer/emergent/v2/econfig"
 	"github.com/emer/emergent/v2/egui"
 	"github.com/emer/emergent/v2/elog"
 	"github.com/emer/emergent/v2/emer"
@@ -36,7 +40,11 @@ func main() {
 	sim := &Sim{}
 	sim.New()
 	sim.ConfigAll()
-	sim.RunGUI()
+	if sim.Config.GUI {
+		sim.RunGUI()
+	} else {
+		sim.RunNoGUI()
+	}
 }
 
 // ParamSets is the default set of parameters.
@@ -69,20 +77,90 @@ var ParamSets = params.Sets{
 	},
 }
 
+// CubeParams are the parameters of the settling of the NeckerCube
+// network into one of its two interpretations of the cube: the noise and
+// the adaptation that drive it to switch between them over time.
+type CubeParams struct {
+
+	// the variance parameter for Gaussian noise added to unit activations on every cycle
+	Noise float32 `default:"0.01" min:"0" step:"0.01"`
+
+	// apply sodium-gated potassium adaptation mechanisms that cause the neuron to reduce spiking over time
+	KNaAdapt bool `default:"false"`
+
+	// total number of cycles to run per trial; increase to 1,000 when testing adaptation
+	Cycles int `default:"100"`
+}
+
+func (cp *CubeParams) Defaults() {
+	cp.Noise = 0.01
+	cp.KNaAdapt = false
+	cp.Cycles = 100
+}
+
+// Config has config parameters related to running the sim.
+// Cube and Dom are where the Sim parameters start: e.g., -KNaAdapt
+// -Cycles 1000 lets adaptation switch the dominant percept within a trial.
+type Config struct {
+
+	// the Cube parameters for the Sim to start with
+	Cube CubeParams `display:"add-fields"`
+
+	// parameters for classifying the dominant percept, and for the
+	// Dominance analysis of the durations of each percept
+	Dom DomParams `display:"add-fields" nest:"+"`
+
+	// run the Dominance analysis instead of the test trials, saving the
+	// results to dominance.tsv and dur_hist.tsv, when running without the GUI.
+	Dominance bool
+
+	// GUI means open the GUI. Otherwise it runs automatically and quits,
+	// saving log files as specified in Log.
+	GUI bool `default:"true"`
+
+	// plots, grids or NetView to save as images without the GUI, or "all" (see simcore.Figures)
+	Figures string
+
+	// logs to export to .npz files for Python without the GUI, or "all" (see simcore.SaveLogsNPZ)
+	Export string
+
+	// Log has config parameters related to logging data.
+	Log LogConfig `display:"add-fields"`
+}
+
+// LogConfig has config parameters related to logging data
+type LogConfig struct {
+
+	// if true, save testing trial log to file, as .tst_trl.tsv typically
+	TestTrial bool `default:"true" nest:"+"`
+
+	// if true, save testing cycle log to file, as .tst_cyc.tsv typically. May be large.
+	TestCycle bool `default:"false" nest:"+"`
+
+	// if true, save the settling trajectory of all the trials, with the
+	// mean activity of each cube on each cycle, as .trajectory.tsv
+	Trajectory bool `default:"false" nest:"+"`
+}
+
 // Sim encapsulates the entire simulation model, and we define all the
 // functionality as methods on this struct.  This structure keeps all relevant
 // state information organized and available without having to pass everything around
 // as arguments to methods, and provides the core GUI interface (note the view tags
 // for the fields which provide hints to how things should be displayed).
 type Sim struct {
-	// the variance parameter for Gaussian noise added to unit activations on every cycle
-	Noise float32 `min:"0" step:"0.01"`
 
-	// apply sodium-gated potassium adaptation mechanisms that cause the neuron to reduce spiking over time
-	KNaAdapt bool
+	// the parameters of the settling of the network
+	Cube CubeParams `display:"add-fields"`
 
-	// total number of cycles to run per trial; increase to 1,000 when testing adaptation
-	Cycles int `default:"100,1000"`
+	// parameters for classifying the dominant percept, and for the
+	// Dominance analysis of the durations of each percept
+	Dom DomParams `display:"add-fields"`
+
+	// tracks the dominant percept over the cycles of the current trial
+	Percepts Percepts `display:"-"`
+
+	// Config contains misc configuration parameters for running the sim
+	Config Config `display:"-"`
 
 	// the network -- click to view / edit parameters for layers, paths, etc
 	Net *leabra.Network `new-window:"+" display:"no-inline"`
@@ -108,6 +186,9 @@ type Sim struct {
 	// manages all the gui elements
 	GUI egui.GUI `display:"-"`
 
+	// the plots, grids and network snapshots that can be saved without the GUI
+	Figures simcore.Figures `display:"-"`
+
 	// a list of random seeds to use for each run
 	RandSeeds randx.Seeds `display:"-"`
 }
@@ -115,6 +196,9 @@ type Sim struct {
 // New creates new blank elements and initializes defaults
 func (ss *Sim) New() {
 	ss.Defaults()
+	econfig.Config(&ss.Config, "config.toml")
+	ss.Cube = ss.Config.Cube
+	ss.Dom = ss.Config.Dom
 	ss.Net = leabra.NewNetwork("NeckerCube")
 	ss.Params.Config(ParamSets, "", "", ss.Net)
 	ss.Stats.Init()
@@ -124,9 +208,8 @@ func (ss *Sim) New() {
 }
 
 func (ss *Sim) Defaults() {
-	ss.Noise = 0.01
-	ss.KNaAdapt = false
-	ss.Cycles = 100
+	ss.Cube.Defaults()
+	ss.Dom.Defaults()
 }
 
 //////////////////////////////////////////////////////////////////////////////
@@ -162,15 +245,15 @@ func (ss *Sim) InitWeights(net *leabra.Network) {
 func (ss *Sim) ApplyParams() {
 	ss.Params.SetAll()
 	ly := ss.Net.LayerByName("NeckerCube")
-	ly.Act.Noise.Var = float64(ss.Noise)
-	ly.Act.KNa.On = ss.KNaAdapt
+	ly.Act.Noise.Var = float64(ss.Cube.Noise)
+	ly.Act.KNa.On = ss.Cube.KNaAdapt
 	ly.Act.Update()
 	if ss.Loops != nil {
 		cyc := ss.Loops.Stacks[etime.Test].Loops[etime.Cycle]
-		cyc.Counter.Max = ss.Cycles
-		cyc.EventByName("Quarter1").AtCounter = ss.Cycles / 4
-		cyc.EventByName("Quarter2").AtCounter = 2 * (ss.Cycles / 4)
-		cyc.EventByName("MinusPhase:End").AtCounter = 3 * (ss.Cycles / 4)
+		cyc.Counter.Max = ss.Cube.Cycles
+		cyc.EventByName("Quarter1").AtCounter = ss.Cube.Cycles / 4
+		cyc.EventByName("Quarter2").AtCounter = 2 * (ss.Cube.Cycles / 4)
+		cyc.EventByName("MinusPhase:End").AtCounter = 3 * (ss.Cube.Cycles / 4)
 	}
 }
 
@@ -191,8 +274,7 @@ func (ss *Sim) Init() {
 
 // InitRandSeed initializes the random seed based on current training run number
 func (ss *Sim) InitRandSeed(run int) {
-	ss.RandSeeds.Set(run)
-	ss.RandSeeds.Set(run, &ss.Net.Rand)
+	simcore.InitRandSeed(&ss.RandSeeds, ss.Net, run)
 }
 
 // ConfigLoops configures the control loops: Training, Testing
@@ -200,7 +282,7 @@ func (ss *Sim) ConfigLoops() {
 	ls := looper.NewStacks()
 
 	ntrls := 100
-	cycles := ss.Cycles
+	cycles := ss.Cube.Cycles
 	ls.AddStack(etime.Test).
 		AddTime(etime.Epoch, 1).
 		AddTime(etime.Trial, ntrls).
@@ -214,6 +296,7 @@ func (ss *Sim) ConfigLoops() {
 		stack := ls.Stacks[m]
 		stack.Loops[etime.Trial].OnStart.Add("ApplyInputs", func() {
 			ss.ApplyInputs()
+			ss.Percepts.Init()
 		})
 	}
 
@@ -228,9 +311,11 @@ func (ss *Sim) ConfigLoops() {
 	////////////////////////////////////////////
 	// GUI
 
-	leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
-	leabra.LooperUpdatePlots(ls, &ss.GUI)
-	ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	if ss.Config.GUI {
+		leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
+		leabra.LooperUpdatePlots(ls, &ss.GUI)
+		ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	}
 
 	ss.Loops = ls
 }
@@ -263,6 +348,7 @@ func (ss *Sim) NewRun() {
 	ss.InitStats()
 	ss.StatCounters()
 	ss.Logs.ResetLog(etime.Test, etime.Epoch)
+	ss.Logs.MiscTable("Trajectory").SetNumRows(0)
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////
@@ -272,17 +358,14 @@ func (ss *Sim) NewRun() {
 // called at start of new run
 func (ss *Sim) InitStats() {
 	ss.Stats.SetString("TrialName", "")
+	ss.Stats.SetString("Percept", "")
+	ss.Percepts.Init()
 }
 
 // StatCounters saves current counters to Stats, so they are available for logging etc
 // Also saves a string rep of them for ViewUpdate.Text
 func (ss *Sim) StatCounters() {
-	ctx := &ss.Context
-	mode := ctx.Mode
-	ss.Loops.Stacks[mode].CountersToStats(&ss.Stats)
-	trl := ss.Stats.Int("Trial")
-	ss.Stats.SetInt("Trial", trl)
-	ss.Stats.SetInt("Cycle", int(ctx.Cycle))
+	simcore.StatCounters(ss.Loops, &ss.Context, &ss.Stats, false)
 }
 
 func (ss *Sim) NetViewCounters(tm etime.Times) {
@@ -296,24 +379,32 @@ func (ss *Sim) NetViewCounters(tm etime.Times) {
 	ss.ViewUpdate.Text = ss.Stats.Print([]string{"Trial", "TrialName", "Cycle"})
 }
 
-// Harmony computes the harmony (excitatory net input Ge * Act)
-func (ss *Sim) Harmony(nt *leabra.Network) float32 {
-	harm := float32(0)
-	nu := 0
-	for _, ly := range nt.Layers {
-		if ly.Off {
-			continue
-		}
-		for i := range ly.Neurons {
-			nrn := &(ly.Neurons[i])
-			harm += nrn.Ge * nrn.Act
-			nu++
+// PoolActs returns the mean activity of the units of each of the two cubes,
+// which are the two pools of the NeckerCube layer.
+func (ss *Sim) PoolActs() (actA, actB float32) {
+	ly := ss.Net.LayerByName("NeckerCube")
+	var acts [2]float32
+	for p := range acts {
+		pl := ly.Pool(1 + p)
+		for ni := pl.StIndex; ni < pl.EdIndex; ni++ {
+			acts[p] += ly.Neurons[ni].Act
 		}
+		acts[p] /= float32(pl.EdIndex - pl.StIndex)
 	}
-	if nu > 0 {
-		harm /= float32(nu)
-	}
-	return harm
+	return acts[0], acts[1]
+}
+
+// TrialStats computes the trial-level statistics: the final Percept, and the
+// number of switches between percepts, their mean duration, and the
+// alternation rate in switches per second, over the cycles of the trial.
+func (ss *Sim) TrialStats() {
+	pc := &ss.Percepts
+	actA, actB := ss.PoolActs()
+	ss.Stats.SetString("Percept", PerceptNames[Final(actA, actB, ss.Dom.Margin)])
+	ss.Stats.SetFloat("NSwitches", float64(pc.NSwitches))
+	mean, _, _ := DurStats(pc.Durs)
+	ss.Stats.SetFloat("MeanDur", mean)
+	ss.Stats.SetFloat("AltHz", 1000*float64(pc.NSwitches)/float64(ss.Cube.Cycles))
 }
 
 //////////////////////////////////////////////////////////////////////////////
@@ -326,20 +417,160 @@ func (ss *Sim) ConfigLogs() {
 	ss.Logs.AddStatAggItem("GknaFast", etime.Trial, etime.Cycle)
 	ss.Logs.AddStatAggItem("GknaMed", etime.Trial, etime.Cycle)
 	ss.Logs.AddStatAggItem("GknaSlow", etime.Trial, etime.Cycle)
+	ss.Logs.AddStatAggItem("ActA", etime.Trial, etime.Cycle)
+	ss.Logs.AddStatAggItem("ActB", etime.Trial, etime.Cycle)
+	ss.Logs.AddStatFloatNoAggItem(etime.Test, etime.Cycle, "Dominant")
+	ss.Logs.AddStatStringItem(etime.Test, etime.Trial, "Percept")
+	ss.Logs.AddStatFloatNoAggItem(etime.Test, etime.Trial, "NSwitches", "MeanDur", "AltHz")
 
 	ss.Logs.AddLayerTensorItems(ss.Net, "Act", etime.Test, etime.Trial, "InputLayer")
 
 	ss.Logs.CreateTables()
 	ss.Logs.SetContext(&ss.Stats, ss.Net)
-	ss.Logs.PlotItems("Harmony")
+	ss.Logs.PlotItems("Harmony", "ActA", "ActB")
+
+	tr := ss.Logs.MiscTable("Trajectory")
+	for _, cn := range []string{"Trial", "Cycle", "ActA", "ActB", "Harmony", "Dominant"} {
+		tr.AddFloat64Column(cn)
+	}
+
+	dom := ss.Logs.MiscTable("Dominance")
+	dom.AddFloat64Column("Noise")
+	dom.AddFloat64Column("KNaAdapt")
+	dom.AddStringColumn("Condition")
+	for _, cn := range []string{"NDurs", "MeanDur", "SDDur", "CV", "GammaShape", "AltHz"} {
+		dom.AddFloat64Column(cn)
+	}
+	dom.SetMetaData("MeanDur:On", "+")
+	dom.SetMetaData("Points", "true")
+
+	dh := ss.Logs.MiscTable("DurHist")
+	dh.AddStringColumn("Condition")
+	dh.AddFloat64Column("Noise")
+	dh.AddFloat64Column("KNaAdapt")
+	dh.AddFloat64Column("Dur")
+	dh.AddFloat64Column("Prop")
+	dh.SetMetaData("Prop:On", "+")
 }
 
 func (ss *Sim) CycleStats() {
-	ss.Stats.SetFloat32("Harmony", ss.Harmony(ss.Net))
+	ss.Stats.SetFloat32("Harmony", simcore.Harmony(ss.Net))
 	ly := ss.Net.LayerByName("NeckerCube")
 	ss.Stats.SetFloat32("GknaFast", ly.Neurons[0].GknaFast)
 	ss.Stats.SetFloat32("GknaMed", ly.Neurons[0].GknaMed)
 	ss.Stats.SetFloat32("GknaSlow", ly.Neurons[0].GknaSlow)
+
+	actA, actB := ss.PoolActs()
+	ss.Stats.SetFloat32("ActA", actA)
+	ss.Stats.SetFloat32("ActB", actB)
+	cyc := ss.Stats.Int("Cycle")
+	ss.Percepts.Update(cyc, actA, actB, ss.Dom.Margin)
+	ss.Stats.SetFloat("Dominant", float64(ss.Percepts.Cur))
+
+	tr := ss.Logs.MiscTable("Trajectory")
+	row := tr.Rows
+	tr.AddRows(1)
+	tr.SetFloat("Trial", row, float64(ss.Stats.Int("Trial")))
+	tr.SetFloat("Cycle", row, float64(cyc))
+	tr.SetFloat("ActA", row, float64(actA))
+	tr.SetFloat("ActB", row, float64(actB))
+	tr.SetFloat("Harmony", row, ss.Stats.Float("Harmony"))
+	tr.SetFloat("Dominant", row, float64(ss.Percepts.Cur))
+}
+
+// Dominance runs the analysis of the durations of dominance of each percept,
+// as in studies of bistable perception: with KNaAdapt off and on, and at
+// Dom.NNoise + 1 levels of Noise from Dom.NoiseMin to Dom.NoiseMax, it runs
+// Dom.NTrials trials of Dom.Cycles cycles each, starting from initialized
+// activations, and records the durations of the periods of dominance
+// between switches. The Dominance table has the number, mean, SD,
+// coefficient of variation (CV) and gamma shape (mean^2 / variance)
+// of the durations, and the alternation rate (switches per second),
+// for each condition, and the DurHist table has the histogram of the
+// durations, as the proportion in each bin of Dom.BinSize cycles.
+func (ss *Sim) Dominance() {
+	dp := &ss.Dom
+	ctx := &ss.Context
+	net := ss.Net
+	noise, adapt := ss.Cube.Noise, ss.Cube.KNaAdapt
+	dom := ss.Logs.MiscTable("Dominance")
+	dom.SetNumRows(0)
+	dh := ss.Logs.MiscTable("DurHist")
+	dh.SetNumRows(0)
+	domp := ss.GUI.PlotByName("Dominance")
+	dhp := ss.GUI.PlotByName("DurHist")
+	ss.InitRandSeed(0)
+	pc := &Percepts{}
+	for _, kna := range []bool{false, true} {
+		for ni := 0; ni <= dp.NNoise; ni++ {
+			ss.Cube.Noise = dp.NoiseMin
+			if dp.NNoise > 0 {
+				ss.Cube.Noise += (dp.NoiseMax - dp.NoiseMin) * float32(ni) / float32(dp.NNoise)
+			}
+			ss.Cube.KNaAdapt = kna
+			ss.ApplyParams()
+			var durs []int
+			nsw := 0
+			for range dp.NTrials {
+				net.InitActs()
+				net.AlphaCycInit(false)
+				ctx.AlphaCycStart()
+				ss.ApplyInputs()
+				pc.Init()
+				for cyc := range dp.Cycles {
+					net.Cycle(ctx)
+					ctx.CycleInc()
+					actA, actB := ss.PoolActs()
+					pc.Update(cyc, actA, actB, dp.Margin)
+				}
+				durs = append(durs, pc.Durs...)
+				nsw += pc.NSwitches
+			}
+			nz := math.Round(float64(ss.Cube.Noise)*1e5) / 1e5
+			cond := fmt.Sprintf("Noise %g KNa %v", nz, kna)
+			knaf := 0.0
+			if kna {
+				knaf = 1
+			}
+			mean, sd, cv := DurStats(durs)
+			row := dom.Rows
+			dom.AddRows(1)
+			dom.SetFloat("Noise", row, nz)
+			dom.SetFloat("KNaAdapt", row, knaf)
+			dom.SetString("Condition", row, cond)
+			dom.SetFloat("NDurs", row, float64(len(durs)))
+			dom.SetFloat("MeanDur", row, mean)
+			dom.SetFloat("SDDur", row, sd)
+			dom.SetFloat("CV", row, cv)
+			dom.SetFloat("GammaShape", row, 1/(cv*cv))
+			dom.SetFloat("AltHz", row, 1000*float64(nsw)/float64(dp.NTrials*dp.Cycles))
+			for bi, prop := range DurHist(durs, dp.BinSize) {
+				hr := dh.Rows
+				dh.AddRows(1)
+				dh.SetString("Condition", hr, cond)
+				dh.SetFloat("Noise", hr, nz)
+				dh.SetFloat("KNaAdapt", hr, knaf)
+				dh.SetFloat("Dur", hr, float64(bi*dp.BinSize+dp.BinSize/2))
+				dh.SetFloat("Prop", hr, prop)
+			}
+			if ss.GUI.StopNow {
+				break
+			}
+			if domp != nil {
+				domp.GoUpdatePlot()
+				dhp.GoUpdatePlot()
+			}
+			simcore.WebYield()
+		}
+	}
+	ss.Cube.Noise, ss.Cube.KNaAdapt = noise, adapt
+	ss.ApplyParams()
+	ss.GUI.IsRunning = false
+	if domp != nil {
+		domp.GoUpdatePlot()
+		dhp.GoUpdatePlot()
+		ss.GUI.UpdateWindow()
+	}
 }
 
 // Log is the main logging function, handles special things for different scopes
@@ -360,6 +591,7 @@ func (ss *Sim) Log(mode etime.Modes, time etime.Times) {
 		ss.CycleStats()
 	case time == etime.Trial:
 		ss.StatCounters()
+		ss.TrialStats()
 		ss.Logs.Log(mode, time) // also logs to file, etc
 		return
 	}
@@ -383,15 +615,43 @@ func (ss *Sim) ConfigGUI() {
 	ss.GUI.ViewUpdate = &ss.ViewUpdate
 	nv.Current()
 
-	ss.GUI.AddPlots(title, &ss.Logs)
+	ss.ConfigPlots(simcore.GUIPlots{GUI: &ss.GUI})
 
 	ss.GUI.FinalizeGUI(false)
 }
 
+// ConfigPlots adds the log plots, and the plots of the Dominance
+// durations by noise and of their DurHist histogram, to the GUI or
+// the Figures.
+func (ss *Sim) ConfigPlots(pl simcore.Plots) {
+	pl.AddLogPlots("NeckerCube", &ss.Logs)
+
+	plt := pl.AddPlot("Dominance")
+	plt.Options.Title = "Dominance Durations"
+	plt.Options.XAxis = "Noise"
+	plt.Options.Legend = "KNaAdapt"
+	plt.SetTable(ss.Logs.MiscTable("Dominance"))
+
+	plt = pl.AddPlot("DurHist")
+	plt.Options.Title = "Dominance Duration Histogram"
+	plt.Options.XAxis = "Dur"
+	plt.Options.Legend = "Condition"
+	plt.SetTable(ss.Logs.MiscTable("DurHist"))
+}
+
 func (ss *Sim) MakeToolbar(p *tree.Plan) {
 	ss.GUI.AddLooperCtrl(p, ss.Loops)
 
 	////////////////////////////////////////////////
+	ss.GUI.AddToolbarItem(p, egui.ToolbarItem{Label: "Dominance", Icon: icons.PlayArrow,
+		Tooltip: "Run long trials with KNaAdapt off and on, at different levels of Noise, and compute the statistics and histogram of the durations of dominance of each percept between switches.",
+		Active:  egui.ActiveStopped,
+		Func: func() {
+			ss.GUI.IsRunning = true
+			go ss.Dominance()
+			ss.GUI.UpdateWindow()
+		},
+	})
 	ss.GUI.AddToolbarItem(p, egui.ToolbarItem{Label: "Defaults", Icon: icons.Update,
 		Tooltip: "Restore initial default parameters.",
 		Active:  egui.ActiveStopped,
@@ -418,3 +678,44 @@ func (ss *Sim) RunGUI() {
 	ss.ConfigGUI()
 	ss.GUI.Body.RunMainWindow()
 }
+
+// RunNoGUI runs the test loop without the GUI, saving the log files
+// as specified in the Config.Log settings, or runs the Dominance
+// analysis and saves its results.
+func (ss *Sim) RunNoGUI() {
+	runName := ss.Params.RunName(0)
+	ss.Stats.SetString("RunName", runName) // used for naming logs, stats, etc
+	netName := ss.Net.Name
+
+	elog.SetLogFile(&ss.Logs, ss.Config.Log.TestTrial, etime.Test, etime.Trial, "tst_trl", netName, runName)
+	elog.SetLogFile(&ss.Logs, ss.Config.Log.TestCycle, etime.Test, etime.Cycle, "tst_cyc", netName, runName)
+
+	if ss.Config.Figures != "" {
+		ss.Figures.Config(ss.Net, ss.ConfigPlots)
+	}
+	ss.Init()
+	if ss.Config.Dominance {
+		ss.Dominance()
+		simcore.SaveTable(ss.Logs.MiscTable("Dominance"), "dominance", netName, runName)
+		simcore.SaveTable(ss.Logs.MiscTable("DurHist"), "dur_hist", netName, runName)
+		dom := ss.Logs.MiscTable("Dominance")
+		for row := range dom.Rows {
+			fmt.Printf("%-22s  Durations: %4d  Mean: %6.1f  CV: %.3f  Alternations: %.2f Hz\n", dom.StringValue("Condition", row), int(dom.Float("NDurs", row)), dom.Float("MeanDur", row), dom.Float("CV", row), dom.Float("AltHz", row))
+		}
+	} else {
+		ss.Loops.Run(etime.Test)
+	}
+
+	ss.Logs.CloseLogFiles()
+	if ss.Config.Log.Trajectory {
+		simcore.SaveTable(ss.Logs.MiscTable("Trajectory"), "trajectory", netName, runName)
+	}
+
+	if ss.Config.Figures != "" {
+		ss.Figures.Save(ss.Config.Figures, netName, runName)
+	}
+
+	if ss.Config.Export != "" {
+		simcore.SaveLogsNPZ(&ss.Logs, ss.Config.Export, netName, runName)
+	}
+}
@@ -6,4 +6,14 @@ import (
 	"cogentcore.org/core/types"
 )
 
-var _ = types.AddType(&types.Type{Name: "main.Sim", IDName: "sim", Doc: "Sim encapsulates the entire simulation model, and we define all the\nfunctionality as methods on this struct.  This structure keeps all relevant\nstate information organized and available without having to pass everything around\nas arguments to methods, and provides the core GUI interface (note the view tags\nfor the fields which provide hints to how things should be displayed).", Fields: []types.Field{{Name: "Noise", Doc: "the variance parameter for Gaussian noise added to unit activations on every cycle"}, {Name: "KNaAdapt", Doc: "apply sodium-gated potassium adaptation mechanisms that cause the neuron to reduce spiking over time"}, {Name: "Cycles", Doc: "total number of cycles to run per trial; increase to 1,000 when testing adaptation"}, {Name: "Net", Doc: "the network -- click to view / edit parameters for layers, paths, etc"}, {Name: "Params", Doc: "network parameter management"}, {Name: "Loops", Doc: "contains looper control loops for running sim"}, {Name: "Stats", Doc: "contains computed statistic values"}, {Name: "Logs", Doc: "Contains all the logs and information about the logs.'"}, {Name: "Context", Doc: "leabra timing parameters and state"}, {Name: "ViewUpdate", Doc: "netview update parameters"}, {Name: "GUI", Doc: "manages all the gui elements"}, {Name: "RandSeeds", Doc: "a list of random seeds to use for each run"}}})
+var _ = types.AddType(&types.Type{Name: "main.DomParams", IDName: "dom-params", Doc: "DomParams are the parameters for classifying which cube interpretation\nis dominant, and for the Dominance analysis of the durations of each\npercept across long runs, as a function of Noise and KNaAdapt.", Fields: []types.Field{{Name: "Margin", Doc: "difference in the mean activity of the two cubes for one of them to\nbecome the dominant percept: there is a switch when the other one\nhas this much more activity, so small fluctuations are ignored"}, {Name: "NTrials", Doc: "number of trials to run for each condition in the Dominance analysis"}, {Name: "Cycles", Doc: "number of cycles per trial in the Dominance analysis, which must be\nlong enough for many alternations with adaptation (1 cycle = 1 msec)"}, {Name: "NoiseMin", Doc: "lowest Noise in the Dominance analysis"}, {Name: "NoiseMax", Doc: "highest Noise in the Dominance analysis"}, {Name: "NNoise", Doc: "number of steps of Noise from NoiseMin to NoiseMax in the Dominance analysis"}, {Name: "BinSize", Doc: "size of the bins of the histogram of dominance durations, in cycles"}}})
+
+var _ = types.AddType(&types.Type{Name: "main.Percepts", IDName: "percepts", Doc: "Percepts tracks the dominant percept over the cycles of a trial,\nand the durations of the periods of dominance between switches.", Fields: []types.Field{{Name: "Cur", Doc: "the current dominant percept: NoPercept, PerceptA or PerceptB"}, {Name: "Start", Doc: "the cycle on which the current percept became dominant"}, {Name: "NSwitches", Doc: "the number of switches between percepts in the trial"}, {Name: "Durs", Doc: "the durations in cycles of the complete periods of dominance in the\ntrial, which start and end with a switch: the first period, which\nstarts with the initial settling, and the last one, which is cut off\nat the end of the trial, are not included"}}})
+
+var _ = types.AddType(&types.Type{Name: "main.CubeParams", IDName: "cube-params", Doc: "CubeParams are the parameters of the settling of the NeckerCube\nnetwork into one of its two interpretations of the cube: the noise and\nthe adaptation that drive it to switch between them over time.", Fields: []types.Field{{Name: "Noise", Doc: "the variance parameter for Gaussian noise added to unit activations on every cycle"}, {Name: "KNaAdapt", Doc: "apply sodium-gated potassium adaptation mechanisms that cause the neuron to reduce spiking over time"}, {Name: "Cycles", Doc: "total number of cycles to run per trial; increase to 1,000 when testing adaptation"}}})
+
+var _ = types.AddType(&types.Type{Name: "main.Config", IDName: "config", Doc: "Config has config parameters related to running the sim.\nCube and Dom are where the Sim parameters start: e.g., -KNaAdapt\n-Cycles 1000 lets adaptation switch the dominant percept within a trial.", Fields: []types.Field{{Name: "Cube", Doc: "the Cube parameters for the Sim to start with"}, {Name: "Dom", Doc: "parameters for classifying the dominant percept, and for the\nDominance analysis of the durations of each percept"}, {Name: "Dominance", Doc: "run the Dominance analysis instead of the test trials, saving the\nresults to dominance.tsv and dur_hist.tsv, when running without the GUI."}, {Name: "GUI", Doc: "GUI means open the GUI. Otherwise it runs automatically and quits,\nsaving log files as specified in Log."}, {Name: "Figures", Doc: "plots, grids or NetView to save as images without the GUI, or \"all\" (see simcore.Figures)"}, {Name: "Export", Doc: "logs to export to .npz files for Python without the GUI, or \"all\" (see simcore.SaveLogsNPZ)"}, {Name: "Log", Doc: "Log has config parameters related to logging data."}}})
+
+var _ = types.AddType(&types.Type{Name: "main.LogConfig", IDName: "log-config", Doc: "LogConfig has config parameters related to logging data", Fields: []types.Field{{Name: "TestTrial", Doc: "if true, save testing trial log to file, as .tst_trl.tsv typically"}, {Name: "TestCycle", Doc: "if true, save testing cycle log to file, as .tst_cyc.tsv typically. May be large."}, {Name: "Trajectory", Doc: "if true, save the settling trajectory of all the trials, with the\nmean activity of each cube on each cycle, as .trajectory.tsv"}}})
+
+var _ = types.AddType(&types.Type{Name: "main.Sim", IDName: "sim", Doc: "Sim encapsulates the entire simulation model, and we define all the\nfunctionality as methods on this struct.  This structure keeps all relevant\nstate information organized and available without having to pass everything around\nas arguments to methods, and provides the core GUI interface (note the view tags\nfor the fields which provide hints to how things should be displayed).", Fields: []types.Field{{Name: "Cube", Doc: "the parameters of the settling of the network"}, {Name: "Dom", Doc: "parameters for classifying the dominant percept, and for the\nDominance analysis of the durations of each percept"}, {Name: "Percepts", Doc: "tracks the dominant percept over the cycles of the current trial"}, {Name: "Config", Doc: "Config contains misc configuration parameters for running the sim"}, {Name: "Net", Doc: "the network -- click to view / edit parameters for layers, paths, etc"}, {Name: "Params", Doc: "network parameter management"}, {Name: "Loops", Doc: "contains looper control loops for running sim"}, {Name: "Stats", Doc: "contains computed statistic values"}, {Name: "Logs", Doc: "Contains all the logs and information about the logs.'"}, {Name: "Context", Doc: "leabra timing parameters and state"}, {Name: "ViewUpdate", Doc: "netview update parameters"}, {Name: "GUI", Doc: "manages all the gui elements"}, {Name: "Figures", Doc: "the plots, grids and network snapshots that can be saved without the GUI"}, {Name: "RandSeeds", Doc: "a list of random seeds to use for each run"}}})
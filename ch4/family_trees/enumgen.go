@@ -6,20 +6,20 @@ import (
 	"cogentcore.org/core/enums"
 )
 
-var _LearnTypeValues = []LearnType{0, 1, 2}
+var _LearnTypeValues = []LearnType{0, 1, 2, 3, 4}
 
 // LearnTypeN is the highest valid value for type LearnType, plus one.
 //
 //gosl:start
-const LearnTypeN LearnType = 3
+const LearnTypeN LearnType = 5
 
 //gosl:end
 
-var _LearnTypeValueMap = map[string]LearnType{`PureHebb`: 0, `PureError`: 1, `HebbError`: 2}
+var _LearnTypeValueMap = map[string]LearnType{`PureHebb`: 0, `PureError`: 1, `HebbError`: 2, `Backprop`: 3, `GeneRec`: 4}
 
-var _LearnTypeDescMap = map[LearnType]string{0: ``, 1: ``, 2: ``}
+var _LearnTypeDescMap = map[LearnType]string{0: ``, 1: ``, 2: ``, 3: `Backprop is standard error backpropagation, in a feedforward network with the same layers, instead of Leabra (see simcore.BPNet)`, 4: `GeneRec is GeneRec learning (contrastive Hebbian learning with symmetric weights), in a bidirectional rate-code network with the same layers, instead of Leabra (see simcore.BPNet)`}
 
-var _LearnTypeMap = map[LearnType]string{0: `PureHebb`, 1: `PureError`, 2: `HebbError`}
+var _LearnTypeMap = map[LearnType]string{0: `PureHebb`, 1: `PureError`, 2: `HebbError`, 3: `Backprop`, 4: `GeneRec`}
 
 // String returns the string representation of this LearnType value.
 func (i LearnType) String() string { return enums.String(i, _LearnTypeMap) }
@@ -23,6 +23,7 @@ import (
 	"cogentcore.org/core/tensor/stats/metric"
 	"cogentcore.org/core/tensor/table"
 	"cogentcore.org/core/tree"
+	"github.com/CompCogNeuro/sims/v2/simcore"
 	"github.com/emer/emergent/v2/econfig"
 	"github.com/emer/emergent/v2/egui"
 	"github.com/emer/emergent/v2/elog"
@@ -48,13 +49,31 @@ const (
 	PureHebb LearnType = iota
 	PureError
 	HebbError
+
+	// Backprop is standard error backpropagation, in a feedforward
+	// network with the same layers, instead of Leabra (see simcore.BPNet)
+	Backprop
+
+	// GeneRec is GeneRec learning (contrastive Hebbian learning with
+	// symmetric weights), in a bidirectional rate-code network with the
+	// same layers, instead of Leabra (see simcore.BPNet)
+	GeneRec
 )
 
+// IsBP returns whether the learning is done by the BPNet instead of Leabra.
+func (lt LearnType) IsBP() bool {
+	return lt == Backprop || lt == GeneRec
+}
+
 func main() {
 	sim := &Sim{}
 	sim.New()
 	sim.ConfigAll()
-	sim.RunGUI()
+	if sim.Config.GUI {
+		sim.RunGUI()
+	} else {
+		sim.RunNoGUI()
+	}
 }
 
 // ParamSets is the default set of parameters.
@@ -120,6 +139,12 @@ var ParamSets = params.Sets{
 				"Path.Learn.Lrate":        ".04", // default
 			}},
 	},
+	"BPNet": {
+		{Sel: "Path", Desc: "the BPNet learns instead, and the Leabra network just shows its activations",
+			Params: params.Params{
+				"Path.Learn.Learn": "false",
+			}},
+	},
 }
 
 // Config has config parameters related to running the sim
@@ -136,6 +161,49 @@ type Config struct {
 	// how often to run through all the test patterns, in terms of training epochs.
 	// can use 0 or -1 for no testing.
 	TestInterval int `default:"5"`
+
+	// GUI means open the GUI. Otherwise it runs automatically and quits,
+	// saving log files as specified in Log.
+	GUI bool `default:"true"`
+
+	// starting run number, which determines the random seed.
+	// runs count up from there, so all runs can be done in parallel
+	// by launching separate jobs with each starting Run and NRuns = 1.
+	Run int `default:"0"`
+
+	// RunConfig has the config parameters for running without the GUI:
+	// Sweep, Parallel, Checkpoint, Resume, Summary, Figures and Export.
+	simcore.RunConfig `display:"add-fields"`
+
+	// Log has config parameters related to logging data.
+	Log LogConfig `display:"add-fields"`
+}
+
+// LogConfig has config parameters related to logging data
+type LogConfig struct {
+
+	// if true, save final weights after each run
+	SaveWeights bool
+
+	// if true, save train epoch log to file, as .epc.tsv typically
+	Epoch bool `default:"true" nest:"+"`
+
+	// if true, save run log to file, as .run.tsv typically
+	Run bool `default:"true" nest:"+"`
+
+	// if true, save train trial log to file, as .trl.tsv typically. May be large.
+	Trial bool `default:"false" nest:"+"`
+
+	// if true, save testing epoch log to file, as .tst_epc.tsv typically.  In general it is better to copy testing items over to the training epoch log and record there.
+	TestEpoch bool `default:"false" nest:"+"`
+
+	// if true, save testing trial log to file, as .tst_trl.tsv typically. May be large.
+	TestTrial bool `default:"false" nest:"+"`
+
+	// if true, compute the Harmony of the network on each cycle (see
+	// simcore.Harmony), and log its average over the cycles of each trial.
+	// This slows down training substantially.
+	Harmony bool `default:"false" nest:"+"`
 }
 
 // Sim encapsulates the entire simulation model, and we define all the
@@ -154,6 +222,10 @@ type Sim struct {
 	// the network -- click to view / edit parameters for layers, paths, etc
 	Net *leabra.Network `new-window:"+" display:"no-inline"`
 
+	// the network that learns with Backprop or GeneRec instead of the
+	// Leabra network, with the same layers, for those Learn types
+	BP *simcore.BPNet `new-window:"+" display:"no-inline"`
+
 	// network parameter management
 	Params emer.NetParams `display:"add-fields"`
 
@@ -181,6 +253,9 @@ type Sim struct {
 	// manages all the gui elements
 	GUI egui.GUI `display:"-"`
 
+	// the plots, grids and network snapshots that can be saved without the GUI
+	Figures simcore.Figures `display:"-"`
+
 	// a list of random seeds to use for each run
 	RandSeeds randx.Seeds `display:"-"`
 }
@@ -277,6 +352,9 @@ func (ss *Sim) ConfigNet(net *leabra.Network) {
 	hid.PlaceAbove(relcd)
 
 	net.Build()
+	ss.BP = errors.Log1(simcore.NewBPNet(net))
+	ss.BP.GeneRecLrate = 0.02 // GeneRec only learns this deep network slowly, without momentum
+	ss.BP.GeneRecMomentum = 0
 	net.Defaults()
 	ss.ApplyParams()
 	net.InitWeights()
@@ -284,11 +362,23 @@ func (ss *Sim) ConfigNet(net *leabra.Network) {
 
 func (ss *Sim) ApplyParams() {
 	ss.Params.SetAll()
-	ss.Params.SetAllSheet(ss.Learn.String())
+	if ss.Learn.IsBP() {
+		ss.Params.SetAllSheet("BPNet")
+	} else {
+		ss.Params.SetAllSheet(ss.Learn.String())
+	}
+	ss.BP.GeneRec = ss.Learn == GeneRec
 	if ss.Loops != nil {
 		trn := ss.Loops.Stacks[etime.Train]
 		trn.Loops[etime.Run].Counter.Max = ss.Config.NRuns
 		trn.Loops[etime.Epoch].Counter.Max = ss.Config.NEpochs
+		ncyc := 100
+		if ss.Learn.IsBP() { // the BPNet does the whole trial in BPTrial
+			ncyc = 1
+		}
+		for _, st := range ss.Loops.Stacks {
+			st.Loops[etime.Cycle].Counter.Max = ncyc
+		}
 	}
 }
 
@@ -298,7 +388,9 @@ func (ss *Sim) ApplyParams() {
 // Init restarts the run, and initializes everything, including network weights
 // and resets the epoch log table
 func (ss *Sim) Init() {
-	ss.Stats.SetString("RunName", ss.Params.RunName(0)) // in case user interactively changes tag
+	if ss.Config.GUI {
+		ss.Stats.SetString("RunName", ss.Params.RunName(0)) // in case user interactively changes tag
+	}
 	ss.Loops.ResetCounters()
 	ss.InitRandSeed(0)
 	ss.ConfigEnv() // re-config env just in case a different set of patterns was
@@ -311,8 +403,7 @@ func (ss *Sim) Init() {
 
 // InitRandSeed initializes the random seed based on current training run number
 func (ss *Sim) InitRandSeed(run int) {
-	ss.RandSeeds.Set(run)
-	ss.RandSeeds.Set(run, &ss.Net.Rand)
+	simcore.InitRandSeed(&ss.RandSeeds, ss.Net, run)
 }
 
 // ConfigLoops configures the control loops: Training, Testing
@@ -339,12 +430,26 @@ func (ss *Sim) ConfigLoops() {
 
 	for m, _ := range ls.Stacks {
 		stack := ls.Stacks[m]
+		// the Leabra network is not cycled when the BPNet learns instead
+		stack.Loops[etime.Cycle].OnStart.Replace("Cycle", func() bool {
+			if !ss.Learn.IsBP() {
+				ss.Net.Cycle(&ss.Context)
+			}
+			ss.Context.CycleInc()
+			return true
+		})
 		stack.Loops[etime.Trial].OnStart.Add("ApplyInputs", func() {
 			ss.ApplyInputs()
 		})
+		stack.Loops[etime.Trial].OnEnd.Add("BPTrial", func() {
+			if ss.Learn.IsBP() {
+				ss.BP.Trial(ss.Context.Mode == etime.Train)
+			}
+		})
 	}
 
 	ls.Loop(etime.Train, etime.Run).OnStart.Add("NewRun", ss.NewRun)
+	simcore.SeedEpochs(ls, &ss.RandSeeds, ss.Net)
 
 	// Train stop early condition
 	ls.Loop(etime.Train, etime.Epoch).IsDone.AddBool("NZeroStop", func() bool {
@@ -381,13 +486,21 @@ func (ss *Sim) ConfigLoops() {
 		ss.Logs.RunStats("PctCor", "FirstZero", "LastZero")
 	})
 
+	// Save weights to file, to look at later
+	ls.Loop(etime.Train, etime.Run).OnEnd.Add("SaveWeights", func() {
+		ctrString := ss.Stats.PrintValues([]string{"Run", "Epoch"}, []string{"%03d", "%05d"}, "_")
+		leabra.SaveWeightsIfConfigSet(ss.Net, ss.Config.Log.SaveWeights, ctrString, ss.Stats.String("RunName"))
+	})
+
 	////////////////////////////////////////////
 	// GUI
 
-	leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
-	leabra.LooperUpdatePlots(ls, &ss.GUI)
-	ls.Stacks[etime.Train].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
-	ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	if ss.Config.GUI {
+		leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
+		leabra.LooperUpdatePlots(ls, &ss.GUI)
+		ls.Stacks[etime.Train].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+		ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	}
 
 	ss.Loops = ls
 }
@@ -409,32 +522,15 @@ func (ss *Sim) ApplyInputs() {
 		out.Type = leabra.TargetLayer
 	}
 
-	lays := net.LayersByType(leabra.InputLayer, leabra.TargetLayer)
-	net.InitExt()
 	ss.Stats.SetString("TrialName", ev.TrialName.Cur)
-	for _, lnm := range lays {
-		ly := ss.Net.LayerByName(lnm)
-		pats := ev.State(ly.Name)
-		if pats != nil {
-			ly.ApplyExt(pats)
-		}
-	}
+	simcore.ApplyInputs(net, ev)
 }
 
 // NewRun intializes a new run of the model, using the TrainEnv.Run counter
 // for the new run value
 func (ss *Sim) NewRun() {
-	ctx := &ss.Context
-	ss.InitRandSeed(ss.Loops.Loop(etime.Train, etime.Run).Counter.Cur)
-	ss.Envs.ByMode(etime.Train).Init(0)
-	ss.Envs.ByMode(etime.Test).Init(0)
-	ctx.Reset()
-	ctx.Mode = etime.Train
-	ss.Net.InitWeights()
-	ss.InitStats()
-	ss.StatCounters()
-	ss.Logs.ResetLog(etime.Train, etime.Epoch)
-	ss.Logs.ResetLog(etime.Test, etime.Epoch)
+	simcore.NewRun(ss.Loops, &ss.Context, ss.Net, ss.Envs, &ss.Logs, &ss.RandSeeds, simcore.Hooks{InitStats: ss.InitStats, StatCounters: ss.StatCounters})
+	ss.BP.InitWeights(&ss.Net.Rand)
 }
 
 // TestAll runs through the full set of testing items
@@ -458,26 +554,11 @@ func (ss *Sim) InitStats() {
 // StatCounters saves current counters to Stats, so they are available for logging etc
 // Also saves a string rep of them for ViewUpdate.Text
 func (ss *Sim) StatCounters() {
-	ctx := &ss.Context
-	mode := ctx.Mode
-	ss.Loops.Stacks[mode].CountersToStats(&ss.Stats)
-	// always use training epoch..
-	trnEpc := ss.Loops.Stacks[etime.Train].Loops[etime.Epoch].Counter.Cur
-	ss.Stats.SetInt("Epoch", trnEpc)
-	trl := ss.Stats.Int("Trial")
-	ss.Stats.SetInt("Trial", trl)
-	ss.Stats.SetInt("Cycle", int(ctx.Cycle))
+	simcore.StatCounters(ss.Loops, &ss.Context, &ss.Stats, true) // always use training epoch
 }
 
 func (ss *Sim) NetViewCounters(tm etime.Times) {
-	if ss.ViewUpdate.View == nil {
-		return
-	}
-	if tm == etime.Trial {
-		ss.TrialStats() // get trial stats for current di
-	}
-	ss.StatCounters()
-	ss.ViewUpdate.Text = ss.Stats.Print([]string{"Run", "Epoch", "Trial", "TrialName", "Cycle", "SSE", "TrlErr"})
+	simcore.NetViewCounters(&ss.ViewUpdate, &ss.Stats, tm, simcore.Hooks{TrialStats: ss.TrialStats, StatCounters: ss.StatCounters}, "Run", "Epoch", "Trial", "TrialName", "Cycle", "SSE", "TrlErr")
 }
 
 // TrialStats computes the trial-level statistics.
@@ -495,6 +576,11 @@ func (ss *Sim) TrialStats() {
 	}
 }
 
+// CycleStats computes the cycle-level statistics.
+func (ss *Sim) CycleStats() {
+	ss.Stats.SetFloat32("Harmony", simcore.Harmony(ss.Net))
+}
+
 // RepsAnalysis analyzes the representations as captured in the Test Trial Log
 func (ss *Sim) RepsAnalysis() {
 	trl := ss.Logs.Table(etime.Test, etime.Trial)
@@ -516,8 +602,8 @@ func (ss *Sim) RepsAnalysis() {
 	errors.Log(ss.Stats.SVD.TableColumn(rels, "Hidden_ActM", metric.Covariance64))
 	svt := ss.Logs.MiscTable("HiddenRelPCA")
 	ss.Stats.SVD.ProjectColumnToTable(svt, rels, "Hidden_ActM", "TrialName", []int{0, 1})
-	estats.ConfigPCAPlot(ss.GUI.PlotByName("HiddenRelPCA"), svt, "Hidden Rel PCA")
-	estats.ClusterPlot(ss.GUI.PlotByName("HiddenRelClust"), rels, "Hidden_ActM", "TrialName", clust.ContrastDist)
+	estats.ConfigPCAPlot(ss.Figures.PlotByName(&ss.GUI, "HiddenRelPCA"), svt, "Hidden Rel PCA")
+	estats.ClusterPlot(ss.Figures.PlotByName(&ss.GUI, "HiddenRelClust"), rels, "Hidden_ActM", "TrialName", clust.ContrastDist)
 
 	// replace name with just agent
 	for i, nm := range names {
@@ -531,8 +617,8 @@ func (ss *Sim) RepsAnalysis() {
 	errors.Log(ss.Stats.SVD.TableColumn(ags, "Hidden_ActM", metric.Covariance64))
 	svt = ss.Logs.MiscTable("HiddenAgentPCA")
 	ss.Stats.SVD.ProjectColumnToTable(svt, ags, "Hidden_ActM", "TrialName", []int{2, 3})
-	estats.ConfigPCAPlot(ss.GUI.PlotByName("HiddenAgentPCA"), svt, "Hidden Agent PCA")
-	estats.ClusterPlot(ss.GUI.PlotByName("HiddenAgentClust"), ags, "Hidden_ActM", "TrialName", clust.ContrastDist)
+	estats.ConfigPCAPlot(ss.Figures.PlotByName(&ss.GUI, "HiddenAgentPCA"), svt, "Hidden Agent PCA")
+	estats.ClusterPlot(ss.Figures.PlotByName(&ss.GUI, "HiddenAgentClust"), ags, "Hidden_ActM", "TrialName", clust.ContrastDist)
 
 	// ss.HiddenAgent.PCAPlot.SetColParams("Prjn3", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 0)
 	// ss.HiddenAgent.PCAPlot.Params.XAxisCol = "Prjn2"
@@ -541,8 +627,8 @@ func (ss *Sim) RepsAnalysis() {
 	errors.Log(ss.Stats.SVD.TableColumn(ags, "AgentCode_ActM", metric.Covariance64))
 	svt = ss.Logs.MiscTable("AgentCodePCA")
 	ss.Stats.SVD.ProjectColumnToTable(svt, ags, "AgentCode_ActM", "TrialName", []int{0, 1})
-	estats.ConfigPCAPlot(ss.GUI.PlotByName("AgentCodePCA"), svt, "AgentCode Agent PCA")
-	estats.ClusterPlot(ss.GUI.PlotByName("AgentCodeClust"), ags, "AgentCode_ActM", "TrialName", clust.ContrastDist)
+	estats.ConfigPCAPlot(ss.Figures.PlotByName(&ss.GUI, "AgentCodePCA"), svt, "AgentCode Agent PCA")
+	estats.ClusterPlot(ss.Figures.PlotByName(&ss.GUI, "AgentCodeClust"), ags, "AgentCode_ActM", "TrialName", clust.ContrastDist)
 
 	copy(nmtsr.Values, names) // restore
 }
@@ -560,6 +646,9 @@ func (ss *Sim) ConfigLogs() {
 	ss.Logs.AddStatAggItem("SSE", etime.Run, etime.Epoch, etime.Trial)
 	ss.Logs.AddStatAggItem("AvgSSE", etime.Run, etime.Epoch, etime.Trial)
 	ss.Logs.AddErrStatAggItems("TrlErr", etime.Run, etime.Epoch, etime.Trial)
+	if ss.Config.Log.Harmony {
+		ss.Logs.AddStatAggItem("Harmony", etime.Run, etime.Epoch, etime.Trial, etime.Cycle)
+	}
 
 	ss.Logs.AddCopyFromFloatItems(etime.Train, []etime.Times{etime.Epoch, etime.Run}, etime.Test, etime.Epoch, "Tst", "SSE", "AvgSSE")
 
@@ -582,26 +671,11 @@ func (ss *Sim) ConfigLogs() {
 
 // Log is the main logging function, handles special things for different scopes
 func (ss *Sim) Log(mode etime.Modes, time etime.Times) {
-	ctx := &ss.Context
-	if mode != etime.Analyze {
-		ctx.Mode = mode // Also set specifically in a Loop callback.
-	}
-	dt := ss.Logs.Table(mode, time)
-	if dt == nil {
-		return
+	h := simcore.Hooks{TrialStats: ss.TrialStats, StatCounters: ss.StatCounters}
+	if ss.Config.Log.Harmony {
+		h.CycleStats = ss.CycleStats
 	}
-	row := dt.Rows
-
-	switch {
-	case time == etime.Cycle:
-		return
-	case time == etime.Trial:
-		ss.TrialStats()
-		ss.StatCounters()
-	}
-
-	ss.Logs.LogRow(mode, time, row) // also logs to file, etc
-
+	simcore.Log(&ss.Context, &ss.Logs, mode, time, h)
 	if mode == etime.Test {
 		ss.GUI.UpdateTableView(etime.Test, etime.Trial)
 	}
@@ -628,20 +702,27 @@ func (ss *Sim) ConfigGUI() {
 	// nv.SceneXYZ().Camera.Pose.Pos.Set(0.1, 1.5, 4) // more "head on" than default which is more "top down"
 	// nv.SceneXYZ().Camera.LookAt(math32.Vec3(0.1, 0.1, 0), math32.Vec3(0, 1, 0))
 
-	ss.GUI.AddPlots(title, &ss.Logs)
+	ss.ConfigPlots(simcore.GUIPlots{GUI: &ss.GUI})
 
 	ss.GUI.AddTableView(&ss.Logs, etime.Test, etime.Trial)
 
-	ss.GUI.AddMiscPlotTab("HiddenRelPCA")
-	ss.GUI.AddMiscPlotTab("HiddenRelClust")
-	ss.GUI.AddMiscPlotTab("HiddenAgentPCA")
-	ss.GUI.AddMiscPlotTab("HiddenAgentClust")
-	ss.GUI.AddMiscPlotTab("AgentCodePCA")
-	ss.GUI.AddMiscPlotTab("AgentCodeClust")
-
 	ss.GUI.FinalizeGUI(false)
 }
 
+// ConfigPlots adds the log plots, and the PCA and cluster plots of the
+// Hidden representations by relation and by agent, and of the AgentCode
+// representations, made by RepsAnalysis, to the GUI or the Figures.
+func (ss *Sim) ConfigPlots(pl simcore.Plots) {
+	pl.AddLogPlots("Family Trees", &ss.Logs)
+
+	pl.AddPlot("HiddenRelPCA")
+	pl.AddPlot("HiddenRelClust")
+	pl.AddPlot("HiddenAgentPCA")
+	pl.AddPlot("HiddenAgentClust")
+	pl.AddPlot("AgentCodePCA")
+	pl.AddPlot("AgentCodeClust")
+}
+
 func (ss *Sim) MakeToolbar(p *tree.Plan) {
 	ss.GUI.AddLooperCtrl(p, ss.Loops)
 
@@ -690,3 +771,17 @@ func (ss *Sim) RunGUI() {
 	ss.ConfigGUI()
 	ss.GUI.Body.RunMainWindow()
 }
+
+func (ss *Sim) RunNoGUI() {
+	ss.Runner().RunNoGUI()
+}
+
+// Runner returns the simcore.Runner that runs the sim without the GUI.
+func (ss *Sim) Runner() *simcore.Runner {
+	return &simcore.Runner{Sim: ss, NetName: ss.Net.Name, RunName: ss.Params.RunName,
+		Fields: []string{"Learn"},
+		Config: &ss.Config.RunConfig, Run: &ss.Config.Run, NRuns: &ss.Config.NRuns, NEpochs: &ss.Config.NEpochs, Log: &ss.Config.Log,
+		Net: ss.Net, Context: &ss.Context, Loops: ss.Loops, Envs: ss.Envs, Stats: &ss.Stats, Logs: &ss.Logs, Seeds: &ss.RandSeeds,
+		Figures: &ss.Figures, Init: ss.Init, ConfigPlots: ss.ConfigPlots, Analyze: ss.TestAll, UpdateFigures: ss.RepsAnalysis,
+		Extra: ss.BP.State, SetExtra: ss.BP.SetState}
+}
@@ -10,9 +10,11 @@ package main
 //go:generate core generate -add-types
 
 import (
-	"embed"
+	"math"
+	"os"
 
 	"cogentcore.org/core/base/errors"
+	"cogentcore.org/core/base/mpi"
 	"cogentcore.org/core/base/randx"
 	"cogentcore.org/core/core"
 	"cogentcore.org/core/enums"
@@ -23,6 +25,7 @@ import (
 	"cogentcore.org/core/tensor/stats/norm"
 	"cogentcore.org/core/tensor/table"
 	"cogentcore.org/core/tree"
+	"github.com/CompCogNeuro/sims/v2/simcore"
 	"github.com/emer/emergent/v2/econfig"
 	"github.com/emer/emergent/v2/egui"
 	"github.com/emer/emergent/v2/elog"
@@ -34,15 +37,10 @@ import (
 	"github.com/emer/emergent/v2/netview"
 	"github.com/emer/emergent/v2/params"
 	"github.com/emer/emergent/v2/paths"
-	"golang.org/x/exp/rand"
-
 	//	"github.com/emer/etable/split"
 	"github.com/emer/leabra/v2/leabra"
 )
 
-//go:embed lines2out1.tsv
-var content embed.FS
-
 // LearnType is the type of learning to use
 type LearnType int32 //enums:enum
 
@@ -56,7 +54,11 @@ func main() {
 	sim := &Sim{}
 	sim.New()
 	sim.ConfigAll()
-	sim.RunGUI()
+	if sim.Config.GUI {
+		sim.RunGUI()
+	} else {
+		sim.RunNoGUI()
+	}
 }
 
 // ParamSets is the default set of parameters.
@@ -147,6 +149,54 @@ type Config struct {
 	// how often to run through all the test patterns, in terms of training epochs.
 	// can use 0 or -1 for no testing.
 	TestInterval int `default:"5"`
+
+	// GUI means open the GUI. Otherwise it runs automatically and quits,
+	// saving log files as specified in Log.
+	GUI bool `default:"true"`
+
+	// starting run number, which determines the random seed.
+	// runs count up from there, so all runs can be done in parallel
+	// by launching separate jobs with each starting Run and NRuns = 1.
+	Run int `default:"0"`
+
+	// RunConfig has the config parameters for running without the GUI:
+	// Sweep, Parallel, Checkpoint, Resume, Summary, Figures and Export.
+	simcore.RunConfig `display:"add-fields"`
+
+	// run the factorial experiment without the GUI, instead of the standard runs:
+	// NRuns runs for each of the LearnType values, saving the run log rows
+	// for all of them to a _factorial.tsv file, and a summary of the epochs
+	// to criterion (EpochsToCrit, only for the runs that reached it),
+	// the proportion of runs that reached it (Solved), the final SSE,
+	// and the generalization error on the held-out patterns (GenPctErr),
+	// for each one to _factorial_summary.tsv and .md files, with bar plots
+	// of each in .svg and .png files.
+	Factorial bool
+
+	// Log has config parameters related to logging data.
+	Log LogConfig `display:"add-fields"`
+}
+
+// LogConfig has config parameters related to logging data
+type LogConfig struct {
+
+	// if true, save final weights after each run
+	SaveWeights bool
+
+	// if true, save train epoch log to file, as .epc.tsv typically
+	Epoch bool `default:"true" nest:"+"`
+
+	// if true, save run log to file, as .run.tsv typically
+	Run bool `default:"true" nest:"+"`
+
+	// if true, save train trial log to file, as .trl.tsv typically. May be large.
+	Trial bool `default:"false" nest:"+"`
+
+	// if true, save testing epoch log to file, as .tst_epc.tsv typically.  In general it is better to copy testing items over to the training epoch log and record there.
+	TestEpoch bool `default:"false" nest:"+"`
+
+	// if true, save testing trial log to file, as .tst_trl.tsv typically. May be large.
+	TestTrial bool `default:"false" nest:"+"`
 }
 
 // Sim encapsulates the entire simulation model, and we define all the
@@ -174,6 +224,13 @@ type Sim struct {
 	// higher because fewer neurons should be active.
 	TestGi float32 `min:"0" step:"0.1" default:"2.5"`
 
+	// proportion of the two-line combinations held out of training, chosen
+	// at random for each run (leaving at least one to train on), to test
+	// generalization to novel combinations of lines: the test includes
+	// all of them, and GenPctErr is the proportion of errors on just the
+	// held-out ones.
+	HoldOut float32 `min:"0" max:"1" step:"0.05" default:"0.15"`
+
 	// Config contains misc configuration parameters for running the sim
 	Config Config `new-window:"+" display:"no-inline"`
 
@@ -183,9 +240,12 @@ type Sim struct {
 	// network parameter management
 	Params emer.NetParams `display:"add-fields"`
 
-	// 2 active lines for training
+	// all of the combinations of 2 active lines, for training and testing
 	Lines2 *table.Table `new-window:"+" display:"no-inline"`
 
+	// whether each of the Lines2 patterns is held out of training in the current run
+	IsHeldOut []bool `display:"-"`
+
 	// contains looper control loops for running sim
 	Loops *looper.Stacks `new-window:"+" display:"no-inline"`
 
@@ -207,6 +267,9 @@ type Sim struct {
 	// manages all the gui elements
 	GUI egui.GUI `display:"-"`
 
+	// the plots, grids and network snapshots that can be saved without the GUI
+	Figures simcore.Figures `display:"-"`
+
 	// a list of random seeds to use for each run
 	RandSeeds randx.Seeds `display:"-"`
 }
@@ -217,7 +280,6 @@ func (ss *Sim) New() {
 	ss.Net = leabra.NewNetwork("SelfOrg")
 	ss.Params.Config(ParamSets, "", "", ss.Net)
 	ss.Stats.Init()
-	ss.Lines2 = &table.Table{}
 	ss.RandSeeds.Init(100) // max 100 runs
 	ss.InitRandSeed(0)
 	ss.Context.Defaults()
@@ -227,6 +289,7 @@ func (ss *Sim) New() {
 func (ss *Sim) Defaults() {
 	ss.AvgLGain = 3.5
 	ss.InputNoise = 0
+	ss.HoldOut = 0.15
 }
 
 //////////////////////////////////////////////////////////////////////////////
@@ -242,9 +305,8 @@ func (ss *Sim) ConfigAll() {
 }
 
 func (ss *Sim) OpenPatterns() {
-	ss.Lines2.SetMetaData("name", "Lines2")
+	ss.Lines2 = simcore.LinesTable("Lines2", 5, 2, true)
 	ss.Lines2.SetMetaData("desc", "2 lines active Training patterns")
-	errors.Log(ss.Lines2.OpenFS(content, "lines2out1.tsv", table.Tab))
 }
 
 func (ss *Sim) ConfigEnv() {
@@ -258,21 +320,13 @@ func (ss *Sim) ConfigEnv() {
 		tst = ss.Envs.ByMode(etime.Test).(*env.FixedTable)
 	}
 
-	n := ss.Lines2.Rows
-	order := rand.Perm(n)
-	ntrn := int(0.85 * float64(n))
-
-	trnEnv := table.NewIndexView(ss.Lines2)
-	tstEnv := table.NewIndexView(ss.Lines2)
-	trnEnv.Indexes = order[:ntrn]
-	tstEnv.Indexes = order[ntrn:]
-
+	// note: the held out patterns are set for each run in HoldOutPatterns
 	trn.Name = etime.Train.String()
-	trn.Config(trnEnv)
+	trn.Config(table.NewIndexView(ss.Lines2))
 	trn.Validate()
 
 	tst.Name = etime.Test.String()
-	tst.Config(tstEnv)
+	tst.Config(table.NewIndexView(ss.Lines2))
 	tst.Sequential = true
 	tst.Validate()
 
@@ -339,7 +393,9 @@ func (ss *Sim) ApplyParams() {
 // Init restarts the run, and initializes everything, including network weights
 // and resets the epoch log table
 func (ss *Sim) Init() {
-	ss.Stats.SetString("RunName", ss.Params.RunName(0)) // in case user interactively changes tag
+	if ss.Config.GUI {
+		ss.Stats.SetString("RunName", ss.Params.RunName(0)) // in case user interactively changes tag
+	}
 	ss.Loops.ResetCounters()
 	ss.InitRandSeed(0)
 	ss.ConfigEnv() // re-config env just in case a different set of patterns was
@@ -352,26 +408,22 @@ func (ss *Sim) Init() {
 
 // InitRandSeed initializes the random seed based on current training run number
 func (ss *Sim) InitRandSeed(run int) {
-	ss.RandSeeds.Set(run)
-	ss.RandSeeds.Set(run, &ss.Net.Rand)
+	simcore.InitRandSeed(&ss.RandSeeds, ss.Net, run)
 }
 
 // ConfigLoops configures the control loops: Training, Testing
 func (ss *Sim) ConfigLoops() {
 	ls := looper.NewStacks()
-	n := ss.Lines2.Rows
-	ntrn := int(0.85 * float64(n))
-	ntst := int(0.15 * float64(n))
 
 	ls.AddStack(etime.Train).
 		AddTime(etime.Run, ss.Config.NRuns).
 		AddTime(etime.Epoch, ss.Config.NEpochs).
-		AddTime(etime.Trial, ntrn). // change when adding train vs test envs
+		AddTime(etime.Trial, ss.Lines2.Rows). // set to the training patterns in HoldOutPatterns
 		AddTime(etime.Cycle, 100)
 
 	ls.AddStack(etime.Test).
 		AddTime(etime.Epoch, 1).
-		AddTime(etime.Trial, ntst). // change when adding test env
+		AddTime(etime.Trial, ss.Lines2.Rows).
 		AddTime(etime.Cycle, 100)
 
 	leabra.LooperStdPhases(ls, &ss.Context, ss.Net, 75, 99)                // plus phase timing
@@ -387,6 +439,7 @@ func (ss *Sim) ConfigLoops() {
 	}
 
 	ls.Loop(etime.Train, etime.Run).OnStart.Add("NewRun", ss.NewRun)
+	simcore.SeedEpochs(ls, &ss.RandSeeds, ss.Net)
 
 	// Add Testing
 	trainEpoch := ls.Loop(etime.Train, etime.Epoch)
@@ -420,13 +473,21 @@ func (ss *Sim) ConfigLoops() {
 	//		ss.Logs.RunStats("UniqPats")
 	//	})
 
+	// Save weights to file, to look at later
+	ls.Loop(etime.Train, etime.Run).OnEnd.Add("SaveWeights", func() {
+		ctrString := ss.Stats.PrintValues([]string{"Run", "Epoch"}, []string{"%03d", "%05d"}, "_")
+		leabra.SaveWeightsIfConfigSet(ss.Net, ss.Config.Log.SaveWeights, ctrString, ss.Stats.String("RunName"))
+	})
+
 	////////////////////////////////////////////
 	// GUI
 
-	leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
-	leabra.LooperUpdatePlots(ls, &ss.GUI)
-	ls.Stacks[etime.Train].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
-	ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	if ss.Config.GUI {
+		leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
+		leabra.LooperUpdatePlots(ls, &ss.GUI)
+		ls.Stacks[etime.Train].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+		ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	}
 
 	ss.Loops = ls
 }
@@ -449,16 +510,28 @@ func (ss *Sim) ApplyInputs() {
 		out.Type = leabra.TargetLayer
 	}
 
-	lays := net.LayersByType(leabra.InputLayer, leabra.TargetLayer)
-	net.InitExt()
 	ss.Stats.SetString("TrialName", ev.TrialName.Cur)
-	for _, lnm := range lays {
-		ly := ss.Net.LayerByName(lnm)
-		pats := ev.State(ly.Name)
-		if pats != nil {
-			ly.ApplyExt(pats)
-		}
+	if ss.IsHeldOut[ev.Row()] {
+		ss.Stats.SetFloat("HeldOut", 1)
+	} else {
+		ss.Stats.SetFloat("HeldOut", 0)
 	}
+	simcore.ApplyInputs(net, ev)
+}
+
+// HoldOutPatterns holds out the HoldOut proportion of the Lines2 patterns
+// from training in the current run, chosen at random from the seed
+// for the run, and tests on all of them.
+func (ss *Sim) HoldOutPatterns() {
+	run := ss.Loops.Loop(etime.Train, etime.Run).Counter.Cur
+	trnIndexes, held := simcore.HoldOut(ss.Lines2.Rows, ss.HoldOut, randx.NewSysRand(ss.RandSeeds[run]))
+	ss.IsHeldOut = make([]bool, ss.Lines2.Rows)
+	for _, i := range held {
+		ss.IsHeldOut[i] = true
+	}
+	trn := ss.Envs.ByMode(etime.Train).(*env.FixedTable)
+	trn.Table.Indexes = trnIndexes
+	simcore.SetEnvTrials(ss.Loops, ss.Envs)
 }
 
 // NewRun intializes a new run of the model, using the TrainEnv.Run counter
@@ -466,6 +539,7 @@ func (ss *Sim) ApplyInputs() {
 func (ss *Sim) NewRun() {
 	ctx := &ss.Context
 	ss.InitRandSeed(ss.Loops.Loop(etime.Train, etime.Run).Counter.Cur)
+	ss.HoldOutPatterns()
 	ss.Envs.ByMode(etime.Train).Init(0)
 	ss.Envs.ByMode(etime.Test).Init(0)
 	ctx.Reset()
@@ -492,6 +566,8 @@ func (ss *Sim) TestAll() {
 // called at start of new run
 func (ss *Sim) InitStats() {
 	ss.Stats.SetFloat("SSE", 0.0)
+	ss.Stats.SetFloat("HeldOut", 0)
+	ss.Stats.SetFloat("GenPctErr", math.NaN())
 	ss.Stats.SetString("TrialName", "")
 	ss.Logs.InitErrStats() // inits TrlErr, FirstZero, LastZero, NZero
 }
@@ -499,26 +575,11 @@ func (ss *Sim) InitStats() {
 // StatCounters saves current counters to Stats, so they are available for logging etc
 // Also saves a string rep of them for ViewUpdate.Text
 func (ss *Sim) StatCounters() {
-	ctx := &ss.Context
-	mode := ctx.Mode
-	ss.Loops.Stacks[mode].CountersToStats(&ss.Stats)
-	// always use training epoch..
-	trnEpc := ss.Loops.Stacks[etime.Train].Loops[etime.Epoch].Counter.Cur
-	ss.Stats.SetInt("Epoch", trnEpc)
-	trl := ss.Stats.Int("Trial")
-	ss.Stats.SetInt("Trial", trl)
-	ss.Stats.SetInt("Cycle", int(ctx.Cycle))
+	simcore.StatCounters(ss.Loops, &ss.Context, &ss.Stats, true) // always use training epoch
 }
 
 func (ss *Sim) NetViewCounters(tm etime.Times) {
-	if ss.ViewUpdate.View == nil {
-		return
-	}
-	if tm == etime.Trial {
-		ss.TrialStats() // get trial stats for current di
-	}
-	ss.StatCounters()
-	ss.ViewUpdate.Text = ss.Stats.Print([]string{"Run", "Epoch", "Trial", "TrialName", "Cycle", "SSE", "TrlErr"})
+	simcore.NetViewCounters(&ss.ViewUpdate, &ss.Stats, tm, simcore.Hooks{TrialStats: ss.TrialStats, StatCounters: ss.StatCounters}, "Run", "Epoch", "Trial", "TrialName", "Cycle", "SSE", "TrlErr")
 }
 
 // TrialStats computes the trial-level statistics.
@@ -536,6 +597,25 @@ func (ss *Sim) TrialStats() {
 	}
 }
 
+// GenPctErrStat returns the proportion of the test patterns held out of
+// training that had errors, in the given test trial log,
+// or NaN if there were none.
+func (ss *Sim) GenPctErrStat(dt *table.Table) float64 {
+	n := 0
+	nerr := 0.0
+	for row := range dt.Rows {
+		if dt.Float("HeldOut", row) == 0 {
+			continue
+		}
+		n++
+		nerr += dt.Float("Err", row)
+	}
+	if n == 0 {
+		return math.NaN()
+	}
+	return nerr / float64(n)
+}
+
 // UniquePatStat analyzes the hidden activity patterns for the single-line test inputs
 // to determine how many such lines have a distinct hidden pattern, as computed
 // from the similarity matrix across patterns
@@ -567,7 +647,7 @@ func (ss *Sim) UniquePatStat(dt *table.Table) float64 {
 }
 
 func (ss *Sim) HiddenFromInput() {
-	if ss.GUI.Grids == nil {
+	if ss.GUI.Grids == nil && ss.Figures.Grids == nil {
 		return
 	}
 	wg := ss.Stats.F32Tensor("HiddenFromInput")
@@ -585,8 +665,10 @@ func (ss *Sim) HiddenFromInput() {
 			inp.SendPathValues(&vls, "Wt", hid, ui, "")
 		}
 	}
-	gv := ss.GUI.Grid("Weights")
-	gv.Update()
+	if ss.GUI.Grids != nil {
+		gv := ss.GUI.Grid("Weights")
+		gv.Update()
+	}
 }
 
 //////////////////////////////////////////////////////////////////////////////
@@ -605,12 +687,18 @@ func (ss *Sim) ConfigLogs() {
 
 	ss.Logs.AddCopyFromFloatItems(etime.Train, []etime.Times{etime.Epoch, etime.Run}, etime.Test, etime.Epoch, "Tst", "SSE", "AvgSSE")
 
+	// generalization to the held out patterns, as of the last test
+	ss.Logs.AddStatFloatNoAggItem(etime.Test, etime.Trial, "HeldOut")
+	ss.Logs.AddStatFloatNoAggItem(etime.Test, etime.Epoch, "GenPctErr")
+	ss.Logs.AddStatFloatNoAggItem(etime.Train, etime.Epoch, "GenPctErr")
+	ss.Logs.AddStatFloatNoAggItem(etime.Train, etime.Run, "GenPctErr")
+
 	ss.Logs.AddPerTrlMSec("PerTrlMSec", etime.Run, etime.Epoch, etime.Trial)
 
 	ss.Logs.AddLayerTensorItems(ss.Net, "ActM", etime.Test, etime.Trial, "InputLayer", "SuperLayer", "TargetLayer")
 	ss.Logs.AddLayerTensorItems(ss.Net, "Targ", etime.Test, etime.Trial, "TargetLayer")
 
-	ss.Logs.PlotItems("PctErr")
+	ss.Logs.PlotItems("PctErr", "GenPctErr")
 
 	ss.Logs.CreateTables()
 	ss.Logs.SetContext(&ss.Stats, ss.Net)
@@ -641,6 +729,8 @@ func (ss *Sim) Log(mode etime.Modes, time etime.Times) {
 	case time == etime.Trial:
 		ss.TrialStats()
 		ss.StatCounters()
+	case mode == etime.Test && time == etime.Epoch:
+		ss.Stats.SetFloat("GenPctErr", ss.GenPctErrStat(ss.Logs.Table(etime.Test, etime.Trial)))
 	}
 
 	ss.Logs.LogRow(mode, time, row) // also logs to file, etc
@@ -674,16 +764,21 @@ func (ss *Sim) ConfigGUI() {
 	nv.SceneXYZ().Camera.Pose.Pos.Set(0.1, 1.5, 4) // more "head on" than default which is more "top down"
 	nv.SceneXYZ().Camera.LookAt(math32.Vec3(0.1, 0.1, 0), math32.Vec3(0, 1, 0))
 
-	ss.GUI.AddPlots(title, &ss.Logs)
+	ss.ConfigPlots(simcore.GUIPlots{GUI: &ss.GUI})
 
 	ss.GUI.AddTableView(&ss.Logs, etime.Test, etime.Trial)
 
-	wgv := ss.GUI.AddGridTab("Weights")
+	ss.GUI.FinalizeGUI(false)
+}
+
+// ConfigPlots adds the log plots, and the grid of the Weights
+// of each Hidden unit from the Input, to the GUI or the Figures.
+func (ss *Sim) ConfigPlots(pl simcore.Plots) {
+	pl.AddLogPlots("HebbErr_Combo", &ss.Logs)
+
 	wg := ss.Stats.F32Tensor("HiddenFromInput")
 	wg.SetShape([]int{6, 5, 5, 5})
-	wgv.SetTensor(wg)
-
-	ss.GUI.FinalizeGUI(false)
+	pl.AddGrid("Weights", wg)
 }
 
 func (ss *Sim) MakeToolbar(p *tree.Plan) {
@@ -726,3 +821,42 @@ func (ss *Sim) RunGUI() {
 	ss.ConfigGUI()
 	ss.GUI.Body.RunMainWindow()
 }
+
+func (ss *Sim) RunNoGUI() {
+	if ss.Config.Factorial {
+		ss.RunFactorial()
+		return
+	}
+	ss.Runner().RunNoGUI()
+}
+
+// Runner returns the simcore.Runner that runs the sim without the GUI.
+func (ss *Sim) Runner() *simcore.Runner {
+	return &simcore.Runner{Sim: ss, NetName: ss.Net.Name, RunName: ss.Params.RunName,
+		Fields: []string{"Learn", "AvgLGain", "InputNoise", "TrainGi", "TestGi", "HoldOut"},
+		Config: &ss.Config.RunConfig, Run: &ss.Config.Run, NRuns: &ss.Config.NRuns, NEpochs: &ss.Config.NEpochs, Log: &ss.Config.Log,
+		Net: ss.Net, Context: &ss.Context, Loops: ss.Loops, Envs: ss.Envs, Stats: &ss.Stats, Logs: &ss.Logs, Seeds: &ss.RandSeeds,
+		Figures: &ss.Figures, Init: ss.Init, ConfigPlots: ss.ConfigPlots, UpdateFigures: ss.HiddenFromInput}
+}
+
+// RunFactorial runs the Config.Factorial experiment without the GUI,
+// with NRuns runs (random seeds) for each of the LearnType values,
+// and saves the results and their summary.
+func (ss *Sim) RunFactorial() {
+	sw := &simcore.Sweep{Grid: true, Params: []simcore.SweepParam{
+		{Path: "Learn", Values: simcore.EnumNames(LearnTypeValues())},
+	}}
+	rn := ss.Runner()
+	runName := rn.SetRunName()
+	dt, err := sw.Run(ss, rn.Runs)
+	if err == nil {
+		err = simcore.AddCriterionColumns(dt, "FirstZero")
+	}
+	if err == nil {
+		err = simcore.SaveFactorial(dt, []string{"Learn"}, []string{"EpochsToCrit", "Solved", "SSE", "GenPctErr"}, ss.Net.Name, runName)
+	}
+	if err != nil {
+		mpi.Printf("%v\n", err)
+		os.Exit(1)
+	}
+}
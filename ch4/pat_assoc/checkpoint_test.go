@@ -0,0 +1,96 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cogentcore.org/core/tensor/table"
+	"github.com/CompCogNeuro/sims/v2/simcore"
+	"github.com/CompCogNeuro/sims/v2/simcore/simtest"
+	"github.com/emer/emergent/v2/etime"
+)
+
+// TestCheckpoint checks that a Leabra ErrorDriven run resumed from a checkpoint has
+// exactly the same epoch log and final weights as the run without
+// interruption and without checkpoints, with the Impossible patterns,
+// which are never learned, so that it does not stop early.
+// The checkpoint is the second one in the run, at epoch 4 of 5.
+func TestCheckpoint(t *testing.T) {
+	testCheckpoint(t, ErrorDriven)
+}
+
+// TestCheckpointBP is TestCheckpoint with Learn = Backprop,
+// for which the weights of the BPNet must be saved in the checkpoint.
+func TestCheckpointBP(t *testing.T) {
+	testCheckpoint(t, Backprop)
+}
+
+func testCheckpoint(t *testing.T, learn LearnType) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil { // for the checkpoint file
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	run := func(args ...string) (*table.Table, []float32) {
+		simtest.SetArgs(append([]string{"-NEpochs", "5"}, args...)...)
+		sim := &Sim{}
+		sim.New()
+		sim.ConfigAll()
+		sim.Learn = learn
+		sim.Patterns = Impossible
+		if err := sim.Runner().RunStd(); err != nil {
+			t.Fatal(err)
+		}
+		var wts []float32
+		for _, ly := range sim.Net.Layers {
+			for _, pt := range ly.RecvPaths {
+				for i := range pt.Syns {
+					wts = append(wts, pt.Syns[i].Wt)
+				}
+			}
+		}
+		return simcore.GoldenTestTable(sim.Logs.Table(etime.Train, etime.Epoch)), wts
+	}
+	full, fullWts := run()
+	if full.Rows != 5 {
+		t.Fatalf("full run: %d epochs != 5", full.Rows)
+	}
+	run("-Checkpoint", "2")
+	ckpt, _ := filepath.Glob("*_ckpt.gob")
+	if len(ckpt) != 1 {
+		t.Fatalf("checkpoint files: %v", ckpt)
+	}
+	resumed, resumedWts := run("-Resume", ckpt[0])
+	if resumed.Rows != full.Rows {
+		t.Fatalf("resumed run: %d epochs != %d", resumed.Rows, full.Rows)
+	}
+	for ci, cl := range full.Columns {
+		rc := resumed.Columns[ci]
+		for row := range full.Rows {
+			if cl.IsString() {
+				if rc.String1D(row) != cl.String1D(row) {
+					t.Errorf("%s epoch %d: resumed %q != %q", full.ColumnNames[ci], row, rc.String1D(row), cl.String1D(row))
+				}
+				continue
+			}
+			if math.Float64bits(rc.Float1D(row)) != math.Float64bits(cl.Float1D(row)) {
+				t.Errorf("%s epoch %d: resumed %g != %g", full.ColumnNames[ci], row, rc.Float1D(row), cl.Float1D(row))
+			}
+		}
+	}
+	for i, wt := range fullWts {
+		if math.Float32bits(resumedWts[i]) != math.Float32bits(wt) {
+			t.Fatalf("weight %d: resumed %g != %g", i, resumedWts[i], wt)
+		}
+	}
+}
@@ -51,20 +51,20 @@ func (i PatsType) MarshalText() ([]byte, error) { return []byte(i.String()), nil
 // UnmarshalText implements the [encoding.TextUnmarshaler] interface.
 func (i *PatsType) UnmarshalText(text []byte) error { return enums.UnmarshalText(i, text, "PatsType") }
 
-var _LearnTypeValues = []LearnType{0, 1}
+var _LearnTypeValues = []LearnType{0, 1, 2, 3}
 
 // LearnTypeN is the highest valid value for type LearnType, plus one.
 //
 //gosl:start
-const LearnTypeN LearnType = 2
+const LearnTypeN LearnType = 4
 
 //gosl:end
 
-var _LearnTypeValueMap = map[string]LearnType{`Hebbian`: 0, `ErrorDriven`: 1}
+var _LearnTypeValueMap = map[string]LearnType{`Hebbian`: 0, `ErrorDriven`: 1, `Backprop`: 2, `GeneRec`: 3}
 
-var _LearnTypeDescMap = map[LearnType]string{0: ``, 1: ``}
+var _LearnTypeDescMap = map[LearnType]string{0: ``, 1: ``, 2: `Backprop is standard error backpropagation, in a feedforward network with the same layers, instead of Leabra (see simcore.BPNet)`, 3: `GeneRec is GeneRec learning (contrastive Hebbian learning with symmetric weights), in a bidirectional rate-code network with the same layers, instead of Leabra (see simcore.BPNet)`}
 
-var _LearnTypeMap = map[LearnType]string{0: `Hebbian`, 1: `ErrorDriven`}
+var _LearnTypeMap = map[LearnType]string{0: `Hebbian`, 1: `ErrorDriven`, 2: `Backprop`, 3: `GeneRec`}
 
 // String returns the string representation of this LearnType value.
 func (i LearnType) String() string { return enums.String(i, _LearnTypeMap) }
@@ -0,0 +1,79 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cogentcore.org/core/tensor/table"
+	"github.com/CompCogNeuro/sims/v2/simcore"
+	"github.com/CompCogNeuro/sims/v2/simcore/simtest"
+	"github.com/emer/emergent/v2/etime"
+)
+
+func TestMain(m *testing.M) {
+	simtest.Main(m, main)
+}
+
+// TestParallel checks that the Parallel runs, each in its own process,
+// have the same results as the same runs done sequentially, with the
+// Learn field, which is not in the Config, set the same in each process.
+func TestParallel(t *testing.T) {
+	runs := func(parallel bool) *table.Table {
+		simtest.SetArgs("-NRuns", "3")
+		sim := &Sim{}
+		sim.New()
+		sim.ConfigAll()
+		sim.Learn = ErrorDriven
+		rn := sim.Runner()
+		run := rn.RunStd
+		if parallel {
+			run = rn.RunParallel
+		}
+		if err := run(); err != nil {
+			t.Fatal(err)
+		}
+		return simcore.GoldenTable(sim.Logs.Table(etime.Train, etime.Run))
+	}
+	seq := runs(false)
+	if seq.Rows != 3 {
+		t.Fatalf("sequential runs: %d rows != 3", seq.Rows)
+	}
+	if err := simcore.CompareGolden(runs(true), seq); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestParallelCheckpoint checks that each of the Parallel runs saves its
+// own checkpoint file, and that Resume is an error with Parallel.
+func TestParallelCheckpoint(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil { // for the checkpoint files
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	simtest.SetArgs("-NRuns", "2", "-Checkpoint", "1", "-Parallel")
+	sim := &Sim{}
+	sim.New()
+	sim.ConfigAll()
+	rn := sim.Runner()
+	if err := rn.RunParallel(); err != nil {
+		t.Fatal(err)
+	}
+	ckpt, _ := filepath.Glob("*_ckpt.gob")
+	if len(ckpt) != 2 {
+		t.Fatalf("checkpoint files: %v, not one per run", ckpt)
+	}
+	sim.Config.Resume = ckpt[0]
+	if err := rn.RunParallel(); err == nil {
+		t.Error("no error for Resume with Parallel")
+	}
+}
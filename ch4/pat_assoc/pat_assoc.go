@@ -11,15 +11,20 @@ package main
 
 import (
 	"embed"
+	"os"
+	"strings"
 
 	"cogentcore.org/core/base/errors"
+	"cogentcore.org/core/base/mpi"
 	"cogentcore.org/core/base/randx"
 	"cogentcore.org/core/core"
 	"cogentcore.org/core/enums"
 	"cogentcore.org/core/icons"
 	"cogentcore.org/core/math32"
+	"cogentcore.org/core/plot/plotcore"
 	"cogentcore.org/core/tensor/table"
 	"cogentcore.org/core/tree"
+	"github.com/CompCogNeuro/sims/v2/simcore"
 	"github.com/emer/emergent/v2/econfig"
 	"github.com/emer/emergent/v2/egui"
 	"github.com/emer/emergent/v2/elog"
@@ -57,13 +62,31 @@ type LearnType int32 //enums:enum
 const (
 	Hebbian LearnType = iota
 	ErrorDriven
+
+	// Backprop is standard error backpropagation, in a feedforward
+	// network with the same layers, instead of Leabra (see simcore.BPNet)
+	Backprop
+
+	// GeneRec is GeneRec learning (contrastive Hebbian learning with
+	// symmetric weights), in a bidirectional rate-code network with the
+	// same layers, instead of Leabra (see simcore.BPNet)
+	GeneRec
 )
 
+// IsBP returns whether the learning is done by the BPNet instead of Leabra.
+func (lt LearnType) IsBP() bool {
+	return lt == Backprop || lt == GeneRec
+}
+
 func main() {
 	sim := &Sim{}
 	sim.New()
 	sim.ConfigAll()
-	sim.RunGUI()
+	if sim.Config.GUI {
+		sim.RunGUI()
+	} else {
+		sim.RunNoGUI()
+	}
 }
 
 // ParamSets is the default set of parameters.
@@ -102,6 +125,12 @@ var ParamSets = params.Sets{
 				"Path.Learn.XCal.LLrn":    "0",
 			}},
 	},
+	"BPNet": {
+		{Sel: "Path", Desc: "the BPNet learns instead, and the Leabra network just shows its activations",
+			Params: params.Params{
+				"Path.Learn.Learn": "false",
+			}},
+	},
 }
 
 // Config has config parameters related to running the sim
@@ -118,6 +147,62 @@ type Config struct {
 	// how often to run through all the test patterns, in terms of training epochs.
 	// can use 0 or -1 for no testing.
 	TestInterval int `default:"5"`
+
+	// GUI means open the GUI. Otherwise it runs automatically and quits,
+	// saving log files as specified in Log.
+	GUI bool `default:"true"`
+
+	// starting run number, which determines the random seed.
+	// runs count up from there, so all runs can be done in parallel
+	// by launching separate jobs with each starting Run and NRuns = 1.
+	Run int `default:"0"`
+
+	// RunConfig has the config parameters for running without the GUI:
+	// Sweep, Parallel, Checkpoint, Resume, Summary, Figures and Export.
+	simcore.RunConfig `display:"add-fields"`
+
+	// run the factorial experiment without the GUI, instead of the standard runs:
+	// NRuns runs for every combination of the LearnType and PatsType values,
+	// saving the run log rows for all of them to a _factorial.tsv file, and
+	// a summary of the epochs to criterion (EpochsToCrit, only for the runs
+	// that reached it), the proportion of runs that reached it (Solved),
+	// and the final SSE, for each combination to _factorial_summary.tsv
+	// and .md files, with grouped bar plots of each in .svg and .png files.
+	Factorial bool
+
+	// Log has config parameters related to logging data.
+	Log LogConfig `display:"add-fields"`
+}
+
+// LogConfig has config parameters related to logging data
+type LogConfig struct {
+
+	// if true, save final weights after each run
+	SaveWeights bool
+
+	// if true, save train epoch log to file, as .epc.tsv typically
+	Epoch bool `default:"true" nest:"+"`
+
+	// if true, save run log to file, as .run.tsv typically
+	Run bool `default:"true" nest:"+"`
+
+	// if true, save train trial log to file, as .trl.tsv typically. May be large.
+	Trial bool `default:"false" nest:"+"`
+
+	// if true, save testing epoch log to file, as .tst_epc.tsv typically.  In general it is better to copy testing items over to the training epoch log and record there.
+	TestEpoch bool `default:"false" nest:"+"`
+
+	// if true, save testing trial log to file, as .tst_trl.tsv typically. May be large.
+	TestTrial bool `default:"false" nest:"+"`
+
+	// if true, record the weights of every synapse, with their Hebbian and
+	// error-driven components, per training trial, and save them to file,
+	// as _wts.tsv. They are always recorded in the GUI, and for the Figures.
+	Weights bool `default:"false" nest:"+"`
+
+	// if true, record the weights at the end of each training epoch
+	// instead of each trial.
+	WeightsEpoch bool `default:"false" nest:"+"`
 }
 
 // Sim encapsulates the entire simulation model, and we define all the
@@ -139,6 +224,10 @@ type Sim struct {
 	// the network -- click to view / edit parameters for layers, paths, etc
 	Net *leabra.Network `new-window:"+" display:"no-inline"`
 
+	// the network that learns with Backprop or GeneRec instead of the
+	// Leabra network, with the same layers, for those Learn types
+	BP *simcore.BPNet `new-window:"+" display:"no-inline"`
+
 	// network parameter management
 	Params emer.NetParams `display:"add-fields"`
 
@@ -170,6 +259,13 @@ type Sim struct {
 	// manages all the gui elements
 	GUI egui.GUI `display:"-"`
 
+	// records the weights of every synapse per training trial, with the
+	// Hebbian and error-driven components of their changes
+	WtLog simcore.WeightLog `display:"add-fields"`
+
+	// the plots, grids and network snapshots that can be saved without the GUI
+	Figures simcore.Figures `display:"-"`
+
 	// a list of random seeds to use for each run
 	RandSeeds randx.Seeds `display:"-"`
 }
@@ -188,6 +284,8 @@ func (ss *Sim) New() {
 	ss.RandSeeds.Init(100) // max 100 runs
 	ss.InitRandSeed(0)
 	ss.Context.Defaults()
+	ss.WtLog.On = ss.Config.GUI || ss.Config.Log.Weights || ss.Config.Figures != ""
+	ss.WtLog.Epoch = ss.Config.Log.WeightsEpoch
 }
 
 //////////////////////////////////////////////////////////////////////////////
@@ -253,6 +351,7 @@ func (ss *Sim) ConfigNet(net *leabra.Network) {
 	net.ConnectLayers(inp, out, full, leabra.ForwardPath)
 
 	net.Build()
+	ss.BP = errors.Log1(simcore.NewBPNet(net))
 	net.Defaults()
 	ss.ApplyParams()
 	net.InitWeights()
@@ -265,11 +364,21 @@ func (ss *Sim) ApplyParams() {
 		ss.Params.SetAllSheet("Hebbian")
 	case ErrorDriven:
 		ss.Params.SetAllSheet("ErrorDriven")
+	case Backprop, GeneRec:
+		ss.Params.SetAllSheet("BPNet")
 	}
+	ss.BP.GeneRec = ss.Learn == GeneRec
 	if ss.Loops != nil {
 		trn := ss.Loops.Stacks[etime.Train]
 		trn.Loops[etime.Run].Counter.Max = ss.Config.NRuns
 		trn.Loops[etime.Epoch].Counter.Max = ss.Config.NEpochs
+		ncyc := 100
+		if ss.Learn.IsBP() { // the BPNet does the whole trial in BPTrial
+			ncyc = 1
+		}
+		for _, st := range ss.Loops.Stacks {
+			st.Loops[etime.Cycle].Counter.Max = ncyc
+		}
 	}
 }
 
@@ -279,7 +388,9 @@ func (ss *Sim) ApplyParams() {
 // Init restarts the run, and initializes everything, including network weights
 // and resets the epoch log table
 func (ss *Sim) Init() {
-	ss.Stats.SetString("RunName", ss.Params.RunName(0)) // in case user interactively changes tag
+	if ss.Config.GUI {
+		ss.Stats.SetString("RunName", ss.Params.RunName(0)) // in case user interactively changes tag
+	}
 	ss.Loops.ResetCounters()
 	ss.InitRandSeed(0)
 	ss.ConfigEnv() // re-config env just in case a different set of patterns was
@@ -292,8 +403,7 @@ func (ss *Sim) Init() {
 
 // InitRandSeed initializes the random seed based on current training run number
 func (ss *Sim) InitRandSeed(run int) {
-	ss.RandSeeds.Set(run)
-	ss.RandSeeds.Set(run, &ss.Net.Rand)
+	simcore.InitRandSeed(&ss.RandSeeds, ss.Net, run)
 }
 
 // ConfigLoops configures the control loops: Training, Testing
@@ -320,12 +430,28 @@ func (ss *Sim) ConfigLoops() {
 
 	for m, _ := range ls.Stacks {
 		stack := ls.Stacks[m]
+		// the Leabra network is not cycled when the BPNet learns instead
+		stack.Loops[etime.Cycle].OnStart.Replace("Cycle", func() bool {
+			if !ss.Learn.IsBP() {
+				ss.Net.Cycle(&ss.Context)
+			}
+			ss.Context.CycleInc()
+			return true
+		})
 		stack.Loops[etime.Trial].OnStart.Add("ApplyInputs", func() {
 			ss.ApplyInputs()
 		})
+		stack.Loops[etime.Trial].OnEnd.Add("BPTrial", func() {
+			if ss.Learn.IsBP() {
+				ss.BP.Trial(ss.Context.Mode == etime.Train)
+			}
+		})
 	}
 
 	ls.Loop(etime.Train, etime.Run).OnStart.Add("NewRun", ss.NewRun)
+	simcore.SeedEpochs(ls, &ss.RandSeeds, ss.Net)
+
+	errors.Log(ss.WtLog.Config(ss.Net, &ss.Stats, ss.Logs.MiscTable("Weights"), ls))
 
 	// Train stop early condition
 	ls.Loop(etime.Train, etime.Epoch).IsDone.AddBool("NZeroStop", func() bool {
@@ -362,13 +488,25 @@ func (ss *Sim) ConfigLoops() {
 		ss.Logs.RunStats("PctCor", "FirstZero", "LastZero")
 	})
 
+	// Save weights to file, to look at later
+	ls.Loop(etime.Train, etime.Run).OnEnd.Add("SaveWeights", func() {
+		ctrString := ss.Stats.PrintValues([]string{"Run", "Epoch"}, []string{"%03d", "%05d"}, "_")
+		leabra.SaveWeightsIfConfigSet(ss.Net, ss.Config.Log.SaveWeights, ctrString, ss.Stats.String("RunName"))
+	})
+
 	////////////////////////////////////////////
 	// GUI
 
-	leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
-	leabra.LooperUpdatePlots(ls, &ss.GUI)
-	ls.Stacks[etime.Train].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
-	ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	if ss.Config.GUI {
+		leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
+		leabra.LooperUpdatePlots(ls, &ss.GUI)
+		ls.Stacks[etime.Train].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+		ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+		ls.Loop(etime.Train, etime.Epoch).OnEnd.Add("WeightPlots", func() {
+			ss.GUI.PlotByName("Weights").GoUpdatePlot()
+			ss.GUI.PlotByName("DWt").GoUpdatePlot()
+		})
+	}
 
 	ss.Loops = ls
 }
@@ -390,16 +528,8 @@ func (ss *Sim) ApplyInputs() {
 		out.Type = leabra.TargetLayer
 	}
 
-	lays := net.LayersByType(leabra.InputLayer, leabra.TargetLayer)
-	net.InitExt()
 	ss.Stats.SetString("TrialName", ev.TrialName.Cur)
-	for _, lnm := range lays {
-		ly := ss.Net.LayerByName(lnm)
-		pats := ev.State(ly.Name)
-		if pats != nil {
-			ly.ApplyExt(pats)
-		}
-	}
+	simcore.ApplyInputs(net, ev)
 }
 
 func (ss *Sim) UpdateEnv() {
@@ -416,6 +546,9 @@ func (ss *Sim) UpdateEnv() {
 		trn.Table = table.NewIndexView(ss.Impossible)
 		tst.Table = table.NewIndexView(ss.Impossible)
 	}
+	if ss.Loops != nil { // edited patterns can have a different number
+		simcore.SetEnvTrials(ss.Loops, ss.Envs)
+	}
 }
 
 // NewRun intializes a new run of the model, using the TrainEnv.Run counter
@@ -429,6 +562,8 @@ func (ss *Sim) NewRun() {
 	ctx.Reset()
 	ctx.Mode = etime.Train
 	ss.Net.InitWeights()
+	ss.BP.InitWeights(&ss.Net.Rand)
+	ss.WtLog.Reset()
 	ss.InitStats()
 	ss.StatCounters()
 	ss.Logs.ResetLog(etime.Train, etime.Epoch)
@@ -456,26 +591,11 @@ func (ss *Sim) InitStats() {
 // StatCounters saves current counters to Stats, so they are available for logging etc
 // Also saves a string rep of them for ViewUpdate.Text
 func (ss *Sim) StatCounters() {
-	ctx := &ss.Context
-	mode := ctx.Mode
-	ss.Loops.Stacks[mode].CountersToStats(&ss.Stats)
-	// always use training epoch..
-	trnEpc := ss.Loops.Stacks[etime.Train].Loops[etime.Epoch].Counter.Cur
-	ss.Stats.SetInt("Epoch", trnEpc)
-	trl := ss.Stats.Int("Trial")
-	ss.Stats.SetInt("Trial", trl)
-	ss.Stats.SetInt("Cycle", int(ctx.Cycle))
+	simcore.StatCounters(ss.Loops, &ss.Context, &ss.Stats, true) // always use training epoch
 }
 
 func (ss *Sim) NetViewCounters(tm etime.Times) {
-	if ss.ViewUpdate.View == nil {
-		return
-	}
-	if tm == etime.Trial {
-		ss.TrialStats() // get trial stats for current di
-	}
-	ss.StatCounters()
-	ss.ViewUpdate.Text = ss.Stats.Print([]string{"Run", "Epoch", "Trial", "TrialName", "Cycle", "SSE", "TrlErr"})
+	simcore.NetViewCounters(&ss.ViewUpdate, &ss.Stats, tm, simcore.Hooks{TrialStats: ss.TrialStats, StatCounters: ss.StatCounters}, "Run", "Epoch", "Trial", "TrialName", "Cycle", "SSE", "TrlErr")
 }
 
 // TrialStats computes the trial-level statistics.
@@ -528,26 +648,7 @@ func (ss *Sim) ConfigLogs() {
 
 // Log is the main logging function, handles special things for different scopes
 func (ss *Sim) Log(mode etime.Modes, time etime.Times) {
-	ctx := &ss.Context
-	if mode != etime.Analyze {
-		ctx.Mode = mode // Also set specifically in a Loop callback.
-	}
-	dt := ss.Logs.Table(mode, time)
-	if dt == nil {
-		return
-	}
-	row := dt.Rows
-
-	switch {
-	case time == etime.Cycle:
-		return
-	case time == etime.Trial:
-		ss.TrialStats()
-		ss.StatCounters()
-	}
-
-	ss.Logs.LogRow(mode, time, row) // also logs to file, etc
-
+	simcore.Log(&ss.Context, &ss.Logs, mode, time, simcore.Hooks{TrialStats: ss.TrialStats, StatCounters: ss.StatCounters})
 	if mode == etime.Test {
 		ss.GUI.UpdateTableView(etime.Test, etime.Trial)
 	}
@@ -574,13 +675,47 @@ func (ss *Sim) ConfigGUI() {
 	nv.SceneXYZ().Camera.Pose.Pos.Set(0.1, 1.5, 4) // more "head on" than default which is more "top down"
 	nv.SceneXYZ().Camera.LookAt(math32.Vec3(0.1, 0.1, 0), math32.Vec3(0, 1, 0))
 
-	ss.GUI.AddPlots(title, &ss.Logs)
+	ss.ConfigPlots(simcore.GUIPlots{GUI: &ss.GUI})
 
 	ss.GUI.AddTableView(&ss.Logs, etime.Test, etime.Trial)
 
 	ss.GUI.FinalizeGUI(false)
 }
 
+// ConfigPlots adds the log plots, and the plots of the Weights and of
+// their Hebbian and error-driven DWt components from the WtLog, to the GUI
+// or the Figures.
+func (ss *Sim) ConfigPlots(pl simcore.Plots) {
+	pl.AddLogPlots("Pat Assoc", &ss.Logs)
+
+	ss.ConfigWeightsPlot(pl.AddPlot("Weights"))
+	ss.ConfigDWtPlot(pl.AddPlot("DWt"))
+}
+
+// ConfigWeightsPlot configures the plot of the trajectory of each synaptic
+// weight over the training trials of the current run, from the WtLog.
+func (ss *Sim) ConfigWeightsPlot(plt *plotcore.PlotEditor) {
+	plt.Options.Title = "Weight Trajectories"
+	plt.Options.XAxis = "Time"
+	plt.Options.Legend = "Syn"
+	plt.SetTable(ss.WtLog.Table)
+	plt.SetColumnOptions("Epoch", plotcore.Off, plotcore.FloatMin, 0, plotcore.FloatMax, 0)
+	plt.SetColumnOptions("Wt", plotcore.On, plotcore.FixMin, 0, plotcore.FixMax, 1)
+}
+
+// ConfigDWtPlot configures the plot of the cumulative Hebbian and
+// error-driven components of the change in each synaptic weight
+// over the training trials of the current run, from the WtLog.
+func (ss *Sim) ConfigDWtPlot(plt *plotcore.PlotEditor) {
+	plt.Options.Title = "Weight Changes: Hebbian vs. Error-Driven"
+	plt.Options.XAxis = "Time"
+	plt.Options.Legend = "Syn"
+	plt.SetTable(ss.WtLog.Table)
+	plt.SetColumnOptions("Epoch", plotcore.Off, plotcore.FloatMin, 0, plotcore.FloatMax, 0)
+	plt.SetColumnOptions("CumHebb", plotcore.On, plotcore.FloatMin, 0, plotcore.FloatMax, 0)
+	plt.SetColumnOptions("CumErr", plotcore.On, plotcore.FloatMin, 0, plotcore.FloatMax, 0)
+}
+
 func (ss *Sim) MakeToolbar(p *tree.Plan) {
 	ss.GUI.AddLooperCtrl(p, ss.Loops)
 
@@ -604,6 +739,16 @@ func (ss *Sim) MakeToolbar(p *tree.Plan) {
 			ss.RandSeeds.NewSeeds()
 		},
 	})
+	ss.GUI.AddToolbarItem(p, egui.ToolbarItem{Label: "Edit Patterns",
+		Icon:    icons.Edit,
+		Tooltip: "Opens an editor for the current Patterns, where you can click and drag on the units to draw new patterns, add, delete and name them, and save them to a .tsv file.",
+		Active:  egui.ActiveStopped,
+		Func: func() {
+			ss.UpdateEnv()
+			simcore.EditEnvPatterns(ss.GUI.Body, ss.Loops, ss.Envs, etime.Train, strings.ToLower(ss.Patterns.String())+".tsv")
+		},
+	})
+	tree.Add(p, func(w *core.Separator) {})
 	ss.GUI.AddToolbarItem(p, egui.ToolbarItem{Label: "README",
 		Icon:    icons.FileMarkdown,
 		Tooltip: "Opens your browser on the README file that contains instructions for how to run this model.",
@@ -619,3 +764,44 @@ func (ss *Sim) RunGUI() {
 	ss.ConfigGUI()
 	ss.GUI.Body.RunMainWindow()
 }
+
+func (ss *Sim) RunNoGUI() {
+	if ss.Config.Factorial {
+		ss.RunFactorial()
+		return
+	}
+	ss.Runner().RunNoGUI()
+}
+
+// Runner returns the simcore.Runner that runs the sim without the GUI.
+func (ss *Sim) Runner() *simcore.Runner {
+	return &simcore.Runner{Sim: ss, NetName: ss.Net.Name, RunName: ss.Params.RunName,
+		Fields: []string{"Learn", "Patterns"},
+		Config: &ss.Config.RunConfig, Run: &ss.Config.Run, NRuns: &ss.Config.NRuns, NEpochs: &ss.Config.NEpochs, Log: &ss.Config.Log,
+		Net: ss.Net, Context: &ss.Context, Loops: ss.Loops, Envs: ss.Envs, Stats: &ss.Stats, Logs: &ss.Logs, Seeds: &ss.RandSeeds,
+		WtLog: &ss.WtLog, Figures: &ss.Figures, Init: ss.Init, ConfigPlots: ss.ConfigPlots,
+		Extra: ss.BP.State, SetExtra: ss.BP.SetState}
+}
+
+// RunFactorial runs the Config.Factorial experiment without the GUI,
+// with NRuns runs (random seeds) for every combination of the LearnType
+// and PatsType values, and saves the results and their summary.
+func (ss *Sim) RunFactorial() {
+	sw := &simcore.Sweep{Grid: true, Params: []simcore.SweepParam{
+		{Path: "Learn", Values: simcore.EnumNames(LearnTypeValues())},
+		{Path: "Patterns", Values: simcore.EnumNames(PatsTypeValues())},
+	}}
+	rn := ss.Runner()
+	runName := rn.SetRunName()
+	dt, err := sw.Run(ss, rn.Runs)
+	if err == nil {
+		err = simcore.AddCriterionColumns(dt, "FirstZero")
+	}
+	if err == nil {
+		err = simcore.SaveFactorial(dt, []string{"Learn", "Patterns"}, []string{"EpochsToCrit", "Solved", "SSE"}, ss.Net.Name, runName)
+	}
+	if err != nil {
+		mpi.Printf("%v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/CompCogNeuro/sims/v2/simcore"
+	"github.com/CompCogNeuro/sims/v2/simcore/simtest"
+)
+
+// TestSweepNEpochs checks that a Sweep of Config.NEpochs runs the swept
+// number of epochs at each point, with the Impossible patterns, which
+// are never learned, so that the runs do not stop early.
+func TestSweepNEpochs(t *testing.T) {
+	simtest.SetArgs()
+	sim := &Sim{}
+	sim.New()
+	sim.ConfigAll()
+	sim.Patterns = Impossible
+	sw, err := simcore.ParseSweep("Config.NEpochs=2,4", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dt, err := sw.Run(sim, sim.Runner().Runs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dt.Rows != 2 {
+		t.Fatalf("sweep: %d rows != 2", dt.Rows)
+	}
+	for i := range dt.Rows {
+		nepc := dt.Float("Config.NEpochs", i)
+		if epc := dt.Float("Epoch", i) + 1; epc != nepc { // Epoch is the last one, from 0
+			t.Errorf("sweep point %d: %g epochs != Config.NEpochs %g", i, epc, nepc)
+		}
+	}
+}
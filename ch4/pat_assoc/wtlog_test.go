@@ -0,0 +1,72 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/CompCogNeuro/sims/v2/simcore/simtest"
+	"github.com/emer/emergent/v2/etime"
+)
+
+// TestWeightLog checks that the Hebbian and error-driven components of
+// the weight changes recorded by the WtLog add up to the actual change
+// in the linear weights, with each kind of learning, and with the
+// Norm and Momentum learning factors on as well as off, using the
+// Impossible patterns, which are never learned, so that the weights
+// keep changing.
+func TestWeightLog(t *testing.T) {
+	tests := []struct {
+		name         string
+		learn        LearnType
+		normMomentum bool
+	}{
+		{"Hebbian", Hebbian, false},
+		{"ErrorDriven", ErrorDriven, false},
+		{"ErrorDriven Norm Momentum", ErrorDriven, true},
+	}
+	for _, tt := range tests {
+		simtest.SetArgs("-NEpochs", "3")
+		sim := &Sim{}
+		sim.New()
+		sim.ConfigAll()
+		sim.Learn = tt.learn
+		sim.Patterns = Impossible
+		sim.WtLog.On = true
+		sim.Init()
+		if tt.normMomentum {
+			for _, ly := range sim.Net.Layers {
+				for _, pt := range ly.RecvPaths {
+					pt.Learn.Norm.On = true
+					pt.Learn.Momentum.On = true
+				}
+			}
+		}
+		sim.Loops.Run(etime.Train)
+		dt := sim.WtLog.Table
+		if dt.Rows == 0 {
+			t.Fatalf("%s: no weights recorded", tt.name)
+		}
+		nchange := 0
+		for row := range dt.Rows {
+			lwt := dt.Float("LWt", row)
+			if lwt <= 0 || lwt >= 1 { // clipped
+				continue
+			}
+			dwt := dt.Float("DWt", row)
+			if dwt != 0 {
+				nchange++
+			}
+			if sum := dt.Float("DWtHebb", row) + dt.Float("DWtErr", row); math.Abs(sum-dwt) > 1.0e-6 {
+				t.Errorf("%s: row %d %s: DWtHebb + DWtErr = %g != DWt %g", tt.name, row, dt.StringValue("Syn", row), sum, dwt)
+				break
+			}
+		}
+		if nchange == 0 {
+			t.Errorf("%s: the weights did not change", tt.name)
+		}
+	}
+}
@@ -10,7 +10,9 @@ package main
 //go:generate core generate -add-types
 
 import (
-	"embed"
+	"math"
+	"slices"
+	"strings"
 
 	"cogentcore.org/core/base/errors"
 	"cogentcore.org/core/base/randx"
@@ -22,6 +24,7 @@ import (
 	"cogentcore.org/core/tensor/stats/norm"
 	"cogentcore.org/core/tensor/table"
 	"cogentcore.org/core/tree"
+	"github.com/CompCogNeuro/sims/v2/simcore"
 	"github.com/emer/emergent/v2/econfig"
 	"github.com/emer/emergent/v2/egui"
 	"github.com/emer/emergent/v2/elog"
@@ -36,14 +39,15 @@ import (
 	"github.com/emer/leabra/v2/leabra"
 )
 
-//go:embed lines_5x5x1.tsv lines_5x5x2.tsv
-var content embed.FS
-
 func main() {
 	sim := &Sim{}
 	sim.New()
 	sim.ConfigAll()
-	sim.RunGUI()
+	if sim.Config.GUI {
+		sim.RunGUI()
+	} else {
+		sim.RunNoGUI()
+	}
 }
 
 // ParamSets is the default set of parameters.
@@ -98,6 +102,44 @@ type Config struct {
 	// how often to run through all the test patterns, in terms of training epochs.
 	// can use 0 or -1 for no testing.
 	TestInterval int `default:"1"`
+
+	// GUI means open the GUI. Otherwise it runs automatically and quits,
+	// saving log files as specified in Log.
+	GUI bool `default:"true"`
+
+	// starting run number, which determines the random seed.
+	// runs count up from there, so all runs can be done in parallel
+	// by launching separate jobs with each starting Run and NRuns = 1.
+	Run int `default:"0"`
+
+	// RunConfig has the config parameters for running without the GUI:
+	// Sweep, Parallel, Checkpoint, Resume, Summary, Figures and Export.
+	simcore.RunConfig `display:"add-fields"`
+
+	// Log has config parameters related to logging data.
+	Log LogConfig `display:"add-fields"`
+}
+
+// LogConfig has config parameters related to logging data
+type LogConfig struct {
+
+	// if true, save final weights after each run
+	SaveWeights bool
+
+	// if true, save train epoch log to file, as .epc.tsv typically
+	Epoch bool `default:"true" nest:"+"`
+
+	// if true, save run log to file, as .run.tsv typically
+	Run bool `default:"true" nest:"+"`
+
+	// if true, save train trial log to file, as .trl.tsv typically. May be large.
+	Trial bool `default:"false" nest:"+"`
+
+	// if true, save testing epoch log to file, as .tst_epc.tsv typically.  In general it is better to copy testing items over to the training epoch log and record there.
+	TestEpoch bool `default:"false" nest:"+"`
+
+	// if true, save testing trial log to file, as .tst_trl.tsv typically. May be large.
+	TestTrial bool `default:"false" nest:"+"`
 }
 
 // Sim encapsulates the entire simulation model, and we define all the
@@ -121,6 +163,13 @@ type Sim struct {
 	// higher because fewer neurons should be active.
 	TestGi float32 `min:"0" step:"0.1" default:"2.5"`
 
+	// proportion of the two-line combinations held out of training, chosen
+	// at random for each run (leaving at least one to train on), to test
+	// generalization to novel combinations of lines: they are tested along
+	// with the single lines, and GenSim is the similarity of their hidden
+	// patterns to those of their two lines.
+	HoldOut float32 `min:"0" max:"1" step:"0.05" default:"0"`
+
 	// Config contains misc configuration parameters for running the sim
 	Config Config `new-window:"+" display:"no-inline"`
 
@@ -135,6 +184,10 @@ type Sim struct {
 	// 1 active lines for testing
 	Lines1 *table.Table `new-window:"+" display:"no-inline"`
 
+	// the Lines1 patterns followed by the Lines2 patterns held out of
+	// training in the current run, for testing
+	TestLines *table.Table `display:"-"`
+
 	// contains looper control loops for running sim
 	Loops *looper.Stacks `new-window:"+" display:"no-inline"`
 
@@ -156,6 +209,9 @@ type Sim struct {
 	// manages all the gui elements
 	GUI egui.GUI `display:"-"`
 
+	// the plots, grids and network snapshots that can be saved without the GUI
+	Figures simcore.Figures `display:"-"`
+
 	// a list of random seeds to use for each run
 	RandSeeds randx.Seeds `display:"-"`
 }
@@ -166,8 +222,6 @@ func (ss *Sim) New() {
 	ss.Net = leabra.NewNetwork("SelfOrg")
 	ss.Params.Config(ParamSets, "", "", ss.Net)
 	ss.Stats.Init()
-	ss.Lines2 = &table.Table{}
-	ss.Lines1 = &table.Table{}
 	ss.RandSeeds.Init(100) // max 100 runs
 	ss.InitRandSeed(0)
 	ss.Context.Defaults()
@@ -179,6 +233,7 @@ func (ss *Sim) Defaults() {
 	ss.InputNoise = 0
 	ss.TrainGi = 1.8
 	ss.TestGi = 2.5
+	ss.HoldOut = 0
 }
 
 //////////////////////////////////////////////////////////////////////////////
@@ -194,12 +249,11 @@ func (ss *Sim) ConfigAll() {
 }
 
 func (ss *Sim) OpenPatterns() {
-	ss.Lines2.SetMetaData("name", "Lines2")
+	ss.Lines2 = simcore.LinesTable("Lines2", 5, 2, false)
 	ss.Lines2.SetMetaData("desc", "2 lines active Training patterns")
-	errors.Log(ss.Lines2.OpenFS(content, "lines_5x5x2.tsv", table.Tab))
-	ss.Lines1.SetMetaData("name", "Lines1")
+	ss.Lines1 = simcore.LinesTable("Lines1", 5, 1, false)
 	ss.Lines1.SetMetaData("desc", "Lines1 Training patterns")
-	errors.Log(ss.Lines1.OpenFS(content, "lines_5x5x1.tsv", table.Tab))
+	ss.TestLines = ss.Lines1
 }
 
 func (ss *Sim) ConfigEnv() {
@@ -214,12 +268,13 @@ func (ss *Sim) ConfigEnv() {
 	}
 
 	// note: names must be standard here!
+	// the held out patterns are set for each run in HoldOutPatterns
 	trn.Name = etime.Train.String()
 	trn.Config(table.NewIndexView(ss.Lines2))
 	trn.Validate()
 
 	tst.Name = etime.Test.String()
-	tst.Config(table.NewIndexView(ss.Lines1))
+	tst.Config(table.NewIndexView(ss.TestLines))
 	tst.Sequential = true
 	tst.Validate()
 
@@ -270,7 +325,9 @@ func (ss *Sim) ApplyParams() {
 // Init restarts the run, and initializes everything, including network weights
 // and resets the epoch log table
 func (ss *Sim) Init() {
-	ss.Stats.SetString("RunName", ss.Params.RunName(0)) // in case user interactively changes tag
+	if ss.Config.GUI {
+		ss.Stats.SetString("RunName", ss.Params.RunName(0)) // in case user interactively changes tag
+	}
 	ss.Loops.ResetCounters()
 	ss.InitRandSeed(0)
 	ss.ConfigEnv() // re-config env just in case a different set of patterns was
@@ -283,8 +340,7 @@ func (ss *Sim) Init() {
 
 // InitRandSeed initializes the random seed based on current training run number
 func (ss *Sim) InitRandSeed(run int) {
-	ss.RandSeeds.Set(run)
-	ss.RandSeeds.Set(run, &ss.Net.Rand)
+	simcore.InitRandSeed(&ss.RandSeeds, ss.Net, run)
 }
 
 // ConfigLoops configures the control loops: Training, Testing
@@ -294,7 +350,7 @@ func (ss *Sim) ConfigLoops() {
 	ls.AddStack(etime.Train).
 		AddTime(etime.Run, ss.Config.NRuns).
 		AddTime(etime.Epoch, ss.Config.NEpochs).
-		AddTime(etime.Trial, ss.Lines2.Rows).
+		AddTime(etime.Trial, ss.Lines2.Rows). // set to the patterns in HoldOutPatterns
 		AddTime(etime.Cycle, 100)
 
 	ls.AddStack(etime.Test).
@@ -314,6 +370,7 @@ func (ss *Sim) ConfigLoops() {
 	}
 
 	ls.Loop(etime.Train, etime.Run).OnStart.Add("NewRun", ss.NewRun)
+	simcore.SeedEpochs(ls, &ss.RandSeeds, ss.Net)
 
 	// Add Testing
 	trainEpoch := ls.Loop(etime.Train, etime.Epoch)
@@ -335,13 +392,21 @@ func (ss *Sim) ConfigLoops() {
 		ss.Logs.RunStats("UniqPats")
 	})
 
+	// Save weights to file, to look at later
+	ls.Loop(etime.Train, etime.Run).OnEnd.Add("SaveWeights", func() {
+		ctrString := ss.Stats.PrintValues([]string{"Run", "Epoch"}, []string{"%03d", "%05d"}, "_")
+		leabra.SaveWeightsIfConfigSet(ss.Net, ss.Config.Log.SaveWeights, ctrString, ss.Stats.String("RunName"))
+	})
+
 	////////////////////////////////////////////
 	// GUI
 
-	leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
-	leabra.LooperUpdatePlots(ls, &ss.GUI)
-	ls.Stacks[etime.Train].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
-	ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	if ss.Config.GUI {
+		leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
+		leabra.LooperUpdatePlots(ls, &ss.GUI)
+		ls.Stacks[etime.Train].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+		ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	}
 
 	ss.Loops = ls
 }
@@ -354,15 +419,21 @@ func (ss *Sim) ApplyInputs() {
 	ctx := &ss.Context
 	net := ss.Net
 	ev := ss.Envs.ByMode(ctx.Mode).(*env.FixedTable)
-	if ctx.Mode == etime.Train {
+	ev.Step()
+	heldOut := ctx.Mode == etime.Test && ev.Row() >= ss.Lines1.Rows
+	if ctx.Mode == etime.Train || heldOut { // two lines
 		ss.Params.SetAllSheet("Hidden2Act")
 	} else {
 		ss.Params.SetAllSheet("Hidden1Act")
 	}
-	ev.Step()
 	lays := net.LayersByType(leabra.InputLayer)
 	net.InitExt()
 	ss.Stats.SetString("TrialName", ev.TrialName.Cur)
+	if heldOut {
+		ss.Stats.SetFloat("HeldOut", 1)
+	} else {
+		ss.Stats.SetFloat("HeldOut", 0)
+	}
 	for _, lnm := range lays {
 		ly := ss.Net.LayerByName(lnm)
 		pats := ev.State(ly.Name)
@@ -372,11 +443,31 @@ func (ss *Sim) ApplyInputs() {
 	}
 }
 
+// HoldOutPatterns holds out the HoldOut proportion of the Lines2 patterns
+// from training in the current run, chosen at random from the seed
+// for the run, and tests on them after the Lines1 patterns.
+func (ss *Sim) HoldOutPatterns() {
+	run := ss.Loops.Loop(etime.Train, etime.Run).Counter.Cur
+	trnIndexes, held := simcore.HoldOut(ss.Lines2.Rows, ss.HoldOut, randx.NewSysRand(ss.RandSeeds[run]))
+	ss.TestLines = ss.Lines1.Clone()
+	ss.TestLines.SetMetaData("name", "TestLines")
+	ss.TestLines.SetMetaData("desc", "Lines1 and held out Lines2 Testing patterns")
+	hix := table.NewIndexView(ss.Lines2)
+	hix.Indexes = held
+	ss.TestLines.AppendRows(hix.NewTable())
+	trn := ss.Envs.ByMode(etime.Train).(*env.FixedTable)
+	trn.Table.Indexes = trnIndexes
+	tst := ss.Envs.ByMode(etime.Test).(*env.FixedTable)
+	tst.Table = table.NewIndexView(ss.TestLines)
+	simcore.SetEnvTrials(ss.Loops, ss.Envs)
+}
+
 // NewRun intializes a new run of the model, using the TrainEnv.Run counter
 // for the new run value
 func (ss *Sim) NewRun() {
 	ctx := &ss.Context
 	ss.InitRandSeed(ss.Loops.Loop(etime.Train, etime.Run).Counter.Cur)
+	ss.HoldOutPatterns()
 	ss.Envs.ByMode(etime.Train).Init(0)
 	ss.Envs.ByMode(etime.Test).Init(0)
 	ctx.Reset()
@@ -403,6 +494,8 @@ func (ss *Sim) TestAll() {
 // called at start of new run
 func (ss *Sim) InitStats() {
 	ss.Stats.SetFloat("UniqPats", 0.0)
+	ss.Stats.SetFloat("HeldOut", 0)
+	ss.Stats.SetFloat("GenSim", math.NaN())
 	ss.Stats.SetString("TrialName", "")
 	ss.Logs.InitErrStats() // inits TrlErr, FirstZero, LastZero, NZero
 }
@@ -410,15 +503,7 @@ func (ss *Sim) InitStats() {
 // StatCounters saves current counters to Stats, so they are available for logging etc
 // Also saves a string rep of them for ViewUpdate.Text
 func (ss *Sim) StatCounters() {
-	ctx := &ss.Context
-	mode := ctx.Mode
-	ss.Loops.Stacks[mode].CountersToStats(&ss.Stats)
-	// always use training epoch..
-	trnEpc := ss.Loops.Stacks[etime.Train].Loops[etime.Epoch].Counter.Cur
-	ss.Stats.SetInt("Epoch", trnEpc)
-	trl := ss.Stats.Int("Trial")
-	ss.Stats.SetInt("Trial", trl)
-	ss.Stats.SetInt("Cycle", int(ctx.Cycle))
+	simcore.StatCounters(ss.Loops, &ss.Context, &ss.Stats, true) // always use training epoch
 }
 
 func (ss *Sim) NetViewCounters(tm etime.Times) {
@@ -450,6 +535,9 @@ func (ss *Sim) UniquePatStat(dt *table.Table) float64 {
 	hc := errors.Log1(dt.ColumnByName("Hidden_Act")).(*tensor.Float32)
 	norm.Binarize32(hc.Values, .5, 1, 0)
 	ix := table.NewIndexView(dt)
+	ix.Filter(func(et *table.Table, row int) bool { // just the single lines
+		return et.Float("HeldOut", row) == 0
+	})
 	sm := ss.Stats.SimMat("UniqPats")
 	sm.TableColumn(ix, "Hidden_Act", "TrialName", false, metric.SumSquares64)
 	dm := sm.Mat
@@ -470,8 +558,49 @@ func (ss *Sim) UniquePatStat(dt *table.Table) float64 {
 	return float64(uniq)
 }
 
+// GenSimStat returns the mean cosine similarity of the binarized hidden
+// activity pattern for each of the held out two-line test inputs, in the
+// given test trial log, to the combination of the patterns for its two
+// single lines, which is 1 if the network represents the novel combinations
+// in terms of the lines that make them up, or NaN if there were none.
+func (ss *Sim) GenSimStat(dt *table.Table) float64 {
+	hc := errors.Log1(dt.ColumnByName("Hidden_Act")).(*tensor.Float32)
+	pat := func(row int) []float32 {
+		vals := slices.Clone(hc.SubSpace([]int{row}).(*tensor.Float32).Values)
+		norm.Binarize32(vals, .5, 1, 0)
+		return vals
+	}
+	lines := map[string]int{}
+	for row := range dt.Rows {
+		if dt.Float("HeldOut", row) == 0 {
+			lines[dt.StringValue("TrialName", row)] = row
+		}
+	}
+	n := 0
+	sum := 0.0
+	for row := range dt.Rows {
+		if dt.Float("HeldOut", row) == 0 {
+			continue
+		}
+		comb := make([]float32, hc.SubSpace([]int{row}).Len())
+		for _, ln := range strings.Split(dt.StringValue("TrialName", row), "_") {
+			if lr, ok := lines[ln]; ok {
+				for i, v := range pat(lr) {
+					comb[i] = max(comb[i], v)
+				}
+			}
+		}
+		sum += float64(metric.Cosine32(pat(row), comb))
+		n++
+	}
+	if n == 0 {
+		return math.NaN()
+	}
+	return sum / float64(n)
+}
+
 func (ss *Sim) HiddenFromInput() {
-	if ss.GUI.Grids == nil {
+	if ss.GUI.Grids == nil && ss.Figures.Grids == nil {
 		return
 	}
 	wg := ss.Stats.F32Tensor("HiddenFromInput")
@@ -489,8 +618,10 @@ func (ss *Sim) HiddenFromInput() {
 			inp.SendPathValues(&vls, "Wt", hid, ui, "")
 		}
 	}
-	gv := ss.GUI.Grid("Weights")
-	gv.Update()
+	if ss.GUI.Grids != nil {
+		gv := ss.GUI.Grid("Weights")
+		gv.Update()
+	}
 }
 
 //////////////////////////////////////////////////////////////////////////////
@@ -505,11 +636,17 @@ func (ss *Sim) ConfigLogs() {
 
 	ss.Logs.AddStatAggItem("UniqPats", etime.Run, etime.Epoch, etime.Trial)
 
+	// generalization to the held out patterns, as of the last test
+	ss.Logs.AddStatFloatNoAggItem(etime.Test, etime.Trial, "HeldOut")
+	ss.Logs.AddStatFloatNoAggItem(etime.Test, etime.Epoch, "GenSim")
+	ss.Logs.AddStatFloatNoAggItem(etime.Train, etime.Epoch, "GenSim")
+	ss.Logs.AddStatFloatNoAggItem(etime.Train, etime.Run, "GenSim")
+
 	ss.Logs.AddPerTrlMSec("PerTrlMSec", etime.Run, etime.Epoch, etime.Trial)
 
 	ss.Logs.AddLayerTensorItems(ss.Net, "Act", etime.Test, etime.Trial, "InputLayer", "SuperLayer")
 
-	ss.Logs.PlotItems("UniqPats")
+	ss.Logs.PlotItems("UniqPats", "GenSim")
 
 	ss.Logs.CreateTables()
 	ss.Logs.SetContext(&ss.Stats, ss.Net)
@@ -539,6 +676,8 @@ func (ss *Sim) Log(mode etime.Modes, time etime.Times) {
 	case time == etime.Trial:
 		ss.TrialStats()
 		ss.StatCounters()
+	case mode == etime.Test && time == etime.Epoch:
+		ss.Stats.SetFloat("GenSim", ss.GenSimStat(ss.Logs.Table(etime.Test, etime.Trial)))
 	}
 
 	ss.Logs.LogRow(mode, time, row) // also logs to file, etc
@@ -569,16 +708,21 @@ func (ss *Sim) ConfigGUI() {
 	ss.GUI.ViewUpdate = &ss.ViewUpdate
 	nv.Current()
 
-	ss.GUI.AddPlots(title, &ss.Logs)
+	ss.ConfigPlots(simcore.GUIPlots{GUI: &ss.GUI})
 
 	ss.GUI.AddTableView(&ss.Logs, etime.Test, etime.Trial)
 
-	wgv := ss.GUI.AddGridTab("Weights")
+	ss.GUI.FinalizeGUI(false)
+}
+
+// ConfigPlots adds the log plots, and the grid of the Weights
+// of each Hidden unit from the Input lines, to the GUI or the Figures.
+func (ss *Sim) ConfigPlots(pl simcore.Plots) {
+	pl.AddLogPlots("Self Org", &ss.Logs)
+
 	wg := ss.Stats.F32Tensor("HiddenFromInput")
 	wg.SetShape([]int{4, 5, 5, 5})
-	wgv.SetTensor(wg)
-
-	ss.GUI.FinalizeGUI(false)
+	pl.AddGrid("Weights", wg)
 }
 
 func (ss *Sim) MakeToolbar(p *tree.Plan) {
@@ -619,3 +763,16 @@ func (ss *Sim) RunGUI() {
 	ss.ConfigGUI()
 	ss.GUI.Body.RunMainWindow()
 }
+
+func (ss *Sim) RunNoGUI() {
+	ss.Runner().RunNoGUI()
+}
+
+// Runner returns the simcore.Runner that runs the sim without the GUI.
+func (ss *Sim) Runner() *simcore.Runner {
+	return &simcore.Runner{Sim: ss, NetName: ss.Net.Name, RunName: ss.Params.RunName,
+		Fields: []string{"AvgLGain", "InputNoise", "TrainGi", "TestGi", "HoldOut"},
+		Config: &ss.Config.RunConfig, Run: &ss.Config.Run, NRuns: &ss.Config.NRuns, NEpochs: &ss.Config.NEpochs, Log: &ss.Config.Log,
+		Net: ss.Net, Context: &ss.Context, Loops: ss.Loops, Envs: ss.Envs, Stats: &ss.Stats, Logs: &ss.Logs, Seeds: &ss.RandSeeds,
+		Figures: &ss.Figures, Init: ss.Init, ConfigPlots: ss.ConfigPlots, UpdateFigures: ss.HiddenFromInput}
+}
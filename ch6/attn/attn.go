@@ -22,11 +22,12 @@ import (
 	"cogentcore.org/core/enums"
 	"cogentcore.org/core/icons"
 	"cogentcore.org/core/math32"
-	"cogentcore.org/core/plot/plotcore"
 	"cogentcore.org/core/tensor"
 	"cogentcore.org/core/tensor/stats/split"
 	"cogentcore.org/core/tensor/table"
 	"cogentcore.org/core/tree"
+	"github.com/CompCogNeuro/sims/v2/simcore"
+	"github.com/emer/emergent/v2/econfig"
 	"github.com/emer/emergent/v2/egui"
 	"github.com/emer/emergent/v2/elog"
 	"github.com/emer/emergent/v2/emer"
@@ -76,7 +77,11 @@ func main() {
 	sim := &Sim{}
 	sim.New()
 	sim.ConfigAll()
-	sim.RunGUI()
+	if sim.Config.GUI {
+		sim.RunGUI()
+	} else {
+		sim.RunNoGUI()
+	}
 }
 
 // ParamSets is the default set of parameters.
@@ -179,14 +184,13 @@ var ParamSets = params.Sets{
 	},
 }
 
-// Sim encapsulates the entire simulation model, and we define all the
-// functionality as methods on this struct.  This structure keeps all relevant
-// state information organized and available without having to pass everything around
-// as arguments to methods, and provides the core GUI interface (note the view tags
-// for the fields which provide hints to how things should be displayed).
-type Sim struct {
+// AttnParams are the parameters of the attention tests: which test
+// to run, the strengths of the pathways between the spatial and object
+// processing streams, and the timing of the cue and the target.
+type AttnParams struct {
+
 	// select which type of test (input patterns) to use
-	Test TestType
+	Test TestType `default:"MultiObjs"`
 
 	// spatial to object projection WtScale.Rel strength -- reduce to 1.5, 1 to test
 	SpatToObj float32 `default:"2"`
@@ -202,6 +206,72 @@ type Sim struct {
 
 	// number of cycles to present a target; 220 by default, 50 to 300 for KNa adapt testing
 	TargetCycles int `default:"220"`
+}
+
+// Defaults sets the default parameters, keeping the choice of Test.
+func (ap *AttnParams) Defaults() {
+	ap.SpatToObj = 2
+	ap.V1ToSpat1 = 0.6
+	ap.KNaAdapt = false
+	ap.CueCycles = 100
+	ap.TargetCycles = 220
+}
+
+// Config has config parameters related to running the sim.
+// New copies the Attn parameters to the Sim, and ConfigAll applies
+// the Lesion, so that, e.g., -Test StdPosner -Lesion LesionSpat1
+// runs the Posner task with one of the spatial layers lesioned.
+type Config struct {
+
+	// the Test to run, with its pathway strengths and timing
+	Attn AttnParams `display:"add-fields"`
+
+	// which layers to lesion, applied to the network when it is configured,
+	// both with and without the GUI
+	Lesion LesionType `default:"NoLesion"`
+
+	// how many locations to lesion in the lesioned layers
+	LesionLocations LesionSize `default:"LesionHalf"`
+
+	// how many units to lesion at each location
+	LesionUnits LesionSize `default:"LesionHalf"`
+
+	// GUI means open the GUI. Otherwise it runs automatically and quits,
+	// saving log files as specified in Log.
+	GUI bool `default:"true"`
+
+	// plots, grids or NetView to save as images without the GUI, or "all" (see simcore.Figures)
+	Figures string
+
+	// logs to export to .npz files for Python without the GUI, or "all" (see simcore.SaveLogsNPZ)
+	Export string
+
+	// Log has config parameters related to logging data.
+	Log LogConfig `display:"add-fields"`
+}
+
+// LogConfig has config parameters related to logging data
+type LogConfig struct {
+
+	// if true, save testing epoch log to file, as .tst_epc.tsv typically
+	TestEpoch bool `default:"false" nest:"+"`
+
+	// if true, save testing trial log to file, as .tst_trl.tsv typically
+	TestTrial bool `default:"true" nest:"+"`
+
+	// if true, save testing cycle log to file, as .tst_cyc.tsv typically. May be large.
+	TestCycle bool `default:"false" nest:"+"`
+}
+
+// Sim encapsulates the entire simulation model, and we define all the
+// functionality as methods on this struct.  This structure keeps all relevant
+// state information organized and available without having to pass everything around
+// as arguments to methods, and provides the core GUI interface (note the view tags
+// for the fields which provide hints to how things should be displayed).
+type Sim struct {
+
+	// the parameters of the attention tests
+	Attn AttnParams `display:"add-fields"`
 
 	// click to see these testing input patterns
 	MultiObjs *table.Table `new-window:"+" display:"no-inline"`
@@ -218,6 +288,9 @@ type Sim struct {
 	// click to see these testing input patterns
 	ObjAttn *table.Table `new-window:"+" display:"no-inline"`
 
+	// Config contains misc configuration parameters for running the sim
+	Config Config `display:"-"`
+
 	// the network -- click to view / edit parameters for layers, paths, etc
 	Net *leabra.Network `new-window:"+" display:"no-inline"`
 
@@ -245,6 +318,9 @@ type Sim struct {
 	// manages all the gui elements
 	GUI egui.GUI `display:"-"`
 
+	// the plots, grids and network snapshots that can be saved without the GUI
+	Figures simcore.Figures `display:"-"`
+
 	// a list of random seeds to use for each run
 	RandSeeds randx.Seeds `display:"-"`
 }
@@ -252,6 +328,8 @@ type Sim struct {
 // New creates new blank elements and initializes defaults
 func (ss *Sim) New() {
 	ss.Defaults()
+	econfig.Config(&ss.Config, "config.toml")
+	ss.Attn = ss.Config.Attn
 	ss.MultiObjs = &table.Table{}
 	ss.StdPosner = &table.Table{}
 	ss.ClosePosner = &table.Table{}
@@ -266,11 +344,7 @@ func (ss *Sim) New() {
 }
 
 func (ss *Sim) Defaults() {
-	ss.SpatToObj = 2
-	ss.V1ToSpat1 = 0.6
-	ss.KNaAdapt = false
-	ss.CueCycles = 100
-	ss.TargetCycles = 220
+	ss.Attn.Defaults()
 }
 
 //////////////////////////////////////////////////////////////////////////////
@@ -281,6 +355,7 @@ func (ss *Sim) ConfigAll() {
 	ss.OpenPatterns()
 	ss.ConfigEnv()
 	ss.ConfigNet(ss.Net)
+	ss.Lesion(ss.Config.Lesion, ss.Config.LesionLocations, ss.Config.LesionUnits)
 	ss.ConfigLogs()
 	ss.ConfigLoops()
 }
@@ -328,7 +403,7 @@ func (ss *Sim) ConfigEnv() {
 
 func (ss *Sim) UpdateEnv() {
 	ev := ss.Envs.ByMode(etime.Test).(*env.FixedTable)
-	switch ss.Test {
+	switch ss.Attn.Test {
 	case MultiObjs:
 		ev.Table = table.NewIndexView(ss.MultiObjs)
 	case StdPosner:
@@ -510,14 +585,14 @@ func (ss *Sim) Lesion(lay LesionType, locations LesionSize, units LesionSize) {
 
 func (ss *Sim) ApplyParams() {
 	spo, _ := errors.Log1(ss.Params.Params.SheetByName("Base")).SelByName(".SpatToObj")
-	spo.Params.SetByName("Path.WtScale.Rel", fmt.Sprintf("%g", ss.SpatToObj))
+	spo.Params.SetByName("Path.WtScale.Rel", fmt.Sprintf("%g", ss.Attn.SpatToObj))
 
 	vsp, _ := errors.Log1(ss.Params.Params.SheetByName("Base")).SelByName("#V1ToSpat1")
-	vsp.Params.SetByName("Path.WtScale.Rel", fmt.Sprintf("%g", ss.V1ToSpat1))
+	vsp.Params.SetByName("Path.WtScale.Rel", fmt.Sprintf("%g", ss.Attn.V1ToSpat1))
 
 	ss.Params.SetAll()
 
-	if ss.KNaAdapt {
+	if ss.Attn.KNaAdapt {
 		ss.Params.SetAllSheet("KNaAdapt")
 	}
 }
@@ -539,8 +614,7 @@ func (ss *Sim) Init() {
 
 // InitRandSeed initializes the random seed based on current training run number
 func (ss *Sim) InitRandSeed(run int) {
-	ss.RandSeeds.Set(run)
-	ss.RandSeeds.Set(run, &ss.Net.Rand)
+	simcore.InitRandSeed(&ss.RandSeeds, ss.Net, run)
 }
 
 // CycleThresholdStop
@@ -563,7 +637,7 @@ func (ss *Sim) ConfigLoops() {
 	ls := looper.NewStacks()
 
 	ntrls := 6
-	cycles := ss.TargetCycles
+	cycles := ss.Attn.TargetCycles
 	ls.AddStack(etime.Test).
 		AddTime(etime.Epoch, 10).
 		AddTime(etime.Trial, ntrls).
@@ -598,9 +672,11 @@ func (ss *Sim) ConfigLoops() {
 	////////////////////////////////////////////
 	// GUI
 
-	leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
-	leabra.LooperUpdatePlots(ls, &ss.GUI)
-	ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	if ss.Config.GUI {
+		leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
+		leabra.LooperUpdatePlots(ls, &ss.GUI)
+		ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	}
 
 	ss.Loops = ls
 }
@@ -624,9 +700,9 @@ func (ss *Sim) ApplyInputs() {
 		net.InitActs()
 		ss.Stats.SetInt("TrialEff", ss.Stats.Int("TrialEff")+1)
 	}
-	maxCyc := ss.TargetCycles
+	maxCyc := ss.Attn.TargetCycles
 	if strings.Contains(ev.TrialName.Cur, "Cue") {
-		maxCyc = ss.CueCycles
+		maxCyc = ss.Attn.CueCycles
 	}
 	ss.Loops.Stacks[etime.Test].Loops[etime.Cycle].Counter.Max = maxCyc
 	for _, lnm := range lays {
@@ -665,12 +741,7 @@ func (ss *Sim) InitStats() {
 // StatCounters saves current counters to Stats, so they are available for logging etc
 // Also saves a string rep of them for ViewUpdate.Text
 func (ss *Sim) StatCounters() {
-	ctx := &ss.Context
-	mode := ctx.Mode
-	ss.Loops.Stacks[mode].CountersToStats(&ss.Stats)
-	trl := ss.Stats.Int("Trial")
-	ss.Stats.SetInt("Trial", trl)
-	ss.Stats.SetInt("Cycle", int(ctx.Cycle))
+	simcore.StatCounters(ss.Loops, &ss.Context, &ss.Stats, false)
 }
 
 func (ss *Sim) NetViewCounters(tm etime.Times) {
@@ -722,7 +793,7 @@ func (ss *Sim) TrialStats() {
 	split.DescColumn(spl, "RT")
 	st := spl.AggsToTableCopy(table.AddAggName)
 	ss.Logs.MiscTables["TrialStats"] = st
-	plt := ss.GUI.Plots[etime.ScopeKey("TrialStats")]
+	plt := ss.Figures.PlotByName(&ss.GUI, "TrialStats")
 
 	st.SetMetaData("XAxis", "Trial")
 
@@ -741,8 +812,12 @@ func (ss *Sim) TrialStats() {
 	// st.SetMetaData("Trial:Min", "0.5")
 	// st.SetMetaData("Trial:Max", "3.5")
 
-	plt.SetTable(st)
-	plt.GoUpdatePlot()
+	if plt != nil {
+		plt.SetTable(st)
+		if ss.GUI.Active {
+			plt.GoUpdatePlot()
+		}
+	}
 }
 
 // Log is the main logging function, handles special things for different scopes
@@ -764,7 +839,7 @@ func (ss *Sim) Log(mode etime.Modes, time etime.Times) {
 		if !strings.Contains(ss.Stats.String("TrialName"), "Cue") {
 			ss.Stats.SetInt("Trial", ss.Stats.Int("TrialEff"))
 			if math.IsNaN(ss.Stats.Float("RT")) { // didn't stop
-				ss.Stats.SetFloat("RT", float64(ss.TargetCycles))
+				ss.Stats.SetFloat("RT", float64(ss.Attn.TargetCycles))
 			}
 			ss.Logs.Log(mode, time)
 		}
@@ -794,18 +869,21 @@ func (ss *Sim) ConfigGUI() {
 	ss.GUI.ViewUpdate = &ss.ViewUpdate
 	nv.Current()
 
-	ss.GUI.AddPlots(title, &ss.Logs)
+	ss.ConfigPlots(simcore.GUIPlots{GUI: &ss.GUI, Tabs: map[string]string{"TrialStats": "TrialStats Plot"}})
+
+	ss.GUI.FinalizeGUI(false)
+}
+
+// ConfigPlots adds the log plots, and the plot of the TrialStats
+// reaction times by condition, to the GUI or the Figures.
+func (ss *Sim) ConfigPlots(pl simcore.Plots) {
+	pl.AddLogPlots("Attn", &ss.Logs)
 
 	stnm := "TrialStats"
-	dt := ss.Logs.MiscTable(stnm)
-	bcp, _ := ss.GUI.Tabs.NewTab(stnm + " Plot")
-	plt := plotcore.NewSubPlot(bcp)
-	ss.GUI.Plots[etime.ScopeKey(stnm)] = plt
+	plt := pl.AddPlot(stnm)
 	plt.Options.Title = "Trial Stats"
 	plt.Options.XAxis = "Trial"
-	plt.SetTable(dt)
-
-	ss.GUI.FinalizeGUI(false)
+	plt.SetTable(ss.Logs.MiscTable(stnm))
 }
 
 func (ss *Sim) MakeToolbar(p *tree.Plan) {
@@ -859,3 +937,34 @@ func (ss *Sim) RunGUI() {
 	ss.ConfigGUI()
 	ss.GUI.Body.RunMainWindow()
 }
+
+// RunNoGUI runs the test loop without the GUI, saving the log files
+// as specified in the Config.Log settings.
+func (ss *Sim) RunNoGUI() {
+	runName := ss.Params.RunName(0)
+	ss.Stats.SetString("RunName", runName) // used for naming logs, stats, etc
+	netName := ss.Net.Name
+
+	elog.SetLogFile(&ss.Logs, ss.Config.Log.TestEpoch, etime.Test, etime.Epoch, "tst_epc", netName, runName)
+	elog.SetLogFile(&ss.Logs, ss.Config.Log.TestTrial, etime.Test, etime.Trial, "tst_trl", netName, runName)
+	elog.SetLogFile(&ss.Logs, ss.Config.Log.TestCycle, etime.Test, etime.Cycle, "tst_cyc", netName, runName)
+
+	if ss.Config.Figures != "" {
+		ss.Figures.Config(ss.Net, ss.ConfigPlots)
+	}
+	ss.Init()
+
+	ss.Loops.Run(etime.Test)
+
+	ss.Logs.CloseLogFiles()
+
+	if ss.Config.Figures != "" {
+		ss.Figures.Save(ss.Config.Figures, netName, runName)
+	}
+
+	if ss.Config.Export != "" {
+		simcore.SaveLogsNPZ(&ss.Logs, ss.Config.Export, netName, runName)
+	}
+
+	simcore.SaveTable(ss.Logs.MiscTable("TrialStats"), "trial_stats", netName, runName)
+}
@@ -12,4 +12,10 @@ var _ = types.AddType(&types.Type{Name: "main.LesionType", IDName: "lesion-type"
 
 var _ = types.AddType(&types.Type{Name: "main.LesionSize", IDName: "lesion-size", Doc: "LesionSize is the size of lesion"})
 
-var _ = types.AddType(&types.Type{Name: "main.Sim", IDName: "sim", Doc: "Sim encapsulates the entire simulation model, and we define all the\nfunctionality as methods on this struct.  This structure keeps all relevant\nstate information organized and available without having to pass everything around\nas arguments to methods, and provides the core GUI interface (note the view tags\nfor the fields which provide hints to how things should be displayed).", Methods: []types.Method{{Name: "Lesion", Doc: "Lesion lesions given set of layers (or unlesions for NoLesion) and\nlocations and number of units (Half = partial = 1/2 units, Full = both units)", Directives: []types.Directive{{Tool: "types", Directive: "add"}}, Args: []string{"lay", "locations", "units"}}}, Fields: []types.Field{{Name: "Test", Doc: "select which type of test (input patterns) to use"}, {Name: "SpatToObj", Doc: "spatial to object projection WtScale.Rel strength -- reduce to 1.5, 1 to test"}, {Name: "V1ToSpat1", Doc: "V1 to Spat1 projection WtScale.Rel strength -- reduce to .55, .5 to test"}, {Name: "KNaAdapt", Doc: "sodium (Na) gated potassium (K) channels that cause neurons to fatigue over time"}, {Name: "CueCycles", Doc: "number of cycles to present the cue; 100 by default, 50 to 300 for KNa adapt testing"}, {Name: "TargetCycles", Doc: "number of cycles to present a target; 220 by default, 50 to 300 for KNa adapt testing"}, {Name: "MultiObjs", Doc: "click to see these testing input patterns"}, {Name: "StdPosner", Doc: "click to see these testing input patterns"}, {Name: "ClosePosner", Doc: "click to see these testing input patterns"}, {Name: "ReversePosner", Doc: "click to see these testing input patterns"}, {Name: "ObjAttn", Doc: "click to see these testing input patterns"}, {Name: "Net", Doc: "the network -- click to view / edit parameters for layers, paths, etc"}, {Name: "Params", Doc: "network parameter management"}, {Name: "Loops", Doc: "contains looper control loops for running sim"}, {Name: "Stats", Doc: "contains computed statistic values"}, {Name: "Logs", Doc: "Contains all the logs and information about the logs.'"}, {Name: "Envs", Doc: "Environments"}, {Name: "Context", Doc: "leabra timing parameters and state"}, {Name: "ViewUpdate", Doc: "netview update parameters"}, {Name: "GUI", Doc: "manages all the gui elements"}, {Name: "RandSeeds", Doc: "a list of random seeds to use for each run"}}})
+var _ = types.AddType(&types.Type{Name: "main.AttnParams", IDName: "attn-params", Doc: "AttnParams are the parameters of the attention tests: which test\nto run, the strengths of the pathways between the spatial and object\nprocessing streams, and the timing of the cue and the target.", Fields: []types.Field{{Name: "Test", Doc: "select which type of test (input patterns) to use"}, {Name: "SpatToObj", Doc: "spatial to object projection WtScale.Rel strength -- reduce to 1.5, 1 to test"}, {Name: "V1ToSpat1", Doc: "V1 to Spat1 projection WtScale.Rel strength -- reduce to .55, .5 to test"}, {Name: "KNaAdapt", Doc: "sodium (Na) gated potassium (K) channels that cause neurons to fatigue over time"}, {Name: "CueCycles", Doc: "number of cycles to present the cue; 100 by default, 50 to 300 for KNa adapt testing"}, {Name: "TargetCycles", Doc: "number of cycles to present a target; 220 by default, 50 to 300 for KNa adapt testing"}}})
+
+var _ = types.AddType(&types.Type{Name: "main.Config", IDName: "config", Doc: "Config has config parameters related to running the sim.\nNew copies the Attn parameters to the Sim, and ConfigAll applies\nthe Lesion, so that, e.g., -Test StdPosner -Lesion LesionSpat1\nruns the Posner task with one of the spatial layers lesioned.", Fields: []types.Field{{Name: "Attn", Doc: "the Test to run, with its pathway strengths and timing"}, {Name: "Lesion", Doc: "which layers to lesion, applied to the network when it is configured,\nboth with and without the GUI"}, {Name: "LesionLocations", Doc: "how many locations to lesion in the lesioned layers"}, {Name: "LesionUnits", Doc: "how many units to lesion at each location"}, {Name: "GUI", Doc: "GUI means open the GUI. Otherwise it runs automatically and quits,\nsaving log files as specified in Log."}, {Name: "Figures", Doc: "plots, grids or NetView to save as images without the GUI, or \"all\" (see simcore.Figures)"}, {Name: "Export", Doc: "logs to export to .npz files for Python without the GUI, or \"all\" (see simcore.SaveLogsNPZ)"}, {Name: "Log", Doc: "Log has config parameters related to logging data."}}})
+
+var _ = types.AddType(&types.Type{Name: "main.LogConfig", IDName: "log-config", Doc: "LogConfig has config parameters related to logging data", Fields: []types.Field{{Name: "TestEpoch", Doc: "if true, save testing epoch log to file, as .tst_epc.tsv typically"}, {Name: "TestTrial", Doc: "if true, save testing trial log to file, as .tst_trl.tsv typically"}, {Name: "TestCycle", Doc: "if true, save testing cycle log to file, as .tst_cyc.tsv typically. May be large."}}})
+
+var _ = types.AddType(&types.Type{Name: "main.Sim", IDName: "sim", Doc: "Sim encapsulates the entire simulation model, and we define all the\nfunctionality as methods on this struct.  This structure keeps all relevant\nstate information organized and available without having to pass everything around\nas arguments to methods, and provides the core GUI interface (note the view tags\nfor the fields which provide hints to how things should be displayed).", Methods: []types.Method{{Name: "Lesion", Doc: "Lesion lesions given set of layers (or unlesions for NoLesion) and\nlocations and number of units (Half = partial = 1/2 units, Full = both units)", Directives: []types.Directive{{Tool: "types", Directive: "add"}}, Args: []string{"lay", "locations", "units"}}}, Fields: []types.Field{{Name: "Attn", Doc: "the parameters of the attention tests"}, {Name: "MultiObjs", Doc: "click to see these testing input patterns"}, {Name: "StdPosner", Doc: "click to see these testing input patterns"}, {Name: "ClosePosner", Doc: "click to see these testing input patterns"}, {Name: "ReversePosner", Doc: "click to see these testing input patterns"}, {Name: "ObjAttn", Doc: "click to see these testing input patterns"}, {Name: "Config", Doc: "Config contains misc configuration parameters for running the sim"}, {Name: "Net", Doc: "the network -- click to view / edit parameters for layers, paths, etc"}, {Name: "Params", Doc: "network parameter management"}, {Name: "Loops", Doc: "contains looper control loops for running sim"}, {Name: "Stats", Doc: "contains computed statistic values"}, {Name: "Logs", Doc: "Contains all the logs and information about the logs.'"}, {Name: "Envs", Doc: "Environments"}, {Name: "Context", Doc: "leabra timing parameters and state"}, {Name: "ViewUpdate", Doc: "netview update parameters"}, {Name: "GUI", Doc: "manages all the gui elements"}, {Name: "Figures", Doc: "the plots, grids and network snapshots that can be saved without the GUI"}, {Name: "RandSeeds", Doc: "a list of random seeds to use for each run"}}})
@@ -4,7 +4,10 @@
 
 package main
 
-import "github.com/emer/emergent/v2/paths"
+import (
+	"github.com/CompCogNeuro/sims/v2/simcore"
+	"github.com/emer/emergent/v2/paths"
+)
 
 // EnvConfig has config params for environment
 // note: only adding fields for key Env params that matter for both Network and Env
@@ -75,6 +78,10 @@ type RunConfig struct { //types:add
 
 	// how often to run through all the test patterns, in terms of training epochs -- can use 0 or -1 for no testing
 	TestInterval int `default:"-1"`
+
+	// RunConfig has the config parameters for running without the GUI:
+	// Sweep, Parallel, Checkpoint, Resume, Summary, Figures and Export.
+	simcore.RunConfig `display:"add-fields"`
 }
 
 // LogConfig has config parameters related to logging data
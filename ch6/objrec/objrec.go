@@ -18,7 +18,6 @@ import (
 	"os"
 	"reflect"
 
-	"cogentcore.org/core/base/mpi"
 	"cogentcore.org/core/base/randx"
 	"cogentcore.org/core/core"
 	"cogentcore.org/core/enums"
@@ -29,8 +28,8 @@ import (
 	"cogentcore.org/core/tensor/stats/split"
 	"cogentcore.org/core/tensor/stats/stats"
 	"cogentcore.org/core/tensor/table"
-	"cogentcore.org/core/tensor/tensorcore"
 	"cogentcore.org/core/tree"
+	"github.com/CompCogNeuro/sims/v2/simcore"
 	"github.com/emer/emergent/v2/econfig"
 	"github.com/emer/emergent/v2/egui"
 	"github.com/emer/emergent/v2/elog"
@@ -100,6 +99,9 @@ type Sim struct {
 	// manages all the gui elements
 	GUI egui.GUI `display:"-"`
 
+	// the plots, grids and network snapshots that can be saved without the GUI
+	Figures simcore.Figures `display:"-"`
+
 	// a list of random seeds to use for each run
 	RandSeeds randx.Seeds `display:"-"`
 }
@@ -242,8 +244,7 @@ func (ss *Sim) Init() {
 
 // InitRandSeed initializes the random seed based on current training run number
 func (ss *Sim) InitRandSeed(run int) {
-	ss.RandSeeds.Set(run)
-	ss.RandSeeds.Set(run, &ss.Net.Rand)
+	simcore.InitRandSeed(&ss.RandSeeds, ss.Net, run)
 }
 
 // ConfigLoops configures the control loops: Training, Testing
@@ -276,6 +277,7 @@ func (ss *Sim) ConfigLoops() {
 	}
 
 	ls.Loop(etime.Train, etime.Run).OnStart.Add("NewRun", ss.NewRun)
+	simcore.SeedEpochs(ls, &ss.RandSeeds, ss.Net)
 
 	// Add Testing
 	trainEpoch := ls.Loop(etime.Train, etime.Epoch)
@@ -331,6 +333,11 @@ func (ss *Sim) ConfigLoops() {
 				ss.GUI.NetDataRecord(ss.ViewUpdate.Text)
 			})
 		}
+		if ss.Config.Run.Figures != "" {
+			ls.Loop(etime.Test, etime.Trial).OnEnd.Add("ActRFs", func() {
+				ss.Stats.UpdateActRFs(ss.Net, "ActM", 0.01, 0)
+			})
+		}
 	} else {
 		ls.Loop(etime.Test, etime.Trial).OnEnd.Add("ActRFs", func() {
 			ss.Stats.UpdateActRFs(ss.Net, "ActM", 0.01, 0)
@@ -389,17 +396,7 @@ func (ss *Sim) ApplyInputs() {
 // NewRun intializes a new run of the model, using the TrainEnv.Run counter
 // for the new run value
 func (ss *Sim) NewRun() {
-	ctx := &ss.Context
-	ss.InitRandSeed(ss.Loops.Loop(etime.Train, etime.Run).Counter.Cur)
-	ss.Envs.ByMode(etime.Train).Init(0)
-	ss.Envs.ByMode(etime.Test).Init(0)
-	ctx.Reset()
-	ctx.Mode = etime.Train
-	ss.Net.InitWeights()
-	ss.InitStats()
-	ss.StatCounters()
-	ss.Logs.ResetLog(etime.Train, etime.Epoch)
-	ss.Logs.ResetLog(etime.Test, etime.Epoch)
+	simcore.NewRun(ss.Loops, &ss.Context, ss.Net, ss.Envs, &ss.Logs, &ss.RandSeeds, simcore.Hooks{InitStats: ss.InitStats, StatCounters: ss.StatCounters})
 }
 
 // TestAll runs through the full set of testing items
@@ -614,16 +611,19 @@ func (ss *Sim) ConfigGUI() {
 
 	ss.GUI.ViewUpdate = &ss.ViewUpdate
 
-	ss.GUI.AddPlots(title, &ss.Logs)
+	ss.ConfigPlots(simcore.GUIPlots{GUI: &ss.GUI})
 
-	itb, _ := ss.GUI.Tabs.NewTab("Image")
-	tg := tensorcore.NewTensorGrid(itb).
-		SetTensor(&ss.Envs.ByMode(etime.Train).(*LEDEnv).Vis.ImgTsr)
-	ss.GUI.SetGrid("Image", tg)
+	ss.GUI.FinalizeGUI(false)
+}
 
-	ss.GUI.AddActRFGridTabs(&ss.Stats.ActRFs)
+// ConfigPlots adds the log plots, the grid of the input Image,
+// and the grids of the activation-based receptive fields, to the GUI
+// or the Figures.
+func (ss *Sim) ConfigPlots(pl simcore.Plots) {
+	pl.AddLogPlots("Object Recognition", &ss.Logs)
 
-	ss.GUI.FinalizeGUI(false)
+	pl.AddGrid("Image", &ss.Envs.ByMode(etime.Train).(*LEDEnv).Vis.ImgTsr)
+	pl.AddActRFGrids(&ss.Stats.ActRFs)
 }
 
 func (ss *Sim) MakeToolbar(p *tree.Plan) {
@@ -706,38 +706,14 @@ func (ss *Sim) RunGUI() {
 }
 
 func (ss *Sim) RunNoGUI() {
-	if ss.Config.Params.Note != "" {
-		mpi.Printf("Note: %s\n", ss.Config.Params.Note)
-	}
-	if ss.Config.Log.SaveWeights {
-		mpi.Printf("Saving final weights per run\n")
-	}
-	runName := ss.Params.RunName(ss.Config.Run.Run)
-	ss.Stats.SetString("RunName", runName) // used for naming logs, stats, etc
-	netName := ss.Net.Name
-
-	elog.SetLogFile(&ss.Logs, ss.Config.Log.Trial, etime.Train, etime.Trial, "trl", netName, runName)
-	elog.SetLogFile(&ss.Logs, ss.Config.Log.Epoch, etime.Train, etime.Epoch, "epc", netName, runName)
-	elog.SetLogFile(&ss.Logs, ss.Config.Log.Run, etime.Train, etime.Run, "run", netName, runName)
-	elog.SetLogFile(&ss.Logs, ss.Config.Log.TestEpoch, etime.Test, etime.Epoch, "tst_epc", netName, runName)
-	elog.SetLogFile(&ss.Logs, ss.Config.Log.TestTrial, etime.Test, etime.Trial, "tst_trl", netName, runName)
-
-	netdata := ss.Config.Log.NetData
-	if netdata {
-		mpi.Printf("Saving NetView data from testing\n")
-		ss.GUI.InitNetData(ss.Net, 200)
-	}
-
-	ss.Init()
-
-	mpi.Printf("Running %d Runs starting at %d\n", ss.Config.Run.NRuns, ss.Config.Run.Run)
-	ss.Loops.Loop(etime.Train, etime.Run).Counter.SetCurMaxPlusN(ss.Config.Run.Run, ss.Config.Run.NRuns)
-
-	ss.Loops.Run(etime.Train)
-
-	ss.Logs.CloseLogFiles()
+	ss.Runner().RunNoGUI()
+}
 
-	if netdata {
-		ss.GUI.SaveNetData(ss.Stats.String("RunName"))
-	}
+// Runner returns the simcore.Runner that runs the sim without the GUI.
+func (ss *Sim) Runner() *simcore.Runner {
+	return &simcore.Runner{Sim: ss, NetName: ss.Net.Name, RunName: ss.Params.RunName,
+		Fields: []string{"PNovel"},
+		Config: &ss.Config.Run.RunConfig, Run: &ss.Config.Run.Run, NRuns: &ss.Config.Run.NRuns, NEpochs: &ss.Config.Run.NEpochs, Log: &ss.Config.Log,
+		Note: &ss.Config.Params.Note, Net: ss.Net, Context: &ss.Context, Loops: ss.Loops, Envs: ss.Envs, Stats: &ss.Stats, Logs: &ss.Logs, Seeds: &ss.RandSeeds,
+		Figures: &ss.Figures, GUI: &ss.GUI, Init: ss.Init, ConfigPlots: ss.ConfigPlots, UpdateFigures: ss.TestAll}
 }
@@ -27,8 +27,8 @@ import (
 	"cogentcore.org/core/system"
 	"cogentcore.org/core/tensor"
 	"cogentcore.org/core/tensor/table"
-	"cogentcore.org/core/tensor/tensorcore"
 	"cogentcore.org/core/tree"
+	"github.com/CompCogNeuro/sims/v2/simcore"
 	"github.com/emer/emergent/v2/econfig"
 	"github.com/emer/emergent/v2/egui"
 	"github.com/emer/emergent/v2/elog"
@@ -50,7 +50,11 @@ func main() {
 	sim := &Sim{}
 	sim.New()
 	sim.ConfigAll()
-	sim.RunGUI()
+	if sim.Config.GUI {
+		sim.RunGUI()
+	} else {
+		sim.RunNoGUI()
+	}
 }
 
 // see params.go for params, config.go for Config
@@ -103,6 +107,9 @@ type Sim struct {
 	// manages all the gui elements
 	GUI egui.GUI `display:"-"`
 
+	// the plots, grids and network snapshots that can be saved without the GUI
+	Figures simcore.Figures `display:"-"`
+
 	// a list of random seeds to use for each run
 	RandSeeds randx.Seeds `display:"-"`
 }
@@ -259,8 +266,7 @@ func (ss *Sim) Init() {
 
 // InitRandSeed initializes the random seed based on current training run number
 func (ss *Sim) InitRandSeed(run int) {
-	ss.RandSeeds.Set(run)
-	ss.RandSeeds.Set(run, &ss.Net.Rand)
+	simcore.InitRandSeed(&ss.RandSeeds, ss.Net, run)
 }
 
 // ConfigLoops configures the control loops: Training, Testing
@@ -293,6 +299,7 @@ func (ss *Sim) ConfigLoops() {
 	}
 
 	ls.Loop(etime.Train, etime.Run).OnStart.Add("NewRun", ss.NewRun)
+	simcore.SeedEpochs(ls, &ss.RandSeeds, ss.Net)
 
 	// Add Testing
 	trainEpoch := ls.Loop(etime.Train, etime.Epoch)
@@ -563,19 +570,19 @@ func (ss *Sim) ConfigGUI() {
 
 	ss.GUI.ViewUpdate = &ss.ViewUpdate
 
-	ss.GUI.AddPlots(title, &ss.Logs)
+	ss.ConfigPlots(simcore.GUIPlots{GUI: &ss.GUI, Tabs: map[string]string{"V1RFs": "V1 RFs"}})
 
-	itb, _ := ss.GUI.Tabs.NewTab("V1 RFs")
-	tg := tensorcore.NewTensorGrid(itb).
-		SetTensor(ss.Stats.F32Tensor("V1Wts"))
-	ss.GUI.SetGrid("V1RFs", tg)
+	ss.GUI.FinalizeGUI(false)
+}
 
-	itb, _ = ss.GUI.Tabs.NewTab("Image")
-	tg = tensorcore.NewTensorGrid(itb).
-		SetTensor(&ss.Envs.ByMode(etime.Train).(*ImgEnv).Vis.ImgTsr)
-	ss.GUI.SetGrid("Image", tg)
+// ConfigPlots adds the log plots, and the grids of the V1RFs
+// weight-based receptive fields and of the input Image, to the GUI
+// or the Figures.
+func (ss *Sim) ConfigPlots(pl simcore.Plots) {
+	pl.AddLogPlots("V1RF", &ss.Logs)
 
-	ss.GUI.FinalizeGUI(false)
+	pl.AddGrid("V1RFs", ss.Stats.F32Tensor("V1Wts"))
+	pl.AddGrid("Image", &ss.Envs.ByMode(etime.Train).(*ImgEnv).Vis.ImgTsr)
 }
 
 func (ss *Sim) MakeToolbar(p *tree.Plan) {
@@ -658,3 +665,16 @@ func (ss *Sim) RunGUI() {
 	ss.ConfigGUI()
 	ss.GUI.Body.RunMainWindow()
 }
+
+func (ss *Sim) RunNoGUI() {
+	ss.Runner().RunNoGUI()
+}
+
+// Runner returns the simcore.Runner that runs the sim without the GUI.
+func (ss *Sim) Runner() *simcore.Runner {
+	return &simcore.Runner{Sim: ss, NetName: ss.Net.Name, RunName: ss.Params.RunName,
+		Fields: []string{"ExcitLateralScale", "InhibLateralScale", "ExcitLateralLearn"},
+		Config: &ss.Config.Run.RunConfig, Run: &ss.Config.Run.Run, NRuns: &ss.Config.Run.NRuns, NEpochs: &ss.Config.Run.NEpochs, Log: &ss.Config.Log,
+		Note: &ss.Config.Params.Note, Net: ss.Net, Context: &ss.Context, Loops: ss.Loops, Envs: ss.Envs, Stats: &ss.Stats, Logs: &ss.Logs, Seeds: &ss.RandSeeds,
+		Figures: &ss.Figures, GUI: &ss.GUI, Init: ss.Init, ConfigPlots: ss.ConfigPlots, UpdateFigures: ss.V1RFs}
+}
@@ -15,15 +15,14 @@ import (
 	"reflect"
 	"strings"
 
-	"cogentcore.org/core/base/errors"
 	"cogentcore.org/core/base/randx"
 	"cogentcore.org/core/core"
 	"cogentcore.org/core/enums"
 	"cogentcore.org/core/icons"
-	"cogentcore.org/core/plot/plotcore"
 	"cogentcore.org/core/tensor/stats/split"
 	"cogentcore.org/core/tensor/table"
 	"cogentcore.org/core/tree"
+	"github.com/CompCogNeuro/sims/v2/simcore"
 	"github.com/emer/emergent/v2/econfig"
 	"github.com/emer/emergent/v2/egui"
 	"github.com/emer/emergent/v2/elog"
@@ -46,7 +45,11 @@ func main() {
 	sim := &Sim{}
 	sim.New()
 	sim.ConfigAll()
-	sim.RunGUI()
+	if sim.Config.GUI {
+		sim.RunGUI()
+	} else {
+		sim.RunNoGUI()
+	}
 }
 
 // ParamSets is the default set of parameters -- Base is always applied, and others can be optionally
@@ -177,6 +180,49 @@ type Config struct {
 
 	// StopMem is the threshold for stopping learning.
 	StopMem float32 `default:"1"`
+
+	// GUI means open the GUI. Otherwise it runs automatically and quits,
+	// saving log files as specified in Log.
+	GUI bool `default:"true"`
+
+	// starting run number, which determines the random seed.
+	// runs count up from there, so all runs can be done in parallel
+	// by launching separate jobs with each starting Run and NRuns = 1.
+	Run int `default:"0"`
+
+	// RunConfig has the config parameters for running without the GUI:
+	// Sweep, Parallel, Checkpoint, Resume, Summary, Figures and Export.
+	simcore.RunConfig `display:"add-fields"`
+
+	// Log has config parameters related to logging data.
+	Log LogConfig `display:"add-fields"`
+}
+
+// LogConfig has config parameters related to logging data
+type LogConfig struct {
+
+	// if true, save final weights after each run
+	SaveWeights bool
+
+	// if true, save train epoch log to file, as .epc.tsv typically
+	Epoch bool `default:"true" nest:"+"`
+
+	// if true, save run log to file, as .run.tsv typically
+	Run bool `default:"true" nest:"+"`
+
+	// if true, save train trial log to file, as .trl.tsv typically. May be large.
+	Trial bool `default:"false" nest:"+"`
+
+	// if true, save testing epoch log to file, as .tst_epc.tsv typically.  In general it is better to copy testing items over to the training epoch log and record there.
+	TestEpoch bool `default:"false" nest:"+"`
+
+	// if true, save testing trial log to file, as .tst_trl.tsv typically. May be large.
+	TestTrial bool `default:"false" nest:"+"`
+
+	// if true, compute the Harmony of the network on each cycle (see
+	// simcore.Harmony), and log its average over the cycles of each trial.
+	// This slows down training substantially.
+	Harmony bool `default:"false" nest:"+"`
 }
 
 // Sim encapsulates the entire simulation model, and we define all the
@@ -246,6 +292,9 @@ type Sim struct {
 	// manages all the gui elements
 	GUI egui.GUI `display:"-"`
 
+	// the plots, grids and network snapshots that can be saved without the GUI
+	Figures simcore.Figures `display:"-"`
+
 	// a list of random seeds to use for each run
 	RandSeeds randx.Seeds `display:"-"`
 }
@@ -397,7 +446,9 @@ func (ss *Sim) ApplyParams() {
 // Init restarts the run, and initializes everything, including network weights
 // and resets the epoch log table
 func (ss *Sim) Init() {
-	ss.Stats.SetString("RunName", ss.Params.RunName(0)) // in case user interactively changes tag
+	if ss.Config.GUI {
+		ss.Stats.SetString("RunName", ss.Params.RunName(0)) // in case user interactively changes tag
+	}
 	ss.Loops.ResetCounters()
 
 	ss.GUI.StopNow = false
@@ -446,6 +497,7 @@ func (ss *Sim) ConfigLoops() {
 	}
 
 	ls.Loop(etime.Train, etime.Run).OnStart.Add("NewRun", ss.NewRun)
+	simcore.SeedEpochs(ls, &ss.RandSeeds, ss.Net)
 
 	ls.Loop(etime.Train, etime.Run).OnEnd.Add("RunDone", func() {
 		if ss.Stats.Int("Run") >= ss.Config.NRuns-1 {
@@ -496,11 +548,19 @@ func (ss *Sim) ConfigLoops() {
 	})
 	leabra.LooperResetLogBelow(ls, &ss.Logs)
 
-	leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
-	leabra.LooperUpdatePlots(ls, &ss.GUI)
+	// Save weights to file, to look at later
+	ls.Loop(etime.Train, etime.Run).OnEnd.Add("SaveWeights", func() {
+		ctrString := ss.Stats.PrintValues([]string{"Run", "Epoch"}, []string{"%03d", "%05d"}, "_")
+		leabra.SaveWeightsIfConfigSet(ss.Net, ss.Config.Log.SaveWeights, ctrString, ss.Stats.String("RunName"))
+	})
 
-	ls.Stacks[etime.Train].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
-	ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	if ss.Config.GUI {
+		leabra.LooperUpdateNetView(ls, &ss.ViewUpdate, ss.Net, ss.NetViewCounters)
+		leabra.LooperUpdatePlots(ls, &ss.GUI)
+
+		ls.Stacks[etime.Train].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+		ls.Stacks[etime.Test].OnInit.Add("GUI-Init", func() { ss.GUI.UpdateWindow() })
+	}
 
 	ss.Loops = ls
 	// fmt.Println(ls.DocString())
@@ -563,15 +623,7 @@ func (ss *Sim) RunTestAll() {
 
 // OpenPatAsset opens pattern file from embedded assets
 func (ss *Sim) OpenPatAsset(dt *table.Table, fnm, name, desc string) error {
-	dt.SetMetaData("name", name)
-	dt.SetMetaData("desc", desc)
-	err := dt.OpenFS(content, fnm, table.Tab)
-	if errors.Log(err) == nil {
-		for i := 1; i < dt.NumColumns(); i++ {
-			dt.Columns[i].SetMetaData("grid-fill", "0.9")
-		}
-	}
-	return err
+	return simcore.OpenPatAsset(content, dt, fnm, name, desc)
 }
 
 func (ss *Sim) OpenPatterns() {
@@ -704,6 +756,11 @@ func (ss *Sim) TrialStats() {
 	ss.MemStats(ss.Loops.Mode.(etime.Modes))
 }
 
+// CycleStats computes the cycle-level statistics.
+func (ss *Sim) CycleStats() {
+	ss.Stats.SetFloat32("Harmony", simcore.Harmony(ss.Net))
+}
+
 // MemStats computes ActM vs. Target on ECout with binary counts
 // must be called at end of 3rd quarter so that Target values are
 // for the entire full pattern as opposed to the plus-phase target
@@ -789,14 +846,20 @@ func (ss *Sim) MemStats(mode etime.Modes) {
 
 }
 
+// RunStats summarizes the TstABMem of the runs logged so far, by Expt.
+// It is called at the end of the last run, before that run is logged,
+// so there are none with NRuns = 1 or in the last process of Parallel.
 func (ss *Sim) RunStats() {
 	dt := ss.Logs.Table(etime.Train, etime.Run)
+	if dt.Rows == 0 {
+		return
+	}
 	runix := table.NewIndexView(dt)
 	spl := split.GroupBy(runix, "Expt")
 	split.DescColumn(spl, "TstABMem")
 	st := spl.AggsToTableCopy(table.AddAggName)
 	ss.Logs.MiscTables["RunStats"] = st
-	plt := ss.GUI.Plots[etime.ScopeKey("RunStats")]
+	plt := ss.Figures.PlotByName(&ss.GUI, "RunStats")
 
 	st.SetMetaData("XAxis", "RunName")
 
@@ -810,8 +873,12 @@ func (ss *Sim) RunStats() {
 	st.SetMetaData("TstABMem:Min:On", "+")
 	st.SetMetaData("TstABMem:Count:On", "-")
 
-	plt.SetTable(st)
-	plt.GoUpdatePlot()
+	if plt != nil {
+		plt.SetTable(st)
+		if ss.GUI.Active {
+			plt.GoUpdatePlot()
+		}
+	}
 }
 
 //////////////////////////////////////////////////////////////////////////////
@@ -851,6 +918,9 @@ func (ss *Sim) ConfigLogs() {
 	ss.Logs.AddStatAggItem("ACMem", etime.Run, etime.Epoch, etime.Trial)
 	ss.Logs.AddStatAggItem("LureMem", etime.Run, etime.Epoch, etime.Trial)
 	ss.Logs.AddStatAggItem("Mem", etime.Run, etime.Epoch, etime.Trial)
+	if ss.Config.Log.Harmony {
+		ss.Logs.AddStatAggItem("Harmony", etime.Run, etime.Epoch, etime.Trial, etime.Cycle)
+	}
 	ss.Logs.AddStatIntNoAggItem(etime.Train, etime.Run, "FirstPerfect")
 
 	// ss.Logs.AddCopyFromFloatItems(etime.Train, etime.Epoch, etime.Test, etime.Epoch, "Tst", "PhaseDiff", "UnitErr", "PctCor", "PctErr", "TrgOnWasOffAll", "TrgOnWasOffCmp", "TrgOffWasOn", "Mem")
@@ -912,7 +982,11 @@ func (ss *Sim) Log(mode etime.Modes, time etime.Times) {
 
 	switch {
 	case time == etime.Cycle:
-		return
+		if !ss.Config.Log.Harmony {
+			return
+		}
+		ss.CycleStats()
+		ss.StatCounters()
 	case time == etime.Trial:
 		ss.TrialStats()
 		ss.StatCounters()
@@ -942,18 +1016,21 @@ func (ss *Sim) ConfigGUI() {
 	// nv.SceneXYZ().Camera.Pose.Pos.Set(0, 1, 2.75)
 	// nv.SceneXYZ().Camera.LookAt(math32.Vec3(0, 0, 0), math32.Vec3(0, 1, 0))
 
-	ss.GUI.AddPlots(title, &ss.Logs)
+	ss.ConfigPlots(simcore.GUIPlots{GUI: &ss.GUI, Tabs: map[string]string{"RunStats": "RunStats Plot"}})
+
+	ss.GUI.FinalizeGUI(false)
+}
+
+// ConfigPlots adds the log plots, and the plot of the RunStats summary
+// of the memory tests across runs, to the GUI or the Figures.
+func (ss *Sim) ConfigPlots(pl simcore.Plots) {
+	pl.AddLogPlots("Hippocampus", &ss.Logs)
 
 	stnm := "RunStats"
-	dt := ss.Logs.MiscTable(stnm)
-	bcp, _ := ss.GUI.Tabs.NewTab(stnm + " Plot")
-	plt := plotcore.NewSubPlot(bcp)
-	ss.GUI.Plots[etime.ScopeKey(stnm)] = plt
+	plt := pl.AddPlot(stnm)
 	plt.Options.Title = "Run Stats"
 	plt.Options.XAxis = "RunName"
-	plt.SetTable(dt)
-
-	ss.GUI.FinalizeGUI(false)
+	plt.SetTable(ss.Logs.MiscTable(stnm))
 }
 
 func (ss *Sim) MakeToolbar(p *tree.Plan) {
@@ -994,3 +1071,15 @@ func (ss *Sim) RunGUI() {
 	ss.ConfigGUI()
 	ss.GUI.Body.RunMainWindow()
 }
+
+func (ss *Sim) RunNoGUI() {
+	ss.Runner().RunNoGUI()
+}
+
+// Runner returns the simcore.Runner that runs the sim without the GUI.
+func (ss *Sim) Runner() *simcore.Runner {
+	return &simcore.Runner{Sim: ss, NetName: ss.Net.Name, RunName: ss.Params.RunName,
+		Config: &ss.Config.RunConfig, Run: &ss.Config.Run, NRuns: &ss.Config.NRuns, NEpochs: &ss.Config.NEpochs, Log: &ss.Config.Log,
+		Net: ss.Net, Context: &ss.Context, Loops: ss.Loops, Envs: ss.Envs, Stats: &ss.Stats, Logs: &ss.Logs, Seeds: &ss.RandSeeds,
+		Figures: &ss.Figures, Init: ss.Init, ConfigPlots: ss.ConfigPlots}
+}
@@ -0,0 +1,420 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+
+	"cogentcore.org/core/base/randx"
+	"cogentcore.org/core/math32"
+	"github.com/emer/leabra/v2/leabra"
+)
+
+// BPNet is a standard network of sigmoidal rate-code units, with the same
+// layers and forward pathways as a leabra.Network, which learns with error
+// backpropagation, or GeneRec, instead of the Leabra algorithm, so that
+// they can be compared on the same patterns. On each trial, it reads the
+// inputs and targets that have been applied to the leabra.Network, and
+// writes its activations back to the ActM, ActP and Act of its neurons,
+// so that the same stats, logs and NetView work for both.
+type BPNet struct {
+
+	// use the GeneRec algorithm, which settles in a minus phase with only
+	// the inputs clamped, and a plus phase with the targets also clamped,
+	// with symmetric bidirectional weights, and learns with contrastive
+	// Hebbian learning (CHL) from the difference between the phases,
+	// instead of backpropagating the error in a single feedforward pass.
+	GeneRec bool
+
+	// learning rate for backpropagation
+	Lrate float32 `default:"0.2"`
+
+	// momentum for backpropagation: proportion of the previous weight change
+	// added to the current one
+	Momentum float32 `default:"0.9"`
+
+	// learning rate for GeneRec, which is the same as for backpropagation
+	// by default, but may need to be lower to learn reliably in networks
+	// with more hidden layers
+	GeneRecLrate float32 `default:"0.2"`
+
+	// momentum for GeneRec: proportion of the previous weight change added
+	// to the current one, which may also need to be lower in deeper networks
+	GeneRecMomentum float32 `default:"0.9"`
+
+	// initial weights and biases are uniformly distributed in +/- this range
+	WtRange float32 `default:"0.5"`
+
+	// number of cycles of settling in each phase for GeneRec
+	NCycles int `default:"50" min:"1"`
+
+	// rate of integration of the activations toward their new values on each
+	// cycle of settling for GeneRec
+	Dt float32 `default:"0.5" min:"0" max:"1"`
+
+	// the layers, in the feedforward order of the leabra.Network layers
+	Layers []*BPLayer `display:"-"`
+}
+
+// BPLayer is one layer of a BPNet.
+type BPLayer struct {
+
+	// the layer of the leabra.Network that this layer mirrors
+	Layer *leabra.Layer
+
+	// activations in the minus phase: the output of the feedforward pass
+	// for backpropagation
+	ActM []float32
+
+	// activations in the plus phase, with the targets clamped
+	ActP []float32
+
+	// bias weights
+	Bias []float32
+
+	// previous change in the bias weights, for momentum
+	DBias []float32
+
+	// error (delta) backpropagated to each unit
+	Err []float32
+
+	// the pathways that this layer receives
+	Paths []*BPPath
+}
+
+// BPPath is one pathway of a BPNet, with all-to-all weights.
+type BPPath struct {
+
+	// the sending layer
+	Send *BPLayer
+
+	// weights, with the sending units inner: [recv * nsend + send]
+	Wts []float32
+
+	// previous changes in the weights, for momentum
+	DWts []float32
+}
+
+// IsInput returns whether this layer is clamped to the inputs.
+func (ly *BPLayer) IsInput() bool {
+	return ly.Layer.Type == leabra.InputLayer
+}
+
+// IsTarget returns whether this layer is an output layer, with targets
+// that determine the error, which are clamped in the plus phase
+// if it is a TargetLayer (i.e., for training), but not a CompareLayer.
+func (ly *BPLayer) IsTarget() bool {
+	return ly.Layer.Type == leabra.TargetLayer || ly.Layer.Type == leabra.CompareLayer
+}
+
+func (bp *BPNet) Defaults() {
+	bp.Lrate = 0.2
+	bp.Momentum = 0.9
+	bp.GeneRecLrate = 0.2
+	bp.GeneRecMomentum = 0.9
+	bp.WtRange = 0.5
+	bp.NCycles = 50
+	bp.Dt = 0.5
+}
+
+// NewBPNet returns a new BPNet with the same layers and ForwardPath pathways
+// as the given leabra.Network, which must be built. The layers must be in
+// feedforward order, with all the senders of each layer before it, as they are
+// added to the network in the sims. The other pathways (e.g., BackPath) are
+// ignored: GeneRec uses the forward weights in both directions.
+func NewBPNet(net *leabra.Network) (*BPNet, error) {
+	bp := &BPNet{}
+	bp.Defaults()
+	idx := map[string]*BPLayer{}
+	for _, ly := range net.Layers {
+		if ly.Off {
+			continue
+		}
+		nn := len(ly.Neurons)
+		bl := &BPLayer{Layer: ly, ActM: make([]float32, nn), ActP: make([]float32, nn),
+			Bias: make([]float32, nn), DBias: make([]float32, nn), Err: make([]float32, nn)}
+		for _, pt := range ly.RecvPaths {
+			if pt.Off || pt.Type != leabra.ForwardPath {
+				continue
+			}
+			sl, ok := idx[pt.Send.Name]
+			if !ok {
+				return nil, fmt.Errorf("NewBPNet: layer %q receives from %q, which is not before it in the network", ly.Name, pt.Send.Name)
+			}
+			nw := nn * len(sl.ActM)
+			bl.Paths = append(bl.Paths, &BPPath{Send: sl, Wts: make([]float32, nw), DWts: make([]float32, nw)})
+		}
+		bp.Layers = append(bp.Layers, bl)
+		idx[ly.Name] = bl
+	}
+	return bp, nil
+}
+
+// InitWeights initializes the weights and biases to uniform random values
+// within WtRange, using the given random number generator (e.g., the Rand
+// of the leabra.Network, so that it depends on the run).
+func (bp *BPNet) InitWeights(rnd randx.Rand) {
+	uni := func() float32 { return bp.WtRange * (2*rnd.Float32() - 1) }
+	for _, ly := range bp.Layers {
+		for i := range ly.Bias {
+			ly.Bias[i] = 0
+			if !ly.IsInput() {
+				ly.Bias[i] = uni()
+			}
+			ly.DBias[i] = 0
+		}
+		for _, pt := range ly.Paths {
+			for i := range pt.Wts {
+				pt.Wts[i] = uni()
+				pt.DWts[i] = 0
+			}
+		}
+	}
+}
+
+// bpLayerState is the learned state of one BPLayer, in the State of a BPNet.
+type bpLayerState struct {
+	Bias, DBias []float32
+	Wts, DWts   [][]float32 // for each of the Paths
+}
+
+// State returns the weights and biases, with their previous changes for
+// momentum, gob encoded, e.g., for the Extra state of a Checkpointer.
+func (bp *BPNet) State() ([]byte, error) {
+	st := make([]bpLayerState, len(bp.Layers))
+	for li, ly := range bp.Layers {
+		ls := &st[li]
+		ls.Bias, ls.DBias = ly.Bias, ly.DBias
+		for _, pt := range ly.Paths {
+			ls.Wts = append(ls.Wts, pt.Wts)
+			ls.DWts = append(ls.DWts, pt.DWts)
+		}
+	}
+	var b bytes.Buffer
+	err := gob.NewEncoder(&b).Encode(st)
+	return b.Bytes(), err
+}
+
+// SetState sets the weights and biases from State,
+// which must be from a BPNet with the same layers.
+func (bp *BPNet) SetState(b []byte) error {
+	var st []bpLayerState
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&st); err != nil {
+		return fmt.Errorf("BPNet.SetState: %w", err)
+	}
+	if len(st) != len(bp.Layers) {
+		return fmt.Errorf("BPNet.SetState: state has %d layers instead of %d", len(st), len(bp.Layers))
+	}
+	for li, ly := range bp.Layers {
+		ls := &st[li]
+		if len(ls.Bias) != len(ly.Bias) || len(ls.Wts) != len(ly.Paths) {
+			return fmt.Errorf("BPNet.SetState: state does not match layer: %s", ly.Layer.Name)
+		}
+		copy(ly.Bias, ls.Bias)
+		copy(ly.DBias, ls.DBias)
+		for pi, pt := range ly.Paths {
+			if len(ls.Wts[pi]) != len(pt.Wts) {
+				return fmt.Errorf("BPNet.SetState: state does not match the paths of layer: %s", ly.Layer.Name)
+			}
+			copy(pt.Wts, ls.Wts[pi])
+			copy(pt.DWts, ls.DWts[pi])
+		}
+	}
+	return nil
+}
+
+// Trial processes one trial, with the inputs and targets that have been
+// applied to the leabra.Network, learning from it if learn is true,
+// and writes the activations back to the leabra.Network neurons:
+// ActM is the minus phase (the output of the network), ActP is the
+// plus phase, with the targets clamped for a TargetLayer, and Act is ActP,
+// as it is at the end of a Leabra trial.
+func (bp *BPNet) Trial(learn bool) {
+	for _, ly := range bp.Layers {
+		for i := range ly.ActM {
+			nrn := &ly.Layer.Neurons[i]
+			ly.ActM[i] = 0
+			if ly.IsInput() {
+				ly.ActM[i] = nrn.Ext
+			}
+		}
+	}
+	if bp.GeneRec {
+		bp.Settle(false)
+		for _, ly := range bp.Layers {
+			copy(ly.ActP, ly.ActM)
+		}
+		bp.Settle(true)
+	} else {
+		bp.Forward()
+	}
+	if learn {
+		if bp.GeneRec {
+			bp.CHL()
+		} else {
+			bp.Backprop()
+		}
+	}
+	for _, ly := range bp.Layers {
+		for i := range ly.ActM {
+			nrn := &ly.Layer.Neurons[i]
+			nrn.ActM = ly.ActM[i]
+			nrn.ActP = ly.ActP[i]
+			nrn.Act = ly.ActP[i]
+			nrn.ActDif = nrn.ActP - nrn.ActM
+		}
+	}
+}
+
+// netInput returns the net input to unit ri of the given layer from the
+// given activations of the sending layers (ActM or ActP), including the
+// bias, and, if bidir, the input from the layers that it sends to,
+// through the same (symmetric) weights.
+func (bp *BPNet) netInput(ly *BPLayer, ri int, acts func(sl *BPLayer) []float32, bidir bool) float32 {
+	net := ly.Bias[ri]
+	for _, pt := range ly.Paths {
+		sa := acts(pt.Send)
+		ns := len(sa)
+		for si, a := range sa {
+			net += pt.Wts[ri*ns+si] * a
+		}
+	}
+	if !bidir {
+		return net
+	}
+	ns := len(ly.ActM)
+	for _, rl := range bp.Layers {
+		ra := acts(rl)
+		for _, pt := range rl.Paths {
+			if pt.Send != ly {
+				continue
+			}
+			for rj, a := range ra {
+				net += pt.Wts[rj*ns+ri] * a
+			}
+		}
+	}
+	return net
+}
+
+// Forward computes the activations of the layers in a single feedforward
+// pass, in ActM, from the inputs, and sets ActP to the targets for a
+// TargetLayer, and to ActM for the others.
+func (bp *BPNet) Forward() {
+	actM := func(sl *BPLayer) []float32 { return sl.ActM }
+	for _, ly := range bp.Layers {
+		for i := range ly.ActM {
+			if !ly.IsInput() {
+				ly.ActM[i] = sigmoid(bp.netInput(ly, i, actM, false))
+			}
+			ly.ActP[i] = ly.ActM[i]
+			if ly.Layer.Type == leabra.TargetLayer {
+				ly.ActP[i] = ly.Layer.Neurons[i].Targ
+			}
+		}
+	}
+}
+
+// Settle runs NCycles of settling with symmetric bidirectional weights,
+// on the ActM activations for the minus phase, with only the inputs clamped,
+// or the ActP activations for the plus phase, with the targets of a
+// TargetLayer also clamped.
+func (bp *BPNet) Settle(plus bool) {
+	acts := func(sl *BPLayer) []float32 { return sl.ActM }
+	if plus {
+		acts = func(sl *BPLayer) []float32 { return sl.ActP }
+	}
+	for _, ly := range bp.Layers {
+		if plus && ly.Layer.Type == leabra.TargetLayer {
+			for i := range ly.ActP {
+				ly.ActP[i] = ly.Layer.Neurons[i].Targ
+			}
+		}
+	}
+	for range bp.NCycles {
+		for _, ly := range bp.Layers {
+			if ly.IsInput() || (plus && ly.Layer.Type == leabra.TargetLayer) {
+				continue
+			}
+			la := acts(ly)
+			for i := range la {
+				la[i] += bp.Dt * (sigmoid(bp.netInput(ly, i, acts, true)) - la[i])
+			}
+		}
+	}
+}
+
+// Backprop computes the error of each unit, from the difference between
+// the targets and the activations of the output layers, backpropagated
+// through the weights, and changes the weights to reduce it.
+func (bp *BPNet) Backprop() {
+	for _, ly := range bp.Layers {
+		for i := range ly.Err {
+			ly.Err[i] = 0
+			if ly.IsTarget() {
+				ly.Err[i] = ly.Layer.Neurons[i].Targ - ly.ActM[i]
+			}
+		}
+	}
+	for li := len(bp.Layers) - 1; li >= 0; li-- {
+		ly := bp.Layers[li]
+		if ly.IsInput() {
+			continue
+		}
+		for ri, a := range ly.ActM {
+			ly.Err[ri] *= a * (1 - a) // derivative of the sigmoid
+		}
+		for _, pt := range ly.Paths {
+			sl := pt.Send
+			ns := len(sl.ActM)
+			for ri, err := range ly.Err {
+				for si, sa := range sl.ActM {
+					wi := ri*ns + si
+					sl.Err[si] += pt.Wts[wi] * err
+					pt.DWts[wi] = bp.Lrate*err*sa + bp.Momentum*pt.DWts[wi]
+					pt.Wts[wi] += pt.DWts[wi]
+				}
+			}
+		}
+		for ri, err := range ly.Err {
+			ly.DBias[ri] = bp.Lrate*err + bp.Momentum*ly.DBias[ri]
+			ly.Bias[ri] += ly.DBias[ri]
+		}
+	}
+}
+
+// CHL changes the weights by contrastive Hebbian learning, in proportion to
+// the difference between the products of the sending and receiving
+// activations in the plus and the minus phases, which is equivalent to
+// GeneRec with symmetric weights.
+func (bp *BPNet) CHL() {
+	for _, ly := range bp.Layers {
+		if ly.IsInput() {
+			continue
+		}
+		for _, pt := range ly.Paths {
+			sl := pt.Send
+			ns := len(sl.ActM)
+			for ri := range ly.ActM {
+				for si := range sl.ActM {
+					wi := ri*ns + si
+					dw := ly.ActP[ri]*sl.ActP[si] - ly.ActM[ri]*sl.ActM[si]
+					pt.DWts[wi] = bp.GeneRecLrate*dw + bp.GeneRecMomentum*pt.DWts[wi]
+					pt.Wts[wi] += pt.DWts[wi]
+				}
+			}
+		}
+		for ri := range ly.ActM {
+			ly.DBias[ri] = bp.GeneRecLrate*(ly.ActP[ri]-ly.ActM[ri]) + bp.GeneRecMomentum*ly.DBias[ri]
+			ly.Bias[ri] += ly.DBias[ri]
+		}
+	}
+}
+
+func sigmoid(x float32) float32 {
+	return 1 / (1 + math32.Exp(-x))
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"math"
+	"testing"
+
+	"cogentcore.org/core/base/randx"
+	"github.com/emer/emergent/v2/paths"
+	"github.com/emer/leabra/v2/leabra"
+)
+
+// xorPats are the inputs and targets of the XOR problem.
+var xorPats = [][3]float32{{0, 0, 0}, {0, 1, 1}, {1, 0, 1}, {1, 1, 0}}
+
+// newXORNet returns a new BPNet for the XOR problem, with a hidden layer
+// of the given size, and its random weights initialized from the seed.
+func newXORNet(t *testing.T, nhid int, seed int64) *BPNet {
+	t.Helper()
+	net := leabra.NewNetwork("XOR")
+	inp := net.AddLayer2D("Input", 1, 2, leabra.InputLayer)
+	hid := net.AddLayer2D("Hidden", 1, nhid, leabra.SuperLayer)
+	out := net.AddLayer2D("Output", 1, 1, leabra.TargetLayer)
+	full := paths.NewFull()
+	net.ConnectLayers(inp, hid, full, leabra.ForwardPath)
+	net.ConnectLayers(hid, out, full, leabra.ForwardPath)
+	net.Build()
+	bp, err := NewBPNet(net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bp.InitWeights(randx.NewSysRand(seed))
+	return bp
+}
+
+// applyXOR applies the given XOR pattern to the leabra.Network neurons,
+// as the inputs and targets that the BPNet reads.
+func (bp *BPNet) applyXOR(pat [3]float32) {
+	in := bp.Layers[0].Layer.Neurons
+	in[0].Ext, in[1].Ext = pat[0], pat[1]
+	bp.Layers[len(bp.Layers)-1].Layer.Neurons[0].Targ = pat[2]
+}
+
+// sse returns the sum squared error of the output of the BPNet
+// over all of the XOR patterns, without learning.
+func (bp *BPNet) sse() float64 {
+	sse := 0.0
+	for _, pat := range xorPats {
+		bp.applyXOR(pat)
+		bp.Trial(false)
+		d := float64(pat[2] - bp.Layers[len(bp.Layers)-1].ActM[0])
+		sse += d * d
+	}
+	return sse
+}
+
+// TestBackpropGradient checks that the weight changes of Backprop, without
+// momentum, are the learning rate times the negative gradient of the
+// half squared error, computed by finite differences, for each weight.
+func TestBackpropGradient(t *testing.T) {
+	bp := newXORNet(t, 3, 1)
+	bp.Momentum = 0
+	pat := xorPats[1]
+	loss := func() float64 {
+		bp.applyXOR(pat)
+		bp.Forward()
+		d := float64(pat[2] - bp.Layers[2].ActM[0])
+		return 0.5 * d * d
+	}
+	const h = 1.0e-2
+	for li, ly := range bp.Layers {
+		for pi, pt := range ly.Paths {
+			for wi := range pt.Wts {
+				w := pt.Wts[wi]
+				pt.Wts[wi] = w + h
+				lp := loss()
+				pt.Wts[wi] = w - h
+				lm := loss()
+				pt.Wts[wi] = w
+				grad := (lp - lm) / (2 * h)
+
+				saved, err := bp.State()
+				if err != nil {
+					t.Fatal(err)
+				}
+				bp.applyXOR(pat)
+				bp.Trial(true)
+				dw := float64(pt.Wts[wi]-w) / float64(bp.Lrate)
+				if err := bp.SetState(saved); err != nil {
+					t.Fatal(err)
+				}
+				if math.Abs(dw+grad) > 1.0e-3+1.0e-2*math.Abs(grad) {
+					t.Errorf("layer %d path %d weight %d: Backprop change / Lrate %g != -gradient %g", li, pi, wi, dw, -grad)
+				}
+			}
+		}
+	}
+}
+
+// TestBPNetXOR checks that Backprop and GeneRec learn the XOR problem,
+// which needs the hidden layer, with their default parameters.
+func TestBPNetXOR(t *testing.T) {
+	for _, generec := range []bool{false, true} {
+		bp := newXORNet(t, 4, 1)
+		bp.GeneRec = generec
+		solved := -1
+		for epc := range 1000 {
+			for _, pat := range xorPats {
+				bp.applyXOR(pat)
+				bp.Trial(true)
+			}
+			if bp.sse() < 0.1 {
+				solved = epc
+				break
+			}
+		}
+		if solved < 0 {
+			t.Errorf("GeneRec = %v: XOR not learned in 1000 epochs: SSE = %g", generec, bp.sse())
+		}
+	}
+}
@@ -0,0 +1,387 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"cogentcore.org/core/base/reflectx"
+	"cogentcore.org/core/tensor/table"
+	"github.com/emer/emergent/v2/elog"
+	"github.com/emer/emergent/v2/env"
+	"github.com/emer/emergent/v2/estats"
+	"github.com/emer/emergent/v2/etime"
+	"github.com/emer/emergent/v2/looper"
+	"github.com/emer/leabra/v2/leabra"
+)
+
+// Checkpoint has the full state of a training run at the end of an epoch,
+// from which the run can be resumed, continuing exactly as it would have
+// without interruption. Checkpoints are saved by a Checkpointer.
+type Checkpoint struct {
+
+	// Run is the training run counter.
+	Run int
+
+	// Epoch is the next training epoch to run, i.e., the number of
+	// epochs completed in this run.
+	Epoch int
+
+	// Context is the leabra timing state.
+	Context leabra.Context
+
+	// Layers has the neuron and pool state for each layer of the network.
+	Layers []LayerState
+
+	// Paths has the synapse state for each pathway of the network,
+	// in order of layers and their receiving paths.
+	Paths []PathState
+
+	// Envs has the state of each environment, by name, as the gob encoded
+	// values of each of its exported fields that have plain data values
+	// (numbers, strings, and slices and structs of those), e.g., the
+	// trial counter and permuted order of an env.FixedTable.
+	Envs map[string]map[string][]byte
+
+	// Floats, Ints and Strings are the values of the Stats.
+	Floats  map[string]float64
+	Ints    map[string]int
+	Strings map[string]string
+
+	// EpochLog has the rows of the training epoch log so far in this run,
+	// as tab-separated values, so that run-level stats computed from it
+	// are the same.
+	EpochLog string
+
+	// TestEpochLog has the rows of the testing epoch log, from which
+	// the last test stats are copied to the training epoch log.
+	TestEpochLog string
+
+	// Extra is any other training state of the sim, from the Extra
+	// function of the Checkpointer, e.g., the weights of a BPNet.
+	Extra []byte
+}
+
+// LayerState is the state of one layer in a Checkpoint.
+type LayerState struct {
+	Name     string
+	Neurons  []leabra.Neuron
+	Pools    []leabra.Pool
+	CosDiff  leabra.CosDiffStats
+	NeuroMod leabra.NeuroMod
+}
+
+// PathState is the state of one pathway in a Checkpoint.
+type PathState struct {
+	Name   string
+	Syns   []leabra.Synapse
+	GScale float32
+	WbRecv []leabra.WtBalRecvPath
+}
+
+// CheckpointFilename returns the standard name of the checkpoint file
+// for the given network and run names.
+func CheckpointFilename(netName, runName string) string {
+	return netName + "_" + runName + "_ckpt.gob"
+}
+
+// Checkpointer saves a Checkpoint of the training state every Interval
+// epochs, and resumes a training run from a saved Checkpoint.
+// It has the elements of the sim that make up the training state.
+type Checkpointer struct {
+
+	// Interval is the number of training epochs between checkpoints.
+	// 0 means no checkpoints are saved.
+	Interval int
+
+	// File is the file name to save the checkpoints to,
+	// each of which replaces the previous one.
+	File string
+
+	Context *leabra.Context
+	Net     *leabra.Network
+	Envs    env.Envs
+	Stats   *estats.Stats
+	Logs    *elog.Logs
+
+	// Extra returns any other training state of the sim that is not in
+	// the elements above, e.g., the weights of a BPNet, to save in the
+	// Checkpoint, and SetExtra restores it, after the usual NewRun.
+	// They are skipped if nil.
+	Extra    func() ([]byte, error)
+	SetExtra func(b []byte) error
+}
+
+// Config adds the looper functions that save a checkpoint at the end
+// of every Interval training epochs, and, if resume is not empty, opens that
+// checkpoint file and sets the training counters to continue from it,
+// restoring its state at the start of its run, after the usual NewRun.
+// Must be called after the sim is initialized and its training run counter set.
+// The random number generators must be reseeded at the start of each epoch
+// with SeedEpochs, so resuming gives the same results as running without
+// interruption, and an error is returned if they are not.
+// Checkpoints are only saved at the end of an epoch, so a job killed
+// in the middle of an epoch redoes that epoch when resumed.
+func (cs *Checkpointer) Config(ls *looper.Stacks, resume string) error {
+	trn := ls.Stacks[etime.Train]
+	if _, err := trn.Loops[etime.Epoch].OnStart.FuncIndex(SeedEpochName); err != nil {
+		return fmt.Errorf("Checkpointer: the training epochs are not reseeded with SeedEpochs, so runs cannot be resumed exactly")
+	}
+	if cs.Interval > 0 {
+		trn.Loops[etime.Epoch].OnEnd.Add("Checkpoint", func() {
+			epc := trn.Loops[etime.Epoch].Counter.Cur + 1 // incremented after OnEnd
+			if epc%cs.Interval != 0 {
+				return
+			}
+			run := trn.Loops[etime.Run].Counter.Cur
+			cp, err := cs.Checkpoint(run, epc)
+			if err == nil {
+				err = cp.Save(cs.File)
+			}
+			if err != nil {
+				fmt.Println(err)
+			}
+		})
+	}
+	if resume == "" {
+		return nil
+	}
+	cp, err := OpenCheckpoint(resume)
+	if err != nil {
+		return err
+	}
+	runCtr := &trn.Loops[etime.Run].Counter
+	if cp.Run < runCtr.Cur || cp.Run >= runCtr.Max {
+		return fmt.Errorf("Checkpointer: checkpoint run %d is not in the runs to do: %d to %d", cp.Run, runCtr.Cur, runCtr.Max-1)
+	}
+	runCtr.Cur = cp.Run
+	trn.Loops[etime.Epoch].Counter.Cur = cp.Epoch
+	trn.Loops[etime.Run].OnStart.Add("Resume", func() {
+		if cp == nil || runCtr.Cur != cp.Run {
+			return
+		}
+		if err := cs.Restore(cp); err != nil {
+			fmt.Println(err)
+		}
+		fmt.Printf("Resumed run %d at epoch %d from: %s\n", cp.Run, cp.Epoch, resume)
+		cp = nil
+	})
+	return nil
+}
+
+// Checkpoint returns a new Checkpoint with the current state,
+// for the given run and (next) epoch.
+func (cs *Checkpointer) Checkpoint(run, epoch int) (*Checkpoint, error) {
+	cp := &Checkpoint{Run: run, Epoch: epoch, Context: *cs.Context}
+	for _, ly := range cs.Net.Layers {
+		cp.Layers = append(cp.Layers, LayerState{Name: ly.Name, Neurons: ly.Neurons, Pools: ly.Pools, CosDiff: ly.CosDiff, NeuroMod: ly.NeuroMod})
+		for _, pt := range ly.RecvPaths {
+			cp.Paths = append(cp.Paths, PathState{Name: pt.Name, Syns: pt.Syns, GScale: pt.GScale, WbRecv: pt.WbRecv})
+		}
+	}
+	cp.Envs = make(map[string]map[string][]byte)
+	for name, ev := range cs.Envs {
+		es, err := envState(ev)
+		if err != nil {
+			return nil, err
+		}
+		cp.Envs[name] = es
+	}
+	var err error
+	if cs.Extra != nil {
+		if cp.Extra, err = cs.Extra(); err != nil {
+			return nil, err
+		}
+	}
+	cp.Floats = cs.Stats.Floats
+	cp.Ints = cs.Stats.Ints
+	cp.Strings = cs.Stats.Strings
+	if cp.EpochLog, err = logRows(cs.Logs.Table(etime.Train, etime.Epoch)); err != nil {
+		return nil, err
+	}
+	if cp.TestEpochLog, err = logRows(cs.Logs.Table(etime.Test, etime.Epoch)); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// Restore restores the state from the given Checkpoint,
+// which must be from the same sim with the same network.
+func (cs *Checkpointer) Restore(cp *Checkpoint) error {
+	if len(cp.Layers) != len(cs.Net.Layers) {
+		return fmt.Errorf("Checkpointer: checkpoint has %d layers instead of %d", len(cp.Layers), len(cs.Net.Layers))
+	}
+	*cs.Context = cp.Context
+	pi := 0
+	for li, ly := range cs.Net.Layers {
+		ls := &cp.Layers[li]
+		if ls.Name != ly.Name || len(ls.Neurons) != len(ly.Neurons) || len(ls.Pools) != len(ly.Pools) {
+			return fmt.Errorf("Checkpointer: checkpoint layer %q does not match layer %q", ls.Name, ly.Name)
+		}
+		copy(ly.Neurons, ls.Neurons)
+		copy(ly.Pools, ls.Pools)
+		ly.CosDiff = ls.CosDiff
+		ly.NeuroMod = ls.NeuroMod
+		for _, pt := range ly.RecvPaths {
+			if pi >= len(cp.Paths) || len(cp.Paths[pi].Syns) != len(pt.Syns) {
+				return fmt.Errorf("Checkpointer: checkpoint paths do not match at: %s", pt.Name)
+			}
+			ps := &cp.Paths[pi]
+			copy(pt.Syns, ps.Syns)
+			pt.GScale = ps.GScale
+			copy(pt.WbRecv, ps.WbRecv)
+			pi++
+		}
+	}
+	for name, es := range cp.Envs {
+		ev, ok := cs.Envs[name]
+		if !ok {
+			return fmt.Errorf("Checkpointer: env %q not found", name)
+		}
+		if err := setEnvState(ev, es); err != nil {
+			return err
+		}
+	}
+	if cs.SetExtra != nil && cp.Extra != nil {
+		if err := cs.SetExtra(cp.Extra); err != nil {
+			return err
+		}
+	}
+	cs.Stats.Floats = cp.Floats
+	cs.Stats.Ints = cp.Ints
+	cs.Stats.Strings = cp.Strings
+	if err := setLogRows(cs.Logs.Table(etime.Train, etime.Epoch), cp.EpochLog); err != nil {
+		return err
+	}
+	if err := setLogRows(cs.Logs.Table(etime.Test, etime.Epoch), cp.TestEpochLog); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Save saves the Checkpoint to the given file.
+func (cp *Checkpoint) Save(fnm string) error {
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(cp); err != nil {
+		return err
+	}
+	// write to a temp file first, so a job killed while saving
+	// does not lose the previous checkpoint
+	if err := os.WriteFile(fnm+".tmp", b.Bytes(), 0666); err != nil {
+		return err
+	}
+	return os.Rename(fnm+".tmp", fnm)
+}
+
+// OpenCheckpoint opens a Checkpoint from the given file.
+func OpenCheckpoint(fnm string) (*Checkpoint, error) {
+	f, err := os.Open(fnm)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	cp := &Checkpoint{}
+	if err := gob.NewDecoder(f).Decode(cp); err != nil {
+		return nil, fmt.Errorf("OpenCheckpoint: %s: %w", fnm, err)
+	}
+	return cp, nil
+}
+
+// logRows returns the rows of the given log table as tab-separated values,
+// or "" if there is no such log. The values are written with full precision,
+// instead of that of the log files, so that they are restored exactly.
+func logRows(dt *table.Table) (string, error) {
+	if dt == nil {
+		return "", nil
+	}
+	if prec, ok := dt.MetaData["precision"]; ok {
+		delete(dt.MetaData, "precision")
+		defer func() { dt.MetaData["precision"] = prec }()
+	}
+	var b strings.Builder
+	err := dt.WriteCSV(&b, table.Tab, table.NoHeaders)
+	return b.String(), err
+}
+
+// setLogRows sets the rows of the given log table from logRows.
+func setLogRows(dt *table.Table, rows string) error {
+	if dt == nil || rows == "" {
+		return nil
+	}
+	return dt.ReadCSV(strings.NewReader(rows), table.Tab)
+}
+
+// envState returns the gob encoded values of the exported
+// plain data fields of the given env.
+func envState(ev env.Env) (map[string][]byte, error) {
+	es := make(map[string][]byte)
+	v := reflectx.NonPointerValue(reflect.ValueOf(ev))
+	if v.Kind() != reflect.Struct {
+		return es, nil
+	}
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if !f.IsExported() || !plainData(f.Type) {
+			continue
+		}
+		var b bytes.Buffer
+		if err := gob.NewEncoder(&b).Encode(v.Field(i).Interface()); err != nil {
+			return nil, fmt.Errorf("Checkpointer: env %s field %s: %w", ev.Label(), f.Name, err)
+		}
+		es[f.Name] = b.Bytes()
+	}
+	return es, nil
+}
+
+// setEnvState sets the fields of the given env from the
+// gob encoded values returned by envState.
+func setEnvState(ev env.Env, es map[string][]byte) error {
+	v := reflectx.NonPointerValue(reflect.ValueOf(ev))
+	for name, b := range es {
+		fv := v.FieldByName(name)
+		if !fv.IsValid() {
+			return fmt.Errorf("Checkpointer: env %s field %s not found", ev.Label(), name)
+		}
+		fv.Set(reflect.Zero(fv.Type())) // gob does not send zero values
+		if err := gob.NewDecoder(bytes.NewReader(b)).DecodeValue(fv); err != nil {
+			return fmt.Errorf("Checkpointer: env %s field %s: %w", ev.Label(), name, err)
+		}
+	}
+	return nil
+}
+
+// plainData returns true if the type only has plain data values
+// (numbers, strings, and slices and structs of those), with
+// at least one exported field for structs.
+func plainData(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	case reflect.Slice, reflect.Array:
+		return plainData(typ.Elem())
+	case reflect.Struct:
+		n := 0
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if !plainData(f.Type) {
+				return false
+			}
+			n++
+		}
+		return n > 0
+	}
+	return false
+}
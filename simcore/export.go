@@ -0,0 +1,229 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"cogentcore.org/core/base/errors"
+	"cogentcore.org/core/tensor"
+	"cogentcore.org/core/tensor/table"
+	"github.com/emer/emergent/v2/elog"
+)
+
+// ExportColumn describes one column of a table saved by SaveNPZ,
+// in the JSON sidecar file.
+type ExportColumn struct {
+
+	// Name is the column name, which is also the name of the array in the .npz file.
+	Name string `json:"name"`
+
+	// DType is the numpy dtype of the array, e.g., float32 or <U12 for strings.
+	DType string `json:"dtype"`
+
+	// Shape is the shape of the array, with the rows as the first dimension,
+	// followed by the cell shape of tensor columns (e.g., the layer shape).
+	Shape []int `json:"shape"`
+
+	// DimNames are the names of the cell dimensions, if the column has them.
+	DimNames []string `json:"dim_names,omitempty"`
+}
+
+// ExportInfo is the content of the JSON sidecar file saved with
+// a table by SaveNPZ, describing all of its columns.
+type ExportInfo struct {
+
+	// Name is the name of the table, e.g., TestTrial.
+	Name string `json:"name"`
+
+	// Rows is the number of rows.
+	Rows int `json:"rows"`
+
+	// Columns are the columns, in the table order.
+	Columns []ExportColumn `json:"columns"`
+
+	// Meta is the metadata of the table.
+	Meta map[string]string `json:"meta,omitempty"`
+}
+
+// SaveNPZ saves the given table to the given file name base with an .npz
+// extension, which has one numpy .npy array per column, named by the
+// column name, so that e.g., a column of layer activations is a single
+// array of shape [rows, layer shape...], and a .json sidecar file with
+// the ExportInfo describing them. In Python:
+//
+//	data = numpy.load("base.npz"); acts = data["Hidden_ActM"]
+func SaveNPZ(dt *table.Table, name, base string) error {
+	info := &ExportInfo{Name: name, Rows: dt.Rows, Meta: dt.MetaData}
+	f, err := os.Create(base + ".npz")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for i, cl := range dt.Columns {
+		cn := dt.ColumnNames[i]
+		var b bytes.Buffer
+		ec, err := WriteNPY(&b, cl, dt.Rows)
+		if err != nil {
+			return fmt.Errorf("SaveNPZ: column %q: %w", cn, err)
+		}
+		ec.Name = cn
+		info.Columns = append(info.Columns, *ec)
+		w, err := zw.Create(cn + ".npy")
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(b.Bytes()); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	var js bytes.Buffer
+	enc := json.NewEncoder(&js)
+	enc.SetEscapeHTML(false) // keep the < in the string dtypes
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(info); err != nil {
+		return err
+	}
+	return os.WriteFile(base+".json", js.Bytes(), 0666)
+}
+
+// WriteNPY writes the first rows of the given table column to the given
+// writer in the numpy .npy format, with the rows as the first dimension,
+// returning the ExportColumn describing it (without the name).
+// Numbers are written in their own type, except that other numbers than
+// float32, float64, int and int32 are converted to float64, and strings
+// are written as fixed-width unicode.
+func WriteNPY(w io.Writer, cl tensor.Tensor, rows int) (*ExportColumn, error) {
+	shp := cl.Shape()
+	ec := &ExportColumn{Shape: append([]int{rows}, shp.Sizes[1:]...)}
+	if len(shp.Names) > 1 && strings.Join(shp.Names[1:], "") != "" {
+		ec.DimNames = shp.Names[1:]
+	}
+	n := rows
+	for _, sz := range ec.Shape[1:] {
+		n *= sz
+	}
+	var data any
+	var descr string
+	switch tsr := cl.(type) {
+	case *tensor.Float32:
+		descr, ec.DType, data = "<f4", "float32", tsr.Values[:n]
+	case *tensor.Float64:
+		descr, ec.DType, data = "<f8", "float64", tsr.Values[:n]
+	case *tensor.Int:
+		vals := make([]int64, n)
+		for i := range vals {
+			vals[i] = int64(tsr.Values[i])
+		}
+		descr, ec.DType, data = "<i8", "int64", vals
+	case *tensor.Int32:
+		descr, ec.DType, data = "<i4", "int32", tsr.Values[:n]
+	case *tensor.String:
+		wd := 1
+		for _, s := range tsr.Values[:n] {
+			wd = max(wd, utf8.RuneCountInString(s))
+		}
+		vals := make([]uint32, n*wd) // UTF-32, zero padded
+		for i, s := range tsr.Values[:n] {
+			j := i * wd
+			for _, r := range s {
+				vals[j] = uint32(r)
+				j++
+			}
+		}
+		descr, data = fmt.Sprintf("<U%d", wd), vals
+		ec.DType = descr
+	default:
+		vals := make([]float64, n)
+		for i := range vals {
+			vals[i] = cl.Float1D(i)
+		}
+		descr, ec.DType, data = "<f8", "float64", vals
+	}
+	shape := make([]string, len(ec.Shape))
+	for i, sz := range ec.Shape {
+		shape[i] = fmt.Sprintf("%d,", sz) // trailing comma needed for 1D tuple
+	}
+	hdr := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': (%s), }", descr, strings.Join(shape, " "))
+	// the header is padded with spaces and a newline, to align the data to 64 bytes
+	pad := 64 - (10+len(hdr)+1)%64
+	hdr += strings.Repeat(" ", pad%64) + "\n"
+	if _, err := w.Write([]byte("\x93NUMPY\x01\x00")); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint16(len(hdr))); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write([]byte(hdr)); err != nil {
+		return nil, err
+	}
+	return ec, binary.Write(w, binary.LittleEndian, data)
+}
+
+// LogTable returns the table of the given log, named by mode and time
+// as in Figures, e.g., TestTrial.
+func LogTable(lg *elog.Logs, name string) (*table.Table, error) {
+	var nms []string
+	for _, key := range lg.TableOrder {
+		modes, times := key.ModesAndTimes()
+		nm := modes[0] + times[0]
+		if nm == name {
+			return lg.Tables[key].Table, nil
+		}
+		nms = append(nms, nm)
+	}
+	return nil, fmt.Errorf("log %q not found, available logs are: %s", name, strings.Join(nms, ", "))
+}
+
+// SaveLogsNPZ saves the given comma-separated logs, named by mode and time
+// (e.g., TestTrial), or all of them if "all", with SaveNPZ in the current
+// directory, using the standard log file names netName_runName_name,
+// with .npz and .json extensions. Logs with no rows are skipped for "all".
+func SaveLogsNPZ(lg *elog.Logs, names, netName, runName string) error {
+	var nms []string
+	if names == "all" {
+		for _, key := range lg.TableOrder {
+			if lg.Tables[key].Table.Rows == 0 {
+				continue
+			}
+			modes, times := key.ModesAndTimes()
+			nms = append(nms, modes[0]+times[0])
+		}
+	} else {
+		for _, nm := range strings.Split(names, ",") {
+			if nm = strings.TrimSpace(nm); nm != "" {
+				nms = append(nms, nm)
+			}
+		}
+	}
+	var errs []error
+	for _, nm := range nms {
+		dt, err := LogTable(lg, nm)
+		if err == nil {
+			fnm := elog.LogFilename(nm, netName, runName)
+			base := strings.TrimSuffix(fnm, filepath.Ext(fnm))
+			if err = SaveNPZ(dt, nm, base); err == nil {
+				fmt.Printf("Saved: %s, %s\n", base+".npz", base+".json")
+			}
+		}
+		if errors.Log(err) != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
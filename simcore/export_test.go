@@ -0,0 +1,140 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"cogentcore.org/core/tensor/table"
+)
+
+// readNPY reads the .npy data in the given bytes, checking the magic
+// string, the version and the 64 byte alignment of the data,
+// and returns the header dictionary and the data.
+func readNPY(t *testing.T, name string, b []byte) (string, []byte) {
+	t.Helper()
+	if len(b) < 10 || string(b[:8]) != "\x93NUMPY\x01\x00" {
+		t.Fatalf("%s: no NUMPY version 1.0 magic string: %q", name, b[:min(len(b), 8)])
+	}
+	hlen := int(binary.LittleEndian.Uint16(b[8:10]))
+	if (10+hlen)%64 != 0 {
+		t.Errorf("%s: the data starts at %d, not aligned to 64 bytes", name, 10+hlen)
+	}
+	hdr := string(b[10 : 10+hlen])
+	if hdr[len(hdr)-1] != '\n' {
+		t.Errorf("%s: the header does not end with a newline: %q", name, hdr)
+	}
+	return string(bytes.TrimRight(b[10:10+hlen], " \n")), b[10+hlen:]
+}
+
+// TestSaveNPZ checks the .npy arrays and the JSON sidecar saved by SaveNPZ
+// for float32 tensor, float64, int and string columns, by reading them back.
+func TestSaveNPZ(t *testing.T) {
+	dt := table.NewTable()
+	act := dt.AddFloat32TensorColumn("Act", []int{2, 3}, "Y", "X")
+	dt.AddFloat64Column("SSE")
+	dt.AddIntColumn("Epoch")
+	dt.AddStringColumn("Name")
+	dt.SetNumRows(3)
+	for i := range act.Values {
+		act.Values[i] = float32(i) / 4
+	}
+	names := []string{"a", "bcd", "é"}
+	for row := range 3 {
+		dt.SetFloat("SSE", row, float64(row)+0.5)
+		dt.SetFloat("Epoch", row, float64(10*row))
+		dt.SetString("Name", row, names[row])
+	}
+	dt.SetMetaData("desc", "test table")
+
+	base := filepath.Join(t.TempDir(), "test")
+	if err := SaveNPZ(dt, "TestTrial", base); err != nil {
+		t.Fatal(err)
+	}
+
+	u := func(s string, wd int) []uint32 { // UTF-32, zero padded
+		vals := make([]uint32, wd)
+		for i, r := range []rune(s) {
+			vals[i] = uint32(r)
+		}
+		return vals
+	}
+	tests := []struct {
+		name string
+		hdr  string
+		data any
+	}{
+		{"Act", "{'descr': '<f4', 'fortran_order': False, 'shape': (3, 2, 3,), }", act.Values},
+		{"SSE", "{'descr': '<f8', 'fortran_order': False, 'shape': (3,), }", []float64{0.5, 1.5, 2.5}},
+		{"Epoch", "{'descr': '<i8', 'fortran_order': False, 'shape': (3,), }", []int64{0, 10, 20}},
+		{"Name", "{'descr': '<U3', 'fortran_order': False, 'shape': (3,), }", append(append(u("a", 3), u("bcd", 3)...), u("é", 3)...)},
+	}
+	zr, err := zip.OpenReader(base + ".npz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	if len(zr.File) != len(tests) {
+		t.Errorf("%d arrays in the .npz file instead of %d", len(zr.File), len(tests))
+	}
+	for i, tt := range tests {
+		if i >= len(zr.File) {
+			break
+		}
+		zf := zr.File[i]
+		if zf.Name != tt.name+".npy" {
+			t.Errorf("array %d: %q != %q", i, zf.Name, tt.name+".npy")
+			continue
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		hdr, data := readNPY(t, tt.name, b)
+		if hdr != tt.hdr {
+			t.Errorf("%s: header %q != %q", tt.name, hdr, tt.hdr)
+		}
+		var want bytes.Buffer
+		binary.Write(&want, binary.LittleEndian, tt.data)
+		if !bytes.Equal(data, want.Bytes()) {
+			t.Errorf("%s: data %v != %v", tt.name, data, want.Bytes())
+		}
+	}
+
+	js, err := os.ReadFile(base + ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(js, []byte(`"<U3"`)) {
+		t.Errorf("the string dtype is escaped in the JSON:\n%s", js)
+	}
+	var info ExportInfo
+	if err := json.Unmarshal(js, &info); err != nil {
+		t.Fatal(err)
+	}
+	want := ExportInfo{Name: "TestTrial", Rows: 3, Meta: map[string]string{"desc": "test table"},
+		Columns: []ExportColumn{
+			{Name: "Act", DType: "float32", Shape: []int{3, 2, 3}, DimNames: []string{"Y", "X"}},
+			{Name: "SSE", DType: "float64", Shape: []int{3}},
+			{Name: "Epoch", DType: "int64", Shape: []int{3}},
+			{Name: "Name", DType: "<U3", Shape: []int{3}},
+		}}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("JSON sidecar:\n%+v\n!= %+v", info, want)
+	}
+}
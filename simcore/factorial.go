@@ -0,0 +1,246 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"cogentcore.org/core/base/errors"
+	"cogentcore.org/core/colors"
+	"cogentcore.org/core/paint"
+	"cogentcore.org/core/plot"
+	"cogentcore.org/core/plot/plots"
+	"cogentcore.org/core/tensor/table"
+	"github.com/emer/emergent/v2/elog"
+)
+
+// EnumNames returns the names of the given enum values, e.g., from
+// the generated LearnTypeValues(), for the Values of a SweepParam.
+func EnumNames[T fmt.Stringer](vals []T) []string {
+	nms := make([]string, len(vals))
+	for i, v := range vals {
+		nms[i] = v.String()
+	}
+	return nms
+}
+
+// AddCriterionColumns adds two columns to the given run log, based on the
+// given column with the epoch at which the run first reached criterion,
+// which is -1 if it never did (e.g., FirstZero): Solved is 1 if the run
+// reached criterion and 0 otherwise, and EpochsToCrit is the epoch at which
+// it did, or NaN if it did not, so that its mean is only over the solved runs.
+func AddCriterionColumns(dt *table.Table, firstZero string) error {
+	fz, err := dt.ColumnByName(firstZero)
+	if err != nil {
+		return fmt.Errorf("AddCriterionColumns: %w", err)
+	}
+	solved := dt.AddFloat64Column("Solved")
+	crit := dt.AddFloat64Column("EpochsToCrit")
+	for row := range dt.Rows {
+		ep := fz.Float1D(row)
+		if ep < 0 {
+			solved.SetFloat1D(row, 0)
+			crit.SetFloat1D(row, math.NaN())
+		} else {
+			solved.SetFloat1D(row, 1)
+			crit.SetFloat1D(row, ep)
+		}
+	}
+	return nil
+}
+
+// FactorialSummary returns a table with the RunSummary of the given metric
+// columns for each condition of a factorial experiment, i.e., for each
+// combination of the values of the given factor columns, as in the results
+// of a Grid Sweep. There is one row per condition and metric, with a string
+// column for each factor, followed by the columns of the RunSummary.
+// Conditions are in the order in which they first appear in the table.
+func FactorialSummary(dt *table.Table, factors []string, metrics ...string) (*table.Table, error) {
+	st := table.NewTable("FactorialSummary")
+	for _, fn := range factors {
+		if _, err := dt.ColumnIndex(fn); err != nil {
+			return st, fmt.Errorf("FactorialSummary: %w", err)
+		}
+		st.AddStringColumn(fn)
+	}
+	st.AddStringColumn("Metric")
+	addSummaryColumns(st)
+	metrics = summaryMetrics(dt, metrics)
+	var conds [][]string
+	idxs := map[string][]int{}
+	for row := range dt.Rows {
+		vals := make([]string, len(factors))
+		for i, fn := range factors {
+			vals[i] = dt.StringValue(fn, row)
+		}
+		cond := strings.Join(vals, "\t")
+		if _, has := idxs[cond]; !has {
+			conds = append(conds, vals)
+		}
+		idxs[cond] = append(idxs[cond], row)
+	}
+	for _, vals := range conds {
+		ix := table.NewIndexView(dt)
+		ix.Indexes = idxs[strings.Join(vals, "\t")]
+		st0 := st.Rows
+		if err := addSummaryRows(st, ix, metrics); err != nil {
+			return st, fmt.Errorf("FactorialSummary: %w", err)
+		}
+		for row := st0; row < st.Rows; row++ {
+			for i, fn := range factors {
+				st.SetString(fn, row, vals[i])
+			}
+		}
+	}
+	return st, nil
+}
+
+// FactorialMarkdown returns the given FactorialSummary table as a markdown
+// table, with the 95% confidence interval in brackets.
+func FactorialMarkdown(st *table.Table, factors []string) string {
+	var b strings.Builder
+	for _, fn := range factors {
+		b.WriteString("| " + fn + " ")
+	}
+	b.WriteString("| Metric | N | Mean | SD | SEM | 95% CI |\n")
+	b.WriteString(strings.Repeat("|------", len(factors)))
+	b.WriteString("|--------|--:|-----:|---:|----:|--------|\n")
+	for row := range st.Rows {
+		for _, fn := range factors {
+			b.WriteString("| " + st.StringValue(fn, row) + " ")
+		}
+		fmt.Fprintf(&b, "| %s | %d | %.4g | %.4g | %.4g | [%.4g, %.4g] |\n",
+			st.StringValue("Metric", row), int(st.Float("N", row)),
+			st.Float("Mean", row), st.Float("SD", row), st.Float("SEM", row),
+			st.Float("CI95Lo", row), st.Float("CI95Hi", row))
+	}
+	return b.String()
+}
+
+// FactorialPlot returns a grouped bar plot of the means of the given metric
+// in the given FactorialSummary table, with error bars for the 95% confidence
+// intervals, which can be rendered without the GUI. The values of the x factor
+// are along the X axis, with a group of bars for each, one per value of the
+// legend factor, which can be empty if there is only one factor.
+func FactorialPlot(st *table.Table, metric, x, legend, title string) (*plot.Plot, error) {
+	var xvals, lvals []string
+	for row := range st.Rows {
+		if st.StringValue("Metric", row) != metric {
+			continue
+		}
+		if xv := st.StringValue(x, row); !slices.Contains(xvals, xv) {
+			xvals = append(xvals, xv)
+		}
+		if legend != "" {
+			if lv := st.StringValue(legend, row); !slices.Contains(lvals, lv) {
+				lvals = append(lvals, lv)
+			}
+		}
+	}
+	if len(xvals) == 0 {
+		return nil, fmt.Errorf("FactorialPlot: metric %q not found", metric)
+	}
+	if legend == "" {
+		lvals = []string{""}
+	}
+	nleg := len(lvals)
+	stride := nleg
+	if stride > 1 {
+		stride++ // gap between groups
+	}
+	// the GUI does this when it starts, but it is needed to render text without it
+	paint.FontLibrary.InitFontPaths(paint.FontPaths...)
+	pt := plot.New()
+	pt.Title.Text = title
+	pt.Y.Label.Text = metric + " Mean (95% CI)"
+	for li, lv := range lvals {
+		means := make(plot.Values, len(xvals))
+		cis := make(plot.Values, len(xvals))
+		for row := range st.Rows {
+			if st.StringValue("Metric", row) != metric || (legend != "" && st.StringValue(legend, row) != lv) {
+				continue
+			}
+			xi := slices.Index(xvals, st.StringValue(x, row))
+			mean := st.Float("Mean", row)
+			ci := st.Float("CI95Hi", row) - mean
+			if math.IsNaN(mean) {
+				mean, ci = 0, 0 // no values, and NaN values are skipped by the bar chart
+			}
+			means[xi] = float32(mean)
+			cis[xi] = float32(ci)
+		}
+		bc, err := plots.NewBarChart(means, cis)
+		if err != nil {
+			return nil, err
+		}
+		bc.Offset = float32(li) - float32(nleg-1)/2 // center the group on its label
+		bc.Stride = float32(stride)
+		if nleg > 1 {
+			bc.Color = colors.Uniform(colors.Spaced(li))
+		}
+		pt.Add(bc)
+		if legend != "" {
+			pt.Legend.Add(lv, bc)
+		}
+	}
+	names := make([]string, (len(xvals)-1)*stride+1)
+	for i, xv := range xvals {
+		names[i*stride] = xv
+	}
+	pt.NominalX(names...)
+	pt.X.Label.Text = x
+	pt.Resize(image.Point{800, 600})
+	return pt, nil
+}
+
+// SaveFactorial saves the results of a factorial experiment in the given
+// table (e.g., from a Grid Sweep over the given factors), in the current
+// directory, using the standard log file names netName_runName_factorial
+// with extensions: .tsv for all of the results, and _summary.tsv and
+// _summary.md for the FactorialSummary of the given metrics,
+// and _<metric>.svg and .png for a FactorialPlot of each metric,
+// with the last factor on the X axis and the first one in the legend.
+func SaveFactorial(dt *table.Table, factors, metrics []string, netName, runName string) error {
+	if err := SaveTable(dt, "factorial", netName, runName); err != nil {
+		return err
+	}
+	st, err := FactorialSummary(dt, factors, metrics...)
+	if err != nil {
+		return err
+	}
+	if err := SaveTable(st, "factorial_summary", netName, runName); err != nil {
+		return err
+	}
+	fnm := elog.LogFilename("factorial", netName, runName)
+	base := strings.TrimSuffix(fnm, filepath.Ext(fnm))
+	if err := os.WriteFile(base+"_summary.md", []byte(FactorialMarkdown(st, factors)), 0666); errors.Log(err) != nil {
+		return err
+	}
+	fmt.Printf("Saved: %s\n", base+"_summary.md")
+	x := factors[len(factors)-1]
+	legend := ""
+	if len(factors) > 1 {
+		legend = factors[0]
+	}
+	for _, m := range summaryMetrics(dt, metrics) {
+		pt, err := FactorialPlot(st, m, x, legend, netName+": "+m)
+		if errors.Log(err) != nil {
+			return err
+		}
+		pt.Draw()
+		mbase := base + "_" + m
+		if err := SavePlotImage(pt, mbase); errors.Log(err) != nil {
+			return err
+		}
+		fmt.Printf("Saved: %s, %s\n", mbase+".svg", mbase+".png")
+	}
+	return nil
+}
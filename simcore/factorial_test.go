@@ -0,0 +1,129 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"cogentcore.org/core/tensor/table"
+)
+
+// factorialTestTable returns the results of a 2 x 2 grid sweep
+// of Learn by Lrate, with 2 runs per condition, interleaved as
+// in the results of parallel runs, with their SSE and FirstZero.
+func factorialTestTable() *table.Table {
+	dt := table.NewTable()
+	dt.AddStringColumn("Learn")
+	dt.AddFloat64Column("Lrate")
+	dt.AddFloat64Column("SSE")
+	dt.AddFloat64Column("FirstZero")
+	rows := []struct {
+		learn      string
+		lrate, sse float64
+		firstZero  float64
+	}{
+		{"Hebbian", 0.01, 4, -1},
+		{"Hebbian", 0.1, 2, 10},
+		{"ErrorDriven", 0.01, 1, 20},
+		{"ErrorDriven", 0.1, 0, 5},
+		{"Hebbian", 0.01, 6, -1},
+		{"Hebbian", 0.1, 3, 12},
+		{"ErrorDriven", 0.01, 2, 30},
+		{"ErrorDriven", 0.1, 0, 7},
+	}
+	dt.SetNumRows(len(rows))
+	for row, r := range rows {
+		dt.SetString("Learn", row, r.learn)
+		dt.SetFloat("Lrate", row, r.lrate)
+		dt.SetFloat("SSE", row, r.sse)
+		dt.SetFloat("FirstZero", row, r.firstZero)
+	}
+	return dt
+}
+
+func TestFactorialSummary(t *testing.T) {
+	dt := factorialTestTable()
+	if err := AddCriterionColumns(dt, "FirstZero"); err != nil {
+		t.Fatal(err)
+	}
+	factors := []string{"Learn", "Lrate"}
+	st, err := FactorialSummary(dt, factors, "SSE", "EpochsToCrit", "Solved")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		learn, lrate, metric string
+		n, mean              float64
+	}{
+		{"Hebbian", "0.01", "SSE", 2, 5},
+		{"Hebbian", "0.01", "EpochsToCrit", 0, math.NaN()},
+		{"Hebbian", "0.01", "Solved", 2, 0},
+		{"Hebbian", "0.1", "SSE", 2, 2.5},
+		{"Hebbian", "0.1", "EpochsToCrit", 2, 11},
+		{"Hebbian", "0.1", "Solved", 2, 1},
+		{"ErrorDriven", "0.01", "SSE", 2, 1.5},
+		{"ErrorDriven", "0.01", "EpochsToCrit", 2, 25},
+		{"ErrorDriven", "0.01", "Solved", 2, 1},
+		{"ErrorDriven", "0.1", "SSE", 2, 0},
+		{"ErrorDriven", "0.1", "EpochsToCrit", 2, 6},
+		{"ErrorDriven", "0.1", "Solved", 2, 1},
+	}
+	if st.Rows != len(want) {
+		t.Fatalf("%d rows != %d", st.Rows, len(want))
+	}
+	for row, w := range want {
+		got := []string{st.StringValue("Learn", row), st.StringValue("Lrate", row), st.StringValue("Metric", row)}
+		if strings.Join(got, " ") != w.learn+" "+w.lrate+" "+w.metric {
+			t.Errorf("row %d: condition %v != %s %s %s", row, got, w.learn, w.lrate, w.metric)
+			continue
+		}
+		if n, mean := st.Float("N", row), st.Float("Mean", row); n != w.n || !floatEqual(mean, w.mean) {
+			t.Errorf("row %d %v: N = %g, Mean = %g != %g, %g", row, got, n, mean, w.n, w.mean)
+		}
+	}
+	if sd := st.Float("SD", 0); !floatEqual(sd, math.Sqrt2) {
+		t.Errorf("Hebbian 0.01 SSE: SD = %g != %g", sd, math.Sqrt2)
+	}
+
+	md := FactorialMarkdown(st, factors)
+	if lines := strings.Split(strings.TrimSpace(md), "\n"); len(lines) != len(want)+2 || !strings.HasPrefix(lines[0], "| Learn | Lrate | Metric |") || !strings.HasPrefix(lines[2], "| Hebbian | 0.01 | SSE | 2 | 5 |") {
+		t.Errorf("FactorialMarkdown:\n%s", md)
+	}
+
+	// a single factor, with all the metrics by default
+	st, err = FactorialSummary(factorialTestTable(), []string{"Learn"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Rows != 6 || st.StringValue("Metric", 0) != "Lrate" || st.Float("N", 1) != 4 || st.Float("Mean", 1) != 3.75 {
+		t.Errorf("single factor: %d rows, %s, N = %g, Mean = %g", st.Rows, st.StringValue("Metric", 0), st.Float("N", 1), st.Float("Mean", 1))
+	}
+
+	if _, err := FactorialSummary(dt, []string{"Missing"}, "SSE"); err == nil {
+		t.Error("no error for a missing factor")
+	}
+	if _, err := FactorialSummary(dt, factors, "Missing"); err == nil {
+		t.Error("no error for a missing metric")
+	}
+}
+
+func TestAddCriterionColumns(t *testing.T) {
+	dt := factorialTestTable()
+	if err := AddCriterionColumns(dt, "Missing"); err == nil {
+		t.Error("no error for a missing FirstZero column")
+	}
+	if err := AddCriterionColumns(dt, "FirstZero"); err != nil {
+		t.Fatal(err)
+	}
+	for row := range dt.Rows {
+		fz := dt.Float("FirstZero", row)
+		solved, crit := dt.Float("Solved", row), dt.Float("EpochsToCrit", row)
+		if (fz < 0 && (solved != 0 || !math.IsNaN(crit))) || (fz >= 0 && (solved != 1 || crit != fz)) {
+			t.Errorf("row %d: FirstZero %g: Solved = %g, EpochsToCrit = %g", row, fz, solved, crit)
+		}
+	}
+}
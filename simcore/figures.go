@@ -0,0 +1,496 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"cogentcore.org/core/base/errors"
+	"cogentcore.org/core/base/iox/imagex"
+	"cogentcore.org/core/colors"
+	"cogentcore.org/core/colors/colormap"
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/math32/minmax"
+	"cogentcore.org/core/paint"
+	"cogentcore.org/core/plot"
+	"cogentcore.org/core/plot/plotcore"
+	"cogentcore.org/core/styles"
+	"cogentcore.org/core/tensor"
+	"cogentcore.org/core/tensor/tensorcore"
+	"github.com/emer/emergent/v2/actrf"
+	"github.com/emer/emergent/v2/egui"
+	"github.com/emer/emergent/v2/elog"
+	"github.com/emer/leabra/v2/leabra"
+)
+
+// Figures are the plots and grids of a sim that can be saved to image files
+// by name without the GUI, so that the figures in the README can be
+// regenerated by a script. The plots are configured by the same functions
+// as the GUI plots, on plot editors that are not shown.
+type Figures struct {
+
+	// Size is the size of the plot images, and the maximum size
+	// of the grid images, in pixels.
+	Size image.Point
+
+	// Plots are the plots by name: the log plots are named by
+	// mode and time, e.g., TrainEpoch.
+	Plots map[string]*plotcore.PlotEditor
+
+	// Grids are the tensors that are shown as grids, by name.
+	Grids map[string]tensor.Tensor
+
+	// Nets are the networks that are shown as a snapshot of the unit
+	// variable given in NetVars for each layer, by name.
+	Nets map[string]*leabra.Network
+
+	// NetVars are the unit variables shown for each of the Nets.
+	NetVars map[string]string
+
+	// body holds the plots, which need a scene for their styles.
+	body *core.Body
+}
+
+// Plots adds the plots and grids of a sim, other than its NetView, either
+// to the tabs of the GUI, with GUIPlots, or to its Figures, so that each sim
+// configures them once, in its ConfigPlots method, for both.
+type Plots interface {
+
+	// AddLogPlots adds a plot for each of the log tables,
+	// configured as in egui.AddPlots.
+	AddLogPlots(title string, lg *elog.Logs)
+
+	// AddPlot adds a new plot of the given name,
+	// returning it to be configured.
+	AddPlot(name string) *plotcore.PlotEditor
+
+	// AddGrid adds the given tensor as a grid of the given name.
+	AddGrid(name string, tsr tensor.Tensor)
+
+	// AddActRFGrids adds a grid for each of the given ActRFs.
+	AddActRFGrids(arfs *actrf.RFs)
+}
+
+// GUIPlots adds the Plots to the tabs of the GUI.
+type GUIPlots struct {
+
+	// GUI is the GUI to add the tabs to.
+	GUI *egui.GUI
+
+	// Tabs are the labels of the tabs of the plots and grids with
+	// the given names, for those labeled other than by their name.
+	Tabs map[string]string
+}
+
+// newTab returns a new tab for the plot or grid of the given name.
+func (gp GUIPlots) newTab(name string) *core.Frame {
+	label, ok := gp.Tabs[name]
+	if !ok {
+		label = name
+	}
+	tab, _ := gp.GUI.Tabs.NewTab(label)
+	return tab
+}
+
+func (gp GUIPlots) AddLogPlots(title string, lg *elog.Logs) {
+	gp.GUI.AddPlots(title, lg)
+}
+
+func (gp GUIPlots) AddPlot(name string) *plotcore.PlotEditor {
+	plt := plotcore.NewSubPlot(gp.newTab(name))
+	gp.GUI.SetPlotByName(name, plt)
+	return plt
+}
+
+func (gp GUIPlots) AddGrid(name string, tsr tensor.Tensor) {
+	gp.GUI.SetGrid(name, tensorcore.NewTensorGrid(gp.newTab(name)).SetTensor(tsr))
+}
+
+func (gp GUIPlots) AddActRFGrids(arfs *actrf.RFs) {
+	gp.GUI.AddActRFGridTabs(arfs)
+}
+
+// Config initializes the figures, with a NetView snapshot of the Act values
+// of the given network, and the plots and grids added by configPlots,
+// which is the ConfigPlots method of the sim that also adds them to the GUI.
+func (fg *Figures) Config(net *leabra.Network, configPlots func(pl Plots)) {
+	fg.Init()
+	fg.AddNetView("NetView", net, "Act")
+	configPlots(fg)
+}
+
+// Init initializes the figures, removing any existing ones.
+func (fg *Figures) Init() {
+	fg.Size = image.Point{800, 600}
+	fg.Plots = make(map[string]*plotcore.PlotEditor)
+	fg.Grids = make(map[string]tensor.Tensor)
+	fg.Nets = make(map[string]*leabra.Network)
+	fg.NetVars = make(map[string]string)
+	fg.body = core.NewBody("figures")
+	// the GUI does this when it starts, but it is needed to render text without it
+	paint.FontLibrary.InitFontPaths(paint.FontPaths...)
+}
+
+// AddLogPlots adds a plot for each of the log tables, configured as
+// in egui.AddPlots, named by mode and time, e.g., TrainEpoch.
+func (fg *Figures) AddLogPlots(title string, lg *elog.Logs) {
+	for _, key := range lg.TableOrder {
+		lt := lg.Tables[key]
+		if doplot, has := lt.Meta["Plot"]; has && doplot == "false" {
+			continue
+		}
+		modes, times := key.ModesAndTimes()
+		plt := fg.AddPlot(modes[0] + times[0])
+		plt.SetTable(lt.Table)
+		plt.Options.FromMetaMap(lt.Meta)
+		egui.ConfigPlotFromLog(title, plt, lg, key)
+	}
+}
+
+// AddPlot adds a new plot of the given name, for a plot that is
+// in a misc plot tab in the GUI, returning it to be configured.
+func (fg *Figures) AddPlot(name string) *plotcore.PlotEditor {
+	plt := plotcore.NewPlotEditor(fg.body)
+	fg.Plots[name] = plt
+	return plt
+}
+
+// AddGrid adds the given tensor as a grid of the given name,
+// which is rendered using the same tensorcore.TensorDisplay
+// options as in the GUI, from the metadata of the tensor.
+func (fg *Figures) AddGrid(name string, tsr tensor.Tensor) {
+	fg.Grids[name] = tsr
+}
+
+// AddActRFGrids adds a grid for each of the given ActRFs,
+// as in egui.AddActRFGridTabs.
+func (fg *Figures) AddActRFGrids(arfs *actrf.RFs) {
+	for _, rf := range arfs.RFs {
+		fg.AddGrid(rf.Name, &rf.NormRF)
+	}
+}
+
+// AddNetView adds a snapshot of the given network of the given name,
+// which has a grid of the given unit variable (e.g., Act) for each
+// layer, stacked with the first layer at the bottom as in the NetView.
+func (fg *Figures) AddNetView(name string, net *leabra.Network, varName string) {
+	fg.Nets[name] = net
+	fg.NetVars[name] = varName
+}
+
+// Plot returns the plot of the given name, or nil if there is none.
+func (fg *Figures) Plot(name string) *plotcore.PlotEditor {
+	return fg.Plots[name]
+}
+
+// PlotByName returns the plot of the given name in the given GUI if it is
+// active, or else the figure plot of that name, or nil if there is neither,
+// so that the plots can be updated in the same way with or without the GUI,
+// except that GoUpdatePlot must only be called when the GUI is active.
+func (fg *Figures) PlotByName(gui *egui.GUI, name string) *plotcore.PlotEditor {
+	if plt := gui.PlotByName(name); plt != nil {
+		return plt
+	}
+	return fg.Plots[name]
+}
+
+// Names returns the sorted names of all of the figures.
+func (fg *Figures) Names() []string {
+	var nms []string
+	for nm := range fg.Plots {
+		nms = append(nms, nm)
+	}
+	for nm := range fg.Grids {
+		nms = append(nms, nm)
+	}
+	for nm := range fg.Nets {
+		nms = append(nms, nm)
+	}
+	slices.Sort(nms)
+	return nms
+}
+
+// Save saves the given comma-separated figure names, or all of them
+// if "all", to .png and .svg files in the current directory, using
+// the standard log file names netName_runName_name.
+func (fg *Figures) Save(names, netName, runName string) error {
+	var nms []string
+	if names == "all" {
+		nms = fg.Names()
+	} else {
+		for _, nm := range strings.Split(names, ",") {
+			if nm = strings.TrimSpace(nm); nm != "" {
+				nms = append(nms, nm)
+			}
+		}
+	}
+	var errs []error
+	for _, nm := range nms {
+		fnm := elog.LogFilename(nm, netName, runName)
+		base := strings.TrimSuffix(fnm, filepath.Ext(fnm))
+		var err error
+		if plt, ok := fg.Plots[nm]; ok {
+			err = SavePlot(plt, fg.Size, base)
+		} else if tsr, ok := fg.Grids[nm]; ok {
+			err = SaveGrid(tsr, fg.Size, base)
+		} else if net, ok := fg.Nets[nm]; ok {
+			err = SaveNetView(net, fg.NetVars[nm], fg.Size, base)
+		} else {
+			err = fmt.Errorf("Figures: %q not found, available figures are: %s", nm, strings.Join(fg.Names(), ", "))
+		}
+		if errors.Log(err) != nil {
+			errs = append(errs, err)
+			continue
+		}
+		fmt.Printf("Saved: %s, %s\n", base+".svg", base+".png")
+	}
+	return errors.Join(errs...)
+}
+
+// SavePlot renders the given plot editor without the GUI at the given size,
+// and saves it to the given file name base with .png and .svg extensions.
+func SavePlot(plt *plotcore.PlotEditor, size image.Point, base string) error {
+	plt.UpdatePlot()
+	pw, ok := plt.ChildByName("plot", 1).(*plotcore.Plot)
+	if !ok || pw.Plot == nil {
+		return fmt.Errorf("SavePlot: no plot for %q", base)
+	}
+	pt := pw.Plot
+	pt.Resize(size)
+	if pw.SetRangesFunc != nil {
+		pw.SetRangesFunc()
+	}
+	pt.Draw()
+	return SavePlotImage(pt, base)
+}
+
+// SavePlotImage saves the given plot, which has been drawn, to the given
+// file name base with .png and .svg extensions. The plots do not yet render
+// text to SVG, so the SVG has the plot image embedded in it.
+func SavePlotImage(pt *plot.Plot, base string) error {
+	if err := pt.SaveImage(base + ".png"); err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	if err := png.Encode(&b, pt.Pixels); err != nil {
+		return err
+	}
+	sz := pt.Pixels.Bounds().Size()
+	svg := fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">
+<image width="%d" height="%d" href="data:image/png;base64,%s"/>
+</svg>
+`, sz.X, sz.Y, sz.X, sz.Y, sz.X, sz.Y, base64.StdEncoding.EncodeToString(b.Bytes()))
+	return os.WriteFile(base+".svg", []byte(svg), 0666)
+}
+
+// SaveGrid renders the given tensor as a grid, as in the tensorcore.TensorGrid
+// of the GUI with the display options from its metadata, and saves it to the
+// given file name base with .png and .svg extensions. The grid squares are
+// as large as will fit within the given size.
+func SaveGrid(tsr tensor.Tensor, size image.Point, base string) error {
+	if tsr == nil || tsr.Len() == 0 {
+		return fmt.Errorf("SaveGrid: no values for %q", base)
+	}
+	td := gridDisplay(tsr)
+	rows, cols, rowEx, colEx := tensor.Projection2DShape(tsr.Shape(), td.OddRow)
+	frw := float32(rows) + float32(rowEx)*td.DimExtra
+	fcl := float32(cols) + float32(colEx)*td.DimExtra
+	sq := max(min(float32(size.X)/fcl, float32(size.Y)/frw), 1)
+	gf := &gridFigure{size: image.Point{int(fcl * sq), int(frw * sq)}}
+	gf.addGrid(tsr, td, math32.Vector2{}, sq)
+	return gf.save(base)
+}
+
+// SaveNetView renders a snapshot of the given unit variable for each layer
+// of the given network as a grid, stacked with the first layer at the bottom
+// and labeled with the layer names, and saves it to the given file name base
+// with .png and .svg extensions. Layers that are Off are skipped.
+func SaveNetView(net *leabra.Network, varName string, size image.Point, base string) error {
+	const labelWidth = 120
+	const gap = 0.5 // in grid squares
+	var tsrs []tensor.Tensor
+	var names []string
+	maxCols := float32(1)
+	totRows := float32(0)
+	for li := len(net.Layers) - 1; li >= 0; li-- {
+		ly := net.Layers[li]
+		if ly.Off {
+			continue
+		}
+		tsr := tensor.NewFloat32(nil)
+		if err := ly.UnitValuesTensor(tsr, varName, 0); err != nil {
+			return err
+		}
+		tsrs = append(tsrs, tsr)
+		names = append(names, ly.Name)
+		rows, cols, rowEx, colEx := tensor.Projection2DShape(tsr.Shape(), false)
+		maxCols = max(maxCols, float32(cols)+float32(colEx)*0.1)
+		totRows += float32(rows) + float32(rowEx)*0.1 + gap
+	}
+	sq := max(min(float32(size.X-labelWidth)/maxCols, float32(size.Y)/totRows, 16), 1)
+	gf := &gridFigure{size: image.Point{labelWidth + int(maxCols*sq), int(totRows * sq)}}
+	pos := math32.Vector2{X: labelWidth, Y: 0.5 * gap * sq}
+	for i, tsr := range tsrs {
+		td := &tensorcore.TensorDisplay{}
+		td.Defaults()
+		td.Range.SetMin(0)
+		rows, _, rowEx, _ := tensor.Projection2DShape(tsr.Shape(), false)
+		ht := (float32(rows) + float32(rowEx)*td.DimExtra) * sq
+		gf.labels = append(gf.labels, gridLabel{pos: math32.Vector2{X: 4, Y: pos.Y + 0.5*ht}, text: names[i]})
+		gf.addGrid(tsr, td, pos, sq)
+		pos.Y += ht + gap*sq
+	}
+	return gf.save(base)
+}
+
+// gridDisplay returns the display options for the given tensor,
+// set from its metadata, with the range updated from its values
+// as in tensorcore.TensorGrid.
+func gridDisplay(tsr tensor.Tensor) *tensorcore.TensorDisplay {
+	td := &tensorcore.TensorDisplay{}
+	td.Defaults()
+	td.FromMeta(tsr)
+	if !td.Range.FixMin || !td.Range.FixMax {
+		mn, mx, _, _ := tsr.Range()
+		if !td.Range.FixMin {
+			td.Range.Min = minmax.NiceRoundNumber(mn, true)
+		}
+		if !td.Range.FixMax {
+			td.Range.Max = minmax.NiceRoundNumber(mx, false)
+		}
+	}
+	return td
+}
+
+// gridFigure has the colored squares and labels for saving grids
+// to both PNG and SVG files.
+type gridFigure struct {
+	size    image.Point
+	squares []gridSquare
+	labels  []gridLabel
+}
+
+type gridSquare struct {
+	pos, size math32.Vector2
+	color     color.RGBA
+}
+
+type gridLabel struct {
+	pos  math32.Vector2
+	text string
+}
+
+// addGrid adds squares for the given tensor at the given position,
+// with the given grid square size, laid out as in tensorcore.TensorGrid.
+func (gf *gridFigure) addGrid(tsr tensor.Tensor, td *tensorcore.TensorDisplay, pos math32.Vector2, sq float32) {
+	cmap := colormap.AvailableMaps[string(td.ColorMap)]
+	if cmap == nil {
+		cmap = colormap.AvailableMaps["ColdHot"]
+	}
+	if td.Image && tsr.NumDims() == 2 {
+		ysz, xsz := tsr.DimSize(0), tsr.DimSize(1)
+		for y := range ysz {
+			ey := y
+			if !td.TopZero {
+				ey = (ysz - 1) - y
+			}
+			for x := range xsz {
+				val := td.Range.ClipNormValue(tsr.Float([]int{y, x}))
+				gf.squares = append(gf.squares, gridSquare{pos: pos.Add(math32.Vec2(float32(x), float32(ey)).MulScalar(sq)),
+					size: math32.Vec2(sq, sq), color: colors.AsRGBA(colors.FromFloat64(val, val, val, 1))})
+			}
+		}
+		return
+	}
+	rows, cols, rowEx, colEx := tensor.Projection2DShape(tsr.Shape(), td.OddRow)
+	rowsInner, colsInner := rows, cols
+	if rowEx > 0 {
+		rowsInner = rows / rowEx
+	}
+	if colEx > 0 {
+		colsInner = cols / colEx
+	}
+	ssz := math32.Vec2(sq, sq).MulScalar(td.GridFill)
+	for y := range rows {
+		yex := float32(int(y/rowsInner)) * td.DimExtra
+		for x := range cols {
+			xex := float32(int(x/colsInner)) * td.DimExtra
+			ey := y
+			if !td.TopZero {
+				ey = (rows - 1) - y
+			}
+			val := tensor.Projection2DValue(tsr, td.OddRow, ey, x)
+			var clr color.RGBA
+			if cmap.Indexed {
+				clr = cmap.MapIndex(int(val))
+			} else {
+				clr = cmap.Map(float32(td.Range.ClipNormValue(val)))
+			}
+			gf.squares = append(gf.squares, gridSquare{pos: pos.Add(math32.Vec2(float32(x)+xex, float32(y)+yex).MulScalar(sq)),
+				size: ssz, color: clr})
+		}
+	}
+}
+
+// save saves the figure to the given file name base with .png and .svg extensions.
+func (gf *gridFigure) save(base string) error {
+	if err := gf.savePNG(base + ".png"); err != nil {
+		return err
+	}
+	return gf.saveSVG(base + ".svg")
+}
+
+func (gf *gridFigure) savePNG(fnm string) error {
+	pc := paint.NewContext(gf.size.X, gf.size.Y)
+	pc.BlitBox(math32.Vector2{}, math32.FromPoint(gf.size), colors.Scheme.Surface)
+	for _, s := range gf.squares {
+		pc.FillBox(s.pos, s.size, colors.Uniform(s.color))
+	}
+	if len(gf.labels) > 0 {
+		paint.FontLibrary.InitFontPaths(paint.FontPaths...)
+		tsty := &styles.Text{}
+		tsty.Defaults()
+		fsty := &styles.FontRender{}
+		fsty.Defaults()
+		fsty.Color = colors.Scheme.OnSurface
+		for _, lb := range gf.labels {
+			txt := &paint.Text{}
+			txt.SetHTML(lb.text, fsty, tsty, &pc.UnitContext, nil)
+			txt.Layout(tsty, fsty, &pc.UnitContext, math32.FromPoint(gf.size))
+			txt.Render(pc, lb.pos.Sub(math32.Vec2(0, 0.5*txt.BBox.Size().Y)))
+		}
+	}
+	return imagex.Save(pc.Image, fnm)
+}
+
+func (gf *gridFigure) saveSVG(fnm string) error {
+	f, err := os.Create(fnm)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", gf.size.X, gf.size.Y, gf.size.X, gf.size.Y)
+	fmt.Fprintf(w, "<rect width=\"100%%\" height=\"100%%\" fill=\"%s\"/>\n", colors.AsHex(colors.ToUniform(colors.Scheme.Surface)))
+	for _, s := range gf.squares {
+		fmt.Fprintf(w, "<rect x=\"%.4g\" y=\"%.4g\" width=\"%.4g\" height=\"%.4g\" fill=\"%s\"/>\n", s.pos.X, s.pos.Y, s.size.X, s.size.Y, colors.AsHex(s.color))
+	}
+	for _, lb := range gf.labels {
+		fmt.Fprintf(w, "<text x=\"%.4g\" y=\"%.4g\" dominant-baseline=\"middle\" font-family=\"sans-serif\" font-size=\"14\" fill=\"%s\">%s</text>\n", lb.pos.X, lb.pos.Y, colors.AsHex(colors.ToUniform(colors.Scheme.OnSurface)), lb.text)
+	}
+	fmt.Fprintln(w, "</svg>")
+	return w.Flush()
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/tensor/stats/stats"
+	"cogentcore.org/core/tensor/table"
+	"github.com/emer/leabra/v2/leabra"
+)
+
+// GoldenColumns are the columns of the epoch log that are saved to
+// and compared with the golden reference tables, for those present in the log.
+var GoldenColumns = []string{"Run", "Epoch", "PctErr", "PctCor", "SSE", "AvgSSE", "UnitErr", "Mem", "RT", "UniqPats"}
+
+// GoldenTol is the tolerance for differences from the golden values,
+// which is relative to the golden value when that is larger than 1.
+var GoldenTol = 0.001
+
+// CheckGolden compares the given table, typically the training epoch log
+// at the end of a headless run with a fixed starting run (and thus random seed),
+// or the GoldenWeights of its network, against the golden reference table
+// in the given tab-separated file, returning an error if they differ
+// or the file does not exist. The file is made with SaveGolden
+// (e.g., by go test -update).
+func CheckGolden(dt *table.Table, fnm string) error {
+	if dt == nil || dt.Rows == 0 {
+		return fmt.Errorf("CheckGolden: no log data to compare with %q", fnm)
+	}
+	gt := table.NewTable()
+	if err := gt.OpenCSV(core.Filename(fnm), table.Tab); err != nil {
+		return fmt.Errorf("CheckGolden: %w", err)
+	}
+	if err := CompareGolden(dt, gt); err != nil {
+		return fmt.Errorf("CheckGolden: %q: %w", fnm, err)
+	}
+	fmt.Printf("CheckGolden: %q matches\n", fnm)
+	return nil
+}
+
+// SaveGolden saves the given golden table, the GoldenTable of a log
+// or the GoldenWeights of a network, to the given tab-separated file,
+// as the golden reference table for CheckGolden.
+func SaveGolden(gt *table.Table, fnm string) error {
+	if gt == nil || gt.Rows == 0 {
+		return fmt.Errorf("SaveGolden: no data to save to %q", fnm)
+	}
+	if err := gt.SaveCSV(core.Filename(fnm), table.Tab, table.Headers); err != nil {
+		return err
+	}
+	fmt.Printf("SaveGolden: saved golden file %q with %d rows\n", fnm, gt.Rows)
+	return nil
+}
+
+// GoldenTable returns a new table with the GoldenColumns present in dt.
+func GoldenTable(dt *table.Table) *table.Table {
+	gt := table.NewTable()
+	var cols []string
+	for _, cn := range GoldenColumns {
+		if _, err := dt.ColumnIndex(cn); err == nil {
+			cols = append(cols, cn)
+			gt.AddFloat64Column(cn)
+		}
+	}
+	gt.SetNumRows(dt.Rows)
+	for _, cn := range cols {
+		for row := 0; row < dt.Rows; row++ {
+			gt.SetFloat(cn, row, dt.Float(cn, row))
+		}
+	}
+	return gt
+}
+
+// GoldenSkipColumns are the columns of the logs that are not in the
+// GoldenTestTable, because they vary from one run to the next, e.g., timing.
+var GoldenSkipColumns = []string{"PerTrlMSec"}
+
+// GoldenTestTable returns a new table with all of the scalar columns of dt
+// other than the GoldenSkipColumns, for the testing logs of the sims that
+// only test a network, which have their own stats, unlike the training
+// epoch logs of the sims that train one, which have the GoldenColumns.
+func GoldenTestTable(dt *table.Table) *table.Table {
+	gt := table.NewTable()
+	var cols []string
+	var strs []bool
+	for i, cl := range dt.Columns {
+		cn := dt.ColumnNames[i]
+		if cl.NumDims() > 1 || slices.Contains(GoldenSkipColumns, cn) {
+			continue
+		}
+		cols = append(cols, cn)
+		strs = append(strs, cl.IsString())
+		if cl.IsString() {
+			gt.AddStringColumn(cn)
+		} else {
+			gt.AddFloat64Column(cn)
+		}
+	}
+	gt.SetNumRows(dt.Rows)
+	for ci, cn := range cols {
+		for row := 0; row < dt.Rows; row++ {
+			if strs[ci] {
+				gt.SetString(cn, row, dt.StringValue(cn, row))
+			} else {
+				gt.SetFloat(cn, row, dt.Float(cn, row))
+			}
+		}
+	}
+	return gt
+}
+
+// GoldenWeights returns a table with the mean and standard deviation of the
+// weights of each pathway of the given network, in order, as a summary of
+// what it has learned, for the golden reference tables of the sims
+// that do not have any performance stats in their logs.
+func GoldenWeights(net *leabra.Network) *table.Table {
+	dt := table.NewTable()
+	dt.AddStringColumn("Path")
+	dt.AddFloat64Column("WtMean")
+	dt.AddFloat64Column("WtSD")
+	var wts []float64
+	for _, ly := range net.Layers {
+		for _, pt := range ly.RecvPaths {
+			wts = wts[:0]
+			for i := range pt.Syns {
+				wts = append(wts, float64(pt.Syns[i].Wt))
+			}
+			row := dt.Rows
+			dt.SetNumRows(row + 1)
+			dt.SetString("Path", row, pt.Name)
+			dt.SetFloat("WtMean", row, stats.Mean64(wts))
+			dt.SetFloat("WtSD", row, stats.Std64(wts))
+		}
+	}
+	return dt
+}
+
+// CompareGolden compares all of the columns in the golden table with
+// the same columns in dt, returning an error listing all of the
+// values that differ by more than GoldenTol (or string values that
+// differ), or if the number of rows differs or a column is missing from dt.
+// NaN values match each other.
+func CompareGolden(dt, golden *table.Table) error {
+	if dt.Rows != golden.Rows {
+		return fmt.Errorf("number of rows: %d != golden %d", dt.Rows, golden.Rows)
+	}
+	var diffs []string
+	for ci, cn := range golden.ColumnNames {
+		if _, err := dt.ColumnIndex(cn); err != nil {
+			diffs = append(diffs, fmt.Sprintf("column %q is missing", cn))
+			continue
+		}
+		if golden.Columns[ci].IsString() {
+			for row := 0; row < golden.Rows; row++ {
+				if gv, v := golden.StringValue(cn, row), dt.StringValue(cn, row); v != gv {
+					diffs = append(diffs, fmt.Sprintf("%s[%d]: %q != golden %q", cn, row, v, gv))
+				}
+			}
+			continue
+		}
+		for row := 0; row < golden.Rows; row++ {
+			gv := golden.Float(cn, row)
+			v := dt.Float(cn, row)
+			if math.IsNaN(gv) && math.IsNaN(v) {
+				continue
+			}
+			if math.IsNaN(v-gv) || math.Abs(v-gv) > GoldenTol*max(1, math.Abs(gv)) {
+				diffs = append(diffs, fmt.Sprintf("%s[%d]: %g != golden %g", cn, row, v, gv))
+			}
+		}
+	}
+	if len(diffs) > 0 {
+		return errors.New(strings.Join(diffs, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cogentcore.org/core/tensor/table"
+)
+
+// goldenTestTable returns a table with a float column SSE and a string
+// column Name with the given values.
+func goldenTestTable(sse []float64, names []string) *table.Table {
+	dt := table.NewTable()
+	dt.AddFloat64Column("SSE")
+	dt.AddStringColumn("Name")
+	dt.SetNumRows(len(sse))
+	for i, v := range sse {
+		dt.SetFloat("SSE", i, v)
+		dt.SetString("Name", i, names[i])
+	}
+	return dt
+}
+
+func TestCompareGolden(t *testing.T) {
+	nan := math.NaN()
+	names := []string{"a", "b"}
+	tests := []struct {
+		name   string
+		dt     *table.Table
+		golden *table.Table
+		err    string // substring of the error, or "" for none
+	}{
+		{"equal", goldenTestTable([]float64{0.5, 2}, names), goldenTestTable([]float64{0.5, 2}, names), ""},
+		{"within tol", goldenTestTable([]float64{0.5 + 0.0009, 2}, names), goldenTestTable([]float64{0.5, 2}, names), ""},
+		{"beyond tol", goldenTestTable([]float64{0.5 + 0.0011, 2}, names), goldenTestTable([]float64{0.5, 2}, names), "SSE[0]"},
+		{"relative tol", goldenTestTable([]float64{0.5, 1000.9}, names), goldenTestTable([]float64{0.5, 1000}, names), ""},
+		{"beyond relative tol", goldenTestTable([]float64{0.5, 1001.1}, names), goldenTestTable([]float64{0.5, 1000}, names), "SSE[1]"},
+		{"NaN matches NaN", goldenTestTable([]float64{nan, 2}, names), goldenTestTable([]float64{nan, 2}, names), ""},
+		{"NaN != golden", goldenTestTable([]float64{nan, 2}, names), goldenTestTable([]float64{0.5, 2}, names), "SSE[0]"},
+		{"golden NaN", goldenTestTable([]float64{0.5, 2}, names), goldenTestTable([]float64{nan, 2}, names), "SSE[0]"},
+		{"string", goldenTestTable([]float64{0.5, 2}, []string{"a", "c"}), goldenTestTable([]float64{0.5, 2}, names), `Name[1]: "c" != golden "b"`},
+		{"rows", goldenTestTable([]float64{0.5}, names[:1]), goldenTestTable([]float64{0.5, 2}, names), "number of rows: 1 != golden 2"},
+		{"missing column", GoldenTable(goldenTestTable([]float64{0.5, 2}, names)), goldenTestTable([]float64{0.5, 2}, names), `column "Name" is missing`},
+	}
+	for _, tt := range tests {
+		err := CompareGolden(tt.dt, tt.golden)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		case tt.err != "" && err == nil:
+			t.Errorf("%s: no error, expected %q", tt.name, tt.err)
+		case tt.err != "" && !strings.Contains(err.Error(), tt.err):
+			t.Errorf("%s: error %q does not contain %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestCompareGoldenAllDiffs(t *testing.T) {
+	err := CompareGolden(goldenTestTable([]float64{1, 3}, []string{"x", "y"}), goldenTestTable([]float64{0.5, 2}, []string{"a", "b"}))
+	if err == nil {
+		t.Fatal("no error")
+	}
+	if n := len(strings.Split(err.Error(), "\n")); n != 4 {
+		t.Errorf("%d differences listed instead of 4:\n%v", n, err)
+	}
+}
+
+func TestCheckGolden(t *testing.T) {
+	fnm := filepath.Join(t.TempDir(), "golden.tsv")
+	dt := goldenTestTable([]float64{0.123456789012345, 2}, []string{"a", "b"})
+	if err := CheckGolden(dt, fnm); err == nil {
+		t.Error("CheckGolden: no error for a missing golden file")
+	}
+	if err := SaveGolden(table.NewTable(), fnm); err == nil {
+		t.Error("SaveGolden: no error for an empty table")
+	}
+	if err := SaveGolden(dt, fnm); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckGolden(dt, fnm); err != nil {
+		t.Error(err)
+	}
+	dt.SetFloat("SSE", 1, 2.1)
+	if err := CheckGolden(dt, fnm); err == nil || !strings.Contains(err.Error(), "SSE[1]") {
+		t.Errorf("CheckGolden: expected an SSE[1] difference, got: %v", err)
+	}
+}
+
+func TestGoldenTables(t *testing.T) {
+	dt := goldenTestTable([]float64{0.5, 2}, []string{"a", "b"})
+	dt.AddFloat64Column("PerTrlMSec")
+	dt.AddFloat64TensorColumn("Act", []int{2, 2})
+	gt := GoldenTable(dt)
+	if len(gt.ColumnNames) != 1 || gt.ColumnNames[0] != "SSE" || gt.Rows != 2 || gt.Float("SSE", 1) != 2 {
+		t.Errorf("GoldenTable: columns %v, %d rows", gt.ColumnNames, gt.Rows)
+	}
+	tt := GoldenTestTable(dt)
+	if strings.Join(tt.ColumnNames, ",") != "SSE,Name" || tt.StringValue("Name", 1) != "b" {
+		t.Errorf("GoldenTestTable: columns %v", tt.ColumnNames)
+	}
+}
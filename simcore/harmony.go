@@ -0,0 +1,80 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"github.com/emer/leabra/v2/leabra"
+)
+
+// Harmony returns the Hopfield-style harmony of the current activation state
+// of the given network, which is high when the active units are connected by
+// strong weights, so that they satisfy the constraints encoded in the
+// weights: constraint satisfaction through settling increases harmony,
+// and the corresponding energy is just its negative.
+//
+// It is the sum, over all the pathways of the network, of the receiving
+// activity times the weight times the sending activity, act_i * w_ij * act_j,
+// with the weights scaled by the GScale of each pathway so that pathways
+// contribute in proportion to how strongly they drive the receiving units,
+// and inhibitory pathways counting negatively.  The bias term of each unit is
+// its external input times its activity, as leabra has no bias weights.
+// Each direction of a bidirectional connection counts separately.
+// The total is divided by the number of units, so that it can be compared
+// across networks of different sizes.
+func Harmony(net *leabra.Network) float32 {
+	harm := float32(0)
+	nu := 0
+	for _, ly := range net.Layers {
+		if ly.Off {
+			continue
+		}
+		for ni := range ly.Neurons {
+			nrn := &ly.Neurons[ni]
+			if nrn.IsOff() {
+				continue
+			}
+			harm += nrn.Ext * nrn.Act
+			nu++
+		}
+		for _, pt := range ly.SendPaths {
+			if pt.Off || pt.Recv.Off {
+				continue
+			}
+			harm += pathHarmony(pt)
+		}
+	}
+	if nu > 0 {
+		harm /= float32(nu)
+	}
+	return harm
+}
+
+// pathHarmony returns the sum of act_i * w_ij * act_j over the
+// synapses of the given pathway, scaled by its GScale.
+func pathHarmony(pt *leabra.Path) float32 {
+	slay := pt.Send
+	rlay := pt.Recv
+	harm := float32(0)
+	for si := range slay.Neurons {
+		sn := &slay.Neurons[si]
+		if sn.Act == 0 {
+			continue
+		}
+		nc := int(pt.SConN[si])
+		st := int(pt.SConIndexSt[si])
+		syns := pt.Syns[st : st+nc]
+		scons := pt.SConIndex[st : st+nc]
+		sum := float32(0)
+		for ci := range syns {
+			sum += syns[ci].Wt * rlay.Neurons[scons[ci]].Act
+		}
+		harm += sn.Act * sum
+	}
+	harm *= pt.GScale
+	if pt.Type == leabra.InhibPath {
+		harm = -harm
+	}
+	return harm
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"math"
+	"testing"
+
+	"github.com/emer/emergent/v2/paths"
+	"github.com/emer/leabra/v2/leabra"
+)
+
+// TestHarmony checks Harmony against the sum of act_i * w_ij * act_j
+// over the given weights of a small network, with an excitatory and an
+// inhibitory pathway, and the external input term, per unit.
+func TestHarmony(t *testing.T) {
+	net := leabra.NewNetwork("Harmony")
+	inp := net.AddLayer2D("Input", 1, 2, leabra.InputLayer)
+	hid := net.AddLayer2D("Hidden", 1, 2, leabra.SuperLayer)
+	full := paths.NewFull()
+	fwd := net.ConnectLayers(inp, hid, full, leabra.ForwardPath)
+	inh := net.ConnectLayers(inp, hid, full, leabra.InhibPath)
+	net.Build()
+	net.Defaults()
+	net.InitWeights()
+
+	wts := [][]float32{{0.1, 0.2}, {0.3, 0.4}} // [send][recv]
+	for si := range 2 {
+		for ri := range 2 {
+			fwd.SetSynValue("Wt", si, ri, wts[si][ri])
+			inh.SetSynValue("Wt", si, ri, wts[si][ri]/2)
+		}
+	}
+	inActs := []float32{1, 0.5}
+	hidActs := []float32{0.8, 0.2}
+	for i := range 2 {
+		inp.Neurons[i].Act = inActs[i]
+		inp.Neurons[i].Ext = inActs[i]
+		hid.Neurons[i].Act = hidActs[i]
+	}
+
+	if fwd.GScale == 0 || inh.GScale == 0 {
+		t.Fatalf("GScale is 0: %g, %g", fwd.GScale, inh.GScale)
+	}
+	ext := float64(inActs[0]*inActs[0] + inActs[1]*inActs[1]) // Ext * Act
+	sum := sumHarmony(inActs, wts, hidActs)
+	want := (ext + float64(fwd.GScale)*sum - float64(inh.GScale)*sum/2) / 4
+	if h := Harmony(net); math.Abs(float64(h)-want) > 1.0e-6 {
+		t.Errorf("Harmony = %g != %g", h, want)
+	}
+
+	inh.Off = true
+	want = (ext + float64(fwd.GScale)*sum) / 4
+	if h := Harmony(net); math.Abs(float64(h)-want) > 1.0e-6 {
+		t.Errorf("Harmony without the inhibitory pathway = %g != %g", h, want)
+	}
+}
+
+// sumHarmony returns the sum of act_i * w_ij * act_j.
+func sumHarmony(sacts []float32, wts [][]float32, racts []float32) float64 {
+	sum := 0.0
+	for si := range sacts {
+		for ri := range racts {
+			sum += float64(sacts[si] * wts[si][ri] * racts[ri])
+		}
+	}
+	return sum
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"cogentcore.org/core/base/randx"
+	"cogentcore.org/core/tensor"
+	"cogentcore.org/core/tensor/table"
+)
+
+// LinesTable returns a table with all of the combinations of the given
+// number of lines in a size x size Input, from the size vertical lines
+// (named V0, V1...) and then the size horizontal lines (H0, H1...),
+// in order, as in the self_org and hebberr_combo sims, named by the lines
+// joined with _ (e.g., V0_H1). If output is true, it has an Output
+// column of size x 2 units, with the first column of units for the
+// vertical lines and the second for the horizontal ones, labeling
+// the lines that are present.
+func LinesTable(name string, size, nlines int, output bool) *table.Table {
+	dt := table.NewTable(name)
+	dt.AddStringColumn("Name")
+	inp := dt.AddFloat32TensorColumn("Input", []int{size, size})
+	var out *tensor.Float32
+	if output {
+		out = dt.AddFloat32TensorColumn("Output", []int{size, 2})
+	}
+	nl := 2 * size
+	var add func(lines []int)
+	add = func(lines []int) {
+		if len(lines) < nlines {
+			st := 0
+			if len(lines) > 0 {
+				st = lines[len(lines)-1] + 1
+			}
+			for li := st; li < nl; li++ {
+				add(append(lines, li))
+			}
+			return
+		}
+		row := dt.Rows
+		dt.SetNumRows(row + 1)
+		names := make([]string, len(lines))
+		for i, li := range lines {
+			horiz := li >= size
+			ln := li % size
+			if horiz {
+				names[i] = "H" + strconv.Itoa(ln)
+			} else {
+				names[i] = "V" + strconv.Itoa(ln)
+			}
+			for j := range size {
+				if horiz {
+					inp.SetFloat([]int{row, ln, j}, 1)
+				} else {
+					inp.SetFloat([]int{row, j, ln}, 1)
+				}
+			}
+			if output {
+				if horiz {
+					out.SetFloat([]int{row, ln, 1}, 1)
+				} else {
+					out.SetFloat([]int{row, ln, 0}, 1)
+				}
+			}
+		}
+		dt.SetString("Name", row, strings.Join(names, "_"))
+	}
+	add(nil)
+	return dt
+}
+
+// HoldOut returns a random split of the indexes of n patterns into those
+// to train on and those held out of training, to test generalization to
+// them, with the given proportion of them held out (rounded to the nearest
+// number of patterns), each in ascending order, but always leaving at least
+// one to train on, as a training env with no patterns would never finish an
+// epoch. The patterns are chosen by the given random numbers, e.g., from
+// the random seed for each run.
+func HoldOut(n int, prop float32, rnd randx.Rand) (train, test []int) {
+	nt := int(math.Round(float64(prop) * float64(n)))
+	nt = max(min(nt, n-1), 0)
+	perm := rnd.Perm(n)
+	train = slices.Clone(perm[nt:])
+	test = slices.Clone(perm[:nt])
+	slices.Sort(train)
+	slices.Sort(test)
+	return
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"slices"
+	"testing"
+
+	"cogentcore.org/core/base/randx"
+)
+
+// TestLinesTable checks the number of combinations of lines,
+// and the Input and Output units of one of them.
+func TestLinesTable(t *testing.T) {
+	dt := LinesTable("Lines2", 5, 2, true)
+	if dt.Rows != 45 { // 10 choose 2
+		t.Fatalf("%d rows != 45", dt.Rows)
+	}
+	if nm := dt.StringValue("Name", 0); nm != "V0_V1" {
+		t.Errorf("first row %q != V0_V1", nm)
+	}
+	row := -1
+	for i := range dt.Rows {
+		if dt.StringValue("Name", i) == "V1_H3" {
+			row = i
+		}
+	}
+	if row < 0 {
+		t.Fatal("no V1_H3 row")
+	}
+	inp := dt.Tensor("Input", row)
+	for y := range 5 {
+		for x := range 5 {
+			want := 0.0
+			if x == 1 || y == 3 {
+				want = 1
+			}
+			if v := inp.Float([]int{y, x}); v != want {
+				t.Errorf("V1_H3 Input[%d,%d] = %g != %g", y, x, v, want)
+			}
+		}
+	}
+	out := dt.Tensor("Output", row)
+	for ln := range 5 {
+		for c := range 2 {
+			want := 0.0
+			if (c == 0 && ln == 1) || (c == 1 && ln == 3) {
+				want = 1
+			}
+			if v := out.Float([]int{ln, c}); v != want {
+				t.Errorf("V1_H3 Output[%d,%d] = %g != %g", ln, c, v, want)
+			}
+		}
+	}
+}
+
+// TestHoldOut checks that HoldOut splits the patterns into sorted,
+// disjoint sets that cover all of them, with the given proportion
+// held out, but always leaving at least one to train on, including
+// at both ends of the range of proportions.
+func TestHoldOut(t *testing.T) {
+	tests := []struct {
+		n     int
+		prop  float32
+		ntest int
+	}{
+		{45, 0, 0},
+		{45, 0.2, 9},
+		{45, 0.5, 23}, // rounded to the nearest
+		{45, 1, 44},   // at least one to train on
+		{1, 1, 0},
+		{0, 0.5, 0},
+	}
+	for _, tt := range tests {
+		train, test := HoldOut(tt.n, tt.prop, randx.NewSysRand(1))
+		if len(test) != tt.ntest || len(train) != tt.n-tt.ntest {
+			t.Errorf("HoldOut(%d, %g): %d train, %d test != %d, %d", tt.n, tt.prop, len(train), len(test), tt.n-tt.ntest, tt.ntest)
+			continue
+		}
+		if !slices.IsSorted(train) || !slices.IsSorted(test) {
+			t.Errorf("HoldOut(%d, %g): not sorted: %v, %v", tt.n, tt.prop, train, test)
+		}
+		all := append(slices.Clone(train), test...)
+		slices.Sort(all)
+		for i, idx := range all {
+			if idx != i {
+				t.Errorf("HoldOut(%d, %g): %v and %v are not a split of 0..%d", tt.n, tt.prop, train, test, tt.n-1)
+				break
+			}
+		}
+	}
+
+	train1, test1 := HoldOut(45, 0.2, randx.NewSysRand(1))
+	train2, test2 := HoldOut(45, 0.2, randx.NewSysRand(1))
+	if !slices.Equal(train1, train2) || !slices.Equal(test1, test2) {
+		t.Error("HoldOut differs for the same random seed")
+	}
+	_, test3 := HoldOut(45, 0.2, randx.NewSysRand(2))
+	if slices.Equal(test1, test3) {
+		t.Error("HoldOut is the same for different random seeds")
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+
+	"cogentcore.org/core/base/reflectx"
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/tensor/table"
+	"github.com/emer/emergent/v2/etime"
+	"github.com/emer/emergent/v2/params"
+)
+
+// ParallelRunEnv is the environment variable with the ParallelRun
+// for each of the processes of RunParallel, as JSON.
+const ParallelRunEnv = "SIMCORE_PARALLEL_RUN"
+
+// ParallelRun is the run done by each of the processes of RunParallel.
+type ParallelRun struct {
+
+	// Run is the run to do.
+	Run int
+
+	// File is the file to save the run log of the run to.
+	File string
+
+	// Fields has the values of the Runner Fields of the Sim, by name.
+	Fields map[string]string
+}
+
+// IsParallelRun returns whether this process is one of the processes
+// of RunParallel, which does its ParallelRun in RunNoGUI.
+func IsParallelRun() bool {
+	return os.Getenv(ParallelRunEnv) != ""
+}
+
+// ParallelRuns does nruns runs starting at startRun in parallel, with up
+// to nthreads (the number of CPUs if <= 0) at a time, each done by doRun,
+// which returns its run log. The run logs from all of the runs are
+// returned merged into one table, in order of run, along with the error
+// of the first run that failed, if any.
+func ParallelRuns(startRun, nruns, nthreads int, doRun func(run int) (*table.Table, error)) (*table.Table, error) {
+	if nthreads <= 0 {
+		nthreads = runtime.NumCPU()
+	}
+	nthreads = min(nthreads, nruns)
+	logs := make([]*table.Table, nruns)
+	errs := make([]error, nruns)
+	runs := make(chan int)
+	var wg sync.WaitGroup
+	for range nthreads {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for run := range runs {
+				logs[run-startRun], errs[run-startRun] = doRun(run)
+			}
+		}()
+	}
+	for run := startRun; run < startRun+nruns; run++ {
+		runs <- run
+	}
+	close(runs)
+	wg.Wait()
+
+	var dt *table.Table
+	for i, lt := range logs {
+		if errs[i] != nil {
+			return dt, errs[i]
+		}
+		if lt == nil {
+			continue
+		}
+		if dt == nil {
+			dt = lt.Clone()
+		} else {
+			dt.AppendRows(lt)
+		}
+	}
+	return dt, nil
+}
+
+// RunParallel does the NRuns runs in parallel with ParallelRuns, each in
+// its own process, which runs this program again with the same args
+// (and thus Config), and the ParallelRun in ParallelRunEnv. The sims use
+// the global random number generator for the initial weights, noise and
+// order of the patterns, and apply their global ParamSets, so only runs
+// in separate processes can be done at the same time, with the same
+// random seeds, and thus results, as the runs done sequentially.
+// The stderr of each process is forwarded a line at a time, prefixed
+// with its run, and the error of a run that fails has its last line.
+// Each run saves its log files, checkpoints, Figures and exports under its
+// own run name, and the run logs of all of the runs are saved together to
+// the run log file if Log.Run, along with their Summary. A checkpoint is
+// for one run, so it is an error to Resume in parallel: each run is
+// resumed from its own checkpoint file without Parallel, with its Run.
+func (rn *Runner) RunParallel() error {
+	cfg := rn.Config
+	if cfg.Resume != "" {
+		return fmt.Errorf("simcore.RunParallel: cannot Resume in parallel: resume the run of the checkpoint without Parallel, with Run set to it")
+	}
+	run, nruns := *rn.Run, *rn.NRuns
+	fields, err := rn.fieldValues()
+	if err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp("", "simcore-parallel")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	fmt.Printf("Running %d Runs in parallel starting at %d\n", nruns, run)
+	dt, err := ParallelRuns(run, nruns, cfg.NThreads, func(run int) (*table.Table, error) {
+		pr := &ParallelRun{Run: run, File: filepath.Join(dir, fmt.Sprintf("run_%03d.tsv", run)), Fields: fields}
+		b, err := json.Marshal(pr)
+		if err != nil {
+			return nil, err
+		}
+		cmd := exec.Command(exe, os.Args[1:]...)
+		cmd.Env = append(os.Environ(), ParallelRunEnv+"="+string(b))
+		stderr := &prefixWriter{w: os.Stderr, mu: &stderrMu, prefix: fmt.Sprintf("run %d: ", run)}
+		cmd.Stdout, cmd.Stderr = os.Stdout, stderr
+		err = cmd.Run()
+		stderr.Flush()
+		if err != nil {
+			if stderr.last != "" {
+				return nil, fmt.Errorf("simcore.RunParallel: run %d: %w: %s", run, err, stderr.last)
+			}
+			return nil, fmt.Errorf("simcore.RunParallel: run %d: %w", run, err)
+		}
+		lt := table.NewTable()
+		return lt, lt.OpenCSV(core.Filename(pr.File), table.Tab)
+	})
+	if err != nil || dt == nil {
+		return err
+	}
+	lt := rn.Logs.Table(etime.Train, etime.Run) // all of the runs, as for RunStd
+	lt.SetNumRows(0)
+	lt.AppendRows(dt)
+	runName := rn.RunName(run)
+	if rn.logOn("Run") {
+		if err := SaveTable(lt, "run", rn.NetName, runName); err != nil {
+			return err
+		}
+	}
+	if cfg.Summary != "" {
+		return SaveSummary(lt, cfg.Summary, rn.NetName, runName)
+	}
+	return nil
+}
+
+// stderrMu keeps the lines forwarded from the stderr of the processes
+// of RunParallel from being interleaved.
+var stderrMu sync.Mutex
+
+// prefixWriter writes each complete line written to it to w, prefixed
+// with prefix, holding mu, and keeps the last non-empty line.
+type prefixWriter struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	prefix string
+	buf    []byte
+	last   string
+}
+
+func (pw *prefixWriter) Write(b []byte) (int, error) {
+	pw.buf = append(pw.buf, b...)
+	for {
+		i := bytes.IndexByte(pw.buf, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		if err := pw.writeLine(pw.buf[:i]); err != nil {
+			return len(b), err
+		}
+		pw.buf = pw.buf[i+1:]
+	}
+}
+
+// Flush writes the rest of the output without a final newline, if any.
+func (pw *prefixWriter) Flush() error {
+	if len(pw.buf) == 0 {
+		return nil
+	}
+	err := pw.writeLine(pw.buf)
+	pw.buf = nil
+	return err
+}
+
+func (pw *prefixWriter) writeLine(line []byte) error {
+	if ln := strings.TrimSpace(string(line)); ln != "" {
+		pw.last = ln
+	}
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	_, err := fmt.Fprintf(pw.w, "%s%s\n", pw.prefix, line)
+	return err
+}
+
+// runParallelRun does the ParallelRun in ParallelRunEnv, as one of the
+// processes of RunParallel, after setting the Fields of the Sim to their
+// values in the parent process, in the same way as RunStd for one run,
+// and saves its run log to its File.
+func (rn *Runner) runParallelRun() error {
+	pr := &ParallelRun{}
+	if err := json.Unmarshal([]byte(os.Getenv(ParallelRunEnv)), pr); err != nil {
+		return err
+	}
+	for name, val := range pr.Fields {
+		if err := params.SetParam(rn.Sim, name, val); err != nil {
+			return err
+		}
+	}
+	runName := rn.RunName(pr.Run)
+	if err := rn.startRuns(runName); err != nil {
+		return err
+	}
+	if err := rn.doRuns(pr.Run, 1, runName); err != nil {
+		return err
+	}
+	if err := rn.saveResults(runName); err != nil {
+		return err
+	}
+	lt := rn.Logs.Table(etime.Train, etime.Run)
+	return lt.SaveCSV(core.Filename(pr.File), table.Tab, table.Headers)
+}
+
+// fieldValues returns the current values of the Fields of the Sim, by name.
+func (rn *Runner) fieldValues() (map[string]string, error) {
+	fields := make(map[string]string, len(rn.Fields))
+	for _, name := range rn.Fields {
+		fld, err := params.FindParam(reflect.ValueOf(rn.Sim), name)
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = fmt.Sprint(reflectx.NonPointerValue(fld).Interface())
+	}
+	return fields, nil
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	"cogentcore.org/core/tensor/table"
+)
+
+// TestPrefixWriter checks that the stderr of each process of RunParallel
+// is forwarded whole lines at a time, prefixed with its run, including
+// lines written in pieces and the last one without a newline.
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	var mu sync.Mutex
+	pw := &prefixWriter{w: &out, mu: &mu, prefix: "run 3: "}
+	for _, s := range []string{"Epoch 1", "\nEpoch", " 2\n\nfailed: ", "no File\n", "exit"} {
+		pw.Write([]byte(s))
+	}
+	want := "run 3: Epoch 1\nrun 3: Epoch 2\nrun 3: \nrun 3: failed: no File\n"
+	if out.String() != want {
+		t.Errorf("before Flush: %q != %q", out.String(), want)
+	}
+	if pw.last != "failed: no File" {
+		t.Errorf("last line %q != %q", pw.last, "failed: no File")
+	}
+	pw.Flush()
+	if want += "run 3: exit\n"; out.String() != want {
+		t.Errorf("after Flush: %q != %q", out.String(), want)
+	}
+	if pw.last != "exit" {
+		t.Errorf("last line after Flush %q != %q", pw.last, "exit")
+	}
+}
+
+// TestParallelRuns checks that the run logs are merged in order of run,
+// and the error is that of the first run that failed.
+func TestParallelRuns(t *testing.T) {
+	doRun := func(fail int) func(run int) (*table.Table, error) {
+		return func(run int) (*table.Table, error) {
+			if run >= fail {
+				return nil, fmt.Errorf("run %d failed", run)
+			}
+			return runLogTable(map[string][]float64{"SSE": {float64(run)}}), nil
+		}
+	}
+	dt, err := ParallelRuns(2, 4, 3, doRun(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dt.Rows != 4 {
+		t.Fatalf("%d rows != 4", dt.Rows)
+	}
+	for row := range 4 {
+		if sse := dt.Float("SSE", row); sse != float64(row+2) {
+			t.Errorf("row %d: run %g != %d", row, sse, row+2)
+		}
+	}
+	if _, err := ParallelRuns(2, 4, 0, doRun(3)); err == nil || err.Error() != "run 3 failed" {
+		t.Errorf("error %v, not that of run 3", err)
+	}
+}
@@ -0,0 +1,475 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"fmt"
+	"image"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/icons"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/styles"
+	"cogentcore.org/core/styles/abilities"
+	"cogentcore.org/core/tensor"
+	"cogentcore.org/core/tensor/table"
+	"cogentcore.org/core/tensor/tensorcore"
+	"cogentcore.org/core/tree"
+	"github.com/emer/emergent/v2/env"
+	"github.com/emer/emergent/v2/etime"
+	"github.com/emer/emergent/v2/looper"
+)
+
+// PatternEditor is an editor for a table of patterns, such as those used by
+// the env.FixedTable environments of many sims, so that new patterns can be
+// made without editing the .tsv files by hand. It shows each of the tensor
+// columns (e.g., Input, Output) of the current row as a layer-shaped grid,
+// where clicking on a unit toggles it between 0 and Value, and dragging
+// over units sets them to the same value. Rows can be added, duplicated,
+// deleted and named, and the table can be opened from and saved to a .tsv
+// file with the emergent headers giving the shape of each column.
+type PatternEditor struct {
+
+	// Row is the row of the patterns being edited.
+	Row int `min:"0"`
+
+	// Name is the name of the current row, in the first string column.
+	Name string
+
+	// Value is the value that units are set to by clicking on them.
+	Value float32 `min:"0" max:"1" step:"0.1"`
+
+	// Filename is the .tsv file to open or save the patterns.
+	Filename core.Filename `ext:".tsv"`
+
+	// Table is the table of patterns being edited.
+	Table *table.Table `display:"-"`
+
+	// OnChange, if set, is called after rows are added or deleted, or the
+	// table is opened, e.g., to update the environment and the number of
+	// trials in the looper. Values are edited in place in the table.
+	OnChange func() `display:"-"`
+
+	// the row shown in Name, to detect changes in Row
+	nameRow int
+
+	// the value being set by dragging
+	drawValue float64
+
+	// the form for the fields
+	form *core.Form
+
+	// the frame of pattern grids
+	grids *core.Frame
+}
+
+// NewPatternEditor returns a new PatternEditor for the given table,
+// with the given file name for saving it.
+func NewPatternEditor(dt *table.Table, fnm string) *PatternEditor {
+	pe := &PatternEditor{Table: dt, Value: 1, Filename: core.Filename(fnm)}
+	pe.SetRow(0)
+	return pe
+}
+
+// EditPatterns opens a window with a PatternEditor for the given table,
+// with the given file name for saving it, and OnChange function.
+func EditPatterns(ctx core.Widget, dt *table.Table, fnm string, onChange func()) *PatternEditor {
+	pe := NewPatternEditor(dt, fnm)
+	pe.OnChange = onChange
+	nm := dt.MetaData["name"]
+	if nm == "" {
+		nm = "Patterns"
+	}
+	b := core.NewBody("pattern-editor").SetTitle("Edit " + nm)
+	pe.MakeBody(b)
+	b.RunWindowDialog(ctx)
+	return pe
+}
+
+// MakeBody makes the toolbar, form and pattern grids of the editor in the given body.
+func (pe *PatternEditor) MakeBody(b *core.Body) {
+	b.AddTopBar(func(bar *core.Frame) {
+		core.NewToolbar(bar).Maker(pe.MakeToolbar)
+	})
+	pe.form = core.NewForm(b).SetStruct(pe)
+	pe.form.OnChange(func(e events.Event) {
+		if pe.Row != pe.nameRow {
+			pe.SetRow(pe.Row)
+		} else {
+			pe.SetName(pe.Name)
+		}
+		pe.Update()
+	})
+	pe.grids = core.NewFrame(b)
+	pe.grids.Styler(func(s *styles.Style) {
+		s.Wrap = true
+	})
+	pe.grids.Maker(pe.makeGrids)
+}
+
+// Update updates the display after changes.
+func (pe *PatternEditor) Update() {
+	if pe.form == nil {
+		return
+	}
+	pe.form.Update()
+	pe.grids.Update()
+}
+
+// changed calls OnChange and updates the display after changes in the rows.
+func (pe *PatternEditor) changed() {
+	if pe.OnChange != nil {
+		pe.OnChange()
+	}
+	pe.Update()
+}
+
+// nameColumn returns the first string column, or nil if none.
+func (pe *PatternEditor) nameColumn() tensor.Tensor {
+	for _, cl := range pe.Table.Columns {
+		if cl.IsString() {
+			return cl
+		}
+	}
+	return nil
+}
+
+// SetRow sets the row being edited, within the rows of the table.
+func (pe *PatternEditor) SetRow(row int) {
+	pe.Row = max(min(row, pe.Table.Rows-1), 0)
+	pe.nameRow = pe.Row
+	pe.Name = ""
+	if cl := pe.nameColumn(); cl != nil && pe.Row < pe.Table.Rows {
+		pe.Name = cl.String1D(pe.Row)
+	}
+}
+
+// SetName sets the name of the current row.
+func (pe *PatternEditor) SetName(name string) {
+	pe.Name = name
+	if cl := pe.nameColumn(); cl != nil && pe.Row < pe.Table.Rows {
+		cl.SetString1D(pe.Row, name)
+	}
+}
+
+// AddRow adds a new row of all zeros after the current row,
+// with the given name, and makes it the current row.
+func (pe *PatternEditor) AddRow(name string) {
+	pe.Row = InsertRow(pe.Table, pe.Row+1)
+	pe.SetRow(pe.Row)
+	pe.SetName(name)
+	pe.changed()
+}
+
+// DuplicateRow adds a copy of the current row after it,
+// and makes it the current row.
+func (pe *PatternEditor) DuplicateRow() {
+	if pe.Table.Rows == 0 {
+		return
+	}
+	from := pe.Row
+	pe.Row = InsertRow(pe.Table, pe.Row+1)
+	CopyRow(pe.Table, pe.Row, from)
+	pe.SetRow(pe.Row)
+	pe.changed()
+}
+
+// DeleteRow deletes the current row, unless it is the only one,
+// as the env must have at least one trial.
+func (pe *PatternEditor) DeleteRow() {
+	if pe.Table.Rows <= 1 {
+		return
+	}
+	DeleteRow(pe.Table, pe.Row)
+	pe.SetRow(pe.Row)
+	pe.changed()
+}
+
+// ClearRow sets all the pattern values of the current row to 0.
+func (pe *PatternEditor) ClearRow() {
+	if pe.Table.Rows == 0 {
+		return
+	}
+	for _, cl := range pe.Table.Columns {
+		if cl.IsString() {
+			continue
+		}
+		_, csz := cl.RowCellSize()
+		for i := range csz {
+			cl.SetFloat1D(pe.Row*csz+i, 0)
+		}
+	}
+	pe.Update()
+}
+
+// Open opens the patterns from the Filename .tsv file, replacing
+// all of the rows, and the columns if it has emergent headers.
+func (pe *PatternEditor) Open() error {
+	if err := pe.Table.OpenCSV(pe.Filename, table.Tab); err != nil {
+		return err
+	}
+	pe.SetRow(0)
+	pe.changed()
+	return nil
+}
+
+// Save saves the patterns to the Filename .tsv file, with the emergent
+// headers giving the type and shape of each column, e.g., %Input[2:0,0]<2:7,5>,
+// so that they can be opened with the same shapes.
+func (pe *PatternEditor) Save() error {
+	return pe.Table.SaveCSV(pe.Filename, table.Tab, table.Headers)
+}
+
+func (pe *PatternEditor) MakeToolbar(p *tree.Plan) {
+	tree.Add(p, func(w *core.Button) {
+		w.SetText("Previous").SetIcon(icons.ArrowBack).SetTooltip("Edit the previous row")
+		w.OnClick(func(e events.Event) {
+			pe.SetRow(pe.Row - 1)
+			pe.Update()
+		})
+	})
+	tree.Add(p, func(w *core.Button) {
+		w.SetText("Next").SetIcon(icons.ArrowForward).SetTooltip("Edit the next row")
+		w.OnClick(func(e events.Event) {
+			pe.SetRow(pe.Row + 1)
+			pe.Update()
+		})
+	})
+	tree.Add(p, func(w *core.Separator) {})
+	tree.Add(p, func(w *core.Button) {
+		w.SetText("Add").SetIcon(icons.Add).SetTooltip("Add a new row of all zeros after the current row")
+		w.OnClick(func(e events.Event) {
+			pe.AddRow(fmt.Sprintf("new%d", pe.Table.Rows))
+		})
+	})
+	tree.Add(p, func(w *core.Button) {
+		w.SetText("Duplicate").SetIcon(icons.ContentCopy).SetTooltip("Add a copy of the current row after it")
+		w.OnClick(func(e events.Event) {
+			pe.DuplicateRow()
+		})
+	})
+	tree.Add(p, func(w *core.Button) {
+		w.SetText("Delete").SetIcon(icons.Delete).SetTooltip("Delete the current row, unless it is the only one")
+		w.OnClick(func(e events.Event) {
+			pe.DeleteRow()
+		})
+	})
+	tree.Add(p, func(w *core.Button) {
+		w.SetText("Clear").SetIcon(icons.Close).SetTooltip("Set all the values of the current row to 0")
+		w.OnClick(func(e events.Event) {
+			pe.ClearRow()
+		})
+	})
+	tree.Add(p, func(w *core.Separator) {})
+	tree.Add(p, func(w *core.Button) {
+		w.SetText("Open").SetIcon(icons.Open).SetTooltip("Open the patterns from the Filename .tsv file")
+		w.OnClick(func(e events.Event) {
+			core.ErrorSnackbar(w, pe.Open())
+		})
+	})
+	tree.Add(p, func(w *core.Button) {
+		w.SetText("Save").SetIcon(icons.Save).SetTooltip("Save the patterns to the Filename .tsv file, with emergent headers")
+		w.OnClick(func(e events.Event) {
+			err := pe.Save()
+			core.ErrorSnackbar(w, err)
+			if err == nil {
+				core.MessageSnackbar(w, "Saved "+string(pe.Filename))
+			}
+		})
+	})
+}
+
+// makeGrids makes a grid for each tensor column of the current row,
+// with its name.
+func (pe *PatternEditor) makeGrids(p *tree.Plan) {
+	if pe.Table.Rows == 0 {
+		return
+	}
+	for ci, cl := range pe.Table.Columns {
+		if cl.IsString() || cl.NumDims() < 2 {
+			continue
+		}
+		cn := pe.Table.ColumnNames[ci]
+		tree.AddAt(p, cn, func(w *core.Frame) {
+			w.Styler(func(s *styles.Style) {
+				s.Direction = styles.Column
+			})
+			core.NewText(w).SetText(cn)
+			tg := tensorcore.NewTensorGrid(w)
+			pe.configGrid(tg)
+			w.Updater(func() {
+				tg.SetTensor(pe.Table.Tensor(cn, pe.Row))
+				tg.Display.Range.SetMin(0)
+				tg.Display.Range.SetMax(1)
+			})
+		})
+	}
+}
+
+// configGrid configures the given grid for drawing on it.
+func (pe *PatternEditor) configGrid(tg *tensorcore.TensorGrid) {
+	tg.Display.GridMinSize = 24
+	tg.Display.GridMaxSize = 40
+	tg.Styler(func(s *styles.Style) {
+		s.SetAbilities(true, abilities.Slideable)
+	})
+	tg.On(events.MouseDown, func(e events.Event) {
+		row, col, ok := GridCell(tg, e.Pos())
+		if !ok {
+			return
+		}
+		cur := tensor.Projection2DValue(tg.Tensor, tg.Display.OddRow, row, col)
+		pe.drawValue = float64(pe.Value)
+		if cur >= pe.drawValue/2 && cur != 0 {
+			pe.drawValue = 0
+		}
+		tensor.Projection2DSet(tg.Tensor, tg.Display.OddRow, row, col, pe.drawValue)
+		tg.NeedsRender()
+	})
+	tg.On(events.SlideMove, func(e events.Event) {
+		row, col, ok := GridCell(tg, e.Pos())
+		if !ok {
+			return
+		}
+		tensor.Projection2DSet(tg.Tensor, tg.Display.OddRow, row, col, pe.drawValue)
+		tg.NeedsRender()
+	})
+}
+
+// GridCell returns the row and column, in the 2D projection of its tensor,
+// of the unit at the given window position in the given grid, as drawn
+// by its Render method, and false if it is not on a unit.
+func GridCell(tg *tensorcore.TensorGrid, pos image.Point) (row, col int, ok bool) {
+	if tg.Tensor == nil || tg.Tensor.Len() == 0 {
+		return
+	}
+	rel := math32.FromPoint(tg.PointToRelPos(pos))
+	sz := tg.Geom.Size.Actual.Content
+	rows, cols, rowEx, colEx := tensor.Projection2DShape(tg.Tensor.Shape(), tg.Display.OddRow)
+	frw := float32(rows) + float32(rowEx)*tg.Display.DimExtra
+	fcl := float32(cols) + float32(colEx)*tg.Display.DimExtra
+	gsz := sz.Div(math32.Vec2(fcl, frw))
+	rowsInner, colsInner := rows, cols
+	if rowEx > 0 {
+		rowsInner = rows / rowEx
+	}
+	if colEx > 0 {
+		colsInner = cols / colEx
+	}
+	for y := range rows {
+		yex := float32(y/rowsInner) * tg.Display.DimExtra
+		for x := range cols {
+			xex := float32(x/colsInner) * tg.Display.DimExtra
+			pr := math32.Vec2(float32(x)+xex, float32(y)+yex).Mul(gsz)
+			if rel.X >= pr.X && rel.X < pr.X+gsz.X && rel.Y >= pr.Y && rel.Y < pr.Y+gsz.Y {
+				row = y
+				if !tg.Display.TopZero {
+					row = (rows - 1) - y
+				}
+				return row, x, true
+			}
+		}
+	}
+	return
+}
+
+// InsertRow inserts a new row of zeros (and empty strings) into the given
+// table at the given row, which is clipped to the number of rows,
+// returning the row.
+func InsertRow(dt *table.Table, row int) int {
+	row = max(min(row, dt.Rows), 0)
+	dt.AddRows(1)
+	for r := dt.Rows - 1; r > row; r-- {
+		CopyRow(dt, r, r-1)
+	}
+	for _, cl := range dt.Columns {
+		_, csz := cl.RowCellSize()
+		for i := range csz {
+			if cl.IsString() {
+				cl.SetString1D(row*csz+i, "")
+			} else {
+				cl.SetFloat1D(row*csz+i, 0)
+			}
+		}
+	}
+	return row
+}
+
+// DeleteRow deletes the given row of the given table.
+func DeleteRow(dt *table.Table, row int) {
+	if row < 0 || row >= dt.Rows {
+		return
+	}
+	for r := row; r < dt.Rows-1; r++ {
+		CopyRow(dt, r, r+1)
+	}
+	dt.SetNumRows(dt.Rows - 1)
+}
+
+// CopyRow copies all the values of the given from row of the given table
+// to the given to row.
+func CopyRow(dt *table.Table, to, from int) {
+	for _, cl := range dt.Columns {
+		_, csz := cl.RowCellSize()
+		for i := range csz {
+			if cl.IsString() {
+				cl.SetString1D(to*csz+i, cl.String1D(from*csz+i))
+			} else {
+				cl.SetFloat1D(to*csz+i, cl.Float1D(from*csz+i))
+			}
+		}
+	}
+}
+
+// EditEnvPatterns opens a PatternEditor on the patterns table of the
+// env.FixedTable environment of the given mode, with the given file name
+// for saving it. When rows are added or deleted, all of the FixedTable
+// environments using the table are updated to its new rows, and the number
+// of trials in their looper stacks is set to match, so that the new patterns
+// are used on the next run.
+func EditEnvPatterns(ctx core.Widget, ls *looper.Stacks, envs env.Envs, mode etime.Modes, fnm string) *PatternEditor {
+	ev, ok := envs.ByMode(mode).(*env.FixedTable)
+	if !ok {
+		return nil
+	}
+	dt := ev.Table.Table
+	return EditPatterns(ctx, dt, fnm, func() {
+		UpdateEnvPatterns(ls, envs, dt)
+	})
+}
+
+// UpdateEnvPatterns updates all of the env.FixedTable environments using
+// the given table after rows are added to it or deleted, and sets the
+// number of trials in their looper stacks to the new number of rows.
+func UpdateEnvPatterns(ls *looper.Stacks, envs env.Envs, dt *table.Table) {
+	for _, ev := range envs {
+		if ft, ok := ev.(*env.FixedTable); ok && ft.Table.Table == dt {
+			ft.Table = table.NewIndexView(dt)
+			ft.Init(0)
+		}
+	}
+	SetEnvTrials(ls, envs)
+}
+
+// SetEnvTrials sets the number of trials in the Trial loop of each looper
+// stack to the number of patterns in its env.FixedTable environment, if it
+// has one, e.g., after the patterns are changed or edited, but at least 1,
+// as a Max of 0 means no maximum, so the loop would never end.
+// It does nothing if the loops have not been configured yet.
+func SetEnvTrials(ls *looper.Stacks, envs env.Envs) {
+	if ls == nil { // not configured yet
+		return
+	}
+	for m, st := range ls.Stacks {
+		ev, ok := envs.ByMode(m.(etime.Modes)).(*env.FixedTable)
+		if !ok {
+			continue
+		}
+		if trl := st.Loops[etime.Trial]; trl != nil {
+			trl.Counter.Max = max(ev.Table.Len(), 1)
+		}
+	}
+}
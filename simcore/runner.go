@@ -0,0 +1,333 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+
+	"cogentcore.org/core/base/randx"
+	"cogentcore.org/core/base/reflectx"
+	"cogentcore.org/core/tensor/table"
+	"github.com/emer/emergent/v2/egui"
+	"github.com/emer/emergent/v2/elog"
+	"github.com/emer/emergent/v2/env"
+	"github.com/emer/emergent/v2/estats"
+	"github.com/emer/emergent/v2/etime"
+	"github.com/emer/emergent/v2/looper"
+	"github.com/emer/leabra/v2/leabra"
+)
+
+// RunConfig has the config parameters for running a sim without the GUI
+// that are the same in every sim, which each sim embeds in its Config,
+// so that they are set from the command line or config.toml, e.g.,
+// -Sweep, along with the other fields of its Config.
+type RunConfig struct {
+
+	// parameter sweep to run without the GUI, e.g., "Config.NEpochs=20,50" (see ParseSweep)
+	Sweep string
+
+	// run all combinations of the Sweep values, instead of a list
+	SweepGrid bool `default:"true"`
+
+	// run the NRuns runs in parallel without the GUI, each in its own process,
+	// with log files, checkpoints, Figures and exports per run
+	Parallel bool
+
+	// maximum number of Parallel runs at the same time; 0 = number of CPU cores
+	NThreads int
+
+	// save a checkpoint at the end of every this many epochs without the GUI, for Resume,
+	// so a job killed mid-epoch redoes that epoch; 0 = none
+	Checkpoint int
+
+	// checkpoint file to resume training from without the GUI (see Checkpointer)
+	Resume string
+
+	// run log columns to summarize across runs without the GUI, or "all" (see SaveSummary)
+	Summary string
+
+	// plots, grids or NetView to save as images without the GUI, or "all" (see Figures)
+	Figures string
+
+	// logs to export to .npz files for Python without the GUI, or "all" (see SaveLogsNPZ)
+	Export string
+}
+
+// Runner runs a sim without the GUI: its standard training runs, a
+// parameter Sweep, or Parallel runs, saving the configured log files,
+// Summary, Figures and exports. It has the elements of the sim, which
+// each sim sets from its own fields in its Runner method, with pointers
+// to the parts of its Config, so that they are always current,
+// along with hooks for the parts that are specific to the sim.
+// Any element or hook that the sim does not have is nil, and skipped.
+type Runner struct {
+
+	// Sim is the sim, with the fields that are set by the Sweep.
+	Sim any
+
+	// Fields are the names of the fields of the Sim, other than its Config,
+	// that can be set before running it, e.g., in the GUI or by a Sweep,
+	// which are set to the same values in each process of RunParallel,
+	// which otherwise only has the same Config, from the same args.
+	Fields []string
+
+	// NetName is the name of the network, for naming the saved files.
+	NetName string
+
+	// RunName returns the name of the runs starting at the given run,
+	// for naming the saved files, e.g., the RunName of the NetParams.
+	RunName func(run int) string
+
+	// Config has the config parameters for running without the GUI,
+	// embedded in the Config of the sim.
+	Config *RunConfig
+
+	// Run, NRuns and NEpochs are the first run, the number of runs
+	// (and thus random seeds), which, for a Sweep, is for each point,
+	// and the number of training epochs per run, in the Config of the sim.
+	// They are read each time they are used, as a Sweep can set them.
+	Run, NRuns, NEpochs *int
+
+	// Log is the LogConfig in the Config of the sim, with a bool field for
+	// each log file to save: Trial, Epoch and Run for training, and TestEpoch
+	// and TestTrial for testing, along with SaveWeights, which is just noted
+	// at the start, Weights for the WtLog and NetData for the GUI, if the
+	// sim has them. Any field that the LogConfig does not have is false.
+	Log any
+
+	// Note is a note about the run, which is printed at the start, if set.
+	Note *string
+
+	Net     *leabra.Network
+	Context *leabra.Context
+	Loops   *looper.Stacks
+	Envs    env.Envs
+	Stats   *estats.Stats
+	Logs    *elog.Logs
+	Seeds   *randx.Seeds
+
+	// WtLog records the weights, to the file set along with the log files.
+	WtLog *WeightLog
+
+	// Figures are the figures that can be saved, as configured by ConfigPlots.
+	Figures *Figures
+
+	// GUI has the NetData that is saved if Log.NetData.
+	GUI *egui.GUI
+
+	// Extra and SetExtra save and restore any other training state of
+	// the sim in checkpoints, e.g., the weights of a BPNet (see Checkpointer).
+	Extra    func() ([]byte, error)
+	SetExtra func(b []byte) error
+
+	// Init initializes the sim, as in its Init method.
+	Init func()
+
+	// ConfigPlots adds the plots and grids of the sim to the Figures,
+	// if any are to be saved, along with the NetView (see Figures.Config).
+	ConfigPlots func(pl Plots)
+
+	// Analyze does the analyses of the final network that are saved
+	// in the Figures and exported logs, e.g., testing it,
+	// if any Figures or Export are to be saved.
+	Analyze func()
+
+	// UpdateFigures updates the Figures from the final network
+	// before they are saved, e.g., with their analysis.
+	UpdateFigures func()
+}
+
+// RunNoGUI runs the sim without the GUI, doing the Sweep or the Parallel
+// runs if either is configured, and otherwise the standard runs.
+// Any error is printed and exits the program,
+// as this is only used from the command line.
+func (rn *Runner) RunNoGUI() {
+	var err error
+	switch {
+	case IsParallelRun():
+		err = rn.runParallelRun()
+	case rn.Config.Sweep != "":
+		err = rn.RunSweep()
+	case rn.Config.Parallel:
+		err = rn.RunParallel()
+	default:
+		err = rn.RunStd()
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// RunStd does the standard NRuns training runs starting at Run, saving
+// the configured log files, checkpoints, Summary, Figures and exports.
+func (rn *Runner) RunStd() error {
+	cfg := rn.Config
+	if rn.Note != nil && *rn.Note != "" {
+		fmt.Printf("Note: %s\n", *rn.Note)
+	}
+	if rn.logOn("SaveWeights") {
+		fmt.Printf("Saving final weights per run\n")
+	}
+	run, nruns := *rn.Run, *rn.NRuns
+	runName := rn.RunName(run)
+	if cfg.Resume != "" {
+		runName += "_resumed" // keep the logs from before the checkpoint
+	}
+	elog.SetLogFile(rn.Logs, rn.logOn("Run"), etime.Train, etime.Run, "run", rn.NetName, runName)
+	if err := rn.startRuns(runName); err != nil {
+		return err
+	}
+	fmt.Printf("Running %d Runs starting at %d\n", nruns, run)
+	if err := rn.doRuns(run, nruns, runName); err != nil {
+		return err
+	}
+	if cfg.Summary != "" {
+		if err := SaveSummary(rn.Logs.Table(etime.Train, etime.Run), cfg.Summary, rn.NetName, runName); err != nil {
+			return err
+		}
+	}
+	return rn.saveResults(runName)
+}
+
+// startRuns sets up the runs with the given name, for RunStd and each
+// process of RunParallel: the log files other than the run log, the
+// NetData and Figures if they are to be saved, and then Init.
+func (rn *Runner) startRuns(runName string) error {
+	rn.Stats.SetString("RunName", runName) // used for naming logs, stats, etc
+	if err := rn.SetLogFiles(runName); err != nil {
+		return err
+	}
+	if rn.logOn("NetData") && rn.GUI != nil {
+		fmt.Printf("Saving NetView data from testing\n")
+		rn.GUI.InitNetData(rn.Net, 200)
+	}
+	if rn.Config.Figures != "" && rn.ConfigPlots != nil {
+		rn.Figures.Config(rn.Net, rn.ConfigPlots)
+	}
+	rn.Init()
+	return nil
+}
+
+// doRuns does nruns training runs starting at the given run, with NEpochs
+// epochs each, after startRuns, saving checkpoints with the given run name
+// or resuming from one, if configured, and then closes the log files.
+func (rn *Runner) doRuns(run, nruns int, runName string) error {
+	cfg := rn.Config
+	rn.Loops.Loop(etime.Train, etime.Run).Counter.SetCurMaxPlusN(run, nruns)
+	rn.Loops.Loop(etime.Train, etime.Epoch).Counter.Max = *rn.NEpochs
+	if cfg.Checkpoint > 0 || cfg.Resume != "" {
+		cs := &Checkpointer{Interval: cfg.Checkpoint, File: CheckpointFilename(rn.NetName, runName),
+			Context: rn.Context, Net: rn.Net, Envs: rn.Envs, Stats: rn.Stats, Logs: rn.Logs,
+			Extra: rn.Extra, SetExtra: rn.SetExtra}
+		if err := cs.Config(rn.Loops, cfg.Resume); err != nil {
+			return err
+		}
+	}
+	rn.Loops.Run(etime.Train)
+	return rn.CloseLogFiles()
+}
+
+// saveResults saves the Figures and exports of the final network of the
+// runs with the given name, after doing the Analyze, and the NetData,
+// if they are configured, for RunStd and each process of RunParallel.
+func (rn *Runner) saveResults(runName string) error {
+	cfg := rn.Config
+	if (cfg.Figures != "" || cfg.Export != "") && rn.Analyze != nil {
+		rn.Analyze()
+	}
+	if cfg.Figures != "" && rn.Figures != nil {
+		if rn.UpdateFigures != nil {
+			rn.UpdateFigures()
+		}
+		if err := rn.Figures.Save(cfg.Figures, rn.NetName, runName); err != nil {
+			return err
+		}
+	}
+	if cfg.Export != "" {
+		if err := SaveLogsNPZ(rn.Logs, cfg.Export, rn.NetName, runName); err != nil {
+			return err
+		}
+	}
+	if rn.logOn("NetData") && rn.GUI != nil {
+		rn.GUI.SaveNetData(runName)
+	}
+	return nil
+}
+
+// SetLogFiles sets the configured log files other than the run log,
+// including that of the WtLog, for the runs with the given name.
+func (rn *Runner) SetLogFiles(runName string) error {
+	elog.SetLogFile(rn.Logs, rn.logOn("Trial"), etime.Train, etime.Trial, "trl", rn.NetName, runName)
+	elog.SetLogFile(rn.Logs, rn.logOn("Epoch"), etime.Train, etime.Epoch, "epc", rn.NetName, runName)
+	elog.SetLogFile(rn.Logs, rn.logOn("TestEpoch"), etime.Test, etime.Epoch, "tst_epc", rn.NetName, runName)
+	elog.SetLogFile(rn.Logs, rn.logOn("TestTrial"), etime.Test, etime.Trial, "tst_trl", rn.NetName, runName)
+	if rn.WtLog != nil {
+		return rn.WtLog.SetFile(rn.logOn("Weights"), "wts", rn.NetName, runName)
+	}
+	return nil
+}
+
+// logOn returns the value of the bool field of the Log with the given
+// name, which is false if it does not have that field.
+func (rn *Runner) logOn(name string) bool {
+	fv := reflectx.NonPointerValue(reflect.ValueOf(rn.Log)).FieldByName(name)
+	return fv.IsValid() && fv.Kind() == reflect.Bool && fv.Bool()
+}
+
+// CloseLogFiles closes the log files, including that of the WtLog,
+// returning any error in closing it.
+func (rn *Runner) CloseLogFiles() error {
+	rn.Logs.CloseLogFiles()
+	if rn.WtLog != nil {
+		return rn.WtLog.CloseFile()
+	}
+	return nil
+}
+
+// TrainRuns does nruns training runs starting at the given run, with
+// NEpochs epochs each, after initializing the sim with Init, returning
+// the training run log, which only has these runs. NEpochs is read from
+// the Config of the sim after Init, so that it can be set by a Sweep.
+func (rn *Runner) TrainRuns(run, nruns int) *table.Table {
+	rn.Init()
+	rn.Loops.Loop(etime.Train, etime.Run).Counter.SetCurMaxPlusN(run, nruns)
+	rn.Loops.Loop(etime.Train, etime.Epoch).Counter.Max = *rn.NEpochs
+	rn.Logs.ResetLog(etime.Train, etime.Run)
+	rn.Loops.Run(etime.Train)
+	return rn.Logs.Table(etime.Train, etime.Run)
+}
+
+// SetRunName sets the RunName stat for the first Run, which is used
+// for naming the logs, and returns it.
+func (rn *Runner) SetRunName() string {
+	runName := rn.RunName(*rn.Run)
+	rn.Stats.SetString("RunName", runName)
+	return runName
+}
+
+// Runs does the NRuns training runs starting at Run, returning the
+// training run log, as the run function for Sweep.Run.
+func (rn *Runner) Runs() *table.Table {
+	return rn.TrainRuns(*rn.Run, *rn.NRuns)
+}
+
+// RunSweep runs the Sweep, with the NRuns runs (random seeds) for each
+// point, and saves the training run log rows for all of the points
+// to a _sweep.tsv file.
+func (rn *Runner) RunSweep() error {
+	sw, err := ParseSweep(rn.Config.Sweep, rn.Config.SweepGrid)
+	if err != nil {
+		return err
+	}
+	runName := rn.SetRunName()
+	dt, err := sw.Run(rn.Sim, rn.Runs)
+	if err != nil {
+		return err
+	}
+	return SaveTable(dt, "sweep", rn.NetName, runName)
+}
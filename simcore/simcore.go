@@ -0,0 +1,228 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package simcore has the standard functionality shared by the sims,
+// which otherwise each re-implement the same methods on their own Sim type.
+// Each Sim method just calls the corresponding function here, passing
+// its own elements, with Hooks for the parts that are specific to the sim.
+package simcore
+
+import (
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"time"
+
+	"cogentcore.org/core/base/errors"
+	"cogentcore.org/core/base/randx"
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/system"
+	"cogentcore.org/core/tensor/table"
+	"github.com/emer/emergent/v2/elog"
+	"github.com/emer/emergent/v2/env"
+	"github.com/emer/emergent/v2/estats"
+	"github.com/emer/emergent/v2/etime"
+	"github.com/emer/emergent/v2/looper"
+	"github.com/emer/emergent/v2/netview"
+	"github.com/emer/leabra/v2/leabra"
+)
+
+// Hooks are the sim-specific methods called by the shared functions.
+// Any hook that is nil is skipped.
+type Hooks struct {
+
+	// InitStats initializes all the statistics, at the start of a new run.
+	InitStats func()
+
+	// TrialStats computes the trial-level statistics.
+	TrialStats func()
+
+	// StatCounters saves the current counters to the Stats.
+	StatCounters func()
+
+	// CycleStats computes the cycle-level statistics, such as the Harmony.
+	// If it is nil, nothing is logged at the Cycle level.
+	CycleStats func()
+}
+
+func (h *Hooks) initStats() {
+	if h.InitStats != nil {
+		h.InitStats()
+	}
+}
+
+func (h *Hooks) trialStats() {
+	if h.TrialStats != nil {
+		h.TrialStats()
+	}
+}
+
+func (h *Hooks) statCounters() {
+	if h.StatCounters != nil {
+		h.StatCounters()
+	}
+}
+
+func (h *Hooks) cycleStats() {
+	if h.CycleStats != nil {
+		h.CycleStats()
+	}
+}
+
+// InitRandSeed initializes the random seed based on current training run number
+func InitRandSeed(seeds *randx.Seeds, net *leabra.Network, run int) {
+	seeds.Set(run)
+	seeds.Set(run, &net.Rand)
+}
+
+// SeedEpochName is the name of the function added by SeedEpochs.
+const SeedEpochName = "SeedEpoch"
+
+// SeedEpochs adds a function at the start of each training epoch that
+// reseeds the global random number generator and that of the network
+// with EpochSeed of the seed for the current run and the epoch.
+// The state of the generators at the start of each epoch then only depends
+// on the run and epoch, so a run resumed from a Checkpoint continues exactly
+// as it would have without interruption: the state of the math/rand
+// generators cannot be saved otherwise.
+func SeedEpochs(ls *looper.Stacks, seeds *randx.Seeds, net *leabra.Network) {
+	trn := ls.Stacks[etime.Train]
+	trn.Loops[etime.Epoch].OnStart.Prepend(SeedEpochName, func() bool {
+		seed := EpochSeed((*seeds)[trn.Loops[etime.Run].Counter.Cur], trn.Loops[etime.Epoch].Counter.Cur)
+		rand.Seed(seed)
+		net.Rand.Seed(seed)
+		return true
+	})
+}
+
+// EpochSeed returns the random seed for the given epoch of a run
+// with the given seed, mixing them as in the splitmix64 generator,
+// so that the seeds of nearby epochs and runs are unrelated.
+func EpochSeed(seed int64, epoch int) int64 {
+	z := uint64(seed) + uint64(epoch+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
+
+// NewRun initializes a new run of the model, using the current training
+// run counter to set the random seed, and resetting the Train and Test
+// environments, the network weights, the stats and the epoch logs.
+func NewRun(ls *looper.Stacks, ctx *leabra.Context, net *leabra.Network, envs env.Envs, logs *elog.Logs, seeds *randx.Seeds, h Hooks) {
+	InitRandSeed(seeds, net, ls.Loop(etime.Train, etime.Run).Counter.Cur)
+	envs.ByMode(etime.Train).Init(0)
+	envs.ByMode(etime.Test).Init(0)
+	ctx.Reset()
+	ctx.Mode = etime.Train
+	net.InitWeights()
+	h.initStats()
+	h.statCounters()
+	logs.ResetLog(etime.Train, etime.Epoch)
+	logs.ResetLog(etime.Test, etime.Epoch)
+}
+
+// StatCounters saves the current counters for the current mode to the Stats,
+// along with the Cycle. If trainEpoch is true, the Epoch is always
+// taken from the Train stack, so testing is labeled with the training epoch.
+func StatCounters(ls *looper.Stacks, ctx *leabra.Context, st *estats.Stats, trainEpoch bool) {
+	ls.Stacks[ctx.Mode].CountersToStats(st)
+	if trainEpoch {
+		trnEpc := ls.Stacks[etime.Train].Loops[etime.Epoch].Counter.Cur
+		st.SetInt("Epoch", trnEpc)
+	}
+	trl := st.Int("Trial")
+	st.SetInt("Trial", trl)
+	st.SetInt("Cycle", int(ctx.Cycle))
+}
+
+// NetViewCounters updates the NetView text with the given stats,
+// computing the trial stats first at the Trial level.
+// Does nothing if there is no NetView, as when running without the GUI.
+func NetViewCounters(vu *netview.ViewUpdate, st *estats.Stats, tm etime.Times, h Hooks, stats ...string) {
+	if vu.View == nil {
+		return
+	}
+	if tm == etime.Trial {
+		h.trialStats() // get trial stats for current di
+	}
+	h.statCounters()
+	vu.Text = st.Print(stats)
+}
+
+// ApplyInputs applies the current state of the given environment
+// to all of the Input and Target layers in the network,
+// using the layer names to get the env state.
+func ApplyInputs(net *leabra.Network, ev env.Env) {
+	lays := net.LayersByType(leabra.InputLayer, leabra.TargetLayer)
+	net.InitExt()
+	for _, lnm := range lays {
+		ly := net.LayerByName(lnm)
+		pats := ev.State(ly.Name)
+		if pats != nil {
+			ly.ApplyExt(pats)
+		}
+	}
+}
+
+// Log adds a new row to the log for the given mode and time,
+// computing the trial stats first at the Trial level.
+// Cycle level logging is skipped unless there is a CycleStats hook.
+func Log(ctx *leabra.Context, logs *elog.Logs, mode etime.Modes, time etime.Times, h Hooks) {
+	if mode != etime.Analyze {
+		ctx.Mode = mode // Also set specifically in a Loop callback.
+	}
+	dt := logs.Table(mode, time)
+	if dt == nil {
+		return
+	}
+	row := dt.Rows
+
+	switch {
+	case time == etime.Cycle:
+		if h.CycleStats == nil {
+			return
+		}
+		h.cycleStats()
+		h.statCounters()
+	case time == etime.Trial:
+		h.trialStats()
+		h.statCounters()
+	}
+
+	logs.LogRow(mode, time, row) // also logs to file, etc
+}
+
+// OpenPatAsset opens a tab-separated pattern file from the given
+// (typically embedded) filesystem, setting the name and desc metadata.
+func OpenPatAsset(fsys fs.FS, dt *table.Table, fnm, name, desc string) error {
+	dt.SetMetaData("name", name)
+	dt.SetMetaData("desc", desc)
+	err := dt.OpenFS(fsys, fnm, table.Tab)
+	if errors.Log(err) == nil {
+		for i := 1; i < dt.NumColumns(); i++ {
+			dt.Columns[i].SetMetaData("grid-fill", "0.9")
+		}
+	}
+	return err
+}
+
+// WebYield must be called periodically within long-running loops,
+// which otherwise hang the browser when running on the web.
+func WebYield() {
+	if core.TheApp.Platform() == system.Web {
+		time.Sleep(time.Millisecond) // critical to prevent hanging!
+	}
+}
+
+// SaveTable saves the given table, e.g., a MiscTable with results of an
+// analysis, to a tab-separated file in the current directory, using
+// the standard log file name: netName_runName_logName.tsv
+func SaveTable(dt *table.Table, logName, netName, runName string) error {
+	fnm := elog.LogFilename(logName, netName, runName)
+	err := dt.SaveCSV(core.Filename(fnm), table.Tab, table.Headers)
+	if errors.Log(err) == nil {
+		fmt.Printf("Saved: %s\n", fnm)
+	}
+	return err
+}
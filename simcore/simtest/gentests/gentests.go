@@ -0,0 +1,150 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command gentests generates the golden_test.go file of each of the Sims,
+// which runs its golden test with simtest.Run, RunTest or RunTestAll, so that
+// they are all the same except for the args of each sim. It is run by
+// go generate in simcore/simtest, from anywhere in the repository.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+// Sim is one of the sims with a golden test.
+type Sim struct {
+
+	// Dir is the directory of the sim, from the root of the repository.
+	Dir string
+
+	// Args are the args for the sim, in addition to the simtest.GoldenArgs
+	// for the sims that train a network, e.g., the number of epochs,
+	// which is as many as needed for its key stats to change, while
+	// keeping the test of the slower sims short.
+	Args []string
+
+	// Test is whether the sim only tests a network, so that it is run
+	// with simtest.RunTest instead of simtest.Run.
+	Test bool
+
+	// TestAll is whether the sim only tests its network after training,
+	// so that it is run with simtest.RunTestAll instead of simtest.Run.
+	TestAll bool
+}
+
+// Sims are all of the sims with golden tests, which is all of them except
+// ch10/ss, whose training patterns are not in the repository, so that it
+// cannot be built without them.
+var Sims = []Sim{
+	{Dir: "ch2/detector", Test: true},
+	{Dir: "ch2/neuron", Test: true},
+	{Dir: "ch3/cats_dogs", Test: true},
+	{Dir: "ch3/faces", Test: true},
+	{Dir: "ch3/inhib", Test: true},
+	{Dir: "ch3/necker_cube", Test: true},
+	{Dir: "ch4/pat_assoc", Args: []string{"-NRuns", "2", "-NEpochs", "20"}},
+	{Dir: "ch4/err_driven_hidden", Args: []string{"-NRuns", "2", "-NEpochs", "30"}},
+	{Dir: "ch4/family_trees", Args: []string{"-NEpochs", "15"}},
+	{Dir: "ch4/hebberr_combo", Args: []string{"-NRuns", "2", "-NEpochs", "20"}},
+	{Dir: "ch4/self_org", Args: []string{"-NRuns", "2", "-NEpochs", "20"}},
+	{Dir: "ch6/attn", Test: true},
+	{Dir: "ch6/objrec", Args: []string{"-NEpochs", "5"}},
+	{Dir: "ch6/v1rf", Args: []string{"-NEpochs", "5"}},
+	{Dir: "ch7/abac", Args: []string{"-NRuns", "2", "-NEpochs", "20"}},
+	{Dir: "ch7/hip", Args: []string{"-NRuns", "2", "-NEpochs", "10"}}, // RunStats needs more than one run
+	{Dir: "ch7/priming", Args: []string{"-NRuns", "2", "-NEpochs", "20"}},
+	{Dir: "ch8/bg", Args: []string{"-NRuns", "2", "-NEpochs", "20"}},
+	{Dir: "ch8/rl", Args: []string{"-NRuns", "2", "-NEpochs", "20"}},
+	{Dir: "ch9/a_not_b", Args: []string{"-NEpochs", "10"}},
+	{Dir: "ch9/sir", Args: []string{"-NRuns", "2", "-NEpochs", "20"}},
+	{Dir: "ch9/sir/sir2", Args: []string{"-NEpochs", "10"}},
+	{Dir: "ch9/stroop", Args: []string{"-NRuns", "2", "-NEpochs", "55"}, TestAll: true}, // trained for the RT
+	{Dir: "ch10/dyslexia", Args: []string{"-NEpochs", "10"}},
+	{Dir: "ch10/sem", Args: []string{"-NEpochs", "3"}},
+	{Dir: "ch10/sg", Args: []string{"-NEpochs", "10"}},
+}
+
+var testTmpl = template.Must(template.New("test").Parse(`// Code generated by "go run ./gentests" in simcore/simtest; DO NOT EDIT.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/CompCogNeuro/sims/v2/simcore/simtest"
+)
+{{if .Test}}
+// TestGolden tests the network without the GUI, and compares
+// the testing log with the golden reference table.
+func TestGolden(t *testing.T) {
+	simtest.RunTest(t, &Sim{}{{range .Args}}, {{printf "%q" .}}{{end}})
+}
+{{else if .TestAll}}
+// TestGolden runs a short training schedule without the GUI, and compares
+// the training epoch log, and the testing log of the trained network,
+// with the golden reference tables.
+func TestGolden(t *testing.T) {
+	simtest.RunTestAll(t, &Sim{}{{range .Args}}, {{printf "%q" .}}{{end}})
+}
+{{else}}
+// TestGolden runs a short training schedule without the GUI, and compares
+// the training epoch log with the golden reference table.
+func TestGolden(t *testing.T) {
+	simtest.Run(t, &Sim{}{{range .Args}}, {{printf "%q" .}}{{end}})
+}
+{{end}}`))
+
+func main() {
+	root, err := rootDir()
+	if err == nil {
+		err = generate(root)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gentests:", err)
+		os.Exit(1)
+	}
+}
+
+// generate writes the golden_test.go file of each of the Sims,
+// in the repository at the given root directory.
+func generate(root string) error {
+	for _, sim := range Sims {
+		var b bytes.Buffer
+		if err := testTmpl.Execute(&b, sim); err != nil {
+			return err
+		}
+		src, err := format.Source(b.Bytes())
+		if err != nil {
+			return fmt.Errorf("%s: %w", sim.Dir, err)
+		}
+		if err := os.WriteFile(filepath.Join(root, sim.Dir, "golden_test.go"), src, 0666); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rootDir returns the root directory of the repository, with the go.mod
+// file, from the current directory or the closest one above it.
+func rootDir() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+		up := filepath.Dir(dir)
+		if up == dir {
+			return "", fmt.Errorf("go.mod not found")
+		}
+		dir = up
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package simtest has the shared code for the golden regression tests
+// of the sims, which run each sim without the GUI for a short training
+// schedule of one or more runs starting at run 0 (and thus with the first
+// of its RandSeeds), and compare the key stats of its training epoch log
+// in each run against the golden_epc.tsv reference table checked in to
+// the sim's directory (or, for the sims without any such stats, a summary
+// of the weights of its network at the end against golden_wts.tsv), along
+// with those of its testing epoch log, if it tests during training, against
+// golden_tst_epc.tsv. The sims that only test a network, or only test it
+// after training, compare all of the stats of their testing log against
+// golden_tst.tsv. These tests make sure that upgrades of leabra or emergent
+// cannot silently change the textbook results.
+// Run them with:
+//
+//	go test ./...
+//
+// and after an intended change in the results, regenerate the reference
+// tables, check them, and check them in, with:
+//
+//	go test ./... -update
+//
+// The golden_test.go file of each sim is generated by the gentests
+// command, from its list of the sims and their args, with:
+//
+//	go generate ./simcore/simtest
+package simtest
+
+//go:generate go run ./gentests
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"cogentcore.org/core/base/reflectx"
+	"cogentcore.org/core/tensor/table"
+	"github.com/CompCogNeuro/sims/v2/simcore"
+	"github.com/emer/emergent/v2/elog"
+	"github.com/emer/emergent/v2/etime"
+)
+
+var update = flag.Bool("update", false, "update the golden reference tables from the runs, instead of comparing with them")
+
+// GoldenFile, GoldenWeightsFile, GoldenTestEpochFile and GoldenTestFile
+// are the names of the golden reference tables in each sim's directory,
+// which is the current directory of its tests.
+const (
+	GoldenFile          = "golden_epc.tsv"
+	GoldenWeightsFile   = "golden_wts.tsv"
+	GoldenTestEpochFile = "golden_tst_epc.tsv"
+	GoldenTestFile      = "golden_tst.tsv"
+)
+
+// GoldenArgs are the command line args for the sims in the golden tests,
+// with the Run (random seed) at its default of 0, and no log files.
+var GoldenArgs = []string{"-nogui", "-NRuns", "1", "-NEpochs", "3", "-Log.Epoch=false", "-Log.Run=false"}
+
+// SetArgs sets os.Args to the GoldenArgs plus any others, replacing the
+// go test args, so that they are used by econfig.Config when the Sim
+// is created with New.
+func SetArgs(args ...string) {
+	os.Args = append(append(os.Args[:1:1], GoldenArgs...), args...)
+}
+
+// Sim is a sim that can be run by Run, as every sim is.
+type Sim interface {
+	New()
+	ConfigAll()
+	Runner() *simcore.Runner
+}
+
+// Run runs the given new Sim without the GUI, with the GoldenArgs plus
+// any others, and compares the key stats of its training epoch log, over
+// all of its runs, with the GoldenFile, failing the test if they differ or the file is missing,
+// or saves them to the file with the -update flag. The sims that have a
+// GoldenWeightsFile instead, as they have no such stats in their logs,
+// compare the GoldenWeights of the network with it. The key stats of the
+// testing epoch log, for the sims that test during training, are also
+// compared with the GoldenTestEpochFile.
+func Run(t *testing.T, sim Sim, args ...string) {
+	t.Helper()
+	SetArgs(args...)
+	sim.New()
+	sim.ConfigAll()
+	rn := sim.Runner()
+	// the epoch logs are reset by NewRun, so their rows are collected
+	// at the end of each run
+	var epc, tstEpc *table.Table
+	rn.Loops.Loop(etime.Train, etime.Run).OnEnd.Add("GoldenRows", func() {
+		epc = appendRows(epc, rn.Logs.Table(etime.Train, etime.Epoch))
+		tstEpc = appendRows(tstEpc, rn.Logs.Table(etime.Test, etime.Epoch))
+	})
+	if err := rn.RunStd(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(GoldenWeightsFile); err == nil {
+		check(t, simcore.GoldenWeights(rn.Net), GoldenWeightsFile)
+	} else if epc != nil {
+		check(t, epc, GoldenFile)
+	} else {
+		t.Fatal("the sim has no training epoch log data")
+	}
+	if tstEpc != nil {
+		if slices.ContainsFunc(tstEpc.ColumnNames, func(cn string) bool { return cn != "Run" && cn != "Epoch" }) {
+			check(t, tstEpc, GoldenTestEpochFile)
+		}
+	}
+}
+
+// appendRows appends the rows of the GoldenTable of the given log,
+// if it has any, to those in gt, returning the result.
+func appendRows(gt, dt *table.Table) *table.Table {
+	if dt == nil || dt.Rows == 0 {
+		return gt
+	}
+	rt := simcore.GoldenTable(dt)
+	if gt == nil {
+		return rt
+	}
+	gt.AppendRows(rt)
+	return gt
+}
+
+// TestSim is a sim that only tests a network, which can be run by RunTest,
+// as each of those sims is, with its Logs field.
+type TestSim interface {
+	New()
+	ConfigAll()
+	RunNoGUI()
+}
+
+// RunTest runs the given new TestSim without the GUI, with -nogui plus any
+// other args, in a temporary directory for any files that it saves, and
+// compares the GoldenTestTable of its testing trial log, or its testing
+// cycle log if it only has that, with the GoldenTestFile, in the same way
+// as Run.
+func RunTest(t *testing.T, sim TestSim, args ...string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	os.Args = append(append(os.Args[:1:1], "-nogui"), args...)
+	sim.New()
+	sim.ConfigAll()
+	sim.RunNoGUI()
+	lv := reflectx.NonPointerValue(reflect.ValueOf(sim)).FieldByName("Logs")
+	if !lv.IsValid() || lv.Type() != reflect.TypeOf(elog.Logs{}) {
+		t.Fatal("RunTest: the sim has no Logs field")
+	}
+	checkTest(t, lv.Addr().Interface().(*elog.Logs), filepath.Join(wd, GoldenTestFile))
+}
+
+// TestAllSim is a sim that trains a network and then tests it with TestAll,
+// which can be run by RunTestAll.
+type TestAllSim interface {
+	Sim
+	TestAll()
+}
+
+// RunTestAll runs the given new TestAllSim as Run does, and then tests
+// the trained network with its TestAll, comparing the GoldenTestTable of
+// its testing trial log with the GoldenTestFile, for the sims that only
+// test their network after training, e.g., for their reaction times.
+func RunTestAll(t *testing.T, sim TestAllSim, args ...string) {
+	t.Helper()
+	Run(t, sim, args...)
+	sim.TestAll()
+	checkTest(t, sim.Runner().Logs, GoldenTestFile)
+}
+
+// checkTest compares the GoldenTestTable of the testing trial log,
+// or the testing cycle log if it only has that, with the given file.
+func checkTest(t *testing.T, logs *elog.Logs, fnm string) {
+	t.Helper()
+	dt := logs.Table(etime.Test, etime.Trial)
+	if dt == nil || dt.Rows == 0 {
+		dt = logs.Table(etime.Test, etime.Cycle)
+	}
+	if dt == nil || dt.Rows == 0 {
+		t.Fatal("the sim has no testing trial or cycle log data")
+	}
+	check(t, simcore.GoldenTestTable(dt), fnm)
+}
+
+// Main is the TestMain of the sims with tests of their Parallel runs,
+// given the main function of the sim: each process of RunParallel runs
+// the test binary again, which then runs the sim instead of the tests.
+func Main(m *testing.M, main func()) {
+	if simcore.IsParallelRun() {
+		main()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func check(t *testing.T, gt *table.Table, fnm string) {
+	t.Helper()
+	if *update {
+		if err := simcore.SaveGolden(gt, fnm); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	if err := simcore.CheckGolden(gt, fnm); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"cogentcore.org/core/base/errors"
+	"cogentcore.org/core/paint"
+	"cogentcore.org/core/plot"
+	"cogentcore.org/core/plot/plots"
+	"cogentcore.org/core/tensor/stats/stats"
+	"cogentcore.org/core/tensor/table"
+	"github.com/emer/emergent/v2/elog"
+)
+
+// SummarySkipColumns are the columns of the run log that are not
+// summarized by default, because they are counters and not results.
+var SummarySkipColumns = []string{"Run", "Epoch", "Trial", "Cycle"}
+
+// RunSummary returns a table with the statistics across rows
+// (typically one per run, i.e., random seed) of the given run log,
+// for each of the given metric columns, or all of the scalar numerical
+// columns other than the SummarySkipColumns if none are given.
+// There is one row per metric, with the number of non-NaN values (N),
+// the Mean, standard deviation (SD), standard error of the mean (SEM),
+// and the lower and upper bounds of the 95% confidence interval of the
+// mean, based on the t distribution with N-1 degrees of freedom.
+// With only one value, the SD and SEM are 0 and the confidence interval
+// is just the mean, and with none, all of the statistics are NaN.
+func RunSummary(dt *table.Table, metrics ...string) (*table.Table, error) {
+	metrics = summaryMetrics(dt, metrics)
+	st := table.NewTable("RunSummary")
+	st.AddStringColumn("Metric")
+	addSummaryColumns(st)
+	if err := addSummaryRows(st, table.NewIndexView(dt), metrics); err != nil {
+		return st, fmt.Errorf("RunSummary: %w", err)
+	}
+	return st, nil
+}
+
+// summaryMetrics returns the given metrics, or all of the scalar numerical
+// columns of the given table other than the SummarySkipColumns if none.
+func summaryMetrics(dt *table.Table, metrics []string) []string {
+	if len(metrics) > 0 {
+		return metrics
+	}
+	for i, cl := range dt.Columns {
+		cn := dt.ColumnNames[i]
+		if cl.IsString() || cl.NumDims() > 1 || slices.Contains(SummarySkipColumns, cn) {
+			continue
+		}
+		metrics = append(metrics, cn)
+	}
+	return metrics
+}
+
+// addSummaryColumns adds the statistics columns of a RunSummary to the given table.
+func addSummaryColumns(st *table.Table) {
+	for _, cn := range []string{"N", "Mean", "SD", "SEM", "CI95Lo", "CI95Hi"} {
+		st.AddFloat64Column(cn)
+	}
+}
+
+// addSummaryRows adds a row to the given summary table for each of the given
+// metrics, with the statistics across the rows of the given view, setting
+// the Metric and statistics columns, so that any other columns of the
+// summary table can be set by the caller for the new rows.
+func addSummaryRows(st *table.Table, ix *table.IndexView, metrics []string) error {
+	dt := ix.Table
+	for _, cn := range metrics {
+		ci, err := dt.ColumnIndex(cn)
+		if err != nil {
+			return err
+		}
+		if dt.Columns[ci].IsString() {
+			return fmt.Errorf("column %q is not numerical", cn)
+		}
+		n := stats.CountIndex(ix, ci)[0]
+		mean := stats.MeanIndex(ix, ci)[0]
+		sd := stats.StdIndex(ix, ci)[0]
+		sem := stats.SemIndex(ix, ci)[0]
+		var ci95 float64
+		switch n {
+		case 0: // e.g., no run reached criterion
+			mean, sd, sem, ci95 = math.NaN(), math.NaN(), math.NaN(), math.NaN()
+		case 1: // no variation to estimate
+			sd, sem = 0, 0
+		default:
+			ci95 = TCrit95(int(n)-1) * sem
+		}
+		row := st.Rows
+		st.SetNumRows(row + 1)
+		st.SetString("Metric", row, cn)
+		st.SetFloat("N", row, n)
+		st.SetFloat("Mean", row, mean)
+		st.SetFloat("SD", row, sd)
+		st.SetFloat("SEM", row, sem)
+		st.SetFloat("CI95Lo", row, mean-ci95)
+		st.SetFloat("CI95Hi", row, mean+ci95)
+	}
+	return nil
+}
+
+// tCrit95 are the two-tailed 95% critical values of the t distribution
+// for 1 to 30 degrees of freedom.
+var tCrit95 = []float64{12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042}
+
+// TCrit95 returns the two-tailed 95% critical value of the t distribution
+// for the given degrees of freedom, which multiplies the SEM to give the
+// half-width of the 95% confidence interval of the mean.
+// Above 30 degrees of freedom, it uses an approximation that converges
+// to the normal value of 1.96. It is NaN for df < 1.
+func TCrit95(df int) float64 {
+	switch {
+	case df < 1:
+		return math.NaN()
+	case df <= len(tCrit95):
+		return tCrit95[df-1]
+	}
+	return 1.96 + 2.37/float64(df)
+}
+
+// SummaryMarkdown returns the given RunSummary table as a markdown table,
+// with the 95% confidence interval in brackets.
+func SummaryMarkdown(st *table.Table) string {
+	var b strings.Builder
+	b.WriteString("| Metric | N | Mean | SD | SEM | 95% CI |\n")
+	b.WriteString("|--------|--:|-----:|---:|----:|--------|\n")
+	for row := range st.Rows {
+		fmt.Fprintf(&b, "| %s | %d | %.4g | %.4g | %.4g | [%.4g, %.4g] |\n",
+			st.StringValue("Metric", row), int(st.Float("N", row)),
+			st.Float("Mean", row), st.Float("SD", row), st.Float("SEM", row),
+			st.Float("CI95Lo", row), st.Float("CI95Hi", row))
+	}
+	return b.String()
+}
+
+// SummaryPlot returns a bar plot of the means in the given RunSummary table,
+// with error bars for the 95% confidence intervals, which can be rendered
+// without the GUI. Metrics with very different scales are best plotted
+// separately, by making a RunSummary with just the metrics of each scale.
+func SummaryPlot(st *table.Table, title string) (*plot.Plot, error) {
+	means := make(plot.Values, st.Rows)
+	cis := make(plot.Values, st.Rows)
+	names := make([]string, st.Rows)
+	for row := range st.Rows {
+		names[row] = st.StringValue("Metric", row)
+		mean := st.Float("Mean", row)
+		ci := st.Float("CI95Hi", row) - mean
+		if math.IsNaN(mean) {
+			mean, ci = 0, 0 // no values, and NaN values are skipped by the bar chart
+		}
+		means[row] = float32(mean)
+		cis[row] = float32(ci)
+	}
+	bc, err := plots.NewBarChart(means, cis)
+	if err != nil {
+		return nil, err
+	}
+	bc.Offset = 0 // align with the NominalX names
+	// the GUI does this when it starts, but it is needed to render text without it
+	paint.FontLibrary.InitFontPaths(paint.FontPaths...)
+	pt := plot.New()
+	pt.Title.Text = title
+	pt.Y.Label.Text = "Mean (95% CI)"
+	pt.Add(bc)
+	pt.NominalX(names...)
+	pt.Resize(image.Point{800, 600})
+	return pt, nil
+}
+
+// criterionColumns are the columns of a run log with the epoch at which
+// the run first and last reached criterion, which are -1 if it never did.
+var criterionColumns = []string{"FirstZero", "LastZero"}
+
+// criterionRuns returns a copy of the given run log with its criterionColumns
+// set to NaN for the runs that never reached criterion, so that their
+// statistics are only over the runs that did, plus a Solved column that is
+// 1 for the runs that did and 0 otherwise, as in AddCriterionColumns,
+// so that its mean is the proportion of them. It returns the run log
+// itself if it has none of the criterionColumns.
+func criterionRuns(dt *table.Table) *table.Table {
+	var ct *table.Table
+	for _, cn := range criterionColumns {
+		if _, err := dt.ColumnIndex(cn); err != nil {
+			continue
+		}
+		if ct == nil {
+			ct = dt.Clone()
+			solved := ct.AddFloat64Column("Solved")
+			fz := errors.Log1(ct.ColumnByName(cn))
+			for row := range ct.Rows {
+				solved.SetFloat1D(row, 1)
+				if fz.Float1D(row) < 0 {
+					solved.SetFloat1D(row, 0)
+				}
+			}
+		}
+		cl := errors.Log1(ct.ColumnByName(cn))
+		for row := range ct.Rows {
+			if cl.Float1D(row) < 0 {
+				cl.SetFloat1D(row, math.NaN())
+			}
+		}
+	}
+	if ct == nil {
+		return dt
+	}
+	return ct
+}
+
+// SaveSummary saves the RunSummary of the given run log for the given
+// comma-separated metrics (or all of them if "all") in the current directory,
+// using the standard log file names netName_runName_summary with extensions:
+// .tsv for the table, .md for a markdown version of it, and .svg and .png
+// for a SummaryPlot of it. The FirstZero and LastZero epochs, if the run log
+// has them, are only summarized over the runs that reached criterion, and
+// the proportion of those runs is added as Solved (see criterionRuns).
+func SaveSummary(dt *table.Table, metrics, netName, runName string) error {
+	dt = criterionRuns(dt)
+	var mets []string
+	if metrics != "all" {
+		for _, m := range strings.Split(metrics, ",") {
+			if m = strings.TrimSpace(m); m != "" {
+				mets = append(mets, m)
+			}
+		}
+		if slices.ContainsFunc(criterionColumns, func(cn string) bool { return slices.Contains(mets, cn) }) && !slices.Contains(mets, "Solved") {
+			mets = append(mets, "Solved")
+		}
+	}
+	st, err := RunSummary(dt, mets...)
+	if err != nil {
+		return err
+	}
+	if err := SaveTable(st, "summary", netName, runName); err != nil {
+		return err
+	}
+	fnm := elog.LogFilename("summary", netName, runName)
+	base := strings.TrimSuffix(fnm, filepath.Ext(fnm))
+	if err := os.WriteFile(base+".md", []byte(SummaryMarkdown(st)), 0666); errors.Log(err) != nil {
+		return err
+	}
+	fmt.Printf("Saved: %s\n", base+".md")
+	pt, err := SummaryPlot(st, fmt.Sprintf("%s: %d runs", netName, dt.Rows))
+	if errors.Log(err) != nil {
+		return err
+	}
+	pt.Draw()
+	if err := SavePlotImage(pt, base); errors.Log(err) != nil {
+		return err
+	}
+	fmt.Printf("Saved: %s, %s\n", base+".svg", base+".png")
+	return nil
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"math"
+	"testing"
+
+	"cogentcore.org/core/tensor/table"
+)
+
+// runLogTable returns a run log table with the given float columns,
+// which all have the same number of rows.
+func runLogTable(cols map[string][]float64) *table.Table {
+	dt := table.NewTable()
+	dt.AddIntColumn("Run")
+	for cn := range cols {
+		dt.AddFloat64Column(cn)
+	}
+	for cn, vals := range cols {
+		dt.SetNumRows(len(vals))
+		for row, v := range vals {
+			dt.SetFloat("Run", row, float64(row))
+			dt.SetFloat(cn, row, v)
+		}
+	}
+	return dt
+}
+
+// floatEqual returns whether a and b are within 1e-4, or both NaN.
+func floatEqual(a, b float64) bool {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	return math.Abs(a-b) < 1.0e-4
+}
+
+func TestTCrit95(t *testing.T) {
+	tests := []struct {
+		df   int
+		want float64
+	}{
+		{-1, math.NaN()},
+		{0, math.NaN()},
+		{1, 12.706},
+		{2, 4.303},
+		{10, 2.228},
+		{30, 2.042},
+		{31, 1.96 + 2.37/31},
+		{1000, 1.96 + 2.37/1000},
+	}
+	for _, tt := range tests {
+		if got := TCrit95(tt.df); !floatEqual(got, tt.want) {
+			t.Errorf("TCrit95(%d) = %g != %g", tt.df, got, tt.want)
+		}
+	}
+	// the approximation above 30 continues to decrease toward 1.96
+	if TCrit95(31) >= TCrit95(30) || TCrit95(1000) <= 1.96 {
+		t.Errorf("TCrit95 is not decreasing toward 1.96 above 30: %g, %g, %g", TCrit95(30), TCrit95(31), TCrit95(1000))
+	}
+}
+
+func TestRunSummary(t *testing.T) {
+	nan := math.NaN()
+	sem3 := 1 / math.Sqrt(3)
+	vals31 := make([]float64, 31)
+	for i := range vals31 {
+		vals31[i] = float64(i % 2) // mean 15/31, SD 0.508
+	}
+	mean31 := 15.0 / 31
+	sd31 := math.Sqrt((15*(1-mean31)*(1-mean31) + 16*mean31*mean31) / 30)
+	sem31 := sd31 / math.Sqrt(31)
+	tests := []struct {
+		name string
+		vals []float64
+		want []float64 // N, Mean, SD, SEM, CI95Lo, CI95Hi
+	}{
+		{"N=0", nil, []float64{0, nan, nan, nan, nan, nan}},
+		{"N=0 NaN", []float64{nan, nan}, []float64{0, nan, nan, nan, nan, nan}},
+		{"N=1", []float64{2}, []float64{1, 2, 0, 0, 2, 2}},
+		{"N=1 NaN", []float64{nan, 2}, []float64{1, 2, 0, 0, 2, 2}},
+		{"N=3", []float64{1, 2, 3}, []float64{3, 2, 1, sem3, 2 - 4.303*sem3, 2 + 4.303*sem3}},
+		{"N=31", vals31, []float64{31, mean31, sd31, sem31, mean31 - TCrit95(30)*sem31, mean31 + TCrit95(30)*sem31}},
+	}
+	cols := []string{"N", "Mean", "SD", "SEM", "CI95Lo", "CI95Hi"}
+	for _, tt := range tests {
+		st, err := RunSummary(runLogTable(map[string][]float64{"SSE": tt.vals}))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if st.Rows != 1 || st.StringValue("Metric", 0) != "SSE" {
+			t.Fatalf("%s: %d rows, not just the SSE metric", tt.name, st.Rows)
+		}
+		for i, cn := range cols {
+			if got := st.Float(cn, 0); !floatEqual(got, tt.want[i]) {
+				t.Errorf("%s: %s = %g != %g", tt.name, cn, got, tt.want[i])
+			}
+		}
+	}
+
+	if _, err := RunSummary(runLogTable(map[string][]float64{"SSE": {1}}), "Missing"); err == nil {
+		t.Error("RunSummary: no error for a missing metric")
+	}
+}
+
+func TestCriterionRuns(t *testing.T) {
+	dt := runLogTable(map[string][]float64{"FirstZero": {4, -1, 6}, "LastZero": {5, -1, 8}})
+	ct := criterionRuns(dt)
+	if ct == dt {
+		t.Fatal("criterionRuns did not copy the run log")
+	}
+	if dt.Float("FirstZero", 1) != -1 {
+		t.Error("criterionRuns changed the run log")
+	}
+	for row, want := range []float64{1, 0, 1} {
+		if got := ct.Float("Solved", row); got != want {
+			t.Errorf("Solved[%d] = %g != %g", row, got, want)
+		}
+	}
+	if !math.IsNaN(ct.Float("FirstZero", 1)) || !math.IsNaN(ct.Float("LastZero", 1)) {
+		t.Errorf("the run that never reached criterion is not NaN: %g, %g", ct.Float("FirstZero", 1), ct.Float("LastZero", 1))
+	}
+	st, err := RunSummary(ct, "FirstZero", "Solved")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, mean := st.Float("N", 0), st.Float("Mean", 0); n != 2 || mean != 5 {
+		t.Errorf("FirstZero: N = %g, Mean = %g, not over the 2 solved runs", n, mean)
+	}
+	if n, mean := st.Float("N", 1), st.Float("Mean", 1); n != 3 || !floatEqual(mean, 2.0/3) {
+		t.Errorf("Solved: N = %g, Mean = %g, not the proportion of the 3 runs", n, mean)
+	}
+
+	none := runLogTable(map[string][]float64{"FirstZero": {-1, -1}})
+	st, err = RunSummary(criterionRuns(none), "FirstZero", "Solved")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, mean := st.Float("N", 0), st.Float("Mean", 0); n != 0 || !math.IsNaN(mean) {
+		t.Errorf("FirstZero with no solved runs: N = %g, Mean = %g", n, mean)
+	}
+	if mean := st.Float("Mean", 1); mean != 0 {
+		t.Errorf("Solved with no solved runs: Mean = %g", mean)
+	}
+
+	other := runLogTable(map[string][]float64{"SSE": {1, 2}})
+	if criterionRuns(other) != other {
+		t.Error("criterionRuns copied a run log without criterion columns")
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"cogentcore.org/core/tensor"
+	"cogentcore.org/core/tensor/table"
+	"github.com/emer/emergent/v2/params"
+)
+
+// SweepParam is one field of the Sim to vary in a Sweep,
+// with the values to set it to.
+type SweepParam struct {
+
+	// Path is the dot-delimited path to the field from the Sim,
+	// e.g., AvgLGain or Config.NEpochs
+	Path string
+
+	// Values are the values to set the field to, in order,
+	// which are converted to the type of the field.
+	Values []string
+}
+
+// Sweep is a parameter sweep over the values of one or more
+// fields of the Sim, which runs the sim at each point and collects
+// the results, typically the training run log rows for each seed,
+// into one table, with the swept values as additional columns.
+type Sweep struct {
+
+	// Params are the fields to vary, with their values.
+	Params []SweepParam
+
+	// Grid means run all combinations of the values of all Params.
+	// Otherwise, all Params must have the same number of values,
+	// and each point uses the values at the same index (a list).
+	Grid bool
+}
+
+// ParseSweep parses a sweep specification of the form:
+// "Path1=v1,v2,v3; Path2=v1,v2", as typically given by Config.Sweep.
+// It returns an error if there are no params, or a param has no Path
+// or values, or the same Path is given more than once.
+func ParseSweep(spec string, grid bool) (*Sweep, error) {
+	sw := &Sweep{Grid: grid}
+	for _, ps := range strings.Split(spec, ";") {
+		ps = strings.TrimSpace(ps)
+		if ps == "" {
+			continue
+		}
+		path, vals, ok := strings.Cut(ps, "=")
+		if !ok {
+			return nil, fmt.Errorf("ParseSweep: %q must be of the form Path=v1,v2", ps)
+		}
+		sp := SweepParam{Path: strings.TrimSpace(path)}
+		if sp.Path == "" {
+			return nil, fmt.Errorf("ParseSweep: no Path in %q", ps)
+		}
+		if slices.ContainsFunc(sw.Params, func(o SweepParam) bool { return o.Path == sp.Path }) {
+			return nil, fmt.Errorf("ParseSweep: %q is swept more than once", sp.Path)
+		}
+		for _, v := range strings.Split(vals, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				sp.Values = append(sp.Values, v)
+			}
+		}
+		if len(sp.Values) == 0 {
+			return nil, fmt.Errorf("ParseSweep: no values for %q", sp.Path)
+		}
+		sw.Params = append(sw.Params, sp)
+	}
+	if len(sw.Params) == 0 {
+		return nil, fmt.Errorf("ParseSweep: no params in %q", spec)
+	}
+	return sw, nil
+}
+
+// Points returns the values of all the Params for each point in the sweep.
+func (sw *Sweep) Points() ([][]string, error) {
+	if !sw.Grid {
+		n := len(sw.Params[0].Values)
+		for _, sp := range sw.Params {
+			if len(sp.Values) != n {
+				return nil, fmt.Errorf("Sweep: %q has %d values instead of %d, which is required for a list (non-Grid) sweep", sp.Path, len(sp.Values), n)
+			}
+		}
+		pts := make([][]string, n)
+		for i := range pts {
+			for _, sp := range sw.Params {
+				pts[i] = append(pts[i], sp.Values[i])
+			}
+		}
+		return pts, nil
+	}
+	pts := [][]string{nil}
+	for _, sp := range sw.Params {
+		var npts [][]string
+		for _, pt := range pts {
+			for _, v := range sp.Values {
+				npts = append(npts, append(append([]string{}, pt...), v))
+			}
+		}
+		pts = npts
+	}
+	return pts, nil
+}
+
+// Run runs the sweep on the given sim, setting its fields for each point,
+// and then calling run, which runs the sim for all seeds at that point,
+// and returns the table of results, typically the training run log.
+// All of the result rows are returned in one table, with a column for
+// each of the swept fields, which is numerical if all of its values are.
+func (sw *Sweep) Run(sim any, run func() *table.Table) (*table.Table, error) {
+	pts, err := sw.Points()
+	if err != nil {
+		return nil, err
+	}
+	numeric := make([]bool, len(sw.Params))
+	for i, sp := range sw.Params {
+		numeric[i] = true
+		for _, v := range sp.Values {
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				numeric[i] = false
+				break
+			}
+		}
+	}
+	var dt *table.Table
+	for pi, pt := range pts {
+		for i, sp := range sw.Params {
+			if err := params.SetParam(sim, sp.Path, pt[i]); err != nil {
+				return dt, err
+			}
+		}
+		fmt.Printf("Sweep point %d of %d: %s\n", pi+1, len(pts), sw.PointString(pt))
+		rt := run()
+		if rt == nil {
+			return dt, fmt.Errorf("Sweep: no results for point %d", pi)
+		}
+		kt := rt.Clone()
+		for i := len(sw.Params) - 1; i >= 0; i-- {
+			if numeric[i] {
+				col := tensor.NewFloat64([]int{kt.Rows})
+				v, _ := strconv.ParseFloat(pt[i], 64)
+				for r := range col.Values {
+					col.Values[r] = v
+				}
+				kt.InsertColumn(col, sw.Params[i].Path, 0)
+			} else {
+				col := tensor.NewString([]int{kt.Rows})
+				for r := range col.Values {
+					col.Values[r] = pt[i]
+				}
+				kt.InsertColumn(col, sw.Params[i].Path, 0)
+			}
+		}
+		if dt == nil {
+			dt = kt
+		} else {
+			dt.AppendRows(kt)
+		}
+	}
+	return dt, nil
+}
+
+// PointString returns a string representation of the values for a point.
+func (sw *Sweep) PointString(pt []string) string {
+	var b strings.Builder
+	for i, sp := range sw.Params {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(sp.Path + "=" + pt[i])
+	}
+	return b.String()
+}
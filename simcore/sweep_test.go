@@ -0,0 +1,127 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cogentcore.org/core/tensor/table"
+)
+
+func TestParseSweep(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []SweepParam
+		err  string // substring of the error, or "" for none
+	}{
+		{"Lrate=0.01,0.1", []SweepParam{{"Lrate", []string{"0.01", "0.1"}}}, ""},
+		{" Lrate = 0.01 , 0.1 ; Config.NEpochs=10,20,; ", []SweepParam{{"Lrate", []string{"0.01", "0.1"}}, {"Config.NEpochs", []string{"10", "20"}}}, ""},
+		{"", nil, "no params"},
+		{" ; ;", nil, "no params"},
+		{"Lrate", nil, "must be of the form Path=v1,v2"},
+		{"Lrate=0.1; NEpochs 10,20", nil, `"NEpochs 10,20" must be of the form`},
+		{"Lrate=", nil, `no values for "Lrate"`},
+		{"Lrate= , ,", nil, `no values for "Lrate"`},
+		{"=0.1,0.2", nil, "no Path"},
+		{"Lrate=0.1; Lrate=0.2", nil, `"Lrate" is swept more than once`},
+	}
+	for _, tt := range tests {
+		sw, err := ParseSweep(tt.spec, false)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%q: unexpected error: %v", tt.spec, err)
+		case tt.err != "" && err == nil:
+			t.Errorf("%q: no error, expected %q", tt.spec, tt.err)
+		case tt.err != "" && !strings.Contains(err.Error(), tt.err):
+			t.Errorf("%q: error %q does not contain %q", tt.spec, err, tt.err)
+		case tt.err == "" && !reflect.DeepEqual(sw.Params, tt.want):
+			t.Errorf("%q: %v != %v", tt.spec, sw.Params, tt.want)
+		}
+	}
+}
+
+func TestSweepPoints(t *testing.T) {
+	tests := []struct {
+		spec string
+		grid bool
+		want [][]string
+		err  string
+	}{
+		{"A=1,2,3", false, [][]string{{"1"}, {"2"}, {"3"}}, ""},
+		{"A=1,2,3", true, [][]string{{"1"}, {"2"}, {"3"}}, ""},
+		{"A=1,2; B=x,y", false, [][]string{{"1", "x"}, {"2", "y"}}, ""},
+		{"A=1,2; B=x,y", true, [][]string{{"1", "x"}, {"1", "y"}, {"2", "x"}, {"2", "y"}}, ""},
+		{"A=1,2; B=x,y,z; C=c", true, [][]string{{"1", "x", "c"}, {"1", "y", "c"}, {"1", "z", "c"}, {"2", "x", "c"}, {"2", "y", "c"}, {"2", "z", "c"}}, ""},
+		{"A=1,2; B=x,y,z", false, nil, `"B" has 3 values instead of 2`},
+	}
+	for _, tt := range tests {
+		sw, err := ParseSweep(tt.spec, tt.grid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pts, err := sw.Points()
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%q grid %v: unexpected error: %v", tt.spec, tt.grid, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%q grid %v: error %v, expected %q", tt.spec, tt.grid, err, tt.err)
+		case tt.err == "" && !reflect.DeepEqual(pts, tt.want):
+			t.Errorf("%q grid %v: points %v != %v", tt.spec, tt.grid, pts, tt.want)
+		}
+	}
+}
+
+// sweepTestSim has fields of different types to sweep.
+type sweepTestSim struct {
+	Lrate  float32
+	Learn  string
+	Config struct{ NEpochs int }
+}
+
+// TestSweepRun checks that Run sets the fields of the sim for each point,
+// and adds the swept values to the results, as numerical columns for
+// numerical values and string columns otherwise.
+func TestSweepRun(t *testing.T) {
+	sim := &sweepTestSim{}
+	sw, err := ParseSweep("Lrate=0.1,0.2; Learn=Hebb,Err; Config.NEpochs=5", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var runs []string
+	dt, err := sw.Run(sim, func() *table.Table {
+		runs = append(runs, fmt.Sprintf("%g %s %d", sim.Lrate, sim.Learn, sim.Config.NEpochs))
+		rt := table.NewTable()
+		rt.AddIntColumn("Run")
+		rt.SetNumRows(2)
+		rt.SetFloat("Run", 1, 1)
+		return rt
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"0.1 Hebb 5", "0.1 Err 5", "0.2 Hebb 5", "0.2 Err 5"}; !reflect.DeepEqual(runs, want) {
+		t.Errorf("runs %v != %v", runs, want)
+	}
+	if want := []string{"Lrate", "Learn", "Config.NEpochs", "Run"}; !reflect.DeepEqual(dt.ColumnNames, want) {
+		t.Fatalf("columns %v != %v", dt.ColumnNames, want)
+	}
+	if dt.Rows != 8 {
+		t.Fatalf("%d rows != 8", dt.Rows)
+	}
+	if !floatEqual(dt.Float("Lrate", 7), 0.2) || dt.StringValue("Learn", 7) != "Err" || dt.Float("Config.NEpochs", 7) != 5 || dt.Float("Run", 7) != 1 {
+		t.Errorf("last row: %g %s %g %g", dt.Float("Lrate", 7), dt.StringValue("Learn", 7), dt.Float("Config.NEpochs", 7), dt.Float("Run", 7))
+	}
+	if dt.Columns[0].IsString() || !dt.Columns[1].IsString() {
+		t.Error("Lrate is not a numerical column, or Learn is not a string column")
+	}
+
+	sw, _ = ParseSweep("Missing=1,2", false)
+	if _, err := sw.Run(sim, func() *table.Table { return table.NewTable() }); err == nil {
+		t.Error("no error for a missing field")
+	}
+}
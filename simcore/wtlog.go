@@ -0,0 +1,369 @@
+// Copyright (c) 2024, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simcore
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"cogentcore.org/core/base/errors"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/tensor/table"
+	"github.com/emer/emergent/v2/elog"
+	"github.com/emer/emergent/v2/estats"
+	"github.com/emer/emergent/v2/etime"
+	"github.com/emer/emergent/v2/looper"
+	"github.com/emer/leabra/v2/leabra"
+)
+
+// WeightLog records the weight of every synapse of the pathways of a
+// small leabra.Network after each training trial (or epoch), in a
+// long-format table with one row per synapse, so that the trajectory of
+// each weight can be plotted with the Syn column as the legend.
+//
+// It also decomposes each weight change into its Hebbian component,
+// from the BCM-like comparison of the short-term receiving activity
+// with its long-term average (scaled by XCal.LLrn or AvgLLrn), and its
+// error-driven component, from the comparison with the medium-term
+// (minus phase) activity (scaled by XCal.MLrn), as computed by the
+// standard leabra DWtStd. Each component is scaled by the same Norm and
+// Momentum factors (if on), learning rate, and soft weight bounding
+// factor as the total, so that they add up to the change in the linear
+// weight (LWt), which makes visible how the mix of the two drives learning.
+// This is exact except when the weight is clipped at 0 or 1. Only the
+// pathways that learn with DWtStd can be recorded, not, e.g., a MatrixPath.
+type WeightLog struct {
+
+	// record the weights; it is off by default
+	On bool
+
+	// names of the pathways to record (e.g., InputToOutput);
+	// all of the pathways of the network if empty
+	Paths []string
+
+	// record at the end of each training epoch instead of each trial,
+	// with the weight change components summed over the trials
+	Epoch bool
+
+	// the network whose weights are recorded
+	Net *leabra.Network `display:"-"`
+
+	// the stats with the TrialName
+	Stats *estats.Stats `display:"-"`
+
+	// the table of recorded weights, with one row per synapse per record:
+	// Run, Epoch, Trial (-1 when recording per epoch), TrialName, Time
+	// (the number of records since the start of the run), Path, Syn
+	// (the sending and receiving layer:unit), Send and Recv unit indexes,
+	// Wt, LWt, DWt (change in LWt since the last record), DWtHebb and
+	// DWtErr (its Hebbian and error-driven components), and CumHebb and
+	// CumErr (their sums since the start of the run)
+	Table *table.Table `display:"-"`
+
+	// the recorded pathways and their per-synapse state
+	paths []*wtLogPath
+
+	// number of records since Reset
+	time int
+
+	// file to write the rows to, if not nil
+	file *os.File
+
+	// whether the column headers have been written to the file
+	wroteHeaders bool
+}
+
+// wtLogPath has the per-synapse state of one pathway of a WeightLog,
+// indexed the same as its Syns.
+type wtLogPath struct {
+	path *leabra.Path
+
+	// LWt at the last record
+	lwt []float32
+
+	// Hebbian and error-driven components of the weight change since the last record
+	hebb, err []float32
+
+	// sums of the components since Reset
+	cumHebb, cumErr []float32
+
+	// components of the synaptic Moment, for Learn.Momentum
+	momHebb, momErr []float32
+
+	// Syn labels and sending and receiving unit indexes
+	syns       []string
+	send, recv []int
+}
+
+// Config configures the table to record the weights of the given network
+// into, which can be a MiscTable of the logs, so that it can be plotted,
+// and adds the looper functions that compute the weight change components
+// just before the "UpdateWeights" function of the training trial
+// (from leabra.LooperSimCycleAndLearn, which must already be added),
+// and record the weights after it, or at the end of the epoch.
+// The TrialName is taken from the given stats. It returns an error if
+// any of the pathways to record does not learn with the standard DWtStd.
+func (wl *WeightLog) Config(net *leabra.Network, stats *estats.Stats, dt *table.Table, ls *looper.Stacks) error {
+	wl.Net = net
+	wl.Stats = stats
+	wl.Table = dt
+	dt.DeleteAll()
+	dt.SetMetaData("name", "Weights")
+	dt.SetMetaData("desc", "Synaptic weights per training trial or epoch")
+	dt.AddIntColumn("Run")
+	dt.AddIntColumn("Epoch")
+	dt.AddIntColumn("Trial")
+	dt.AddStringColumn("TrialName")
+	dt.AddIntColumn("Time")
+	dt.AddStringColumn("Path")
+	dt.AddStringColumn("Syn")
+	dt.AddIntColumn("Send")
+	dt.AddIntColumn("Recv")
+	for _, cn := range []string{"Wt", "LWt", "DWt", "DWtHebb", "DWtErr", "CumHebb", "CumErr"} {
+		dt.AddFloat64Column(cn)
+	}
+	if err := wl.configPaths(); err != nil {
+		return err
+	}
+
+	trn := ls.Stacks[etime.Train]
+	trl := trn.Loops[etime.Trial]
+	trl.OnEnd.InsertBefore("UpdateWeights", "WeightLog:DWt", func() bool {
+		if wl.On {
+			wl.DWt()
+		}
+		return true
+	})
+	trl.OnEnd.Add("WeightLog", func() {
+		if wl.On && !wl.Epoch {
+			errors.Log(wl.Record(trn.Loops[etime.Run].Counter.Cur, trn.Loops[etime.Epoch].Counter.Cur, trl.Counter.Cur, wl.Stats.String("TrialName")))
+		}
+	})
+	trn.Loops[etime.Epoch].OnEnd.Add("WeightLog", func() {
+		if wl.On && wl.Epoch {
+			errors.Log(wl.Record(trn.Loops[etime.Run].Counter.Cur, trn.Loops[etime.Epoch].Counter.Cur, -1, ""))
+		}
+	})
+	return nil
+}
+
+// configPaths configures the per-synapse state of the Paths to record,
+// returning an error for any that do not learn with DWtStd.
+func (wl *WeightLog) configPaths() error {
+	wl.paths = nil
+	for _, ly := range wl.Net.Layers {
+		for _, pt := range ly.RecvPaths {
+			if len(wl.Paths) > 0 && !slices.Contains(wl.Paths, pt.Name) {
+				continue
+			}
+			if !dwtStd(pt) {
+				return fmt.Errorf("WeightLog: path %s of type %s does not learn with the standard DWtStd", pt.Name, pt.Type)
+			}
+			np := len(pt.Syns)
+			lp := &wtLogPath{path: pt, lwt: make([]float32, np), hebb: make([]float32, np), err: make([]float32, np),
+				cumHebb: make([]float32, np), cumErr: make([]float32, np), momHebb: make([]float32, np), momErr: make([]float32, np),
+				syns: make([]string, np), send: make([]int, np), recv: make([]int, np)}
+			for si := range pt.Send.Neurons {
+				nc := int(pt.SConN[si])
+				st := int(pt.SConIndexSt[si])
+				for ci := range nc {
+					ri := int(pt.SConIndex[st+ci])
+					lp.send[st+ci] = si
+					lp.recv[st+ci] = ri
+					lp.syns[st+ci] = fmt.Sprintf("%s:%d->%s:%d", pt.Send.Name, si, pt.Recv.Name, ri)
+				}
+			}
+			wl.paths = append(wl.paths, lp)
+		}
+	}
+	return nil
+}
+
+// dwtStd returns true if the given path learns with DWtStd,
+// as in leabra Path.DWt and WtFromDWt.
+func dwtStd(pt *leabra.Path) bool {
+	switch pt.Type {
+	case leabra.CHLPath:
+		return !pt.CHL.On
+	case leabra.CTCtxtPath, leabra.EcCa1Path, leabra.MatrixPath, leabra.RWPath, leabra.TDPredPath, leabra.DaHebbPath:
+		return false
+	}
+	return true
+}
+
+// Reset removes all of the recorded rows, and starts the weight change
+// components from the current weights, e.g., at the start of a new run
+// after the weights are initialized, which also resets their Moment.
+func (wl *WeightLog) Reset() {
+	if wl.Table == nil {
+		return
+	}
+	wl.Table.SetNumRows(0)
+	wl.time = 0
+	for _, lp := range wl.paths {
+		for i := range lp.path.Syns {
+			lp.lwt[i] = lp.path.Syns[i].LWt
+			lp.hebb[i], lp.err[i] = 0, 0
+			lp.cumHebb[i], lp.cumErr[i] = 0, 0
+			lp.momHebb[i], lp.momErr[i] = 0, 0
+		}
+	}
+}
+
+// DWt adds the Hebbian and error-driven components of the weight changes
+// for the current trial, computed as in the standard leabra DWtStd and
+// WtFromDWt, which must be called before the weights are updated.
+func (wl *WeightLog) DWt() {
+	for _, lp := range wl.paths {
+		pt := lp.path
+		if !pt.Learn.Learn {
+			continue
+		}
+		ln := &pt.Learn
+		xc := &ln.XCal
+		slay := pt.Send
+		rlay := pt.Recv
+		for si := range slay.Neurons {
+			sn := &slay.Neurons[si]
+			if sn.AvgS < xc.LrnThr && sn.AvgM < xc.LrnThr {
+				continue
+			}
+			nc := int(pt.SConN[si])
+			st := int(pt.SConIndexSt[si])
+			for ci := range nc {
+				i := st + ci
+				ri := pt.SConIndex[i]
+				rn := &rlay.Neurons[ri]
+				sy := &pt.Syns[i]
+				err, hebb := ln.CHLdWt(sn.AvgSLrn, sn.AvgM, rn.AvgSLrn, rn.AvgM, rn.AvgL)
+				hebb *= xc.LongLrate(rn.AvgLLrn)
+				err *= xc.MLrn
+				norm := float32(1)
+				if ln.Norm.On { // as in NormFromAbsDWt, without updating sy.Norm
+					if n := math32.Max(ln.Norm.DecayDtC*sy.Norm, math32.Abs(hebb+err)); n != 0 {
+						norm = ln.Norm.LrComp / math32.Max(n, ln.Norm.NormMin)
+					}
+				}
+				if ln.Momentum.On { // as in MomentFromDWt, for each component of sy.Moment
+					lp.momHebb[i] = ln.Momentum.MDtC*lp.momHebb[i] + hebb
+					lp.momErr[i] = ln.Momentum.MDtC*lp.momErr[i] + err
+					hebb = ln.Momentum.LrComp * lp.momHebb[i]
+					err = ln.Momentum.LrComp * lp.momErr[i]
+				}
+				hebb *= ln.Lrate * norm
+				err *= ln.Lrate * norm
+				var bound float32
+				inc, dec := float32(1), float32(1)
+				if ln.WtBal.On {
+					inc, dec = pt.WbRecv[ri].Inc, pt.WbRecv[ri].Dec
+				}
+				lwt := sy.LWt
+				if hebb+err > 0 {
+					bound = inc
+					if ln.WtSig.SoftBound {
+						bound *= 1 - lwt
+					}
+				} else {
+					bound = dec
+					if ln.WtSig.SoftBound {
+						bound *= lwt
+					}
+				}
+				lp.hebb[i] += bound * hebb
+				lp.err[i] += bound * err
+			}
+		}
+	}
+}
+
+// Record adds a row for each synapse with its current weights and their
+// change since the last record, for the given counters, and writes them
+// to the file if one is set, returning any error in writing them.
+func (wl *WeightLog) Record(run, epoch, trial int, trialName string) error {
+	dt := wl.Table
+	start := dt.Rows
+	for _, lp := range wl.paths {
+		for i := range lp.path.Syns {
+			sy := &lp.path.Syns[i]
+			lp.cumHebb[i] += lp.hebb[i]
+			lp.cumErr[i] += lp.err[i]
+			row := dt.Rows
+			dt.SetNumRows(row + 1)
+			dt.SetFloat("Run", row, float64(run))
+			dt.SetFloat("Epoch", row, float64(epoch))
+			dt.SetFloat("Trial", row, float64(trial))
+			dt.SetString("TrialName", row, trialName)
+			dt.SetFloat("Time", row, float64(wl.time))
+			dt.SetString("Path", row, lp.path.Name)
+			dt.SetString("Syn", row, lp.syns[i])
+			dt.SetFloat("Send", row, float64(lp.send[i]))
+			dt.SetFloat("Recv", row, float64(lp.recv[i]))
+			dt.SetFloat("Wt", row, float64(sy.Wt))
+			dt.SetFloat("LWt", row, float64(sy.LWt))
+			dt.SetFloat("DWt", row, float64(sy.LWt-lp.lwt[i]))
+			dt.SetFloat("DWtHebb", row, float64(lp.hebb[i]))
+			dt.SetFloat("DWtErr", row, float64(lp.err[i]))
+			dt.SetFloat("CumHebb", row, float64(lp.cumHebb[i]))
+			dt.SetFloat("CumErr", row, float64(lp.cumErr[i]))
+			lp.lwt[i] = sy.LWt
+			lp.hebb[i], lp.err[i] = 0, 0
+		}
+	}
+	wl.time++
+	return wl.writeRows(start)
+}
+
+// writeRows writes the rows of the Table from start to the file, if one
+// is set, with the column headers first if they have not been written.
+// The file is closed if there is an error, so that it is only reported once.
+func (wl *WeightLog) writeRows(start int) error {
+	if wl.file == nil {
+		return nil
+	}
+	dt := wl.Table
+	var err error
+	if !wl.wroteHeaders {
+		_, err = dt.WriteCSVHeaders(wl.file, table.Tab)
+		wl.wroteHeaders = true
+	}
+	for row := start; row < dt.Rows && err == nil; row++ {
+		err = dt.WriteCSVRow(wl.file, row, table.Tab)
+	}
+	if err != nil {
+		fnm := wl.file.Name()
+		wl.CloseFile()
+		return fmt.Errorf("WeightLog: writing to %s: %w", fnm, err)
+	}
+	return nil
+}
+
+// SetFile sets the file to write the recorded rows of all of the runs to,
+// using the standard log file name with the given log name (e.g., wts),
+// as with elog.SetLogFile, if configOn is true.
+func (wl *WeightLog) SetFile(configOn bool, logName, netName, runName string) error {
+	if !configOn {
+		return nil
+	}
+	fnm := elog.LogFilename(logName, netName, runName)
+	f, err := os.Create(fnm)
+	if err != nil {
+		return fmt.Errorf("WeightLog: %w", err)
+	}
+	wl.file = f
+	wl.wroteHeaders = false
+	fmt.Printf("Saving log to: %s\n", fnm)
+	return nil
+}
+
+// CloseFile closes the file set by SetFile, if any.
+func (wl *WeightLog) CloseFile() error {
+	if wl.file == nil {
+		return nil
+	}
+	err := wl.file.Close()
+	wl.file = nil
+	return err
+}